@@ -0,0 +1,124 @@
+package account
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/middlewares"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/labstack/echo/v4"
+)
+
+type AccountService interface {
+	UpdateProfile(ctx context.Context, user *entities.User, req *request.UpdateUser) (*response.User, error)
+	ChangePassword(ctx context.Context, user *entities.User, current uuid.UUID, req *request.ChangePassword) error
+	DeleteAccount(ctx context.Context, user *entities.User, req *request.DeleteAccount) (*response.AccountDeletion, error)
+}
+
+// TokenCookies clears the token cookies of a deleted account in cookie mode.
+type TokenCookies interface {
+	Enabled() bool
+	Clear(c echo.Context)
+}
+
+type Handler struct {
+	accountService AccountService
+	cookies        TokenCookies
+}
+
+func NewHandler(accountService AccountService, cookies TokenCookies) *Handler {
+	return &Handler{
+		accountService: accountService,
+		cookies:        cookies,
+	}
+}
+
+func (h *Handler) GetProfile(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, response.ToUserResponse(user))
+}
+
+func (h *Handler) UpdateProfile(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.UpdateUser
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid request body"},
+		)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	result, err := h.accountService.UpdateProfile(c.Request().Context(), user, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) ChangePassword(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.ChangePassword
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid request body"},
+		)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.accountService.ChangePassword(c.Request().Context(), user, middlewares.GetAuthenticatedSession(c), &req); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) DeleteAccount(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.DeleteAccount
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid request body"},
+		)
+	}
+
+	result, err := h.accountService.DeleteAccount(c.Request().Context(), user, &req)
+	if err != nil {
+		return err
+	}
+	if h.cookies.Enabled() {
+		h.cookies.Clear(c)
+	}
+
+	return c.JSON(http.StatusAccepted, result)
+}
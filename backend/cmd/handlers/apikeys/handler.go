@@ -0,0 +1,94 @@
+package apikeys
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/middlewares"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/labstack/echo/v4"
+)
+
+const codeParam = "code"
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, user *entities.User, req *request.CreateAPIKey) (*response.CreatedAPIKey, error)
+	ListAPIKeys(ctx context.Context, user *entities.User) ([]*response.APIKey, error)
+	RevokeAPIKey(ctx context.Context, user *entities.User, code uuid.UUID) error
+}
+
+type Handler struct {
+	apiKeyService APIKeyService
+}
+
+func NewHandler(apiKeyService APIKeyService) *Handler {
+	return &Handler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+func (h *Handler) CreateAPIKey(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.CreateAPIKey
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid request body"},
+		)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	result, err := h.apiKeyService.CreateAPIKey(c.Request().Context(), user, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, result)
+}
+
+func (h *Handler) ListAPIKeys(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.apiKeyService.ListAPIKeys(c.Request().Context(), user)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) RevokeAPIKey(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	code, err := uuid.Parse(c.Param(codeParam))
+	if err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid API key code"},
+		)
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(c.Request().Context(), user, code); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
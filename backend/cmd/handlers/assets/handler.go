@@ -0,0 +1,153 @@
+package assets
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/middlewares"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/labstack/echo/v4"
+)
+
+const codeParam = "code"
+
+type AssetService interface {
+	CreateAsset(ctx context.Context, userID uint64, req *request.CreateAsset) (*response.Asset, error)
+	GetAsset(ctx context.Context, userID uint64, code uuid.UUID) (*response.Asset, error)
+	ListAssets(ctx context.Context, userID uint64) ([]*response.Asset, error)
+	UpdateAsset(ctx context.Context, userID uint64, code uuid.UUID, req *request.UpdateAsset) (*response.Asset, error)
+	DeleteAsset(ctx context.Context, userID uint64, code uuid.UUID) error
+}
+
+type Handler struct {
+	assetService AssetService
+}
+
+func NewHandler(assetService AssetService) *Handler {
+	return &Handler{
+		assetService: assetService,
+	}
+}
+
+func (h *Handler) CreateAsset(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.CreateAsset
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid request body"},
+		)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	result, err := h.assetService.CreateAsset(c.Request().Context(), user.ID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, result)
+}
+
+func (h *Handler) GetAsset(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	code, err := parseCode(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.assetService.GetAsset(c.Request().Context(), user.ID, code)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) ListAssets(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.assetService.ListAssets(c.Request().Context(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) UpdateAsset(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	code, err := parseCode(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.UpdateAsset
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid request body"},
+		)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	result, err := h.assetService.UpdateAsset(c.Request().Context(), user.ID, code, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) DeleteAsset(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	code, err := parseCode(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.assetService.DeleteAsset(c.Request().Context(), user.ID, code); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func parseCode(c echo.Context) (uuid.UUID, error) {
+	code, err := uuid.Parse(c.Param(codeParam))
+	if err != nil {
+		return uuid.Nil, errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid asset code"},
+		)
+	}
+	return code, nil
+}
@@ -2,28 +2,66 @@ package auth
 
 import (
 	"context"
+	libErrors "errors"
+	"math"
 	"net/http"
+	"strconv"
 
+	"github.com/google/uuid"
 	"github.com/juanMaAV92/go-utils/errors"
-	"github.com/juanMaAV92/go-utils/headers"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/middlewares"
 	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
 	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/lockout"
 	"github.com/labstack/echo/v4"
 )
 
 type AuthService interface {
 	Login(ctx context.Context, req *request.UserLogin) (*response.UserLogin, error)
+	LoginMFA(ctx context.Context, req *request.MFALogin) (*response.UserLogin, error)
 	Logout(ctx context.Context, authHeader string) error
+	LogoutSession(ctx context.Context, refreshToken string) error
 	RefreshToken(ctx context.Context, refreshToekn string) (*response.TokensResponse, error)
+	ListSessions(ctx context.Context, user *entities.User, current uuid.UUID) ([]*response.Session, error)
+	RevokeSession(ctx context.Context, user *entities.User, code uuid.UUID) error
+	RevokeOtherSessions(ctx context.Context, user *entities.User, current uuid.UUID) error
+	LogoutEverywhere(ctx context.Context, user *entities.User) error
 }
 
+type OIDCService interface {
+	Authorize(ctx context.Context, provider string) (*response.OIDCAuthorization, error)
+	Callback(ctx context.Context, req *request.OIDCCallback) (*response.UserLogin, error)
+}
+
+// TokenCookies delivers the tokens as cookies to browser clients when cookie
+// mode is enabled.
+type TokenCookies interface {
+	Enabled() bool
+	SetTokens(c echo.Context, accessToken, refreshToken string) error
+	Clear(c echo.Context)
+	AccessToken(c echo.Context) string
+	RefreshToken(c echo.Context) string
+	ValidCSRF(c echo.Context) bool
+}
+
+const (
+	sessionCodeParam = "code"
+	providerParam    = "provider"
+	retryAfterHeader = "Retry-After"
+)
+
 type Handler struct {
 	authService AuthService
+	oidcService OIDCService
+	cookies     TokenCookies
 }
 
-func NewHandler(authService AuthService) *Handler {
+func NewHandler(authService AuthService, oidcService OIDCService, cookies TokenCookies) *Handler {
 	return &Handler{
 		authService: authService,
+		oidcService: oidcService,
+		cookies:     cookies,
 	}
 }
 
@@ -37,8 +75,46 @@ func (h *Handler) Login(c echo.Context) error {
 			[]string{"Invalid request body"},
 		)
 	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+	req.UserAgent = c.Request().UserAgent()
+	req.IPAddress = c.RealIP()
 
 	result, err := h.authService.Login(c.Request().Context(), &req)
+	if err != nil {
+		return withRetryAfter(c, err)
+	}
+
+	return h.loginResponse(c, result)
+}
+
+func (h *Handler) LoginMFA(c echo.Context) error {
+	var req request.MFALogin
+
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid request body"},
+		)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+	req.UserAgent = c.Request().UserAgent()
+	req.IPAddress = c.RealIP()
+
+	result, err := h.authService.LoginMFA(c.Request().Context(), &req)
+	if err != nil {
+		return withRetryAfter(c, err)
+	}
+
+	return h.loginResponse(c, result)
+}
+
+func (h *Handler) OIDCAuthorize(c echo.Context) error {
+	result, err := h.oidcService.Authorize(c.Request().Context(), c.Param(providerParam))
 	if err != nil {
 		return err
 	}
@@ -46,39 +122,193 @@ func (h *Handler) Login(c echo.Context) error {
 	return c.JSON(http.StatusOK, result)
 }
 
-func (h *Handler) Logout(c echo.Context) error {
-	var authHeader string
-	if authHeader = c.Request().Header.Get(headers.Authorization); authHeader == "" {
+func (h *Handler) OIDCCallback(c echo.Context) error {
+	var req request.OIDCCallback
+
+	if err := c.Bind(&req); err != nil {
 		return errors.New(
-			http.StatusUnauthorized,
-			errors.StatusUnauthorizedCode,
-			[]string{"Authorization header is required"},
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid request body"},
 		)
 	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+	req.UserAgent = c.Request().UserAgent()
+	req.IPAddress = c.RealIP()
 
-	err := h.authService.Logout(c.Request().Context(), authHeader)
+	result, err := h.oidcService.Callback(c.Request().Context(), &req)
 	if err != nil {
 		return err
 	}
 
+	return h.loginResponse(c, result)
+}
+
+// Logout ends the session of the access token. In cookie mode the cookies are
+// cleared whatever the outcome, and the refresh cookie ends the session once
+// the access cookie has expired.
+func (h *Handler) Logout(c echo.Context) error {
+	ctx := c.Request().Context()
+	if h.cookies.Enabled() {
+		h.cookies.Clear(c)
+	}
+
+	token, err := middlewares.AccessToken(c, h.cookies)
+	if err == nil {
+		err = h.authService.Logout(ctx, token)
+	}
+	if err != nil {
+		// The access cookie expires long before the refresh one, which must
+		// not outlive the logout.
+		refreshToken := h.cookies.RefreshToken(c)
+		if refreshToken == "" {
+			return err
+		}
+		if !h.cookies.ValidCSRF(c) {
+			return middlewares.InvalidCSRFToken()
+		}
+		if err := h.authService.LogoutSession(ctx, refreshToken); err != nil {
+			return err
+		}
+	}
+
 	return c.JSON(http.StatusOK, map[string]string{"message": "Logout successful"})
 }
 
+// RefreshToken reads the refresh token from its cookie in cookie mode, and from
+// the body otherwise.
 func (h *Handler) RefreshToken(c echo.Context) error {
-	var req request.RefreshToken
+	refreshToken := h.cookies.RefreshToken(c)
+	if refreshToken != "" {
+		if !h.cookies.ValidCSRF(c) {
+			return middlewares.InvalidCSRFToken()
+		}
+	} else {
+		var req request.RefreshToken
 
-	if err := c.Bind(&req); err != nil {
+		if err := c.Bind(&req); err != nil {
+			return errors.New(
+				http.StatusBadRequest,
+				errors.StatusBadRequestCode,
+				[]string{"Invalid request body"},
+			)
+		}
+		if err := c.Validate(&req); err != nil {
+			return err
+		}
+		refreshToken = req.RefreshToken
+	}
+
+	result, err := h.authService.RefreshToken(c.Request().Context(), refreshToken)
+	if err != nil {
+		return err
+	}
+
+	if h.cookies.Enabled() {
+		if err := h.setTokenCookies(c, result); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) ListSessions(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.authService.ListSessions(c.Request().Context(), user, middlewares.GetAuthenticatedSession(c))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) RevokeSession(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	code, err := uuid.Parse(c.Param(sessionCodeParam))
+	if err != nil {
 		return errors.New(
 			http.StatusBadRequest,
 			errors.StatusBadRequestCode,
-			[]string{"Invalid request body"},
+			[]string{"Invalid session code"},
 		)
 	}
 
-	result, err := h.authService.RefreshToken(c.Request().Context(), req.RefreshToken)
+	if err := h.authService.RevokeSession(c.Request().Context(), user, code); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) RevokeOtherSessions(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.authService.RevokeOtherSessions(c.Request().Context(), user, middlewares.GetAuthenticatedSession(c)); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) LogoutEverywhere(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
 	if err != nil {
 		return err
 	}
 
+	if err := h.authService.LogoutEverywhere(c.Request().Context(), user); err != nil {
+		return err
+	}
+	if h.cookies.Enabled() {
+		h.cookies.Clear(c)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Logout successful"})
+}
+
+// loginResponse moves the tokens of a completed login to cookies in cookie mode,
+// so they never reach the JavaScript of the frontend.
+func (h *Handler) loginResponse(c echo.Context, result *response.UserLogin) error {
+	if h.cookies.Enabled() && result.TokensResponse != nil {
+		if err := h.setTokenCookies(c, result.TokensResponse); err != nil {
+			return err
+		}
+		result.TokensResponse = nil
+	}
+
 	return c.JSON(http.StatusOK, result)
 }
+
+func (h *Handler) setTokenCookies(c echo.Context, tokens *response.TokensResponse) error {
+	if err := h.cookies.SetTokens(c, tokens.AccessToken, tokens.RefreshToken); err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	return nil
+}
+
+// withRetryAfter sets the Retry-After header of throttled logins, in whole
+// seconds, and returns the response to render.
+func withRetryAfter(c echo.Context, err error) error {
+	var retryError *lockout.RetryError
+	if !libErrors.As(err, &retryError) {
+		return err
+	}
+	seconds := int(math.Ceil(retryError.RetryAfter.Seconds()))
+	c.Response().Header().Set(retryAfterHeader, strconv.Itoa(seconds))
+	return retryError.Err
+}
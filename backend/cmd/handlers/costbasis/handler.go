@@ -0,0 +1,55 @@
+package costbasis
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/middlewares"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	assetCodeParam = "code"
+	methodQuery    = "method"
+)
+
+type CostBasisService interface {
+	GetAssetCostBasis(ctx context.Context, user *entities.User, assetCode uuid.UUID, method string) (*response.CostBasis, error)
+}
+
+type Handler struct {
+	costBasisService CostBasisService
+}
+
+func NewHandler(costBasisService CostBasisService) *Handler {
+	return &Handler{
+		costBasisService: costBasisService,
+	}
+}
+
+func (h *Handler) GetAssetCostBasis(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	assetCode, err := uuid.Parse(c.Param(assetCodeParam))
+	if err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid asset code"},
+		)
+	}
+
+	result, err := h.costBasisService.GetAssetCostBasis(c.Request().Context(), user, assetCode, c.QueryParam(methodQuery))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,68 @@
+package emailverification
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/labstack/echo/v4"
+)
+
+type EmailVerificationService interface {
+	VerifyEmail(ctx context.Context, req *request.VerifyEmail) error
+	ResendVerification(ctx context.Context, req *request.ResendVerification) error
+}
+
+type Handler struct {
+	emailVerificationService EmailVerificationService
+}
+
+func NewHandler(emailVerificationService EmailVerificationService) *Handler {
+	return &Handler{
+		emailVerificationService: emailVerificationService,
+	}
+}
+
+func (h *Handler) VerifyEmail(c echo.Context) error {
+	var req request.VerifyEmail
+
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid request body"},
+		)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.emailVerificationService.VerifyEmail(c.Request().Context(), &req); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Email verified"})
+}
+
+// ResendVerification answers the same whether the email is registered or not.
+func (h *Handler) ResendVerification(c echo.Context) error {
+	var req request.ResendVerification
+
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid request body"},
+		)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.emailVerificationService.ResendVerification(c.Request().Context(), &req); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]string{"message": "If the email is pending verification, a new link has been sent"})
+}
@@ -0,0 +1,109 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/middlewares"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	codeParam      = "code"
+	zipContentType = "application/zip"
+)
+
+type ExportService interface {
+	Export(ctx context.Context, user *entities.User) (*response.ExportArchive, *response.Export, error)
+	GetExport(ctx context.Context, user *entities.User, code uuid.UUID) (*response.Export, error)
+	Download(ctx context.Context, user *entities.User, code uuid.UUID) (*response.ExportArchive, error)
+}
+
+type Handler struct {
+	exportService ExportService
+}
+
+func NewHandler(exportService ExportService) *Handler {
+	return &Handler{
+		exportService: exportService,
+	}
+}
+
+// Export responds with the archive of small accounts, and with 202 and the
+// export to poll for larger ones.
+func (h *Handler) Export(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	archive, pending, err := h.exportService.Export(c.Request().Context(), user)
+	if err != nil {
+		return err
+	}
+	if archive != nil {
+		return attachment(c, archive)
+	}
+
+	return c.JSON(http.StatusAccepted, pending)
+}
+
+func (h *Handler) GetExport(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	code, err := exportCode(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.exportService.GetExport(c.Request().Context(), user, code)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) Download(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	code, err := exportCode(c)
+	if err != nil {
+		return err
+	}
+
+	archive, err := h.exportService.Download(c.Request().Context(), user, code)
+	if err != nil {
+		return err
+	}
+
+	return attachment(c, archive)
+}
+
+func attachment(c echo.Context, archive *response.ExportArchive) error {
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", archive.Filename))
+	return c.Blob(http.StatusOK, zipContentType, archive.Content)
+}
+
+func exportCode(c echo.Context) (uuid.UUID, error) {
+	code, err := uuid.Parse(c.Param(codeParam))
+	if err != nil {
+		return uuid.Nil, errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid export code"},
+		)
+	}
+	return code, nil
+}
@@ -0,0 +1,46 @@
+package fx
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/labstack/echo/v4"
+)
+
+type FxService interface {
+	ConvertAmount(ctx context.Context, req *request.ConvertAmount) (*response.Conversion, error)
+}
+
+type Handler struct {
+	fxService FxService
+}
+
+func NewHandler(fxService FxService) *Handler {
+	return &Handler{
+		fxService: fxService,
+	}
+}
+
+func (h *Handler) Convert(c echo.Context) error {
+	var req request.ConvertAmount
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid query parameters"},
+		)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	result, err := h.fxService.ConvertAmount(c.Request().Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,31 @@
+package jwks
+
+import (
+	"net/http"
+
+	"github.com/juanMaAV92/zenith-financial/backend/utils/tokens"
+	"github.com/labstack/echo/v4"
+)
+
+// cacheControl lets verifiers cache the keys for a while; a new key is
+// published before it becomes active, so they pick it up in time.
+const cacheControl = "public, max-age=300"
+
+type KeySet interface {
+	JWKS() tokens.JWKS
+}
+
+type Handler struct {
+	keys KeySet
+}
+
+func NewHandler(keys KeySet) *Handler {
+	return &Handler{
+		keys: keys,
+	}
+}
+
+func (h *Handler) GetJWKS(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderCacheControl, cacheControl)
+	return c.JSON(http.StatusOK, h.keys.JWKS())
+}
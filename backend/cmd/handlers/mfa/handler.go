@@ -0,0 +1,94 @@
+package mfa
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/middlewares"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/labstack/echo/v4"
+)
+
+type MFAService interface {
+	Enroll(ctx context.Context, user *entities.User) (*response.MFAEnrollment, error)
+	Confirm(ctx context.Context, user *entities.User, req *request.MFACode) (*response.MFARecoveryCodes, error)
+	Disable(ctx context.Context, user *entities.User, req *request.MFACode) error
+}
+
+type Handler struct {
+	mfaService MFAService
+}
+
+func NewHandler(mfaService MFAService) *Handler {
+	return &Handler{
+		mfaService: mfaService,
+	}
+}
+
+func (h *Handler) Enroll(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.mfaService.Enroll(c.Request().Context(), user)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) Confirm(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.MFACode
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid request body"},
+		)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	result, err := h.mfaService.Confirm(c.Request().Context(), user, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) Disable(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.MFACode
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid request body"},
+		)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.mfaService.Disable(c.Request().Context(), user, &req); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Two-factor authentication disabled"})
+}
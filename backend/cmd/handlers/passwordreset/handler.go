@@ -0,0 +1,68 @@
+package passwordreset
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/labstack/echo/v4"
+)
+
+type PasswordResetService interface {
+	ForgotPassword(ctx context.Context, req *request.ForgotPassword) error
+	ResetPassword(ctx context.Context, req *request.ResetPassword) error
+}
+
+type Handler struct {
+	passwordResetService PasswordResetService
+}
+
+func NewHandler(passwordResetService PasswordResetService) *Handler {
+	return &Handler{
+		passwordResetService: passwordResetService,
+	}
+}
+
+// ForgotPassword answers the same whether the email is registered or not.
+func (h *Handler) ForgotPassword(c echo.Context) error {
+	var req request.ForgotPassword
+
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid request body"},
+		)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.passwordResetService.ForgotPassword(c.Request().Context(), &req); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]string{"message": "If the email is registered, a reset link has been sent"})
+}
+
+func (h *Handler) ResetPassword(c echo.Context) error {
+	var req request.ResetPassword
+
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid request body"},
+		)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.passwordResetService.ResetPassword(c.Request().Context(), &req); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Password updated"})
+}
@@ -0,0 +1,53 @@
+package performance
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/middlewares"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/labstack/echo/v4"
+)
+
+type PerformanceService interface {
+	GetPerformance(ctx context.Context, user *entities.User, req *request.PortfolioPerformance) (*response.PortfolioPerformance, error)
+}
+
+type Handler struct {
+	performanceService PerformanceService
+}
+
+func NewHandler(performanceService PerformanceService) *Handler {
+	return &Handler{
+		performanceService: performanceService,
+	}
+}
+
+func (h *Handler) GetPerformance(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.PortfolioPerformance
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid query parameters"},
+		)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	result, err := h.performanceService.GetPerformance(c.Request().Context(), user, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,39 @@
+package portfolio
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/middlewares"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/labstack/echo/v4"
+)
+
+type PortfolioService interface {
+	GetSummary(ctx context.Context, user *entities.User) (*response.PortfolioSummary, error)
+}
+
+type Handler struct {
+	portfolioService PortfolioService
+}
+
+func NewHandler(portfolioService PortfolioService) *Handler {
+	return &Handler{
+		portfolioService: portfolioService,
+	}
+}
+
+func (h *Handler) GetSummary(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.portfolioService.GetSummary(c.Request().Context(), user)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
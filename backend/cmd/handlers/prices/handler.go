@@ -0,0 +1,96 @@
+package prices
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/middlewares"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	assetCodeParam = "code"
+	fromQuery      = "from"
+	toQuery        = "to"
+)
+
+type PriceHistoryService interface {
+	AddPrice(ctx context.Context, userID uint64, assetCode uuid.UUID, req *request.CreateAssetPrice) (*response.PricePoint, error)
+	GetPriceSeries(ctx context.Context, userID uint64, assetCode uuid.UUID, from, to string) (*response.PriceSeries, error)
+}
+
+type Handler struct {
+	priceHistoryService PriceHistoryService
+}
+
+func NewHandler(priceHistoryService PriceHistoryService) *Handler {
+	return &Handler{
+		priceHistoryService: priceHistoryService,
+	}
+}
+
+func (h *Handler) AddPrice(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	assetCode, err := parseAssetCode(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.CreateAssetPrice
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid request body"},
+		)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	result, err := h.priceHistoryService.AddPrice(c.Request().Context(), user.ID, assetCode, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, result)
+}
+
+func (h *Handler) GetPriceSeries(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	assetCode, err := parseAssetCode(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.priceHistoryService.GetPriceSeries(c.Request().Context(), user.ID, assetCode, c.QueryParam(fromQuery), c.QueryParam(toQuery))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func parseAssetCode(c echo.Context) (uuid.UUID, error) {
+	code, err := uuid.Parse(c.Param(assetCodeParam))
+	if err != nil {
+		return uuid.Nil, errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid asset code"},
+		)
+	}
+	return code, nil
+}
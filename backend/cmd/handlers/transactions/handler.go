@@ -0,0 +1,92 @@
+package transactions
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/middlewares"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/labstack/echo/v4"
+)
+
+const assetCodeParam = "code"
+
+type TransactionService interface {
+	CreateTransaction(ctx context.Context, userID uint64, assetCode uuid.UUID, req *request.CreateTransaction) (*response.TransactionResult, error)
+	ListTransactions(ctx context.Context, userID uint64, assetCode uuid.UUID) ([]*response.Transaction, error)
+}
+
+type Handler struct {
+	transactionService TransactionService
+}
+
+func NewHandler(transactionService TransactionService) *Handler {
+	return &Handler{
+		transactionService: transactionService,
+	}
+}
+
+func (h *Handler) CreateTransaction(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	assetCode, err := parseAssetCode(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.CreateTransaction
+	if err := c.Bind(&req); err != nil {
+		return errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid request body"},
+		)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	result, err := h.transactionService.CreateTransaction(c.Request().Context(), user.ID, assetCode, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, result)
+}
+
+func (h *Handler) ListTransactions(c echo.Context) error {
+	user, err := middlewares.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	assetCode, err := parseAssetCode(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.transactionService.ListTransactions(c.Request().Context(), user.ID, assetCode)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func parseAssetCode(c echo.Context) (uuid.UUID, error) {
+	code, err := uuid.Parse(c.Param(assetCodeParam))
+	if err != nil {
+		return uuid.Nil, errors.New(
+			http.StatusBadRequest,
+			errors.StatusBadRequestCode,
+			[]string{"Invalid asset code"},
+		)
+	}
+	return code, nil
+}
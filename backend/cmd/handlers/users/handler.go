@@ -34,6 +34,9 @@ func (h *Handler) CreateUser(c echo.Context) error {
 			[]string{"Invalid request body"},
 		)
 	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
 
 	result, err := h.userService.CreateUser(c.Request().Context(), &req)
 	if err != nil {
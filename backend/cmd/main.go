@@ -14,10 +14,11 @@ const (
 	initServerStep = "init_server_step"
 	shutdownStep   = "shutdown_server_step"
 
-	errStartingMsg         = "Error starting server"
-	errStartingServicesMsg = "Error starting services"
-	errRunningMsg          = "Error while running server"
-	errInitTracingMsg      = "Error initializing tracing"
+	errStartingMsg          = "Error starting server"
+	errStartingServicesMsg  = "Error starting services"
+	errRunningMsg           = "Error while running server"
+	errInitTracingMsg       = "Error initializing tracing"
+	errStoppingSchedulerMsg = "Error stopping scheduler"
 )
 
 const (
@@ -59,11 +60,20 @@ func Start() {
 
 	configRoutes(srv, svc)
 
+	svc.scheduler.Start(ctx)
 	errC := srv.Run(cfg.Port, cfg.GracefulTime)
 
 	logger.Info(ctx, "Server started successfully", "")
 
-	if errS := <-errC; errS != nil {
+	errS := <-errC
+
+	stopCtx, cancel := context.WithTimeout(ctx, cfg.GracefulTime)
+	if err := svc.scheduler.Stop(stopCtx); err != nil {
+		logger.Error(ctx, shutdownStep, errStoppingSchedulerMsg, log.Field("error", err))
+	}
+	cancel()
+
+	if errS != nil {
 		logger.Fatal(ctx, errRunningMsg, errS.Error())
 		os.Exit(exitCodeFailRunningServer)
 	}
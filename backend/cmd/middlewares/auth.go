@@ -0,0 +1,195 @@
+package middlewares
+
+import (
+	"context"
+	libErrors "errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/headers"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/tokens"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	AuthenticatedUserKey    = "authenticated_user"
+	AuthenticatedSessionKey = "authenticated_session"
+	AuthenticatedScopesKey  = "authenticated_scopes"
+	APIKeyHeader            = "X-API-Key"
+
+	bearerPrefix    = "Bearer "
+	scopeGrantedKey = "scope_granted"
+
+	invalidCSRFTokenCode  = "INVALID_CSRF_TOKEN"
+	insufficientScopeCode = "INSUFFICIENT_SCOPE"
+)
+
+type userRepository interface {
+	GetByCode(ctx context.Context, code uuid.UUID) (*entities.User, error)
+}
+
+type tokenParser interface {
+	ParseAccessToken(token string) (*tokens.Claims, error)
+}
+
+type tokenDenylist interface {
+	IsRevoked(ctx context.Context, tokenID, sessionID string) (bool, error)
+}
+
+type tokenCookies interface {
+	AccessToken(c echo.Context) string
+	ValidCSRF(c echo.Context) bool
+}
+
+type apiKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*entities.User, []string, error)
+}
+
+// Authenticate accepts access tokens that were neither denylisted nor issued
+// before the last bump of the token version of their user. The token is read
+// from the Authorization header or, in cookie mode, from the access token
+// cookie, which requires the CSRF token on mutating requests.
+//
+// API keys are accepted instead of access tokens, in the X-API-Key header or as
+// bearer tokens. Their requests only reach the routes that RequireScope one of
+// the scopes of the key.
+func Authenticate(userRepo userRepository, parser tokenParser, denylist tokenDenylist, cookies tokenCookies, apiKeys apiKeyAuthenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if key := apiKey(c); key != "" {
+				user, scopes, err := apiKeys.Authenticate(c.Request().Context(), key)
+				if err != nil {
+					return err
+				}
+				c.Set(AuthenticatedUserKey, user)
+				c.Set(AuthenticatedScopesKey, scopes)
+				return next(c)
+			}
+
+			token, err := AccessToken(c, cookies)
+			if err != nil {
+				return err
+			}
+
+			claims, err := parser.ParseAccessToken(token)
+			switch {
+			case libErrors.Is(err, tokens.ErrExpiredToken):
+				return unauthorized("Token expired")
+			case libErrors.Is(err, tokens.ErrInvalidTokenType):
+				return unauthorized("Invalid token type")
+			case err != nil:
+				return unauthorized("Invalid token")
+			}
+
+			userCode, err := uuid.Parse(claims.UserCode)
+			if err != nil {
+				return unauthorized("Invalid user code in token")
+			}
+
+			revoked, err := denylist.IsRevoked(c.Request().Context(), claims.ID, claims.SessionID)
+			if err != nil {
+				return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+			}
+			if revoked {
+				return unauthorized("Token has been revoked")
+			}
+
+			user, err := userRepo.GetByCode(c.Request().Context(), userCode)
+			if err != nil {
+				return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+			}
+			if user == nil || claims.TokenVersion < user.TokenVersion {
+				return unauthorized("Token has been revoked")
+			}
+
+			sessionCode, _ := uuid.Parse(claims.SessionID)
+
+			c.Set(AuthenticatedUserKey, user)
+			c.Set(AuthenticatedSessionKey, sessionCode)
+			return next(c)
+		}
+	}
+}
+
+// AccessToken returns the bearer token of the request, falling back to the
+// access token cookie. Cookie authenticated requests must pass the CSRF check.
+func AccessToken(c echo.Context, cookies tokenCookies) (string, error) {
+	if authHeader := c.Request().Header.Get(headers.Authorization); authHeader != "" {
+		return strings.TrimPrefix(authHeader, bearerPrefix), nil
+	}
+
+	token := cookies.AccessToken(c)
+	if token == "" {
+		return "", unauthorized("Authorization header is required")
+	}
+	if !cookies.ValidCSRF(c) {
+		return "", InvalidCSRFToken()
+	}
+	return token, nil
+}
+
+// RequireScope lets API keys reach a route only when they were granted the
+// scope. Access tokens are not limited by scopes.
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if scopes, ok := c.Get(AuthenticatedScopesKey).([]string); ok {
+				if !slices.Contains(scopes, scope) {
+					return insufficientScope("API key requires the " + scope + " scope")
+				}
+				c.Set(scopeGrantedKey, true)
+			}
+			return next(c)
+		}
+	}
+}
+
+// InvalidCSRFToken is the response to cookie authenticated requests without a
+// matching CSRF token.
+func InvalidCSRFToken() error {
+	return errors.New(http.StatusForbidden, invalidCSRFTokenCode, []string{"Invalid CSRF token"})
+}
+
+// GetAuthenticatedUser returns the user of the request. API keys are refused
+// on routes that do not RequireScope, such as the management of the account.
+func GetAuthenticatedUser(c echo.Context) (*entities.User, error) {
+	user, ok := c.Get(AuthenticatedUserKey).(*entities.User)
+	if !ok || user == nil {
+		return nil, unauthorized("Authentication required")
+	}
+	if _, isAPIKey := c.Get(AuthenticatedScopesKey).([]string); isAPIKey && c.Get(scopeGrantedKey) != true {
+		return nil, insufficientScope("API keys cannot access this resource")
+	}
+	return user, nil
+}
+
+// GetAuthenticatedSession returns the session of the access token, uuid.Nil for
+// tokens issued before sessions existed.
+func GetAuthenticatedSession(c echo.Context) uuid.UUID {
+	session, _ := c.Get(AuthenticatedSessionKey).(uuid.UUID)
+	return session
+}
+
+// apiKey returns the API key of the request, empty when it carries none.
+func apiKey(c echo.Context) string {
+	if key := c.Request().Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	authHeader := c.Request().Header.Get(headers.Authorization)
+	if strings.HasPrefix(authHeader, bearerPrefix+entities.APIKeyPrefix) {
+		return strings.TrimPrefix(authHeader, bearerPrefix)
+	}
+	return ""
+}
+
+func insufficientScope(message string) error {
+	return errors.New(http.StatusForbidden, insufficientScopeCode, []string{message})
+}
+
+func unauthorized(message string) error {
+	return errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{message})
+}
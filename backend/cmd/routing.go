@@ -2,40 +2,180 @@ package cmd
 
 import (
 	utilsMiddleware "github.com/juanMaAV92/go-utils/middleware"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/account"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/apikeys"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/assets"
 	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/auth"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/costbasis"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/emailverification"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/export"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/fx"
 	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/health"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/jwks"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/mfa"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/passwordreset"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/performance"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/portfolio"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/prices"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/transactions"
 	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/users"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/middlewares"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
 const (
-	apiV1Group       = "/v1"
-	healthCheckPath  = "/health-check"
-	loginPath        = "/auth/login"
-	logoutPath       = "/auth/logout"
-	refreshTokenPath = "/auth/refresh-token"
-	registerPath     = "/users/register"
+	apiV1Group               = "/v1"
+	healthCheckPath          = "/health-check"
+	jwksPath                 = "/.well-known/jwks.json"
+	authPath                 = "/auth"
+	loginPath                = "/auth/login"
+	loginMFAPath             = "/auth/login/mfa"
+	oidcAuthorizePath        = "/auth/oidc/:provider/authorize"
+	oidcCallbackPath         = "/auth/oidc/:provider/callback"
+	logoutPath               = "/auth/logout"
+	logoutAllPath            = "/auth/logout-all"
+	refreshTokenPath         = "/auth/refresh-token"
+	forgotPasswordPath       = "/auth/password/forgot"
+	resetPasswordPath        = "/auth/password/reset"
+	sessionsPath             = "/auth/sessions"
+	sessionPath              = "/auth/sessions/:code"
+	mfaEnrollPath            = "/auth/mfa/enroll"
+	mfaConfirmPath           = "/auth/mfa/confirm"
+	mfaDisablePath           = "/auth/mfa/disable"
+	apiKeysPath              = "/auth/api-keys"
+	apiKeyPath               = "/auth/api-keys/:code"
+	registerPath             = "/users/register"
+	verifyEmailPath          = "/users/verify-email"
+	resendVerificationPath   = "/users/verify-email/resend"
+	profilePath              = "/users/me"
+	changePasswordPath       = "/users/me/password"
+	exportsPath              = "/users/me/export"
+	exportPath               = "/users/me/export/:code"
+	exportDownloadPath       = "/users/me/export/:code/download"
+	assetsPath               = "/assets"
+	assetPath                = "/assets/:code"
+	transactionsPath         = "/assets/:code/transactions"
+	costBasisPath            = "/assets/:code/cost-basis"
+	pricesPath               = "/assets/:code/prices"
+	fxConvertPath            = "/fx/convert"
+	portfolioSummaryPath     = "/portfolio/summary"
+	portfolioPerformancePath = "/portfolio/performance"
 )
 
 type HealthHandler interface {
 	Check(ctx echo.Context) error
 }
 
+type JWKSHandler interface {
+	GetJWKS(ctx echo.Context) error
+}
+
 type UserHandler interface {
 	CreateUser(ctx echo.Context) error
 }
 
+type AccountHandler interface {
+	GetProfile(ctx echo.Context) error
+	UpdateProfile(ctx echo.Context) error
+	ChangePassword(ctx echo.Context) error
+	DeleteAccount(ctx echo.Context) error
+}
+
+type ExportHandler interface {
+	Export(ctx echo.Context) error
+	GetExport(ctx echo.Context) error
+	Download(ctx echo.Context) error
+}
+
+type EmailVerificationHandler interface {
+	VerifyEmail(ctx echo.Context) error
+	ResendVerification(ctx echo.Context) error
+}
+
 type AuthHandler interface {
 	Login(ctx echo.Context) error
+	LoginMFA(ctx echo.Context) error
+	OIDCAuthorize(ctx echo.Context) error
+	OIDCCallback(ctx echo.Context) error
 	Logout(ctx echo.Context) error
 	RefreshToken(ctx echo.Context) error
+	ListSessions(ctx echo.Context) error
+	RevokeSession(ctx echo.Context) error
+	RevokeOtherSessions(ctx echo.Context) error
+	LogoutEverywhere(ctx echo.Context) error
+}
+
+type MFAHandler interface {
+	Enroll(ctx echo.Context) error
+	Confirm(ctx echo.Context) error
+	Disable(ctx echo.Context) error
+}
+
+type APIKeyHandler interface {
+	CreateAPIKey(ctx echo.Context) error
+	ListAPIKeys(ctx echo.Context) error
+	RevokeAPIKey(ctx echo.Context) error
+}
+
+type PasswordResetHandler interface {
+	ForgotPassword(ctx echo.Context) error
+	ResetPassword(ctx echo.Context) error
+}
+
+type AssetHandler interface {
+	CreateAsset(ctx echo.Context) error
+	GetAsset(ctx echo.Context) error
+	ListAssets(ctx echo.Context) error
+	UpdateAsset(ctx echo.Context) error
+	DeleteAsset(ctx echo.Context) error
+}
+
+type TransactionHandler interface {
+	CreateTransaction(ctx echo.Context) error
+	ListTransactions(ctx echo.Context) error
+}
+
+type CostBasisHandler interface {
+	GetAssetCostBasis(ctx echo.Context) error
+}
+
+type PriceHistoryHandler interface {
+	AddPrice(ctx echo.Context) error
+	GetPriceSeries(ctx echo.Context) error
+}
+
+type FxHandler interface {
+	Convert(ctx echo.Context) error
+}
+
+type PortfolioHandler interface {
+	GetSummary(ctx echo.Context) error
+}
+
+type PerformanceHandler interface {
+	GetPerformance(ctx echo.Context) error
 }
 
 type handlers struct {
-	health HealthHandler
-	user   UserHandler
-	auth   AuthHandler
+	health        HealthHandler
+	jwks          JWKSHandler
+	user          UserHandler
+	account       AccountHandler
+	export        ExportHandler
+	verification  EmailVerificationHandler
+	auth          AuthHandler
+	mfa           MFAHandler
+	apiKeys       APIKeyHandler
+	passwordReset PasswordResetHandler
+	asset         AssetHandler
+	transaction   TransactionHandler
+	costBasis     CostBasisHandler
+	prices        PriceHistoryHandler
+	fx            FxHandler
+	portfolio     PortfolioHandler
+	performance   PerformanceHandler
 }
 
 func configRoutes(inst *Instance, services *services) {
@@ -43,30 +183,110 @@ func configRoutes(inst *Instance, services *services) {
 
 	handlers := initializeHandlers(services)
 
+	// Other services fetch the keys at the well-known location of the host.
+	inst.Server.GET(jwksPath, handlers.jwks.GetJWKS)
+
 	baseGroup := inst.Server.Group(inst.config.ServerName)
 	baseGroup.GET(healthCheckPath, handlers.health.Check)
 
 	v1 := baseGroup.Group(apiV1Group)
 	configureV1Routes(v1, handlers)
+
+	v1Authenticated := v1.Group("", services.authenticate)
+	configureV1AuthenticatedRoutes(v1Authenticated, handlers)
 }
 
 func initializeHandlers(services *services) *handlers {
 	healthHandler := health.NewHandler(services.healthService)
+	jwksHandler := jwks.NewHandler(services.signingKeys)
 	UserHandler := users.NewHandler(services.userService)
-	authHandler := auth.NewHandler(services.authService)
+	accountHandler := account.NewHandler(services.accountService, services.tokenCookies)
+	exportHandler := export.NewHandler(services.exportService)
+	verificationHandler := emailverification.NewHandler(services.emailVerificationService)
+	authHandler := auth.NewHandler(services.authService, services.oidcService, services.tokenCookies)
+	mfaHandler := mfa.NewHandler(services.mfaService)
+	apiKeyHandler := apikeys.NewHandler(services.apiKeyService)
+	passwordResetHandler := passwordreset.NewHandler(services.passwordResetService)
+	assetHandler := assets.NewHandler(services.assetService)
+	transactionHandler := transactions.NewHandler(services.transactionService)
+	costBasisHandler := costbasis.NewHandler(services.costBasisService)
+	pricesHandler := prices.NewHandler(services.priceHistoryService)
+	fxHandler := fx.NewHandler(services.fxService)
+	portfolioHandler := portfolio.NewHandler(services.portfolioService)
+	performanceHandler := performance.NewHandler(services.performanceService)
 
 	return &handlers{
-		health: healthHandler,
-		user:   UserHandler,
-		auth:   authHandler,
+		health:        healthHandler,
+		jwks:          jwksHandler,
+		user:          UserHandler,
+		account:       accountHandler,
+		export:        exportHandler,
+		verification:  verificationHandler,
+		auth:          authHandler,
+		mfa:           mfaHandler,
+		apiKeys:       apiKeyHandler,
+		passwordReset: passwordResetHandler,
+		asset:         assetHandler,
+		transaction:   transactionHandler,
+		costBasis:     costBasisHandler,
+		prices:        pricesHandler,
+		fx:            fxHandler,
+		portfolio:     portfolioHandler,
+		performance:   performanceHandler,
 	}
 }
 
 func configureV1Routes(v1 *echo.Group, h *handlers) {
 	v1.POST(registerPath, h.user.CreateUser)
+	v1.POST(verifyEmailPath, h.verification.VerifyEmail)
+	v1.POST(resendVerificationPath, h.verification.ResendVerification)
 	v1.POST(loginPath, h.auth.Login)
+	v1.POST(loginMFAPath, h.auth.LoginMFA)
+	v1.GET(oidcAuthorizePath, h.auth.OIDCAuthorize)
+	v1.POST(oidcCallbackPath, h.auth.OIDCCallback)
 	v1.POST(logoutPath, h.auth.Logout)
 	v1.POST(refreshTokenPath, h.auth.RefreshToken)
+	v1.POST(forgotPasswordPath, h.passwordReset.ForgotPassword)
+	v1.POST(resetPasswordPath, h.passwordReset.ResetPassword)
+}
+
+// configureV1AuthenticatedRoutes also serves API keys on the routes that
+// require one of their scopes. The account routes are left to access tokens.
+func configureV1AuthenticatedRoutes(v1 *echo.Group, h *handlers) {
+	readPortfolio := middlewares.RequireScope(entities.APIKeyScopePortfolioRead)
+	writeAssets := middlewares.RequireScope(entities.APIKeyScopeAssetsWrite)
+	writeTransactions := middlewares.RequireScope(entities.APIKeyScopeTransactionsWrite)
+
+	v1.GET(profilePath, h.account.GetProfile)
+	v1.PATCH(profilePath, h.account.UpdateProfile)
+	v1.DELETE(profilePath, h.account.DeleteAccount)
+	v1.POST(changePasswordPath, h.account.ChangePassword)
+	v1.POST(exportsPath, h.export.Export)
+	v1.GET(exportPath, h.export.GetExport)
+	v1.GET(exportDownloadPath, h.export.Download)
+	v1.POST(logoutAllPath, h.auth.LogoutEverywhere)
+	v1.GET(sessionsPath, h.auth.ListSessions)
+	v1.DELETE(sessionsPath, h.auth.RevokeOtherSessions)
+	v1.DELETE(sessionPath, h.auth.RevokeSession)
+	v1.POST(mfaEnrollPath, h.mfa.Enroll)
+	v1.POST(mfaConfirmPath, h.mfa.Confirm)
+	v1.POST(mfaDisablePath, h.mfa.Disable)
+	v1.GET(apiKeysPath, h.apiKeys.ListAPIKeys)
+	v1.POST(apiKeysPath, h.apiKeys.CreateAPIKey)
+	v1.DELETE(apiKeyPath, h.apiKeys.RevokeAPIKey)
+	v1.GET(assetsPath, h.asset.ListAssets, readPortfolio)
+	v1.POST(assetsPath, h.asset.CreateAsset, writeAssets)
+	v1.GET(assetPath, h.asset.GetAsset, readPortfolio)
+	v1.PATCH(assetPath, h.asset.UpdateAsset, writeAssets)
+	v1.DELETE(assetPath, h.asset.DeleteAsset, writeAssets)
+	v1.GET(transactionsPath, h.transaction.ListTransactions, readPortfolio)
+	v1.POST(transactionsPath, h.transaction.CreateTransaction, writeTransactions)
+	v1.GET(costBasisPath, h.costBasis.GetAssetCostBasis, readPortfolio)
+	v1.GET(pricesPath, h.prices.GetPriceSeries, readPortfolio)
+	v1.POST(pricesPath, h.prices.AddPrice, writeAssets)
+	v1.GET(fxConvertPath, h.fx.Convert, readPortfolio)
+	v1.GET(portfolioSummaryPath, h.portfolio.GetSummary, readPortfolio)
+	v1.GET(portfolioPerformancePath, h.performance.GetPerformance, readPortfolio)
 }
 
 func configMiddleware(inst *Instance) {
@@ -1,21 +1,67 @@
 package cmd
 
 import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+
 	"github.com/juanMaAV92/go-utils/cache"
 	"github.com/juanMaAV92/go-utils/database"
 	"github.com/juanMaAV92/go-utils/env"
 	"github.com/juanMaAV92/go-utils/errors"
-	"github.com/juanMaAV92/go-utils/jwt"
 	"github.com/juanMaAV92/go-utils/log"
 	"github.com/juanMaAV92/go-utils/platform/server"
+	accountHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/account"
+	apiKeyHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/apikeys"
+	assetHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/assets"
 	authHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/auth"
+	costBasisHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/costbasis"
+	emailVerificationHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/emailverification"
+	exportHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/export"
+	fxHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/fx"
 	healthHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/health"
+	jwksHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/jwks"
+	mfaHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/mfa"
+	passwordResetHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/passwordreset"
+	performanceHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/performance"
+	portfolioHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/portfolio"
+	pricesHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/prices"
+	transactionHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/transactions"
 	userHandler "github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/users"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/middlewares"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/jobs"
 	"github.com/juanMaAV92/zenith-financial/backend/internal/repositories"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/account"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/apikeys"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/assets"
 	"github.com/juanMaAV92/zenith-financial/backend/internal/services/auth"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/costbasis"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/emailverification"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/export"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/fx"
 	"github.com/juanMaAV92/zenith-financial/backend/internal/services/health"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/mfa"
+	oidcLogin "github.com/juanMaAV92/zenith-financial/backend/internal/services/oidc"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/passwordreset"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/performance"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/portfolio"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/pricehistory"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/pricing"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/transactions"
 	"github.com/juanMaAV92/zenith-financial/backend/internal/services/users"
 	"github.com/juanMaAV92/zenith-financial/backend/platform/config"
+	"github.com/juanMaAV92/zenith-financial/backend/platform/filestore"
+	"github.com/juanMaAV92/zenith-financial/backend/platform/mailer"
+	"github.com/juanMaAV92/zenith-financial/backend/platform/scheduler"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/authcookies"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/crypto"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/lockout"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/oidc"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/tokens"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/validation"
 	"github.com/labstack/echo/v4"
 	"github.com/shopspring/decimal"
 )
@@ -27,15 +73,40 @@ type Instance struct {
 }
 
 type services struct {
-	healthService healthHandler.Service
-	userService   userHandler.UserService
-	authService   authHandler.AuthService
+	healthService            healthHandler.Service
+	userService              userHandler.UserService
+	accountService           accountHandler.AccountService
+	exportService            exportHandler.ExportService
+	emailVerificationService emailVerificationHandler.EmailVerificationService
+	authService              authHandler.AuthService
+	oidcService              authHandler.OIDCService
+	tokenCookies             authHandler.TokenCookies
+	signingKeys              jwksHandler.KeySet
+	mfaService               mfaHandler.MFAService
+	apiKeyService            apiKeyHandler.APIKeyService
+	passwordResetService     passwordResetHandler.PasswordResetService
+	assetService             assetHandler.AssetService
+	transactionService       transactionHandler.TransactionService
+	costBasisService         costBasisHandler.CostBasisService
+	priceHistoryService      pricesHandler.PriceHistoryService
+	fxService                fxHandler.FxService
+	portfolioService         portfolioHandler.PortfolioService
+	performanceService       performanceHandler.PerformanceService
+	authenticate             echo.MiddlewareFunc
+	scheduler                *scheduler.Scheduler
 }
 
 func NewServer(cfg *config.Config, logger log.Logger) (*Instance, error) {
+	ipExtractor, err := newIPExtractor(cfg.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
 	instance := echo.New()
+	instance.IPExtractor = ipExtractor
 	instance.HideBanner = true
 	instance.HTTPErrorHandler = errors.CustomHTTPErrorHandler
+	instance.Validator = validation.New()
 	instance.Debug = cfg.Environment == env.LocalEnvironment
 	decimal.MarshalJSONWithoutQuotes = true
 
@@ -49,9 +120,6 @@ func NewServer(cfg *config.Config, logger log.Logger) (*Instance, error) {
 }
 
 func (inst Instance) initServices() (*services, error) {
-	jwt.InitJWTConfig(inst.config.Jwt)
-	healthService := health.NewService()
-
 	db, err := database.New(*inst.config.Database, inst.Logger)
 	if err != nil {
 		return nil, err
@@ -62,13 +130,226 @@ func (inst Instance) initServices() (*services, error) {
 		return nil, err
 	}
 
+	mail, err := newMailer(inst.config.Mailer, inst.config.Environment, inst.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	mfaCipher, err := newMFACipher(inst.config.Auth.MFAEncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	signingKeys, err := newKeySet(inst.config)
+	if err != nil {
+		return nil, err
+	}
+	tokenIssuer := tokens.NewIssuer(inst.config.Jwt, signingKeys)
+	tokenCookies := authcookies.New(authcookies.Config{
+		Enabled:     inst.config.Cookies.Enabled,
+		Domain:      inst.config.Cookies.Domain,
+		Secure:      inst.config.Cookies.Secure,
+		SameSite:    inst.config.Cookies.SameSite,
+		RefreshPath: path.Join("/", inst.config.ServerName, apiV1Group, authPath),
+		AccessTTL:   tokenIssuer.AccessTTL(),
+		RefreshTTL:  tokenIssuer.RefreshTTL(),
+	})
+
 	userRepository := repositories.NewUserRepository(db)
-	userService := users.NewService(userRepository)
-	authService := auth.NewService(userRepository, cache, inst.Logger)
+	emailVerificationService := emailverification.NewService(userRepository, repositories.NewEmailVerificationRepository(db), cache, mail,
+		inst.Logger, inst.config.Auth.FrontendURL, inst.config.Auth.EmailVerificationTTL, inst.config.Auth.VerificationResendCooldown)
+	userService := users.NewService(userRepository, emailVerificationService, inst.Logger)
+	tokenDenylist := tokens.NewDenylist(cache)
+	loginGuard := lockout.NewGuard(cache, lockout.Limits(*inst.config.Lockout))
+	recoveryCodeRepository := repositories.NewRecoveryCodeRepository(db)
+	mfaService := mfa.NewService(userRepository, recoveryCodeRepository, mfaCipher, inst.Logger)
+	authService := auth.NewService(userRepository, repositories.NewSessionRepository(db), tokenIssuer, tokenDenylist, mfaService,
+		loginGuard, inst.Logger, inst.config.Auth.RequireVerifiedEmail)
+	oidcProviders := make(map[string]oidc.Config, len(inst.config.OIDC.Providers))
+	for name, provider := range inst.config.OIDC.Providers {
+		oidcProviders[name] = oidc.Config(provider)
+	}
+	apiKeyRepository := repositories.NewAPIKeyRepository(db)
+	oidcService := oidcLogin.NewService(oidc.NewRegistry(oidcProviders, &http.Client{Timeout: inst.config.OIDC.Timeout}), userRepository,
+		repositories.NewExternalIdentityRepository(db), apiKeyRepository, recoveryCodeRepository, cache, authService, inst.Logger, inst.config.OIDC.StateTTL)
+	apiKeyService := apikeys.NewService(apiKeyRepository, userRepository, inst.Logger)
+	passwordResetService := passwordreset.NewService(userRepository, repositories.NewPasswordResetRepository(db), authService, loginGuard, mail,
+		inst.Logger, inst.config.Auth.FrontendURL, inst.config.Auth.PasswordResetTTL)
+
+	assetRepository := repositories.NewAssetRepository(db)
+	accountService := account.NewService(userRepository, repositories.NewAccountDeletionRepository(db), assetRepository, emailVerificationService,
+		authService, db, inst.Logger, inst.config.Auth.AccountDeletionGracePeriod)
+	assetPriceRepository := repositories.NewAssetPriceRepository(db)
+	priceHistoryService := pricehistory.NewService(assetRepository, assetPriceRepository)
+	assetService := assets.NewService(assetRepository, priceHistoryService, inst.Logger)
+
+	transactionRepository := repositories.NewTransactionRepository(db)
+	transactionService := transactions.NewService(assetRepository, transactionRepository, db)
+
+	fxRateProvider := fx.NewExchangeRateProvider(inst.config.FX.BaseURL, &http.Client{Timeout: inst.config.FX.Timeout})
+	fxService := fx.NewService(fxRateProvider, repositories.NewFxRateRepository(db), inst.config.FX.PivotCurrency, inst.Logger)
+
+	priceRegistry := newPriceRegistry(inst.config.Pricing)
+	pricingService := pricing.NewService(priceRegistry, cache, priceHistoryService, fxService, inst.Logger, inst.config.Pricing.CacheTTL)
+	costBasisService := costbasis.NewService(assetRepository, transactionRepository, pricingService)
+	portfolioService := portfolio.NewService(assetRepository, pricingService, fxService, inst.Logger)
+	performanceService := performance.NewService(assetRepository, transactionRepository, assetPriceRepository, fxService, inst.Logger)
+	if inst.config.Export.Dir == "" {
+		return nil, fmt.Errorf("an export directory is required")
+	}
+	exportService := export.NewService(assetRepository, transactionRepository, assetPriceRepository, cache,
+		filestore.New(inst.config.Export.Dir), inst.Logger, export.Limits{
+			SyncAssetLimit: inst.config.Export.SyncAssetLimit,
+			Timeout:        inst.config.Export.Timeout,
+			LinkTTL:        inst.config.Export.LinkTTL,
+			MaxArchiveSize: inst.config.Export.MaxArchiveSize,
+		}, path.Join("/", inst.config.ServerName, apiV1Group, exportsPath))
+
+	jobScheduler := scheduler.New(scheduler.NewCacheLocker(cache, inst.config.ServerName), inst.Logger)
+	if inst.config.Scheduler.Enabled {
+		snapshotRepository := repositories.NewPortfolioSnapshotRepository(db)
+		err := registerJobs(jobScheduler, inst.config.Scheduler, map[string]scheduler.JobFunc{
+			jobs.PriceRefreshJobName:      jobs.NewPriceRefresh(assetRepository, pricingService, inst.Logger).Run,
+			jobs.PortfolioSnapshotJobName: jobs.NewPortfolioSnapshot(assetRepository, snapshotRepository, pricingService, inst.Logger).Run,
+			jobs.FxRefreshJobName:         jobs.NewFxRefresh(fxService, inst.config.FX.Currencies).Run,
+			jobs.AccountPurgeJobName:      jobs.NewAccountPurge(accountService).Run,
+			jobs.ExportPurgeJobName:       jobs.NewExportPurge(exportService).Run,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	healthService := health.NewService(jobScheduler)
 
 	return &services{
-		healthService: healthService,
-		userService:   userService,
-		authService:   authService,
+		healthService:            healthService,
+		userService:              userService,
+		accountService:           accountService,
+		exportService:            exportService,
+		emailVerificationService: emailVerificationService,
+		authService:              authService,
+		oidcService:              oidcService,
+		tokenCookies:             tokenCookies,
+		signingKeys:              signingKeys,
+		mfaService:               mfaService,
+		apiKeyService:            apiKeyService,
+		passwordResetService:     passwordResetService,
+		assetService:             assetService,
+		transactionService:       transactionService,
+		costBasisService:         costBasisService,
+		priceHistoryService:      priceHistoryService,
+		fxService:                fxService,
+		portfolioService:         portfolioService,
+		performanceService:       performanceService,
+		authenticate:             middlewares.Authenticate(userRepository, tokenIssuer, tokenDenylist, tokenCookies, apiKeyService),
+		scheduler:                jobScheduler,
 	}, nil
 }
+
+// registerJobs schedules the jobs that have a schedule in the configuration.
+func registerJobs(s *scheduler.Scheduler, cfg *config.SchedulerConfig, jobFuncs map[string]scheduler.JobFunc) error {
+	for name, run := range jobFuncs {
+		jobConfig, ok := cfg.Jobs[name]
+		if !ok || jobConfig.Schedule == "" {
+			continue
+		}
+		if err := s.Register(name, jobConfig.Schedule, run, jobConfig.Timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newMailer builds the mailer of the configured driver. Only the local
+// environment falls back to the log one when none is set: the emails carry
+// tokens, so deployments have to choose their driver explicitly.
+func newMailer(cfg *config.MailerConfig, environment string, logger log.Logger) (mailer.Mailer, error) {
+	if cfg.Driver == "" && environment != env.LocalEnvironment {
+		return nil, fmt.Errorf("a mailer driver is required in the %s environment", environment)
+	}
+
+	switch cfg.Driver {
+	case mailer.DriverLog, "":
+		return mailer.NewLogMailer(cfg.From, logger), nil
+	case mailer.DriverFile:
+		if cfg.OutboxDir == "" {
+			return nil, fmt.Errorf("mailer driver %q requires an outbox directory", cfg.Driver)
+		}
+		return mailer.NewFileMailer(cfg.From, cfg.OutboxDir), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer driver %q", cfg.Driver)
+	}
+}
+
+// newKeySet loads the token signing keys. The shared secret of the jwt
+// configuration, when set, is kept for the tokens without a kid: it signs them
+// until an active key is configured and verifies them afterwards, until it is
+// removed.
+func newKeySet(cfg *config.Config) (*tokens.KeySet, error) {
+	keys, err := tokens.LoadKeys(cfg.JwtKeys.Dir)
+	if err != nil {
+		return nil, err
+	}
+	for id, data := range cfg.JwtKeys.PEM {
+		key, err := tokens.ParseKey(id, []byte(data))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if cfg.Jwt.SecretKey != "" {
+		keys = append(keys, tokens.NewSecretKey(cfg.Jwt.SecretKey, cfg.Jwt.SigningMethod))
+	}
+	return tokens.NewKeySet(cfg.JwtKeys.ActiveKeyID, keys...)
+}
+
+// newMFACipher builds the cipher of the TOTP secrets from the base64 encoded
+// key.
+func newMFACipher(key string) (*crypto.Cipher, error) {
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MFA encryption key: %w", err)
+	}
+	cipher, err := crypto.NewCipher(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MFA encryption key: %w", err)
+	}
+	return cipher, nil
+}
+
+// newIPExtractor reads the client IP from X-Forwarded-For only behind the
+// trusted proxies, so clients cannot choose the IP their failed logins are
+// counted on. Without trusted proxies the IP of the connection is used.
+func newIPExtractor(cfg *config.ProxyConfig) (echo.IPExtractor, error) {
+	if len(cfg.TrustedRanges) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, trusted := range cfg.TrustedRanges {
+		_, ipRange, err := net.ParseCIDR(trusted)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy range %q: %w", trusted, err)
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+func newPriceRegistry(cfg *config.PricingConfig) *pricing.Registry {
+	registry := pricing.NewRegistry()
+
+	yahoo := cfg.Providers[entities.PriceSourceYahoo]
+	registry.Register(
+		pricing.NewYahooProvider(yahoo.BaseURL, nil),
+		pricing.ProviderOptions{Timeout: yahoo.Timeout, RequestsPerMinute: yahoo.RequestsPerMinute},
+	)
+
+	coinGecko := cfg.Providers[entities.PriceSourceCoinGecko]
+	registry.Register(
+		pricing.NewCoinGeckoProvider(coinGecko.BaseURL, coinGecko.APIKey, nil),
+		pricing.ProviderOptions{Timeout: coinGecko.Timeout, RequestsPerMinute: coinGecko.RequestsPerMinute},
+	)
+
+	return registry
+}
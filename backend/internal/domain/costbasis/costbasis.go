@@ -0,0 +1,240 @@
+package costbasis
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+)
+
+type Method string
+
+const (
+	FIFO        Method = "FIFO"
+	LIFO        Method = "LIFO"
+	AverageCost Method = "AVERAGE"
+
+	DefaultMethod = FIFO
+)
+
+var (
+	ErrUnknownMethod     = errors.New("unknown cost basis method")
+	ErrInsufficientUnits = errors.New("sale exceeds the units held in open lots")
+)
+
+// Lot is an open tax lot. Cost includes the fees paid to acquire it.
+type Lot struct {
+	TransactionCode uuid.UUID
+	AcquiredAt      time.Time
+	Units           decimal.Decimal
+	Cost            decimal.Decimal
+}
+
+func (l Lot) UnitCost() decimal.Decimal {
+	if l.Units.IsZero() {
+		return decimal.Zero
+	}
+	return l.Cost.Div(l.Units)
+}
+
+// Sale is the realized result of an outflow. Proceeds are net of fees.
+type Sale struct {
+	TransactionCode uuid.UUID
+	SoldAt          time.Time
+	Units           decimal.Decimal
+	Proceeds        decimal.Decimal
+	Fee             decimal.Decimal
+	CostBasis       decimal.Decimal
+	RealizedGain    decimal.Decimal
+}
+
+type Result struct {
+	Method       Method
+	Lots         []Lot
+	Sales        []Sale
+	TotalUnits   decimal.Decimal
+	TotalCost    decimal.Decimal
+	RealizedGain decimal.Decimal
+}
+
+func ParseMethod(value string) (Method, error) {
+	method := Method(strings.ToUpper(strings.TrimSpace(value)))
+	switch method {
+	case FIFO, LIFO, AverageCost:
+		return method, nil
+	}
+	return "", ErrUnknownMethod
+}
+
+// Ledger returns the transactions of the asset preceded by its opening balance,
+// the units and cost it was created with, which no transaction records. The
+// opening balance is a deposit without code, dated at the creation of the asset
+// or at the first transaction when the ledger was backdated further.
+func Ledger(asset *entities.Asset, transactions []entities.Transaction) []entities.Transaction {
+	if !asset.OpeningUnits.IsPositive() {
+		return transactions
+	}
+
+	openedAt := asset.CreatedAt
+	for _, transaction := range transactions {
+		if transaction.CreatedAt.Before(openedAt) {
+			openedAt = transaction.CreatedAt
+		}
+	}
+	ledger := make([]entities.Transaction, 0, len(transactions)+1)
+	ledger = append(ledger, entities.Transaction{
+		AssetID:   asset.ID,
+		Type:      entities.TransactionTypeDeposit,
+		Units:     asset.OpeningUnits,
+		Total:     asset.OpeningCost,
+		FeeTotal:  decimal.Zero,
+		Currency:  asset.Currency,
+		CreatedAt: openedAt,
+	})
+	return append(ledger, transactions...)
+}
+
+// Replay rebuilds the open lots and realized sales of an asset from its ledger.
+// Transactions are processed in chronological order regardless of input order.
+func Replay(method Method, transactions []entities.Transaction) (*Result, error) {
+	if _, err := ParseMethod(string(method)); err != nil {
+		return nil, err
+	}
+
+	ordered := make([]entities.Transaction, len(transactions))
+	copy(ordered, transactions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].CreatedAt.Before(ordered[j].CreatedAt)
+	})
+
+	result := &Result{
+		Method:       method,
+		TotalUnits:   decimal.Zero,
+		TotalCost:    decimal.Zero,
+		RealizedGain: decimal.Zero,
+	}
+
+	for _, transaction := range ordered {
+		if transaction.IsInflow() {
+			result.Lots = append(result.Lots, Lot{
+				TransactionCode: transaction.Code,
+				AcquiredAt:      transaction.CreatedAt,
+				Units:           transaction.Units,
+				Cost:            transaction.Total.Add(transaction.FeeTotal),
+			})
+			result.TotalUnits = result.TotalUnits.Add(transaction.Units)
+			result.TotalCost = result.TotalCost.Add(transaction.Total).Add(transaction.FeeTotal)
+			continue
+		}
+
+		if transaction.Units.GreaterThan(result.TotalUnits) {
+			return nil, ErrInsufficientUnits
+		}
+
+		costBasis := result.consume(transaction.Units)
+		proceeds := transaction.Total.Sub(transaction.FeeTotal)
+		sale := Sale{
+			TransactionCode: transaction.Code,
+			SoldAt:          transaction.CreatedAt,
+			Units:           transaction.Units,
+			Proceeds:        proceeds,
+			Fee:             transaction.FeeTotal,
+			CostBasis:       costBasis,
+			RealizedGain:    proceeds.Sub(costBasis),
+		}
+		result.Sales = append(result.Sales, sale)
+		result.RealizedGain = result.RealizedGain.Add(sale.RealizedGain)
+	}
+
+	return result, nil
+}
+
+// MarketValue values the open position at the given unit price.
+func (r *Result) MarketValue(unitPrice decimal.Decimal) decimal.Decimal {
+	return r.TotalUnits.Mul(unitPrice)
+}
+
+// UnrealizedGain is the gain of the open lots if sold at the given unit price.
+func (r *Result) UnrealizedGain(unitPrice decimal.Decimal) decimal.Decimal {
+	return r.MarketValue(unitPrice).Sub(r.TotalCost)
+}
+
+func (r *Result) AverageUnitCost() decimal.Decimal {
+	if r.TotalUnits.IsZero() {
+		return decimal.Zero
+	}
+	return r.TotalCost.Div(r.TotalUnits)
+}
+
+// consume removes units from the open lots and returns the cost basis removed.
+func (r *Result) consume(units decimal.Decimal) decimal.Decimal {
+	var costBasis decimal.Decimal
+
+	if units.Equal(r.TotalUnits) {
+		costBasis = r.TotalCost
+		r.Lots = nil
+		r.TotalUnits = decimal.Zero
+		r.TotalCost = decimal.Zero
+		return costBasis
+	}
+
+	switch r.Method {
+	case AverageCost:
+		costBasis = r.consumeProportionally(units)
+	case LIFO:
+		costBasis = r.consumeInOrder(units, true)
+	default:
+		costBasis = r.consumeInOrder(units, false)
+	}
+
+	r.TotalUnits = r.TotalUnits.Sub(units)
+	r.TotalCost = r.TotalCost.Sub(costBasis)
+	return costBasis
+}
+
+func (r *Result) consumeInOrder(units decimal.Decimal, newestFirst bool) decimal.Decimal {
+	costBasis := decimal.Zero
+	remaining := units
+
+	for remaining.IsPositive() && len(r.Lots) > 0 {
+		index := 0
+		if newestFirst {
+			index = len(r.Lots) - 1
+		}
+		lot := &r.Lots[index]
+
+		if remaining.GreaterThanOrEqual(lot.Units) {
+			costBasis = costBasis.Add(lot.Cost)
+			remaining = remaining.Sub(lot.Units)
+			r.Lots = append(r.Lots[:index], r.Lots[index+1:]...)
+			continue
+		}
+
+		portion := lot.Cost.Mul(remaining).Div(lot.Units)
+		costBasis = costBasis.Add(portion)
+		lot.Units = lot.Units.Sub(remaining)
+		lot.Cost = lot.Cost.Sub(portion)
+		remaining = decimal.Zero
+	}
+
+	return costBasis
+}
+
+func (r *Result) consumeProportionally(units decimal.Decimal) decimal.Decimal {
+	costBasis := decimal.Zero
+	remainingFraction := r.TotalUnits.Sub(units).Div(r.TotalUnits)
+
+	for i := range r.Lots {
+		lot := &r.Lots[i]
+		keptCost := lot.Cost.Mul(remainingFraction)
+		costBasis = costBasis.Add(lot.Cost.Sub(keptCost))
+		lot.Cost = keptCost
+		lot.Units = lot.Units.Mul(remainingFraction)
+	}
+
+	return costBasis
+}
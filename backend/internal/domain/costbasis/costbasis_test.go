@@ -0,0 +1,267 @@
+package costbasis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var baseTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func tx(day int, transactionType string, units, total, fee string) entities.Transaction {
+	return entities.Transaction{
+		Type:      transactionType,
+		Units:     decimal.RequireFromString(units),
+		Total:     decimal.RequireFromString(total),
+		FeeTotal:  decimal.RequireFromString(fee),
+		CreatedAt: baseTime.AddDate(0, 0, day),
+	}
+}
+
+func d(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func Test_Replay(t *testing.T) {
+	// Buy 10 @ 10 (+1 fee), buy 10 @ 20 (+1 fee), sell 15 for 450 (-3 fee).
+	ledger := []entities.Transaction{
+		tx(0, entities.TransactionTypeBuy, "10", "100", "1"),
+		tx(1, entities.TransactionTypeBuy, "10", "200", "1"),
+		tx(2, entities.TransactionTypeSell, "15", "450", "3"),
+	}
+
+	testCases := []struct {
+		name                 string
+		method               Method
+		transactions         []entities.Transaction
+		expectedUnits        decimal.Decimal
+		expectedCost         decimal.Decimal
+		expectedRealized     decimal.Decimal
+		expectedSaleBasis    []decimal.Decimal
+		expectedOpenLotUnits []decimal.Decimal
+		expectedError        error
+	}{
+		{
+			name:                 "fifo consumes oldest lots first",
+			method:               FIFO,
+			transactions:         ledger,
+			expectedUnits:        d("5"),
+			expectedCost:         d("100.5"),
+			expectedRealized:     d("447").Sub(d("201.5")),
+			expectedSaleBasis:    []decimal.Decimal{d("201.5")},
+			expectedOpenLotUnits: []decimal.Decimal{d("5")},
+		},
+		{
+			name:                 "lifo consumes newest lots first",
+			method:               LIFO,
+			transactions:         ledger,
+			expectedUnits:        d("5"),
+			expectedCost:         d("50.5"),
+			expectedRealized:     d("447").Sub(d("251.5")),
+			expectedSaleBasis:    []decimal.Decimal{d("251.5")},
+			expectedOpenLotUnits: []decimal.Decimal{d("5")},
+		},
+		{
+			name:                 "average cost spreads the sale across all lots",
+			method:               AverageCost,
+			transactions:         ledger,
+			expectedUnits:        d("5"),
+			expectedCost:         d("75.5"),
+			expectedRealized:     d("447").Sub(d("226.5")),
+			expectedSaleBasis:    []decimal.Decimal{d("226.5")},
+			expectedOpenLotUnits: []decimal.Decimal{d("2.5"), d("2.5")},
+		},
+		{
+			name:   "transactions are replayed chronologically",
+			method: FIFO,
+			transactions: []entities.Transaction{
+				tx(2, entities.TransactionTypeSell, "15", "450", "3"),
+				tx(1, entities.TransactionTypeBuy, "10", "200", "1"),
+				tx(0, entities.TransactionTypeBuy, "10", "100", "1"),
+			},
+			expectedUnits:        d("5"),
+			expectedCost:         d("100.5"),
+			expectedRealized:     d("245.5"),
+			expectedSaleBasis:    []decimal.Decimal{d("201.5")},
+			expectedOpenLotUnits: []decimal.Decimal{d("5")},
+		},
+		{
+			name:   "selling every unit realizes the full cost",
+			method: AverageCost,
+			transactions: []entities.Transaction{
+				tx(0, entities.TransactionTypeBuy, "3", "100", "0"),
+				tx(1, entities.TransactionTypeWithdraw, "3", "90", "0"),
+			},
+			expectedUnits:     d("0"),
+			expectedCost:      d("0"),
+			expectedRealized:  d("-10"),
+			expectedSaleBasis: []decimal.Decimal{d("100")},
+		},
+		{
+			name:   "deposits open lots like buys",
+			method: FIFO,
+			transactions: []entities.Transaction{
+				tx(0, entities.TransactionTypeDeposit, "1000000", "1000000", "0"),
+				tx(5, entities.TransactionTypeDeposit, "500000", "500000", "0"),
+			},
+			expectedUnits:        d("1500000"),
+			expectedCost:         d("1500000"),
+			expectedRealized:     d("0"),
+			expectedOpenLotUnits: []decimal.Decimal{d("1000000"), d("500000")},
+		},
+		{
+			name:   "multiple sales across partial lots",
+			method: FIFO,
+			transactions: []entities.Transaction{
+				tx(0, entities.TransactionTypeBuy, "4", "40", "0"),
+				tx(1, entities.TransactionTypeBuy, "4", "80", "0"),
+				tx(2, entities.TransactionTypeSell, "2", "40", "0"),
+				tx(3, entities.TransactionTypeSell, "4", "100", "0"),
+			},
+			expectedUnits:        d("2"),
+			expectedCost:         d("40"),
+			expectedRealized:     d("20").Add(d("40")),
+			expectedSaleBasis:    []decimal.Decimal{d("20"), d("60")},
+			expectedOpenLotUnits: []decimal.Decimal{d("2")},
+		},
+		{
+			name:   "sale exceeding open lots",
+			method: LIFO,
+			transactions: []entities.Transaction{
+				tx(0, entities.TransactionTypeBuy, "1", "10", "0"),
+				tx(1, entities.TransactionTypeSell, "2", "30", "0"),
+			},
+			expectedError: ErrInsufficientUnits,
+		},
+		{
+			name:          "unknown method",
+			method:        Method("HIFO"),
+			transactions:  ledger,
+			expectedError: ErrUnknownMethod,
+		},
+		{
+			name:             "empty ledger",
+			method:           FIFO,
+			expectedUnits:    d("0"),
+			expectedCost:     d("0"),
+			expectedRealized: d("0"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Replay(tc.method, tc.transactions)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, tc.expectedUnits.Equal(result.TotalUnits), "units: %s", result.TotalUnits)
+			assert.True(t, tc.expectedCost.Equal(result.TotalCost), "cost: %s", result.TotalCost)
+			assert.True(t, tc.expectedRealized.Equal(result.RealizedGain), "realized: %s", result.RealizedGain)
+
+			assert.Len(t, result.Sales, len(tc.expectedSaleBasis))
+			for i, basis := range tc.expectedSaleBasis {
+				assert.True(t, basis.Equal(result.Sales[i].CostBasis), "sale %d basis: %s", i, result.Sales[i].CostBasis)
+			}
+
+			assert.Len(t, result.Lots, len(tc.expectedOpenLotUnits))
+			for i, units := range tc.expectedOpenLotUnits {
+				assert.True(t, units.Equal(result.Lots[i].Units), "lot %d units: %s", i, result.Lots[i].Units)
+			}
+		})
+	}
+}
+
+func Test_Replay_DoesNotMutateInput(t *testing.T) {
+	ledger := []entities.Transaction{
+		tx(1, entities.TransactionTypeSell, "1", "20", "0"),
+		tx(0, entities.TransactionTypeBuy, "2", "20", "0"),
+	}
+
+	_, err := Replay(FIFO, ledger)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransactionTypeSell, ledger[0].Type)
+}
+
+func Test_Ledger_OpeningBalance(t *testing.T) {
+	asset := &entities.Asset{
+		Currency:     "COP",
+		OpeningUnits: d("10"),
+		OpeningCost:  d("100"),
+		CreatedAt:    baseTime.AddDate(0, 0, 5),
+	}
+
+	t.Run("the first sale consumes the opening lot", func(t *testing.T) {
+		result, err := Replay(FIFO, Ledger(asset, []entities.Transaction{
+			tx(6, entities.TransactionTypeSell, "4", "60", "0"),
+		}))
+		assert.NoError(t, err)
+		assert.True(t, d("6").Equal(result.TotalUnits))
+		assert.True(t, d("60").Equal(result.TotalCost))
+		assert.True(t, d("20").Equal(result.RealizedGain))
+	})
+
+	t.Run("the opening balance precedes a backdated ledger", func(t *testing.T) {
+		ledger := Ledger(asset, []entities.Transaction{
+			tx(8, entities.TransactionTypeBuy, "1", "20", "0"),
+			tx(2, entities.TransactionTypeSell, "5", "50", "0"),
+		})
+		assert.Len(t, ledger, 3)
+		assert.Equal(t, baseTime.AddDate(0, 0, 2), ledger[0].CreatedAt)
+		assert.Equal(t, "COP", ledger[0].Currency)
+
+		result, err := Replay(FIFO, ledger)
+		assert.NoError(t, err)
+		assert.True(t, d("6").Equal(result.TotalUnits))
+	})
+
+	t.Run("assets created empty have no opening balance", func(t *testing.T) {
+		ledger := []entities.Transaction{tx(0, entities.TransactionTypeBuy, "1", "10", "0")}
+		assert.Equal(t, ledger, Ledger(&entities.Asset{}, ledger))
+	})
+}
+
+func Test_Result_UnrealizedGain(t *testing.T) {
+	result, err := Replay(FIFO, []entities.Transaction{
+		tx(0, entities.TransactionTypeBuy, "2", "100", "2"),
+		tx(1, entities.TransactionTypeBuy, "2", "140", "2"),
+	})
+	assert.NoError(t, err)
+
+	assert.True(t, d("61").Equal(result.AverageUnitCost()))
+	assert.True(t, d("300").Equal(result.MarketValue(d("75"))))
+	assert.True(t, d("56").Equal(result.UnrealizedGain(d("75"))))
+	assert.True(t, d("-44").Equal(result.UnrealizedGain(d("50"))))
+}
+
+func Test_ParseMethod(t *testing.T) {
+	testCases := []struct {
+		value         string
+		expected      Method
+		expectedError error
+	}{
+		{value: "fifo", expected: FIFO},
+		{value: " LIFO ", expected: LIFO},
+		{value: "average", expected: AverageCost},
+		{value: "", expectedError: ErrUnknownMethod},
+		{value: "specific", expectedError: ErrUnknownMethod},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			method, err := ParseMethod(tc.value)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, method)
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+)
+
+// FormatVersion is the version of the archive layout. It is bumped on any
+// change that an importer of the previous version would misread, and importers
+// refuse the versions they do not know.
+const FormatVersion = 1
+
+// Files of the archive. The JSON document is the one to import, the CSV files
+// carry the same rows for spreadsheets.
+const (
+	DocumentFile     = "export.json"
+	ProfileFile      = "profile.csv"
+	AssetsFile       = "assets.csv"
+	TransactionsFile = "transactions.csv"
+	PricesFile       = "prices.csv"
+
+	dateLayout = "2006-01-02"
+)
+
+var (
+	ErrMissingDocument    = errors.New("archive has no export document")
+	ErrUnsupportedVersion = errors.New("unsupported export format version")
+)
+
+// Document is the data of a user. Assets are referenced by code, so it can be
+// imported into another account.
+type Document struct {
+	FormatVersion int           `json:"format_version"`
+	ExportedAt    time.Time     `json:"exported_at"`
+	Profile       Profile       `json:"profile"`
+	Settings      Settings      `json:"settings"`
+	Assets        []Asset       `json:"assets"`
+	Transactions  []Transaction `json:"transactions"`
+	Prices        []Price       `json:"prices"`
+}
+
+type Profile struct {
+	Code      uuid.UUID `json:"code"`
+	UserName  string    `json:"user_name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Settings struct {
+	Currency        string `json:"currency"`
+	CostBasisMethod string `json:"cost_basis_method"`
+}
+
+type Asset struct {
+	Code               uuid.UUID           `json:"code"`
+	Name               string              `json:"name"`
+	Symbol             string              `json:"symbol"`
+	Ticker             *string             `json:"ticker"`
+	Currency           string              `json:"currency"`
+	CategoryID         int                 `json:"category_id"`
+	TotalUnits         decimal.Decimal     `json:"total_units"`
+	CurrentValue       decimal.NullDecimal `json:"current_value"`
+	InvestedTotal      decimal.Decimal     `json:"invested_total"`
+	AutoPricingEnabled bool                `json:"auto_pricing_enabled"`
+	PriceSource        *string             `json:"price_source"`
+	CreatedAt          time.Time           `json:"created_at"`
+}
+
+type Transaction struct {
+	Code      uuid.UUID       `json:"code"`
+	AssetCode uuid.UUID       `json:"asset_code"`
+	Type      string          `json:"type"`
+	Units     decimal.Decimal `json:"units"`
+	Total     decimal.Decimal `json:"total"`
+	FeeTotal  decimal.Decimal `json:"fee_total"`
+	Currency  string          `json:"currency"`
+	Note      *string         `json:"note"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Price is a price the user set for a day, Date being formatted as
+// "2006-01-02".
+type Price struct {
+	AssetCode uuid.UUID       `json:"asset_code"`
+	Date      string          `json:"date"`
+	Price     decimal.Decimal `json:"price"`
+	Currency  string          `json:"currency"`
+}
+
+// AssetData is an asset with its transactions and the prices set by the user.
+type AssetData struct {
+	Asset        entities.Asset
+	Transactions []entities.Transaction
+	Prices       []entities.AssetPrice
+}
+
+func NewDocument(user *entities.User, assets []AssetData, exportedAt time.Time) *Document {
+	doc := &Document{
+		FormatVersion: FormatVersion,
+		ExportedAt:    exportedAt,
+		Profile: Profile{
+			Code:      user.Code,
+			UserName:  user.Username,
+			Email:     user.Email,
+			CreatedAt: user.CreatedAt,
+		},
+		Settings: Settings{
+			Currency:        user.Currency,
+			CostBasisMethod: user.CostBasisMethod,
+		},
+		Assets:       []Asset{},
+		Transactions: []Transaction{},
+		Prices:       []Price{},
+	}
+
+	for _, data := range assets {
+		asset := data.Asset
+		doc.Assets = append(doc.Assets, Asset{
+			Code:               asset.Code,
+			Name:               asset.Name,
+			Symbol:             asset.Symbol,
+			Ticker:             asset.Ticker,
+			Currency:           asset.Currency,
+			CategoryID:         asset.CategoryID,
+			TotalUnits:         asset.TotalUnits,
+			CurrentValue:       asset.CurrentValue,
+			InvestedTotal:      asset.InvestedTotal,
+			AutoPricingEnabled: asset.AutoPricingEnabled,
+			PriceSource:        asset.PriceSource,
+			CreatedAt:          asset.CreatedAt,
+		})
+		for _, transaction := range data.Transactions {
+			doc.Transactions = append(doc.Transactions, Transaction{
+				Code:      transaction.Code,
+				AssetCode: asset.Code,
+				Type:      transaction.Type,
+				Units:     transaction.Units,
+				Total:     transaction.Total,
+				FeeTotal:  transaction.FeeTotal,
+				Currency:  transaction.Currency,
+				Note:      transaction.Note,
+				CreatedAt: transaction.CreatedAt,
+			})
+		}
+		for _, price := range data.Prices {
+			doc.Prices = append(doc.Prices, Price{
+				AssetCode: asset.Code,
+				Date:      entities.PriceDate(price.Date).Format(dateLayout),
+				Price:     price.Price,
+				Currency:  price.Currency,
+			})
+		}
+	}
+	return doc
+}
+
+// Rows returns the rows of the document, by CSV file, starting with their
+// header.
+func (d *Document) Rows() map[string][][]string {
+	profile := [][]string{
+		{"code", "user_name", "email", "currency", "cost_basis_method", "created_at"},
+		{d.Profile.Code.String(), d.Profile.UserName, d.Profile.Email, d.Settings.Currency, d.Settings.CostBasisMethod, formatTime(d.Profile.CreatedAt)},
+	}
+
+	assets := [][]string{{"code", "name", "symbol", "ticker", "currency", "category_id", "total_units", "current_value",
+		"invested_total", "auto_pricing_enabled", "price_source", "created_at"}}
+	for _, asset := range d.Assets {
+		currentValue := ""
+		if asset.CurrentValue.Valid {
+			currentValue = asset.CurrentValue.Decimal.String()
+		}
+		assets = append(assets, []string{asset.Code.String(), asset.Name, asset.Symbol, optional(asset.Ticker), asset.Currency,
+			strconv.Itoa(asset.CategoryID), asset.TotalUnits.String(), currentValue, asset.InvestedTotal.String(),
+			strconv.FormatBool(asset.AutoPricingEnabled), optional(asset.PriceSource), formatTime(asset.CreatedAt)})
+	}
+
+	transactions := [][]string{{"code", "asset_code", "type", "units", "total", "fee_total", "currency", "note", "created_at"}}
+	for _, transaction := range d.Transactions {
+		transactions = append(transactions, []string{transaction.Code.String(), transaction.AssetCode.String(), transaction.Type,
+			transaction.Units.String(), transaction.Total.String(), transaction.FeeTotal.String(), transaction.Currency,
+			optional(transaction.Note), formatTime(transaction.CreatedAt)})
+	}
+
+	prices := [][]string{{"asset_code", "date", "price", "currency"}}
+	for _, price := range d.Prices {
+		prices = append(prices, []string{price.AssetCode.String(), price.Date, price.Price.String(), price.Currency})
+	}
+
+	return map[string][][]string{
+		ProfileFile:      profile,
+		AssetsFile:       assets,
+		TransactionsFile: transactions,
+		PricesFile:       prices,
+	}
+}
+
+// WriteArchive writes the document and its CSV files as a ZIP archive.
+func WriteArchive(w io.Writer, doc *Document) error {
+	archive := zip.NewWriter(w)
+
+	file, err := archive.Create(DocumentFile)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	rows := doc.Rows()
+	for _, name := range []string{ProfileFile, AssetsFile, TransactionsFile, PricesFile} {
+		file, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		writer := csv.NewWriter(file)
+		if err := writer.WriteAll(rows[name]); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// ReadArchive reads the document of an archive, refusing versions newer than
+// FormatVersion.
+func ReadArchive(data []byte) (*Document, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	file, err := archive.Open(DocumentFile)
+	if err != nil {
+		return nil, ErrMissingDocument
+	}
+	defer file.Close()
+
+	var doc Document
+	if err := json.NewDecoder(file).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.FormatVersion < 1 || doc.FormatVersion > FormatVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, doc.FormatVersion)
+	}
+	return &doc, nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func optional(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,148 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var exportedAt = time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func d(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func newDocument() *Document {
+	ticker := "AAPL"
+	note := "first buy, at market"
+	user := &entities.User{
+		Code:            uuid.New(),
+		Username:        "ana",
+		Email:           "ana@example.com",
+		Currency:        "USD",
+		CostBasisMethod: "FIFO",
+		CreatedAt:       exportedAt.AddDate(-1, 0, 0),
+	}
+	asset := entities.Asset{
+		ID:            7,
+		Code:          uuid.New(),
+		Name:          "Apple",
+		Symbol:        "AAPL",
+		Ticker:        &ticker,
+		Currency:      "USD",
+		CategoryID:    1,
+		TotalUnits:    d("10"),
+		InvestedTotal: d("1500.5"),
+		CreatedAt:     exportedAt.AddDate(0, -2, 0),
+	}
+	return NewDocument(user, []AssetData{{
+		Asset: asset,
+		Transactions: []entities.Transaction{{
+			Code:      uuid.New(),
+			Type:      entities.TransactionTypeBuy,
+			Units:     d("10"),
+			Total:     d("1500.5"),
+			FeeTotal:  d("1"),
+			Currency:  "USD",
+			Note:      &note,
+			CreatedAt: exportedAt.AddDate(0, -2, 0),
+		}},
+		Prices: []entities.AssetPrice{{
+			Date:     time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC),
+			Price:    d("172.25"),
+			Currency: "USD",
+		}},
+	}}, exportedAt)
+}
+
+func Test_Archive(t *testing.T) {
+	t.Run("the document survives a round trip", func(t *testing.T) {
+		doc := newDocument()
+
+		var buffer bytes.Buffer
+		assert.NoError(t, WriteArchive(&buffer, doc))
+
+		read, err := ReadArchive(buffer.Bytes())
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, FormatVersion, read.FormatVersion)
+		assert.Equal(t, doc.Profile.Email, read.Profile.Email)
+		assert.Equal(t, doc.Settings, read.Settings)
+		if !assert.Len(t, read.Assets, 1) {
+			return
+		}
+		assert.Equal(t, doc.Assets[0].Code, read.Assets[0].Code)
+		assert.True(t, doc.Assets[0].InvestedTotal.Equal(read.Assets[0].InvestedTotal))
+		assert.False(t, read.Assets[0].CurrentValue.Valid)
+		if !assert.Len(t, read.Transactions, 1) {
+			return
+		}
+		assert.Equal(t, doc.Assets[0].Code, read.Transactions[0].AssetCode)
+		assert.Equal(t, doc.Transactions[0].Note, read.Transactions[0].Note)
+		if !assert.Len(t, read.Prices, 1) {
+			return
+		}
+		assert.Equal(t, "2025-03-09", read.Prices[0].Date)
+	})
+
+	t.Run("the CSV files carry the rows", func(t *testing.T) {
+		doc := newDocument()
+
+		var buffer bytes.Buffer
+		assert.NoError(t, WriteArchive(&buffer, doc))
+		archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		file, err := archive.Open(TransactionsFile)
+		if !assert.NoError(t, err) {
+			return
+		}
+		rows, err := csv.NewReader(file).ReadAll()
+		assert.NoError(t, err)
+		if !assert.Len(t, rows, 2) {
+			return
+		}
+		assert.Equal(t, "asset_code", rows[0][1])
+		assert.Equal(t, doc.Assets[0].Code.String(), rows[1][1])
+		assert.Equal(t, "first buy, at market", rows[1][7])
+
+		for _, name := range []string{DocumentFile, ProfileFile, AssetsFile, PricesFile} {
+			_, err := archive.Open(name)
+			assert.NoError(t, err, name)
+		}
+	})
+
+	t.Run("newer versions are refused", func(t *testing.T) {
+		doc := newDocument()
+		doc.FormatVersion = FormatVersion + 1
+
+		var buffer bytes.Buffer
+		archive := zip.NewWriter(&buffer)
+		file, err := archive.Create(DocumentFile)
+		assert.NoError(t, err)
+		assert.NoError(t, json.NewEncoder(file).Encode(doc))
+		assert.NoError(t, archive.Close())
+
+		_, err = ReadArchive(buffer.Bytes())
+		assert.ErrorIs(t, err, ErrUnsupportedVersion)
+	})
+
+	t.Run("archives without document are refused", func(t *testing.T) {
+		var buffer bytes.Buffer
+		assert.NoError(t, zip.NewWriter(&buffer).Close())
+
+		_, err := ReadArchive(buffer.Bytes())
+		assert.ErrorIs(t, err, ErrMissingDocument)
+	})
+}
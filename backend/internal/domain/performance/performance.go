@@ -0,0 +1,181 @@
+package performance
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const daysPerYear = 365.0
+
+var (
+	ErrInsufficientData = errors.New("not enough valuations to compute a return")
+	ErrNoCashFlows      = errors.New("no cash flows")
+	ErrNoSignChange     = errors.New("cash flows must contain both contributions and withdrawals")
+	ErrNoConvergence    = errors.New("rate of return did not converge")
+)
+
+// Valuation is the value of a position at the end of a day. NetFlow is the money
+// that moved into (positive) or out of (negative) the position during that day,
+// so Value already includes it.
+type Valuation struct {
+	Date    time.Time
+	Value   decimal.Decimal
+	NetFlow decimal.Decimal
+}
+
+// CashFlow is a movement of money seen from the investor: contributions are
+// negative and withdrawals, including the final value, are positive.
+type CashFlow struct {
+	Date   time.Time
+	Amount decimal.Decimal
+}
+
+// TimeWeightedReturn chains the returns of the sub-periods between valuations,
+// which removes the effect of the size and timing of the cash flows. The first
+// valuation is the starting point, its flow is not part of the return. Periods
+// that start without capital are skipped.
+func TimeWeightedReturn(valuations []Valuation) (decimal.Decimal, error) {
+	if len(valuations) < 2 {
+		return decimal.Zero, ErrInsufficientData
+	}
+
+	growth := decimal.NewFromInt(1)
+	periods := 0
+	for i := 1; i < len(valuations); i++ {
+		start := valuations[i-1].Value
+		if !start.IsPositive() {
+			continue
+		}
+		end := valuations[i].Value.Sub(valuations[i].NetFlow)
+		growth = growth.Mul(end.Div(start))
+		periods++
+	}
+	if periods == 0 {
+		return decimal.Zero, ErrInsufficientData
+	}
+
+	return growth.Sub(decimal.NewFromInt(1)), nil
+}
+
+// Annualize converts a return over the given number of days into a yearly rate.
+func Annualize(rate decimal.Decimal, days int) (decimal.Decimal, error) {
+	if days <= 0 {
+		return decimal.Zero, ErrInsufficientData
+	}
+	growth := 1 + rate.InexactFloat64()
+	if growth < 0 {
+		return decimal.Zero, ErrNoConvergence
+	}
+	return decimal.NewFromFloat(math.Pow(growth, daysPerYear/float64(days)) - 1), nil
+}
+
+const (
+	xirrTolerance     = 1e-10
+	xirrMaxIterations = 100
+	minRate           = -0.999999
+	maxRate           = 1e6
+)
+
+// XIRR returns the annualized money-weighted return, the rate that makes the net
+// present value of the flows zero. It tries Newton's method first and falls back
+// to bisection over a bracket with a sign change when Newton does not converge.
+func XIRR(flows []CashFlow) (decimal.Decimal, error) {
+	if len(flows) == 0 {
+		return decimal.Zero, ErrNoCashFlows
+	}
+
+	sorted := make([]CashFlow, len(flows))
+	copy(sorted, flows)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	hasPositive, hasNegative := false, false
+	years := make([]float64, len(sorted))
+	amounts := make([]float64, len(sorted))
+	for i, flow := range sorted {
+		years[i] = sorted[i].Date.Sub(sorted[0].Date).Hours() / 24 / daysPerYear
+		amounts[i] = flow.Amount.InexactFloat64()
+		hasPositive = hasPositive || amounts[i] > 0
+		hasNegative = hasNegative || amounts[i] < 0
+	}
+	if !hasPositive || !hasNegative {
+		return decimal.Zero, ErrNoSignChange
+	}
+
+	npv := func(rate float64) float64 {
+		var total float64
+		for i := range amounts {
+			total += amounts[i] / math.Pow(1+rate, years[i])
+		}
+		return total
+	}
+	derivative := func(rate float64) float64 {
+		var total float64
+		for i := range amounts {
+			total -= years[i] * amounts[i] / math.Pow(1+rate, years[i]+1)
+		}
+		return total
+	}
+
+	if rate, ok := newton(npv, derivative, 0.1); ok {
+		return decimal.NewFromFloat(rate), nil
+	}
+	if rate, ok := bisect(npv); ok {
+		return decimal.NewFromFloat(rate), nil
+	}
+	return decimal.Zero, ErrNoConvergence
+}
+
+func newton(f, df func(float64) float64, guess float64) (float64, bool) {
+	rate := guess
+	for i := 0; i < xirrMaxIterations; i++ {
+		value := f(rate)
+		if math.Abs(value) < xirrTolerance {
+			return rate, true
+		}
+		slope := df(rate)
+		if slope == 0 || math.IsNaN(slope) || math.IsInf(slope, 0) {
+			return 0, false
+		}
+		next := rate - value/slope
+		if math.IsNaN(next) || math.IsInf(next, 0) || next <= minRate || next > maxRate {
+			return 0, false
+		}
+		if math.Abs(next-rate) < xirrTolerance {
+			return next, true
+		}
+		rate = next
+	}
+	return 0, false
+}
+
+// bisect looks for a bracket with a sign change by widening the upper bound and
+// then halves it until the root is isolated.
+func bisect(f func(float64) float64) (float64, bool) {
+	low, high := minRate, 1.0
+	fLow, fHigh := f(low), f(high)
+	for fLow*fHigh > 0 && high < maxRate {
+		high *= 2
+		fHigh = f(high)
+	}
+	if math.IsNaN(fLow) || math.IsNaN(fHigh) || fLow*fHigh > 0 {
+		return 0, false
+	}
+
+	for i := 0; i < xirrMaxIterations*2; i++ {
+		mid := (low + high) / 2
+		fMid := f(mid)
+		if math.Abs(fMid) < xirrTolerance || (high-low)/2 < xirrTolerance {
+			return mid, true
+		}
+		if fLow*fMid < 0 {
+			high = mid
+		} else {
+			low, fLow = mid, fMid
+		}
+	}
+	return 0, false
+}
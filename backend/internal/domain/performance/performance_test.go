@@ -0,0 +1,170 @@
+package performance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var baseTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func d(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func valuation(day int, value, flow string) Valuation {
+	return Valuation{Date: baseTime.AddDate(0, 0, day), Value: d(value), NetFlow: d(flow)}
+}
+
+func flow(day int, amount string) CashFlow {
+	return CashFlow{Date: baseTime.AddDate(0, 0, day), Amount: d(amount)}
+}
+
+func Test_TimeWeightedReturn(t *testing.T) {
+	testCases := []struct {
+		name          string
+		valuations    []Valuation
+		expected      string
+		expectedError error
+	}{
+		{
+			name:       "no flows is the plain growth",
+			valuations: []Valuation{valuation(0, "100", "0"), valuation(30, "110", "0")},
+			expected:   "0.1",
+		},
+		{
+			name: "a deposit does not count as return",
+			// +10% on the first period, deposit of 100, then +10% on 210.
+			valuations: []Valuation{
+				valuation(0, "100", "0"),
+				valuation(10, "210", "100"),
+				valuation(20, "231", "0"),
+			},
+			expected: "0.21",
+		},
+		{
+			name: "a withdrawal does not count as loss",
+			valuations: []Valuation{
+				valuation(0, "100", "0"),
+				valuation(10, "60", "-50"),
+				valuation(20, "66", "0"),
+			},
+			expected: "0.21",
+		},
+		{
+			name: "periods without capital are skipped",
+			valuations: []Valuation{
+				valuation(0, "0", "0"),
+				valuation(5, "100", "100"),
+				valuation(10, "90", "0"),
+			},
+			expected: "-0.1",
+		},
+		{
+			name:          "a single valuation is not enough",
+			valuations:    []Valuation{valuation(0, "100", "0")},
+			expectedError: ErrInsufficientData,
+		},
+		{
+			name:          "without capital there is no return",
+			valuations:    []Valuation{valuation(0, "0", "0"), valuation(10, "0", "0")},
+			expectedError: ErrInsufficientData,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := TimeWeightedReturn(tc.valuations)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, d(tc.expected).Equal(result.Round(10)), "expected %s, got %s", tc.expected, result)
+		})
+	}
+}
+
+func Test_Annualize(t *testing.T) {
+	rate, err := Annualize(d("0.21"), 730)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.1, rate.InexactFloat64(), 1e-9)
+
+	_, err = Annualize(d("0.1"), 0)
+	assert.ErrorIs(t, err, ErrInsufficientData)
+}
+
+func Test_XIRR(t *testing.T) {
+	testCases := []struct {
+		name          string
+		flows         []CashFlow
+		expected      float64
+		expectedError error
+	}{
+		{
+			name:     "one year at ten percent",
+			flows:    []CashFlow{flow(0, "-100"), flow(365, "110")},
+			expected: 0.1,
+		},
+		{
+			name:     "a total loss close to minus one hundred percent",
+			flows:    []CashFlow{flow(0, "-100"), flow(365, "0.01")},
+			expected: -0.9999,
+		},
+		{
+			name: "excel reference example",
+			flows: []CashFlow{
+				{Date: time.Date(2008, 1, 1, 0, 0, 0, 0, time.UTC), Amount: d("-10000")},
+				{Date: time.Date(2008, 3, 1, 0, 0, 0, 0, time.UTC), Amount: d("2750")},
+				{Date: time.Date(2008, 10, 30, 0, 0, 0, 0, time.UTC), Amount: d("4250")},
+				{Date: time.Date(2009, 2, 15, 0, 0, 0, 0, time.UTC), Amount: d("3250")},
+				{Date: time.Date(2009, 4, 1, 0, 0, 0, 0, time.UTC), Amount: d("2750")},
+			},
+			expected: 0.373362535,
+		},
+		{
+			name:     "unordered flows are sorted",
+			flows:    []CashFlow{flow(365, "110"), flow(0, "-100")},
+			expected: 0.1,
+		},
+		{
+			name:     "a very high rate still converges",
+			flows:    []CashFlow{flow(0, "-100"), flow(100, "1000")},
+			expected: 4466.8359,
+		},
+		{
+			name:          "no flows",
+			expectedError: ErrNoCashFlows,
+		},
+		{
+			name:          "a single flow",
+			flows:         []CashFlow{flow(0, "-100")},
+			expectedError: ErrNoSignChange,
+		},
+		{
+			name:          "only contributions",
+			flows:         []CashFlow{flow(0, "-100"), flow(30, "-50")},
+			expectedError: ErrNoSignChange,
+		},
+		{
+			name: "flows without a real root do not converge",
+			// 100 - 100x + 100x² has no real root, the net present value never crosses zero.
+			flows:         []CashFlow{flow(0, "100"), flow(365, "-100"), flow(730, "100")},
+			expectedError: ErrNoConvergence,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := XIRR(tc.flows)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.InEpsilon(t, tc.expected, result.InexactFloat64(), 1e-3)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package request
+
+import (
+	"strings"
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/validation"
+)
+
+const maxAPIKeyNameLength = 100
+
+// CreateAPIKey creates a key that never expires when ExpiresAt is nil.
+type CreateAPIKey struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (r *CreateAPIKey) Validate(v *validation.Errors) {
+	v.Required("name", r.Name)
+	v.Check(len([]rune(r.Name)) <= maxAPIKeyNameLength, "name", "must have at most 100 characters")
+	v.Check(len(r.Scopes) > 0, "scopes", "is required")
+	for _, scope := range r.Scopes {
+		v.Check(entities.IsValidAPIKeyScope(scope), "scopes", "must be any of: "+strings.Join(entities.APIKeyScopes(), ", "))
+	}
+}
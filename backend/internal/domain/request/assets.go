@@ -0,0 +1,45 @@
+package request
+
+import (
+	"github.com/juanMaAV92/zenith-financial/backend/utils/validation"
+	"github.com/shopspring/decimal"
+)
+
+type CreateAsset struct {
+	Name               string           `json:"name"`
+	Symbol             string           `json:"symbol"`
+	Ticker             *string          `json:"ticker"`
+	Currency           string           `json:"currency"`
+	CategoryID         int              `json:"category_id"`
+	TotalUnits         decimal.Decimal  `json:"total_units"`
+	CurrentValue       *decimal.Decimal `json:"current_value"`
+	InvestedTotal      decimal.Decimal  `json:"invested_total"`
+	AutoPricingEnabled *bool            `json:"auto_pricing_enabled"`
+	PriceSource        *string          `json:"price_source"`
+}
+
+func (r *CreateAsset) Validate(v *validation.Errors) {
+	v.Required("name", r.Name)
+	v.Required("symbol", r.Symbol)
+	v.Required("currency", r.Currency)
+	v.AssetCurrency("currency", r.Currency)
+}
+
+type UpdateAsset struct {
+	Name               *string          `json:"name"`
+	Symbol             *string          `json:"symbol"`
+	Ticker             *string          `json:"ticker"`
+	CategoryID         *int             `json:"category_id"`
+	CurrentValue       *decimal.Decimal `json:"current_value"`
+	AutoPricingEnabled *bool            `json:"auto_pricing_enabled"`
+	PriceSource        *string          `json:"price_source"`
+}
+
+func (r *UpdateAsset) Validate(v *validation.Errors) {
+	if r.Name != nil {
+		v.Required("name", *r.Name)
+	}
+	if r.Symbol != nil {
+		v.Required("symbol", *r.Symbol)
+	}
+}
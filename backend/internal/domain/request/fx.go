@@ -0,0 +1,20 @@
+package request
+
+import (
+	"github.com/juanMaAV92/zenith-financial/backend/utils/validation"
+	"github.com/shopspring/decimal"
+)
+
+type ConvertAmount struct {
+	Amount decimal.Decimal `query:"amount"`
+	From   string          `query:"from"`
+	To     string          `query:"to"`
+	Date   string          `query:"date"`
+}
+
+func (r *ConvertAmount) Validate(v *validation.Errors) {
+	v.Required("from", r.From)
+	v.Currency("from", r.From)
+	v.Required("to", r.To)
+	v.Currency("to", r.To)
+}
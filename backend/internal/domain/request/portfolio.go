@@ -0,0 +1,7 @@
+package request
+
+type PortfolioPerformance struct {
+	From    string `query:"from"`
+	To      string `query:"to"`
+	GroupBy string `query:"group_by"`
+}
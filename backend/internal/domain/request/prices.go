@@ -0,0 +1,15 @@
+package request
+
+import (
+	"github.com/juanMaAV92/zenith-financial/backend/utils/validation"
+	"github.com/shopspring/decimal"
+)
+
+type CreateAssetPrice struct {
+	Date  string          `json:"date"`
+	Price decimal.Decimal `json:"price"`
+}
+
+func (r *CreateAssetPrice) Validate(v *validation.Errors) {
+	v.Required("date", r.Date)
+}
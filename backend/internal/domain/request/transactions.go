@@ -0,0 +1,24 @@
+package request
+
+import (
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/utils/validation"
+	"github.com/shopspring/decimal"
+)
+
+type CreateTransaction struct {
+	Type       string          `json:"type"`
+	Units      decimal.Decimal `json:"units"`
+	Total      decimal.Decimal `json:"total"`
+	FeeTotal   decimal.Decimal `json:"fee_total"`
+	Currency   string          `json:"currency"`
+	Note       *string         `json:"note"`
+	ExecutedAt *time.Time      `json:"executed_at"`
+}
+
+// Validate leaves the type and the amounts to the service. An empty currency
+// defaults to the one of the asset.
+func (r *CreateTransaction) Validate(v *validation.Errors) {
+	v.AssetCurrency("currency", r.Currency)
+}
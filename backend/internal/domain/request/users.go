@@ -1,17 +1,157 @@
 package request
 
+import "github.com/juanMaAV92/zenith-financial/backend/utils/validation"
+
 type CreateUser struct {
-	UserName string `json:"user_name"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Currency string `json:"currency"`
+	UserName        string `json:"user_name"`
+	Email           string `json:"email"`
+	Password        string `json:"password"`
+	Currency        string `json:"currency"`
+	CostBasisMethod string `json:"cost_basis_method"`
+}
+
+func (r *CreateUser) Validate(v *validation.Errors) {
+	v.Username("user_name", r.UserName)
+	v.Email("email", r.Email)
+	v.Password("password", r.Password)
+	v.Currency("currency", r.Currency)
 }
 
+// UserLogin identifies the device of the session. UserAgent and IPAddress are
+// taken from the request, not from the body.
 type UserLogin struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email       string `json:"email"`
+	Password    string `json:"password"`
+	DeviceLabel string `json:"device_label"`
+	UserAgent   string `json:"-"`
+	IPAddress   string `json:"-"`
+}
+
+// Validate does not check the strength of the password, as accounts created
+// before the rules were added must still be able to log in.
+func (r *UserLogin) Validate(v *validation.Errors) {
+	v.Email("email", r.Email)
+	v.Required("password", r.Password)
 }
 
 type RefreshToken struct {
 	RefreshToken string `json:"refresh_token"`
 }
+
+func (r *RefreshToken) Validate(v *validation.Errors) {
+	v.Required("refresh_token", r.RefreshToken)
+}
+
+type ForgotPassword struct {
+	Email string `json:"email"`
+}
+
+func (r *ForgotPassword) Validate(v *validation.Errors) {
+	v.Email("email", r.Email)
+}
+
+type ResetPassword struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (r *ResetPassword) Validate(v *validation.Errors) {
+	v.Required("token", r.Token)
+	v.Password("password", r.Password)
+}
+
+type VerifyEmail struct {
+	Token string `json:"token"`
+}
+
+func (r *VerifyEmail) Validate(v *validation.Errors) {
+	v.Required("token", r.Token)
+}
+
+type ResendVerification struct {
+	Email string `json:"email"`
+}
+
+func (r *ResendVerification) Validate(v *validation.Errors) {
+	v.Email("email", r.Email)
+}
+
+// MFACode is a TOTP code, or a recovery code where the request allows one.
+type MFACode struct {
+	Code string `json:"code"`
+}
+
+func (r *MFACode) Validate(v *validation.Errors) {
+	v.Required("code", r.Code)
+}
+
+// MFALogin completes a login that required a second factor. Like in UserLogin,
+// UserAgent and IPAddress are taken from the request.
+type MFALogin struct {
+	MFAToken    string `json:"mfa_token"`
+	Code        string `json:"code"`
+	DeviceLabel string `json:"device_label"`
+	UserAgent   string `json:"-"`
+	IPAddress   string `json:"-"`
+}
+
+func (r *MFALogin) Validate(v *validation.Errors) {
+	v.Required("mfa_token", r.MFAToken)
+	v.Required("code", r.Code)
+}
+
+// OIDCCallback carries the code and state the provider redirected the user
+// back with. As in UserLogin, UserAgent and IPAddress are taken from the
+// request.
+type OIDCCallback struct {
+	Provider    string `param:"provider" json:"-"`
+	Code        string `json:"code"`
+	State       string `json:"state"`
+	DeviceLabel string `json:"device_label"`
+	UserAgent   string `json:"-"`
+	IPAddress   string `json:"-"`
+}
+
+func (r *OIDCCallback) Validate(v *validation.Errors) {
+	v.Required("code", r.Code)
+	v.Required("state", r.State)
+}
+
+// UpdateUser changes the fields that are set. CurrentPassword is required to
+// change the email of users with a password.
+type UpdateUser struct {
+	UserName        *string `json:"user_name"`
+	Email           *string `json:"email"`
+	Currency        *string `json:"currency"`
+	CostBasisMethod *string `json:"cost_basis_method"`
+	CurrentPassword string  `json:"current_password"`
+}
+
+func (r *UpdateUser) Validate(v *validation.Errors) {
+	if r.UserName != nil {
+		v.Username("user_name", *r.UserName)
+	}
+	if r.Email != nil {
+		v.Email("email", *r.Email)
+	}
+	if r.Currency != nil {
+		v.Required("currency", *r.Currency)
+		v.Currency("currency", *r.Currency)
+	}
+}
+
+type ChangePassword struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+func (r *ChangePassword) Validate(v *validation.Errors) {
+	v.Required("current_password", r.CurrentPassword)
+	v.Password("new_password", r.NewPassword)
+}
+
+// DeleteAccount confirms the deletion with the password of users that have
+// one.
+type DeleteAccount struct {
+	Password string `json:"password"`
+}
@@ -0,0 +1,36 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+)
+
+type APIKey struct {
+	Code       uuid.UUID  `json:"code"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+// CreatedAPIKey carries the key itself, shown once, only its hash is kept.
+type CreatedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+func ToAPIKeyResponse(key *entities.APIKey) *APIKey {
+	return &APIKey{
+		Code:       key.Code,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		ExpiresAt:  key.ExpiresAt,
+	}
+}
@@ -0,0 +1,58 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+)
+
+type Asset struct {
+	Code               uuid.UUID        `json:"code"`
+	Name               string           `json:"name"`
+	Symbol             string           `json:"symbol"`
+	Ticker             *string          `json:"ticker"`
+	Currency           string           `json:"currency"`
+	CategoryID         int              `json:"category_id"`
+	Category           string           `json:"category"`
+	TotalUnits         decimal.Decimal  `json:"total_units"`
+	CurrentValue       *decimal.Decimal `json:"current_value"`
+	InvestedTotal      decimal.Decimal  `json:"invested_total"`
+	AutoPricingEnabled bool             `json:"auto_pricing_enabled"`
+	PriceSource        *string          `json:"price_source"`
+	CreatedAt          time.Time        `json:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at"`
+}
+
+func ToAssetResponse(asset *entities.Asset) *Asset {
+	category, _ := entities.CategoryName(asset.CategoryID)
+	var currentValue *decimal.Decimal
+	if asset.CurrentValue.Valid {
+		currentValue = &asset.CurrentValue.Decimal
+	}
+	return &Asset{
+		Code:               asset.Code,
+		Name:               asset.Name,
+		Symbol:             asset.Symbol,
+		Ticker:             asset.Ticker,
+		Currency:           asset.Currency,
+		CategoryID:         asset.CategoryID,
+		Category:           category,
+		TotalUnits:         asset.TotalUnits,
+		CurrentValue:       currentValue,
+		InvestedTotal:      asset.InvestedTotal,
+		AutoPricingEnabled: asset.AutoPricingEnabled,
+		PriceSource:        asset.PriceSource,
+		CreatedAt:          asset.CreatedAt,
+		UpdatedAt:          asset.UpdatedAt,
+	}
+}
+
+func ToAssetsResponse(assets []entities.Asset) []*Asset {
+	result := make([]*Asset, 0, len(assets))
+	for i := range assets {
+		result = append(result, ToAssetResponse(&assets[i]))
+	}
+	return result
+}
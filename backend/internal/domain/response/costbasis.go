@@ -0,0 +1,40 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type CostBasis struct {
+	AssetCode       uuid.UUID        `json:"asset_code"`
+	Method          string           `json:"method"`
+	Currency        string           `json:"currency"`
+	TotalUnits      decimal.Decimal  `json:"total_units"`
+	TotalCost       decimal.Decimal  `json:"total_cost"`
+	AverageUnitCost decimal.Decimal  `json:"average_unit_cost"`
+	RealizedGain    decimal.Decimal  `json:"realized_gain"`
+	MarketValue     *decimal.Decimal `json:"market_value"`
+	UnrealizedGain  *decimal.Decimal `json:"unrealized_gain"`
+	Lots            []*TaxLot        `json:"lots"`
+	Sales           []*RealizedSale  `json:"sales"`
+}
+
+type TaxLot struct {
+	TransactionCode uuid.UUID       `json:"transaction_code"`
+	AcquiredAt      time.Time       `json:"acquired_at"`
+	Units           decimal.Decimal `json:"units"`
+	Cost            decimal.Decimal `json:"cost"`
+	UnitCost        decimal.Decimal `json:"unit_cost"`
+}
+
+type RealizedSale struct {
+	TransactionCode uuid.UUID       `json:"transaction_code"`
+	SoldAt          time.Time       `json:"sold_at"`
+	Units           decimal.Decimal `json:"units"`
+	Proceeds        decimal.Decimal `json:"proceeds"`
+	Fee             decimal.Decimal `json:"fee"`
+	CostBasis       decimal.Decimal `json:"cost_basis"`
+	RealizedGain    decimal.Decimal `json:"realized_gain"`
+}
@@ -0,0 +1,23 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Export is an export generated in the background. DownloadURL is set once its
+// archive is ready, and works until ExpiresAt.
+type Export struct {
+	Code        uuid.UUID  `json:"code"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	DownloadURL string     `json:"download_url,omitempty"`
+}
+
+// ExportArchive is the ZIP archive of an export.
+type ExportArchive struct {
+	Filename string
+	Content  []byte
+}
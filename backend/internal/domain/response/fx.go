@@ -0,0 +1,12 @@
+package response
+
+import "github.com/shopspring/decimal"
+
+type Conversion struct {
+	Amount          decimal.Decimal `json:"amount"`
+	From            string          `json:"from"`
+	To              string          `json:"to"`
+	Date            string          `json:"date"`
+	Rate            decimal.Decimal `json:"rate"`
+	ConvertedAmount decimal.Decimal `json:"converted_amount"`
+}
@@ -0,0 +1,12 @@
+package response
+
+// MFAEnrollment is shown once, to be scanned by an authenticator app.
+type MFAEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFARecoveryCodes are shown once, only their hashes are kept.
+type MFARecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
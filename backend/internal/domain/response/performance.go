@@ -0,0 +1,28 @@
+package response
+
+import "github.com/shopspring/decimal"
+
+// PortfolioPerformance amounts are in Currency, the user's base currency.
+type PortfolioPerformance struct {
+	Currency       string              `json:"currency"`
+	From           string              `json:"from"`
+	To             string              `json:"to"`
+	GroupBy        string              `json:"group_by"`
+	Groups         []*PerformanceGroup `json:"groups"`
+	UnvaluedAssets []*UnvaluedAsset    `json:"unvalued_assets"`
+}
+
+// PerformanceGroup returns are percentages. A return is null when it cannot be
+// computed for the period, Notes explains why.
+type PerformanceGroup struct {
+	Key                          string           `json:"key"`
+	Name                         string           `json:"name"`
+	StartValue                   decimal.Decimal  `json:"start_value"`
+	EndValue                     decimal.Decimal  `json:"end_value"`
+	NetFlows                     decimal.Decimal  `json:"net_flows"`
+	Gain                         decimal.Decimal  `json:"gain"`
+	TimeWeightedReturn           *decimal.Decimal `json:"time_weighted_return"`
+	AnnualizedTimeWeightedReturn *decimal.Decimal `json:"annualized_time_weighted_return"`
+	MoneyWeightedReturn          *decimal.Decimal `json:"money_weighted_return"`
+	Notes                        []string         `json:"notes,omitempty"`
+}
@@ -0,0 +1,52 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// PortfolioSummary amounts are in Currency, the user's base currency, unless a
+// field says otherwise.
+type PortfolioSummary struct {
+	Currency                 string             `json:"currency"`
+	NetWorth                 decimal.Decimal    `json:"net_worth"`
+	InvestedTotal            decimal.Decimal    `json:"invested_total"`
+	UnrealizedGain           decimal.Decimal    `json:"unrealized_gain"`
+	UnrealizedGainPercentage decimal.Decimal    `json:"unrealized_gain_percentage"`
+	AsOf                     time.Time          `json:"as_of"`
+	ByCategory               []*Allocation      `json:"by_category"`
+	ByCurrency               []*Allocation      `json:"by_currency"`
+	ByAsset                  []*AssetAllocation `json:"by_asset"`
+	UnvaluedAssets           []*UnvaluedAsset   `json:"unvalued_assets"`
+}
+
+type Allocation struct {
+	Name       string          `json:"name"`
+	Value      decimal.Decimal `json:"value"`
+	Percentage decimal.Decimal `json:"percentage"`
+	Count      int             `json:"count"`
+}
+
+type AssetAllocation struct {
+	Code           uuid.UUID       `json:"code"`
+	Name           string          `json:"name"`
+	Symbol         string          `json:"symbol"`
+	Category       string          `json:"category"`
+	AssetCurrency  string          `json:"asset_currency"`
+	TotalUnits     decimal.Decimal `json:"total_units"`
+	UnitPrice      decimal.Decimal `json:"unit_price"`
+	PriceSource    string          `json:"price_source"`
+	Value          decimal.Decimal `json:"value"`
+	InvestedTotal  decimal.Decimal `json:"invested_total"`
+	UnrealizedGain decimal.Decimal `json:"unrealized_gain"`
+	Percentage     decimal.Decimal `json:"percentage"`
+}
+
+// UnvaluedAsset is an asset left out of the totals because it could not be valued.
+type UnvaluedAsset struct {
+	Code   uuid.UUID `json:"code"`
+	Name   string    `json:"name"`
+	Reason string    `json:"reason"`
+}
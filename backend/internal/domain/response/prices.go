@@ -0,0 +1,21 @@
+package response
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type PricePoint struct {
+	Date     string          `json:"date"`
+	Price    decimal.Decimal `json:"price"`
+	Currency string          `json:"currency"`
+	Source   string          `json:"source"`
+	Filled   bool            `json:"filled"`
+}
+
+type PriceSeries struct {
+	AssetCode uuid.UUID     `json:"asset_code"`
+	From      string        `json:"from"`
+	To        string        `json:"to"`
+	Points    []*PricePoint `json:"points"`
+}
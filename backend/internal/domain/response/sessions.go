@@ -0,0 +1,32 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+)
+
+type Session struct {
+	Code        uuid.UUID `json:"code"`
+	DeviceLabel string    `json:"device_label"`
+	UserAgent   string    `json:"user_agent"`
+	IPAddress   string    `json:"ip_address"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Current     bool      `json:"current"`
+}
+
+func ToSessionResponse(session *entities.Session, current uuid.UUID) *Session {
+	return &Session{
+		Code:        session.Code,
+		DeviceLabel: session.DeviceLabel,
+		UserAgent:   session.UserAgent,
+		IPAddress:   session.IPAddress,
+		CreatedAt:   session.CreatedAt,
+		LastUsedAt:  session.LastUsedAt,
+		ExpiresAt:   session.ExpiresAt,
+		Current:     session.Code == current,
+	}
+}
@@ -0,0 +1,46 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+)
+
+type Transaction struct {
+	Code       uuid.UUID       `json:"code"`
+	Type       string          `json:"type"`
+	Units      decimal.Decimal `json:"units"`
+	Total      decimal.Decimal `json:"total"`
+	FeeTotal   decimal.Decimal `json:"fee_total"`
+	Currency   string          `json:"currency"`
+	Note       *string         `json:"note"`
+	ExecutedAt time.Time       `json:"executed_at"`
+}
+
+type TransactionResult struct {
+	Transaction *Transaction `json:"transaction"`
+	Asset       *Asset       `json:"asset"`
+}
+
+func ToTransactionResponse(transaction *entities.Transaction) *Transaction {
+	return &Transaction{
+		Code:       transaction.Code,
+		Type:       transaction.Type,
+		Units:      transaction.Units,
+		Total:      transaction.Total,
+		FeeTotal:   transaction.FeeTotal,
+		Currency:   transaction.Currency,
+		Note:       transaction.Note,
+		ExecutedAt: transaction.CreatedAt,
+	}
+}
+
+func ToTransactionsResponse(transactions []entities.Transaction) []*Transaction {
+	result := make([]*Transaction, 0, len(transactions))
+	for i := range transactions {
+		result = append(result, ToTransactionResponse(&transactions[i]))
+	}
+	return result
+}
@@ -8,16 +8,23 @@ import (
 )
 
 type User struct {
-	Code      uuid.UUID `json:"code"`
-	UserName  string    `json:"user_name"`
-	Email     string    `json:"email"`
-	Currency  string    `json:"currency"`
-	CreatedAt time.Time `json:"created_at"`
+	Code            uuid.UUID `json:"code"`
+	UserName        string    `json:"user_name"`
+	Email           string    `json:"email"`
+	Currency        string    `json:"currency"`
+	CostBasisMethod string    `json:"cost_basis_method"`
+	EmailVerified   bool      `json:"email_verified"`
+	MFAEnabled      bool      `json:"mfa_enabled"`
+	CreatedAt       time.Time `json:"created_at"`
 }
 
+// UserLogin holds either the user and their tokens or, when the user has a
+// second factor, the MFA challenge to complete the login with.
 type UserLogin struct {
 	*User
 	*TokensResponse
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
 }
 
 type TokensResponse struct {
@@ -27,10 +34,25 @@ type TokensResponse struct {
 
 func ToUserResponse(user *entities.User) *User {
 	return &User{
-		Code:      user.Code,
-		UserName:  user.Username,
-		Email:     user.Email,
-		Currency:  user.Currency,
-		CreatedAt: user.CreatedAt,
+		Code:            user.Code,
+		UserName:        user.Username,
+		Email:           user.Email,
+		Currency:        user.Currency,
+		CostBasisMethod: user.CostBasisMethod,
+		EmailVerified:   user.EmailVerifiedAt != nil,
+		MFAEnabled:      user.MFAEnabled(),
+		CreatedAt:       user.CreatedAt,
 	}
 }
+
+// OIDCAuthorization is the provider URL the user is sent to for an OpenID
+// Connect login.
+type OIDCAuthorization struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// AccountDeletion tells when the data of a deleted account is purged.
+type AccountDeletion struct {
+	DeletedAt  time.Time `json:"deleted_at"`
+	PurgeAfter time.Time `json:"purge_after"`
+}
@@ -0,0 +1,16 @@
+package entities
+
+import "time"
+
+// AccountDeletion schedules the purge of a deleted account, with its assets and
+// transactions, once PurgeAfter is reached.
+type AccountDeletion struct {
+	ID          uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID      uint64    `gorm:"column:user_id;not null;unique" json:"user_id"`
+	RequestedAt time.Time `gorm:"column:requested_at;type:timestamp with time zone;not null;default:now()" json:"requested_at"`
+	PurgeAfter  time.Time `gorm:"column:purge_after;type:timestamp with time zone;not null" json:"purge_after"`
+}
+
+func (AccountDeletion) TableName() string {
+	return "AccountDeletions"
+}
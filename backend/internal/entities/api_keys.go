@@ -0,0 +1,61 @@
+package entities
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix starts every API key, so they are told apart from access tokens
+// and recognized by secret scanners.
+const APIKeyPrefix = "zfk_"
+
+const (
+	APIKeyScopePortfolioRead     = "portfolio:read"
+	APIKeyScopeAssetsWrite       = "assets:write"
+	APIKeyScopeTransactionsWrite = "transactions:write"
+)
+
+// APIKey is a long-lived credential of a user for scripts. Only the hash of the
+// key is kept, Prefix is enough for the user to recognize it.
+type APIKey struct {
+	ID         uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Code       uuid.UUID  `gorm:"column:code;type:uuid;not null;unique;default:gen_random_uuid()" json:"code"`
+	UserID     uint64     `gorm:"column:user_id;not null;index" json:"user_id"`
+	Name       string     `gorm:"column:name;type:varchar(100);not null" json:"name"`
+	Prefix     string     `gorm:"column:prefix;type:varchar(16);not null" json:"prefix"`
+	KeyHash    string     `gorm:"column:key_hash;type:varchar(64);not null;unique" json:"-"`
+	Scopes     string     `gorm:"column:scopes;type:text;not null" json:"scopes"`
+	CreatedAt  time.Time  `gorm:"column:created_at;type:timestamp with time zone;not null;default:now()" json:"created_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at;type:timestamp with time zone" json:"last_used_at"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at;type:timestamp with time zone" json:"expires_at"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at;type:timestamp with time zone" json:"revoked_at"`
+}
+
+func (APIKey) TableName() string {
+	return "APIKeys"
+}
+
+// IsActive reports whether the key is neither revoked nor expired.
+func (k APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// ScopeList returns the scopes of the key, stored comma separated.
+func (k APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return nil
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+func IsValidAPIKeyScope(scope string) bool {
+	return slices.Contains(APIKeyScopes(), scope)
+}
+
+// APIKeyScopes returns every scope an API key can be granted.
+func APIKeyScopes() []string {
+	return []string{APIKeyScopePortfolioRead, APIKeyScopeAssetsWrite, APIKeyScopeTransactionsWrite}
+}
@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type AssetPrice struct {
+	ID        uint64          `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	AssetID   uint64          `gorm:"column:asset_id;not null;uniqueIndex:idx_asset_prices_asset_date" json:"asset_id"`
+	Date      time.Time       `gorm:"column:date;type:date;not null;uniqueIndex:idx_asset_prices_asset_date" json:"date"`
+	Price     decimal.Decimal `gorm:"column:price;type:decimal;not null" json:"price"`
+	Currency  string          `gorm:"column:currency;type:varchar(3);not null" json:"currency"`
+	Source    string          `gorm:"column:source;type:varchar(255);not null" json:"source"`
+	CreatedAt time.Time       `gorm:"column:created_at;type:timestamp with time zone;not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time       `gorm:"column:updated_at;type:timestamp with time zone;not null;default:now()" json:"updated_at"`
+}
+
+func (AssetPrice) TableName() string {
+	return "AssetPrices"
+}
+
+// PriceDate normalizes a timestamp to the UTC calendar day used by AssetPrice.Date.
+func PriceDate(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,41 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type Asset struct {
+	ID                 uint64              `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Code               uuid.UUID           `gorm:"column:code;type:uuid;not null;default:gen_random_uuid()" json:"code"`
+	UserID             uint64              `gorm:"column:user_id;not null" json:"user_id"`
+	Name               string              `gorm:"column:name;type:varchar(255);not null" json:"name"`
+	Symbol             string              `gorm:"column:symbol;type:varchar(255);not null" json:"symbol"`
+	Ticker             *string             `gorm:"column:ticker;type:varchar(255)" json:"ticker"`
+	Currency           string              `gorm:"column:currency;type:varchar(3);not null" json:"currency"`
+	CategoryID         int                 `gorm:"column:category_id;not null" json:"category_id"`
+	TotalUnits         decimal.Decimal     `gorm:"column:total_units;type:decimal;not null" json:"total_units"`
+	CurrentValue       decimal.NullDecimal `gorm:"column:current_value;type:decimal" json:"current_value"`
+	InvestedTotal      decimal.Decimal     `gorm:"column:invested_total;type:decimal;not null;default:0" json:"invested_total"`
+	OpeningUnits       decimal.Decimal     `gorm:"column:opening_units;type:decimal;not null;default:0" json:"opening_units"`
+	OpeningCost        decimal.Decimal     `gorm:"column:opening_cost;type:decimal;not null;default:0" json:"opening_cost"`
+	AutoPricingEnabled bool                `gorm:"column:auto_pricing_enabled;not null;default:true" json:"auto_pricing_enabled"`
+	PriceSource        *string             `gorm:"column:price_source;type:varchar(255)" json:"price_source"`
+	CreatedAt          time.Time           `gorm:"column:created_at;type:timestamp with time zone;not null;default:now()" json:"created_at"`
+	UpdatedAt          time.Time           `gorm:"column:updated_at;type:timestamp with time zone;not null;default:now()" json:"updated_at"`
+}
+
+func (Asset) TableName() string {
+	return "Assets"
+}
+
+const (
+	PriceSourceYahoo     = "yahoo"
+	PriceSourceCoinGecko = "coingecko"
+)
+
+func IsValidPriceSource(source string) bool {
+	return source == PriceSourceYahoo || source == PriceSourceCoinGecko
+}
@@ -0,0 +1,32 @@
+package entities
+
+const (
+	CategoryCash = iota + 1
+	CategorySavingsAccount
+	CategoryFixedIncome
+	CategoryStock
+	CategoryETF
+	CategoryCrypto
+	CategoryMutualFund
+	CategoryCommodity
+	CategoryCurrencies
+	CategoryOther
+)
+
+var categoryNames = map[int]string{
+	CategoryCash:           "CASH",
+	CategorySavingsAccount: "SAVINGS_ACCOUNT",
+	CategoryFixedIncome:    "FIXED_INCOME",
+	CategoryStock:          "STOCK",
+	CategoryETF:            "ETF",
+	CategoryCrypto:         "CRYPTO",
+	CategoryMutualFund:     "MUTUAL_FUND",
+	CategoryCommodity:      "COMMODITY",
+	CategoryCurrencies:     "CURRENCIES",
+	CategoryOther:          "OTHER",
+}
+
+func CategoryName(id int) (string, bool) {
+	name, ok := categoryNames[id]
+	return name, ok
+}
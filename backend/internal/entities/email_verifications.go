@@ -0,0 +1,25 @@
+package entities
+
+import "time"
+
+// EmailVerification is a link emailed to confirm the address of a user. Only
+// the hash of its token is stored, with the address it was sent to, which is
+// the only one it verifies.
+type EmailVerification struct {
+	ID        uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID    uint64     `gorm:"column:user_id;not null;index" json:"user_id"`
+	TokenHash string     `gorm:"column:token_hash;type:varchar(64);uniqueIndex;not null" json:"-"`
+	Email     string     `gorm:"column:email;type:varchar(255);not null" json:"email"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:timestamp with time zone;not null;default:now()" json:"created_at"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;type:timestamp with time zone;not null" json:"expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at;type:timestamp with time zone" json:"used_at"`
+}
+
+func (EmailVerification) TableName() string {
+	return "EmailVerifications"
+}
+
+// IsUsable reports whether the link can still verify the email.
+func (v EmailVerification) IsUsable(now time.Time) bool {
+	return v.UsedAt == nil && now.Before(v.ExpiresAt)
+}
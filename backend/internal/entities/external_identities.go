@@ -0,0 +1,18 @@
+package entities
+
+import "time"
+
+// ExternalIdentity links a user to their account at an OpenID Connect
+// provider, identified by the subject of its ID tokens.
+type ExternalIdentity struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID    uint64    `gorm:"column:user_id;not null;index" json:"user_id"`
+	Provider  string    `gorm:"column:provider;type:varchar(63);not null" json:"provider"`
+	Subject   string    `gorm:"column:subject;type:varchar(255);not null" json:"subject"`
+	Email     string    `gorm:"column:email;type:varchar(255)" json:"email"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp with time zone;not null;default:now()" json:"created_at"`
+}
+
+func (ExternalIdentity) TableName() string {
+	return "ExternalIdentities"
+}
@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FxRate is the number of Quote units one Base unit buys on Date.
+type FxRate struct {
+	ID        uint64          `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Base      string          `gorm:"column:base;type:varchar(3);not null;uniqueIndex:idx_fx_rates_pair_date" json:"base"`
+	Quote     string          `gorm:"column:quote;type:varchar(3);not null;uniqueIndex:idx_fx_rates_pair_date" json:"quote"`
+	Date      time.Time       `gorm:"column:date;type:date;not null;uniqueIndex:idx_fx_rates_pair_date" json:"date"`
+	Rate      decimal.Decimal `gorm:"column:rate;type:decimal;not null" json:"rate"`
+	Source    string          `gorm:"column:source;type:varchar(255);not null" json:"source"`
+	CreatedAt time.Time       `gorm:"column:created_at;type:timestamp with time zone;not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time       `gorm:"column:updated_at;type:timestamp with time zone;not null;default:now()" json:"updated_at"`
+}
+
+func (FxRate) TableName() string {
+	return "FxRates"
+}
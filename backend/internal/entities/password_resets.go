@@ -0,0 +1,23 @@
+package entities
+
+import "time"
+
+// PasswordReset is a password reset requested by email. Only the hash of the
+// token sent to the user is stored.
+type PasswordReset struct {
+	ID        uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID    uint64     `gorm:"column:user_id;not null;index" json:"user_id"`
+	TokenHash string     `gorm:"column:token_hash;type:varchar(64);uniqueIndex;not null" json:"-"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:timestamp with time zone;not null;default:now()" json:"created_at"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;type:timestamp with time zone;not null" json:"expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at;type:timestamp with time zone" json:"used_at"`
+}
+
+func (PasswordReset) TableName() string {
+	return "PasswordResets"
+}
+
+// IsUsable reports whether the reset can still change the password.
+func (r PasswordReset) IsUsable(now time.Time) bool {
+	return r.UsedAt == nil && now.Before(r.ExpiresAt)
+}
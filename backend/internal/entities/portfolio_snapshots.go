@@ -0,0 +1,25 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PortfolioSnapshot is the end of day position of an asset.
+type PortfolioSnapshot struct {
+	ID            uint64          `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID        uint64          `gorm:"column:user_id;not null" json:"user_id"`
+	AssetID       uint64          `gorm:"column:asset_id;not null;uniqueIndex:idx_portfolio_snapshots_asset_date" json:"asset_id"`
+	Date          time.Time       `gorm:"column:date;type:date;not null;uniqueIndex:idx_portfolio_snapshots_asset_date" json:"date"`
+	Units         decimal.Decimal `gorm:"column:units;type:decimal;not null" json:"units"`
+	InvestedTotal decimal.Decimal `gorm:"column:invested_total;type:decimal;not null" json:"invested_total"`
+	MarketValue   decimal.Decimal `gorm:"column:market_value;type:decimal;not null" json:"market_value"`
+	Currency      string          `gorm:"column:currency;type:varchar(3);not null" json:"currency"`
+	CreatedAt     time.Time       `gorm:"column:created_at;type:timestamp with time zone;not null;default:now()" json:"created_at"`
+	UpdatedAt     time.Time       `gorm:"column:updated_at;type:timestamp with time zone;not null;default:now()" json:"updated_at"`
+}
+
+func (PortfolioSnapshot) TableName() string {
+	return "PortfolioSnapshots"
+}
@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+// RecoveryCode replaces a TOTP code once, for users who lost their
+// authenticator. Only its hash is stored.
+type RecoveryCode struct {
+	ID        uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID    uint64     `gorm:"column:user_id;not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"column:code_hash;type:varchar(64);not null" json:"-"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:timestamp with time zone;not null;default:now()" json:"created_at"`
+	UsedAt    *time.Time `gorm:"column:used_at;type:timestamp with time zone" json:"used_at"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "RecoveryCodes"
+}
@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is a login on a device. Its refresh tokens are rotated on every use
+// and only RefreshTokenID, the last one issued, is accepted.
+type Session struct {
+	ID             uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Code           uuid.UUID  `gorm:"column:code;type:uuid;not null;unique;default:gen_random_uuid()" json:"code"`
+	UserID         uint64     `gorm:"column:user_id;not null" json:"user_id"`
+	DeviceLabel    string     `gorm:"column:device_label;type:varchar(255)" json:"device_label"`
+	UserAgent      string     `gorm:"column:user_agent;type:text" json:"user_agent"`
+	IPAddress      string     `gorm:"column:ip_address;type:varchar(45)" json:"ip_address"`
+	RefreshTokenID uuid.UUID  `gorm:"column:refresh_token_id;type:uuid;not null" json:"-"`
+	CreatedAt      time.Time  `gorm:"column:created_at;type:timestamp with time zone;not null;default:now()" json:"created_at"`
+	LastUsedAt     time.Time  `gorm:"column:last_used_at;type:timestamp with time zone;not null;default:now()" json:"last_used_at"`
+	ExpiresAt      time.Time  `gorm:"column:expires_at;type:timestamp with time zone;not null" json:"expires_at"`
+	RevokedAt      *time.Time `gorm:"column:revoked_at;type:timestamp with time zone" json:"revoked_at"`
+}
+
+func (Session) TableName() string {
+	return "Sessions"
+}
+
+// IsActive reports whether the session can still be refreshed.
+func (s Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
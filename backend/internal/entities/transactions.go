@@ -0,0 +1,45 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	TransactionTypeBuy      = "BUY"
+	TransactionTypeSell     = "SELL"
+	TransactionTypeDeposit  = "DEPOSIT"
+	TransactionTypeWithdraw = "WITHDRAW"
+)
+
+type Transaction struct {
+	ID        uint64          `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Code      uuid.UUID       `gorm:"column:code;type:uuid;not null;default:gen_random_uuid()" json:"code"`
+	AssetID   uint64          `gorm:"column:asset_id;not null" json:"asset_id"`
+	Type      string          `gorm:"column:type;type:varchar(50);not null" json:"type"`
+	Units     decimal.Decimal `gorm:"column:units;type:decimal;not null" json:"units"`
+	Total     decimal.Decimal `gorm:"column:total;type:decimal;not null" json:"total"`
+	FeeTotal  decimal.Decimal `gorm:"column:fee_total;type:decimal;default:0" json:"fee_total"`
+	Currency  string          `gorm:"column:currency;type:varchar(3);not null" json:"currency"`
+	Note      *string         `gorm:"column:note;type:text" json:"note"`
+	CreatedAt time.Time       `gorm:"column:created_at;type:timestamp with time zone;not null;default:now()" json:"created_at"`
+}
+
+func (Transaction) TableName() string {
+	return "Transactions"
+}
+
+func IsValidTransactionType(transactionType string) bool {
+	switch transactionType {
+	case TransactionTypeBuy, TransactionTypeSell, TransactionTypeDeposit, TransactionTypeWithdraw:
+		return true
+	}
+	return false
+}
+
+// IsInflow reports whether the transaction adds units to the asset.
+func (t Transaction) IsInflow() bool {
+	return t.Type == TransactionTypeBuy || t.Type == TransactionTypeDeposit
+}
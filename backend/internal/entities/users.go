@@ -7,17 +7,36 @@ import (
 )
 
 type User struct {
-	ID           uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
-	Code         uuid.UUID `gorm:"column:code;type:uuid;uniqueIndex;not null;default:gen_random_uuid()" json:"code"`
-	Username     string    `gorm:"column:username;type:varchar(63);uniqueIndex;not null" json:"username"`
-	Email        string    `gorm:"column:email;type:varchar(255);uniqueIndex;not null" json:"email"`
-	PasswordHash string    `gorm:"column:password_hash;type:varchar(255);not null" json:"-"`
-	PasswordSalt string    `gorm:"column:password_salt;type:varchar(32);not null" json:"-"`
-	Currency     string    `gorm:"column:currency;type:varchar(3);not null;default:'USD'" json:"currency"`
-	CreatedAt    time.Time `gorm:"column:created_at;type:timestamp with time zone;not null;default:now()" json:"created_at"`
-	UpdatedAt    time.Time `gorm:"column:updated_at;type:timestamp with time zone;not null;default:now()" json:"updated_at"`
+	ID              uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Code            uuid.UUID  `gorm:"column:code;type:uuid;uniqueIndex;not null;default:gen_random_uuid()" json:"code"`
+	Username        string     `gorm:"column:username;type:varchar(63);uniqueIndex;not null" json:"username"`
+	Email           string     `gorm:"column:email;type:varchar(255);uniqueIndex;not null" json:"email"`
+	PasswordHash    string     `gorm:"column:password_hash;type:varchar(255);not null" json:"-"`
+	PasswordSalt    string     `gorm:"column:password_salt;type:varchar(32);not null;default:''" json:"-"`
+	Currency        string     `gorm:"column:currency;type:varchar(3);not null;default:'USD'" json:"currency"`
+	CostBasisMethod string     `gorm:"column:cost_basis_method;type:varchar(16);not null;default:'FIFO'" json:"cost_basis_method"`
+	TokenVersion    int        `gorm:"column:token_version;not null;default:0" json:"-"`
+	EmailVerifiedAt *time.Time `gorm:"column:email_verified_at;type:timestamp with time zone" json:"email_verified_at"`
+	MFASecret       string     `gorm:"column:mfa_secret;type:text" json:"-"`
+	MFAEnabledAt    *time.Time `gorm:"column:mfa_enabled_at;type:timestamp with time zone" json:"mfa_enabled_at"`
+	MFALastStep     int64      `gorm:"column:mfa_last_step;not null;default:0" json:"-"`
+	CreatedAt       time.Time  `gorm:"column:created_at;type:timestamp with time zone;not null;default:now()" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at;type:timestamp with time zone;not null;default:now()" json:"updated_at"`
+	DeletedAt       *time.Time `gorm:"column:deleted_at;type:timestamp with time zone" json:"deleted_at"`
 }
 
 func (User) TableName() string {
 	return "Users"
 }
+
+// MFAEnabled reports whether the user confirmed a second factor, and so has to
+// present a code to log in.
+func (u User) MFAEnabled() bool {
+	return u.MFAEnabledAt != nil
+}
+
+// IsDeleted reports whether the user deleted their account, which is purged
+// once its grace period ends.
+func (u User) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
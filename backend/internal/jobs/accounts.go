@@ -0,0 +1,23 @@
+package jobs
+
+import "context"
+
+const AccountPurgeJobName = "account_purge"
+
+type accountPurger interface {
+	PurgeDeletedAccounts(ctx context.Context) error
+}
+
+// AccountPurge removes the data of the deleted accounts whose grace period
+// ended.
+type AccountPurge struct {
+	purger accountPurger
+}
+
+func NewAccountPurge(purger accountPurger) *AccountPurge {
+	return &AccountPurge{purger: purger}
+}
+
+func (j *AccountPurge) Run(ctx context.Context) error {
+	return j.purger.PurgeDeletedAccounts(ctx)
+}
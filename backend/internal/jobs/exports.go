@@ -0,0 +1,22 @@
+package jobs
+
+import "context"
+
+const ExportPurgeJobName = "export_purge"
+
+type exportPurger interface {
+	PurgeExpiredArchives(ctx context.Context) error
+}
+
+// ExportPurge removes the archives of the exports whose link expired.
+type ExportPurge struct {
+	purger exportPurger
+}
+
+func NewExportPurge(purger exportPurger) *ExportPurge {
+	return &ExportPurge{purger: purger}
+}
+
+func (j *ExportPurge) Run(ctx context.Context) error {
+	return j.purger.PurgeExpiredArchives(ctx)
+}
@@ -0,0 +1,24 @@
+package jobs
+
+import "context"
+
+const FxRefreshJobName = "fx_refresh"
+
+type rateRefresher interface {
+	RefreshRates(ctx context.Context, currencies []string) error
+}
+
+// FxRefresh stores the daily exchange rate of the configured currencies so past
+// valuations can be converted with the rate of their day.
+type FxRefresh struct {
+	refresher  rateRefresher
+	currencies []string
+}
+
+func NewFxRefresh(refresher rateRefresher, currencies []string) *FxRefresh {
+	return &FxRefresh{refresher: refresher, currencies: currencies}
+}
+
+func (j *FxRefresh) Run(ctx context.Context) error {
+	return j.refresher.RefreshRates(ctx, j.currencies)
+}
@@ -0,0 +1,151 @@
+package jobs
+
+import (
+	"context"
+	libErrors "errors"
+	"testing"
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/pricing"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAssetRepository struct {
+	mock.Mock
+}
+
+func (m *MockAssetRepository) ListAll(ctx context.Context) ([]entities.Asset, error) {
+	args := m.Called(ctx)
+	if assets, ok := args.Get(0).([]entities.Asset); ok {
+		return assets, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAssetRepository) ListAutoPriced(ctx context.Context) ([]entities.Asset, error) {
+	args := m.Called(ctx)
+	if assets, ok := args.Get(0).([]entities.Asset); ok {
+		return assets, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockPricing struct {
+	mock.Mock
+}
+
+func (m *MockPricing) RefreshAsset(ctx context.Context, asset *entities.Asset) (*pricing.Valuation, error) {
+	args := m.Called(ctx, asset.ID)
+	if valuation, ok := args.Get(0).(*pricing.Valuation); ok {
+		return valuation, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockPricing) ValueAsset(ctx context.Context, asset *entities.Asset) (*pricing.Valuation, error) {
+	args := m.Called(ctx, asset.ID)
+	if valuation, ok := args.Get(0).(*pricing.Valuation); ok {
+		return valuation, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockSnapshotRepository struct {
+	mock.Mock
+}
+
+func (m *MockSnapshotRepository) Upsert(ctx context.Context, snapshot *entities.PortfolioSnapshot) error {
+	args := m.Called(ctx, snapshot)
+	return args.Error(0)
+}
+
+func Test_PriceRefresh_Run(t *testing.T) {
+	ctx := context.Background()
+	assets := new(MockAssetRepository)
+	prices := new(MockPricing)
+	logger := new(mocks.Logger)
+
+	assets.On("ListAutoPriced", mock.Anything).Return([]entities.Asset{{ID: 1}, {ID: 2}, {ID: 3}}, nil)
+	prices.On("RefreshAsset", mock.Anything, uint64(1)).Return(&pricing.Valuation{}, nil)
+	prices.On("RefreshAsset", mock.Anything, uint64(2)).Return(nil, pricing.ErrPriceNotFound)
+	prices.On("RefreshAsset", mock.Anything, uint64(3)).Return(&pricing.Valuation{}, nil)
+	logger.On("Warning", mock.Anything, PriceRefreshJobName, mock.Anything, mock.Anything).Return()
+
+	err := NewPriceRefresh(assets, prices, logger).Run(ctx)
+
+	assert.ErrorIs(t, err, pricing.ErrPriceNotFound)
+	assets.AssertExpectations(t)
+	prices.AssertExpectations(t)
+	logger.AssertExpectations(t)
+}
+
+func Test_PortfolioSnapshot_Run(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		mockFunc      func(*MockAssetRepository, *MockSnapshotRepository, *MockPricing, *mocks.Logger)
+		expectedError bool
+	}{
+		{
+			name: "snapshots every asset with its market value",
+			mockFunc: func(assets *MockAssetRepository, snapshots *MockSnapshotRepository, prices *MockPricing, logger *mocks.Logger) {
+				assets.On("ListAll", mock.Anything).Return([]entities.Asset{
+					{ID: 1, UserID: 7, TotalUnits: decimal.NewFromInt(2), InvestedTotal: decimal.NewFromInt(150), Currency: "USD"},
+				}, nil)
+				prices.On("ValueAsset", mock.Anything, uint64(1)).Return(&pricing.Valuation{MarketValue: decimal.NewFromInt(200)}, nil)
+				snapshots.On("Upsert", mock.Anything, mock.MatchedBy(func(snapshot *entities.PortfolioSnapshot) bool {
+					return snapshot.UserID == 7 && snapshot.AssetID == 1 && snapshot.Date.Equal(day) &&
+						snapshot.MarketValue.Equal(decimal.NewFromInt(200)) && snapshot.InvestedTotal.Equal(decimal.NewFromInt(150))
+				})).Return(nil)
+			},
+		},
+		{
+			name: "valuation errors do not stop other assets",
+			mockFunc: func(assets *MockAssetRepository, snapshots *MockSnapshotRepository, prices *MockPricing, logger *mocks.Logger) {
+				assets.On("ListAll", mock.Anything).Return([]entities.Asset{{ID: 1}, {ID: 2}}, nil)
+				prices.On("ValueAsset", mock.Anything, uint64(1)).Return(nil, pricing.ErrPriceNotFound)
+				prices.On("ValueAsset", mock.Anything, uint64(2)).Return(&pricing.Valuation{}, nil)
+				snapshots.On("Upsert", mock.Anything, mock.Anything).Return(nil).Once()
+				logger.On("Warning", mock.Anything, PortfolioSnapshotJobName, mock.Anything, mock.Anything).Return()
+			},
+			expectedError: true,
+		},
+		{
+			name: "listing assets fails",
+			mockFunc: func(assets *MockAssetRepository, snapshots *MockSnapshotRepository, prices *MockPricing, logger *mocks.Logger) {
+				assets.On("ListAll", mock.Anything).Return(nil, libErrors.New("db error"))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assets := new(MockAssetRepository)
+			snapshots := new(MockSnapshotRepository)
+			prices := new(MockPricing)
+			logger := new(mocks.Logger)
+			tc.mockFunc(assets, snapshots, prices, logger)
+
+			job := NewPortfolioSnapshot(assets, snapshots, prices, logger)
+			job.now = func() time.Time { return day.Add(23*time.Hour + 50*time.Minute) }
+			err := job.Run(ctx)
+
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assets.AssertExpectations(t)
+			snapshots.AssertExpectations(t)
+			prices.AssertExpectations(t)
+			logger.AssertExpectations(t)
+		})
+	}
+}
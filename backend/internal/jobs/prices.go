@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/pricing"
+)
+
+const PriceRefreshJobName = "price_refresh"
+
+type autoPricedAssetRepository interface {
+	ListAutoPriced(ctx context.Context) ([]entities.Asset, error)
+}
+
+type priceRefresher interface {
+	RefreshAsset(ctx context.Context, asset *entities.Asset) (*pricing.Valuation, error)
+}
+
+// PriceRefresh fetches the market price of every auto priced asset so quotes stay
+// warm in the cache and the price history gets a point per run.
+type PriceRefresh struct {
+	assetRepository autoPricedAssetRepository
+	refresher       priceRefresher
+	logger          log.Logger
+}
+
+func NewPriceRefresh(assetRepo autoPricedAssetRepository, refresher priceRefresher, logger log.Logger) *PriceRefresh {
+	return &PriceRefresh{
+		assetRepository: assetRepo,
+		refresher:       refresher,
+		logger:          logger,
+	}
+}
+
+// Run refreshes every asset even when some of them fail, and reports the
+// failures together.
+func (j *PriceRefresh) Run(ctx context.Context) error {
+	assets, err := j.assetRepository.ListAutoPriced(ctx)
+	if err != nil {
+		return fmt.Errorf("listing auto priced assets: %w", err)
+	}
+
+	var errs []error
+	for i := range assets {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := j.refresher.RefreshAsset(ctx, &assets[i]); err != nil {
+			j.logger.Warning(ctx, PriceRefreshJobName, "error refreshing asset price",
+				log.Field("asset_id", assets[i].ID), log.Field("error", err))
+			errs = append(errs, fmt.Errorf("asset %d: %w", assets[i].ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
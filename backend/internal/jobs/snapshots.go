@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/pricing"
+)
+
+const PortfolioSnapshotJobName = "portfolio_snapshot"
+
+type assetRepository interface {
+	ListAll(ctx context.Context) ([]entities.Asset, error)
+}
+
+type snapshotRepository interface {
+	Upsert(ctx context.Context, snapshot *entities.PortfolioSnapshot) error
+}
+
+type assetValuer interface {
+	ValueAsset(ctx context.Context, asset *entities.Asset) (*pricing.Valuation, error)
+}
+
+// PortfolioSnapshot stores the end of day position and market value of every asset.
+type PortfolioSnapshot struct {
+	assetRepository    assetRepository
+	snapshotRepository snapshotRepository
+	valuer             assetValuer
+	logger             log.Logger
+	now                func() time.Time
+}
+
+func NewPortfolioSnapshot(assetRepo assetRepository, snapshotRepo snapshotRepository, valuer assetValuer, logger log.Logger) *PortfolioSnapshot {
+	return &PortfolioSnapshot{
+		assetRepository:    assetRepo,
+		snapshotRepository: snapshotRepo,
+		valuer:             valuer,
+		logger:             logger,
+		now:                time.Now,
+	}
+}
+
+// Run snapshots every asset even when some of them fail. Running it twice on
+// the same day replaces that day's snapshots.
+func (j *PortfolioSnapshot) Run(ctx context.Context) error {
+	assets, err := j.assetRepository.ListAll(ctx)
+	if err != nil {
+		return fmt.Errorf("listing assets: %w", err)
+	}
+
+	day := entities.PriceDate(j.now())
+	var errs []error
+	for i := range assets {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := j.snapshot(ctx, &assets[i], day); err != nil {
+			j.logger.Warning(ctx, PortfolioSnapshotJobName, "error taking asset snapshot",
+				log.Field("asset_id", assets[i].ID), log.Field("error", err))
+			errs = append(errs, fmt.Errorf("asset %d: %w", assets[i].ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (j *PortfolioSnapshot) snapshot(ctx context.Context, asset *entities.Asset, day time.Time) error {
+	valuation, err := j.valuer.ValueAsset(ctx, asset)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return j.snapshotRepository.Upsert(ctx, &entities.PortfolioSnapshot{
+		UserID:        asset.UserID,
+		AssetID:       asset.ID,
+		Date:          day,
+		Units:         asset.TotalUnits,
+		InvestedTotal: asset.InvestedTotal,
+		MarketValue:   valuation.MarketValue,
+		Currency:      asset.Currency,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+)
+
+type AccountDeletionRepository struct {
+	store Store
+}
+
+func NewAccountDeletionRepository(store Store) *AccountDeletionRepository {
+	return &AccountDeletionRepository{store: store}
+}
+
+func (r *AccountDeletionRepository) Create(ctx context.Context, deletion *entities.AccountDeletion) error {
+	return r.store.Create(ctx, deletion)
+}
+
+// ListDue returns the deletions whose grace period ended by now. Purged
+// accounts take their deletion with them, so the pending ones are few.
+func (r *AccountDeletionRepository) ListDue(ctx context.Context, now time.Time) ([]entities.AccountDeletion, error) {
+	var deletions []entities.AccountDeletion
+	if err := r.store.FindAll(ctx, &deletions, map[string]interface{}{}); err != nil {
+		return nil, err
+	}
+
+	due := deletions[:0]
+	for _, deletion := range deletions {
+		if !deletion.PurgeAfter.After(now) {
+			due = append(due, deletion)
+		}
+	}
+	return due, nil
+}
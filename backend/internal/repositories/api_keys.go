@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+)
+
+const FieldKeyHash = "key_hash"
+
+type APIKeyRepository struct {
+	store Store
+}
+
+func NewAPIKeyRepository(store Store) *APIKeyRepository {
+	return &APIKeyRepository{store: store}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *entities.APIKey) error {
+	return r.store.Create(ctx, key)
+}
+
+func (r *APIKeyRepository) GetByCode(ctx context.Context, code uuid.UUID) (*entities.APIKey, error) {
+	return r.getOne(ctx, map[string]interface{}{FieldCode: code})
+}
+
+func (r *APIKeyRepository) GetByKeyHash(ctx context.Context, keyHash string) (*entities.APIKey, error) {
+	return r.getOne(ctx, map[string]interface{}{FieldKeyHash: keyHash})
+}
+
+// ListByUser returns the keys of a user that were not revoked, expired ones
+// included, newest first.
+func (r *APIKeyRepository) ListByUser(ctx context.Context, userID uint64) ([]entities.APIKey, error) {
+	var keys []entities.APIKey
+	condition := map[string]interface{}{FieldUserID: userID}
+	if err := r.store.FindAll(ctx, &keys, condition); err != nil {
+		return nil, err
+	}
+
+	kept := keys[:0]
+	for _, key := range keys {
+		if key.RevokedAt == nil {
+			kept = append(kept, key)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		return kept[i].CreatedAt.After(kept[j].CreatedAt)
+	})
+	return kept, nil
+}
+
+func (r *APIKeyRepository) Update(ctx context.Context, key *entities.APIKey) error {
+	return r.store.Update(ctx, key)
+}
+
+const revokeAPIKeysByUserQuery = `UPDATE "APIKeys" SET "revoked_at" = ? WHERE "user_id" = ? AND "revoked_at" IS NULL`
+
+// RevokeByUser revokes every key of the user that was not revoked yet.
+func (r *APIKeyRepository) RevokeByUser(ctx context.Context, userID uint64, revokedAt time.Time) error {
+	_, err := r.store.Exec(ctx, revokeAPIKeysByUserQuery, revokedAt, userID)
+	return err
+}
+
+func (r *APIKeyRepository) getOne(ctx context.Context, condition map[string]interface{}) (*entities.APIKey, error) {
+	var key entities.APIKey
+	exists, err := r.store.FindOne(ctx, &key, condition)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	return &key, nil
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_APIKeyRepository_ListByUser(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	revokedAt := now.Add(-time.Hour)
+	expiredAt := now.Add(-time.Minute)
+	sheets, script, expired := uuid.New(), uuid.New(), uuid.New()
+
+	store := &MockStore{}
+	store.On("FindAll",
+		mock.Anything,
+		mock.Anything,
+		map[string]interface{}{FieldUserID: uint64(1)},
+	).Return(nil).Run(func(args mock.Arguments) {
+		keys := args.Get(1).(*[]entities.APIKey)
+		*keys = []entities.APIKey{
+			{Code: sheets, CreatedAt: now.Add(-48 * time.Hour)},
+			{Code: uuid.New(), CreatedAt: now.Add(-time.Hour), RevokedAt: &revokedAt},
+			{Code: expired, CreatedAt: now.Add(-72 * time.Hour), ExpiresAt: &expiredAt},
+			{Code: script, CreatedAt: now.Add(-2 * time.Hour)},
+		}
+	})
+	repo := NewAPIKeyRepository(store)
+
+	keys, err := repo.ListByUser(ctx, 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, len(keys))
+	assert.Equal(t, script, keys[0].Code)
+	assert.Equal(t, sheets, keys[1].Code)
+	assert.Equal(t, expired, keys[2].Code)
+	store.AssertExpectations(t)
+}
+
+func Test_APIKeyRepository_RevokeByUser(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	store := &MockStore{}
+	store.On("Exec", mock.Anything, revokeAPIKeysByUserQuery, []interface{}{now, uint64(7)}).Return(int64(2), nil)
+	repo := NewAPIKeyRepository(store)
+
+	err := repo.RevokeByUser(ctx, 7, now)
+	assert.Equal(t, nil, err)
+	store.AssertExpectations(t)
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+)
+
+const (
+	FieldDate = "date"
+)
+
+type AssetPriceRepository struct {
+	store Store
+}
+
+func NewAssetPriceRepository(store Store) *AssetPriceRepository {
+	return &AssetPriceRepository{store: store}
+}
+
+const (
+	upsertAssetPriceQuery = `INSERT INTO "AssetPrices" ("asset_id", "date", "price", "currency", "source", "created_at", "updated_at")
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT ("asset_id", "date") DO UPDATE
+		SET "price" = EXCLUDED."price", "currency" = EXCLUDED."currency", "source" = EXCLUDED."source", "updated_at" = EXCLUDED."updated_at"
+		RETURNING *`
+	// The latest price before the range is added to carry it forward into
+	// the first days of the range.
+	listAssetPricesQuery = `(SELECT * FROM "AssetPrices" WHERE "asset_id" = ? AND "date" BETWEEN ? AND ?)
+		UNION ALL
+		(SELECT * FROM "AssetPrices" WHERE "asset_id" = ? AND "date" < ? ORDER BY "date" DESC LIMIT 1)
+		ORDER BY "date"`
+)
+
+// Upsert stores the price of an asset for a day, replacing any price already
+// recorded for it. It is a single statement, so concurrent writes of the same
+// day do not race into the unique constraint.
+func (r *AssetPriceRepository) Upsert(ctx context.Context, price *entities.AssetPrice) error {
+	price.Date = entities.PriceDate(price.Date)
+	now := time.Now()
+
+	var stored []entities.AssetPrice
+	err := r.store.Raw(ctx, &stored, upsertAssetPriceQuery,
+		price.AssetID, price.Date, price.Price, price.Currency, price.Source, now, now)
+	if err != nil {
+		return err
+	}
+	if len(stored) > 0 {
+		*price = stored[0]
+	}
+	return nil
+}
+
+// ListByAsset returns the prices of an asset between the given days, oldest
+// first, preceded by the latest price before from when there is one.
+func (r *AssetPriceRepository) ListByAsset(ctx context.Context, assetID uint64, from, to time.Time) ([]entities.AssetPrice, error) {
+	from, to = entities.PriceDate(from), entities.PriceDate(to)
+
+	var prices []entities.AssetPrice
+	if err := r.store.Raw(ctx, &prices, listAssetPricesQuery, assetID, from, to, assetID, from); err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_AssetPriceRepository_Upsert(t *testing.T) {
+	ctx := context.Background()
+	store := &MockStore{}
+	repo := NewAssetPriceRepository(store)
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	store.On("Raw",
+		mock.Anything,
+		mock.AnythingOfType("*[]entities.AssetPrice"),
+		mock.MatchedBy(func(query string) bool { return strings.Contains(query, `ON CONFLICT ("asset_id", "date") DO UPDATE`) }),
+		mock.MatchedBy(func(args []interface{}) bool {
+			return args[0] == uint64(3) && args[1].(time.Time).Equal(day) && args[2].(decimal.Decimal).Equal(decimal.NewFromInt(120))
+		}),
+	).Return(nil).Run(func(args mock.Arguments) {
+		prices := args.Get(1).(*[]entities.AssetPrice)
+		*prices = []entities.AssetPrice{{ID: 9, AssetID: 3, Date: day, Price: decimal.NewFromInt(120)}}
+	})
+
+	price := &entities.AssetPrice{
+		AssetID:  3,
+		Date:     day.Add(15 * time.Hour),
+		Price:    decimal.NewFromInt(120),
+		Currency: "USD",
+		Source:   entities.PriceSourceYahoo,
+	}
+	err := repo.Upsert(ctx, price)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(9), price.ID)
+	store.AssertExpectations(t)
+}
+
+func Test_AssetPriceRepository_ListByAsset(t *testing.T) {
+	ctx := context.Background()
+	store := &MockStore{}
+	repo := NewAssetPriceRepository(store)
+
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	store.On("Raw",
+		mock.Anything,
+		mock.AnythingOfType("*[]entities.AssetPrice"),
+		listAssetPricesQuery,
+		[]interface{}{uint64(3), day(5), day(10), uint64(3), day(5)},
+	).Return(nil).Run(func(args mock.Arguments) {
+		prices := args.Get(1).(*[]entities.AssetPrice)
+		*prices = []entities.AssetPrice{{Date: day(2)}, {Date: day(8)}}
+	})
+
+	prices, err := repo.ListByAsset(ctx, 3, day(5).Add(9*time.Hour), day(10).Add(22*time.Hour))
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(prices))
+	store.AssertExpectations(t)
+}
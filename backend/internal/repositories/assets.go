@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+)
+
+const (
+	FieldUserID             = "user_id"
+	FieldAutoPricingEnabled = "auto_pricing_enabled"
+)
+
+type AssetRepository struct {
+	store Store
+}
+
+func NewAssetRepository(store Store) *AssetRepository {
+	return &AssetRepository{store: store}
+}
+
+func (r *AssetRepository) Create(ctx context.Context, asset *entities.Asset) error {
+	return r.store.Create(ctx, asset)
+}
+
+func (r *AssetRepository) GetByCode(ctx context.Context, userID uint64, code uuid.UUID) (*entities.Asset, error) {
+	var asset entities.Asset
+	condition := map[string]interface{}{FieldCode: code, FieldUserID: userID}
+	exists, err := r.store.FindOne(ctx, &asset, condition)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	return &asset, nil
+}
+
+// GetByCodeForUpdate is GetByCode locking the row until the transaction of ctx
+// ends, so concurrent writes on the holdings of the asset are serialized.
+func (r *AssetRepository) GetByCodeForUpdate(ctx context.Context, userID uint64, code uuid.UUID) (*entities.Asset, error) {
+	var assets []entities.Asset
+	query := `SELECT * FROM "Assets" WHERE "code" = ? AND "user_id" = ? LIMIT 1 FOR UPDATE`
+	if err := r.store.Raw(ctx, &assets, query, code, userID); err != nil {
+		return nil, err
+	}
+	if len(assets) == 0 {
+		return nil, nil
+	}
+	return &assets[0], nil
+}
+
+func (r *AssetRepository) ListByUser(ctx context.Context, userID uint64) ([]entities.Asset, error) {
+	var assets []entities.Asset
+	condition := map[string]interface{}{FieldUserID: userID}
+	if err := r.store.FindAll(ctx, &assets, condition); err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// ListAll returns the assets of every user.
+func (r *AssetRepository) ListAll(ctx context.Context) ([]entities.Asset, error) {
+	var assets []entities.Asset
+	if err := r.store.FindAll(ctx, &assets, map[string]interface{}{}); err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// ListAutoPriced returns the assets of every user valued with a price provider.
+func (r *AssetRepository) ListAutoPriced(ctx context.Context) ([]entities.Asset, error) {
+	var assets []entities.Asset
+	condition := map[string]interface{}{FieldAutoPricingEnabled: true}
+	if err := r.store.FindAll(ctx, &assets, condition); err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+func (r *AssetRepository) Update(ctx context.Context, asset *entities.Asset) error {
+	return r.store.Update(ctx, asset)
+}
+
+func (r *AssetRepository) Delete(ctx context.Context, asset *entities.Asset) error {
+	condition := map[string]interface{}{FieldCode: asset.Code, FieldUserID: asset.UserID}
+	return r.store.Delete(ctx, &entities.Asset{}, condition)
+}
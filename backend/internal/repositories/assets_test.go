@@ -0,0 +1,223 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_AssetRepository_GetByCode(t *testing.T) {
+	ctx := context.Background()
+	userID := uint64(1)
+
+	testCase := []struct {
+		name          string
+		code          uuid.UUID
+		mockFunc      func(*MockStore, uuid.UUID)
+		expectedAsset *entities.Asset
+		expectError   error
+	}{
+		{
+			name: "find one by code scoped to user",
+			code: uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"),
+			mockFunc: func(store *MockStore, code uuid.UUID) {
+				store.On("FindOne",
+					mock.Anything,
+					&entities.Asset{},
+					map[string]interface{}{FieldCode: code, FieldUserID: userID},
+				).Return(true, nil).Run(func(args mock.Arguments) {
+					asset := args.Get(1).(*entities.Asset)
+					asset.Code = code
+					asset.UserID = userID
+				})
+			},
+			expectedAsset: &entities.Asset{
+				Code:   uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"),
+				UserID: 1,
+			},
+		},
+		{
+			name: "not found asset",
+			code: uuid.New(),
+			mockFunc: func(store *MockStore, code uuid.UUID) {
+				store.On("FindOne",
+					mock.Anything,
+					&entities.Asset{},
+					map[string]interface{}{FieldCode: code, FieldUserID: userID},
+				).Return(false, nil)
+			},
+			expectedAsset: nil,
+		},
+		{
+			name: "error finding asset",
+			code: uuid.New(),
+			mockFunc: func(store *MockStore, code uuid.UUID) {
+				store.On("FindOne",
+					mock.Anything,
+					&entities.Asset{},
+					map[string]interface{}{FieldCode: code, FieldUserID: userID},
+				).Return(false, errors.New("error finding asset"))
+			},
+			expectError: errors.New("error finding asset"),
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &MockStore{}
+			repo := NewAssetRepository(store)
+
+			tc.mockFunc(store, tc.code)
+			asset, err := repo.GetByCode(ctx, userID, tc.code)
+
+			if tc.expectError != nil {
+				assert.Equal(t, tc.expectError, err)
+			} else {
+				if err != nil {
+					t.Fatalf("Error getting asset by code: %v", err)
+				}
+				assert.Equal(t, tc.expectedAsset, asset)
+			}
+		})
+	}
+}
+
+func Test_AssetRepository_GetByCodeForUpdate(t *testing.T) {
+	ctx := context.Background()
+	userID := uint64(1)
+	code := uuid.New()
+
+	testCase := []struct {
+		name          string
+		mockFunc      func(*MockStore)
+		expectedAsset *entities.Asset
+		expectError   error
+	}{
+		{
+			name: "lock the asset of the user",
+			mockFunc: func(store *MockStore) {
+				store.On("Raw",
+					mock.Anything,
+					mock.AnythingOfType("*[]entities.Asset"),
+					mock.MatchedBy(func(query string) bool { return strings.HasSuffix(query, "FOR UPDATE") }),
+					[]interface{}{code, userID},
+				).Return(nil).Run(func(args mock.Arguments) {
+					assets := args.Get(1).(*[]entities.Asset)
+					*assets = []entities.Asset{{Code: code, UserID: userID}}
+				})
+			},
+			expectedAsset: &entities.Asset{Code: code, UserID: userID},
+		},
+		{
+			name: "not found asset",
+			mockFunc: func(store *MockStore) {
+				store.On("Raw", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedAsset: nil,
+		},
+		{
+			name: "error locking asset",
+			mockFunc: func(store *MockStore) {
+				store.On("Raw", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("error locking asset"))
+			},
+			expectError: errors.New("error locking asset"),
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &MockStore{}
+			repo := NewAssetRepository(store)
+
+			tc.mockFunc(store)
+			asset, err := repo.GetByCodeForUpdate(ctx, userID, code)
+
+			if tc.expectError != nil {
+				assert.Equal(t, tc.expectError, err)
+			} else {
+				if err != nil {
+					t.Fatalf("Error locking asset: %v", err)
+				}
+				assert.Equal(t, tc.expectedAsset, asset)
+			}
+		})
+	}
+}
+
+func Test_AssetRepository_ListByUser(t *testing.T) {
+	ctx := context.Background()
+	userID := uint64(7)
+
+	testCase := []struct {
+		name           string
+		mockFunc       func(*MockStore)
+		expectedAssets []entities.Asset
+		expectError    error
+	}{
+		{
+			name: "list assets of user",
+			mockFunc: func(store *MockStore) {
+				store.On("FindAll",
+					mock.Anything,
+					mock.AnythingOfType("*[]entities.Asset"),
+					map[string]interface{}{FieldUserID: userID},
+				).Return(nil).Run(func(args mock.Arguments) {
+					assets := args.Get(1).(*[]entities.Asset)
+					*assets = []entities.Asset{{UserID: userID, TotalUnits: decimal.NewFromInt(3)}}
+				})
+			},
+			expectedAssets: []entities.Asset{{UserID: userID, TotalUnits: decimal.NewFromInt(3)}},
+		},
+		{
+			name: "error listing assets",
+			mockFunc: func(store *MockStore) {
+				store.On("FindAll", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("error listing assets"))
+			},
+			expectError: errors.New("error listing assets"),
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &MockStore{}
+			repo := NewAssetRepository(store)
+
+			tc.mockFunc(store)
+			assets, err := repo.ListByUser(ctx, userID)
+
+			if tc.expectError != nil {
+				assert.Equal(t, tc.expectError, err)
+			} else {
+				if err != nil {
+					t.Fatalf("Error listing assets: %v", err)
+				}
+				assert.Equal(t, tc.expectedAssets, assets)
+			}
+		})
+	}
+}
+
+func Test_AssetRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+	asset := &entities.Asset{Code: uuid.New(), UserID: 3}
+
+	store := &MockStore{}
+	store.On("Delete",
+		mock.Anything,
+		&entities.Asset{},
+		map[string]interface{}{FieldCode: asset.Code, FieldUserID: asset.UserID},
+	).Return(nil)
+
+	repo := NewAssetRepository(store)
+	err := repo.Delete(ctx, asset)
+
+	assert.Equal(t, nil, err)
+	store.AssertExpectations(t)
+}
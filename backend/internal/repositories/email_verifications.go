@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+)
+
+type EmailVerificationRepository struct {
+	store Store
+}
+
+func NewEmailVerificationRepository(store Store) *EmailVerificationRepository {
+	return &EmailVerificationRepository{store: store}
+}
+
+func (r *EmailVerificationRepository) Create(ctx context.Context, verification *entities.EmailVerification) error {
+	return r.store.Create(ctx, verification)
+}
+
+func (r *EmailVerificationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.EmailVerification, error) {
+	var verification entities.EmailVerification
+	condition := map[string]interface{}{FieldTokenHash: tokenHash}
+	exists, err := r.store.FindOne(ctx, &verification, condition)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	return &verification, nil
+}
+
+func (r *EmailVerificationRepository) Update(ctx context.Context, verification *entities.EmailVerification) error {
+	return r.store.Update(ctx, verification)
+}
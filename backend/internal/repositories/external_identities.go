@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+)
+
+const (
+	FieldProvider = "provider"
+	FieldSubject  = "subject"
+)
+
+type ExternalIdentityRepository struct {
+	store Store
+}
+
+func NewExternalIdentityRepository(store Store) *ExternalIdentityRepository {
+	return &ExternalIdentityRepository{store: store}
+}
+
+func (r *ExternalIdentityRepository) Create(ctx context.Context, identity *entities.ExternalIdentity) error {
+	return r.store.Create(ctx, identity)
+}
+
+func (r *ExternalIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*entities.ExternalIdentity, error) {
+	var identity entities.ExternalIdentity
+	condition := map[string]interface{}{FieldProvider: provider, FieldSubject: subject}
+	exists, err := r.store.FindOne(ctx, &identity, condition)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	return &identity, nil
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_ExternalIdentityRepository_GetByProviderSubject(t *testing.T) {
+	ctx := context.Background()
+	condition := map[string]interface{}{FieldProvider: "google", FieldSubject: "1234"}
+
+	store := &MockStore{}
+	store.On("FindOne", mock.Anything, mock.Anything, condition).Return(true, nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*entities.ExternalIdentity).UserID = 7
+	}).Once()
+	store.On("FindOne", mock.Anything, mock.Anything, condition).Return(false, nil).Once()
+	repo := NewExternalIdentityRepository(store)
+
+	identity, err := repo.GetByProviderSubject(ctx, "google", "1234")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(7), identity.UserID)
+
+	identity, err = repo.GetByProviderSubject(ctx, "google", "1234")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, (*entities.ExternalIdentity)(nil), identity)
+}
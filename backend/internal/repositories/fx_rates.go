@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+)
+
+const (
+	FieldBase  = "base"
+	FieldQuote = "quote"
+)
+
+type FxRateRepository struct {
+	store Store
+}
+
+func NewFxRateRepository(store Store) *FxRateRepository {
+	return &FxRateRepository{store: store}
+}
+
+const (
+	upsertFxRateQuery = `INSERT INTO "FxRates" ("base", "quote", "date", "rate", "source", "created_at", "updated_at")
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT ("base", "quote", "date") DO UPDATE
+		SET "rate" = EXCLUDED."rate", "source" = EXCLUDED."source", "updated_at" = EXCLUDED."updated_at"
+		RETURNING *`
+	latestFxRateQuery = `SELECT * FROM "FxRates" WHERE "base" = ? AND "quote" = ? AND "date" <= ? ORDER BY "date" DESC LIMIT 1`
+)
+
+// Upsert stores the rate of a currency pair for a day, replacing any rate
+// already recorded for it in a single statement.
+func (r *FxRateRepository) Upsert(ctx context.Context, rate *entities.FxRate) error {
+	rate.Date = entities.PriceDate(rate.Date)
+	now := time.Now()
+
+	var stored []entities.FxRate
+	err := r.store.Raw(ctx, &stored, upsertFxRateQuery, rate.Base, rate.Quote, rate.Date, rate.Rate, rate.Source, now, now)
+	if err != nil {
+		return err
+	}
+	if len(stored) > 0 {
+		*rate = stored[0]
+	}
+	return nil
+}
+
+// FindLatest returns the most recent rate of a currency pair on or before the given day.
+func (r *FxRateRepository) FindLatest(ctx context.Context, base, quote string, at time.Time) (*entities.FxRate, error) {
+	var rates []entities.FxRate
+	if err := r.store.Raw(ctx, &rates, latestFxRateQuery, base, quote, entities.PriceDate(at)); err != nil {
+		return nil, err
+	}
+	if len(rates) == 0 {
+		return nil, nil
+	}
+	return &rates[0], nil
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_FxRateRepository_Upsert(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	store := &MockStore{}
+	store.On("Raw",
+		mock.Anything,
+		mock.AnythingOfType("*[]entities.FxRate"),
+		mock.MatchedBy(func(query string) bool {
+			return strings.Contains(query, `ON CONFLICT ("base", "quote", "date") DO UPDATE`)
+		}),
+		mock.MatchedBy(func(args []interface{}) bool {
+			return args[0] == "USD" && args[1] == "COP" && args[2].(time.Time).Equal(day)
+		}),
+	).Return(nil).Run(func(args mock.Arguments) {
+		rates := args.Get(1).(*[]entities.FxRate)
+		*rates = []entities.FxRate{{ID: 4, Base: "USD", Quote: "COP", Date: day, Rate: decimal.NewFromInt(4100)}}
+	})
+	repo := NewFxRateRepository(store)
+
+	rate := &entities.FxRate{Base: "USD", Quote: "COP", Date: day.Add(8 * time.Hour), Rate: decimal.NewFromInt(4100), Source: "exchangerate"}
+	err := repo.Upsert(ctx, rate)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(4), rate.ID)
+	store.AssertExpectations(t)
+}
+
+func Test_FxRateRepository_FindLatest(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	store := &MockStore{}
+	store.On("Raw", mock.Anything, mock.Anything, latestFxRateQuery, []interface{}{"USD", "COP", day(8)}).
+		Return(nil).Run(func(args mock.Arguments) {
+		rates := args.Get(1).(*[]entities.FxRate)
+		*rates = []entities.FxRate{{Date: day(7), Rate: decimal.NewFromInt(4000)}}
+	})
+	store.On("Raw", mock.Anything, mock.Anything, latestFxRateQuery, []interface{}{"USD", "COP", day(1)}).Return(nil)
+	repo := NewFxRateRepository(store)
+
+	rate, err := repo.FindLatest(ctx, "USD", "COP", day(8).Add(10*time.Hour))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, day(7), rate.Date)
+
+	rate, err = repo.FindLatest(ctx, "USD", "COP", day(1))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, (*entities.FxRate)(nil), rate)
+	store.AssertExpectations(t)
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+)
+
+const FieldTokenHash = "token_hash"
+
+type PasswordResetRepository struct {
+	store Store
+}
+
+func NewPasswordResetRepository(store Store) *PasswordResetRepository {
+	return &PasswordResetRepository{store: store}
+}
+
+func (r *PasswordResetRepository) Create(ctx context.Context, reset *entities.PasswordReset) error {
+	return r.store.Create(ctx, reset)
+}
+
+func (r *PasswordResetRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.PasswordReset, error) {
+	var reset entities.PasswordReset
+	condition := map[string]interface{}{FieldTokenHash: tokenHash}
+	exists, err := r.store.FindOne(ctx, &reset, condition)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	return &reset, nil
+}
+
+const spendPasswordResetQuery = `UPDATE "PasswordResets" SET "used_at" = ? WHERE "token_hash" = ? AND "used_at" IS NULL`
+
+// Spend marks the reset used if it was not yet. false means another request
+// used the same token first.
+func (r *PasswordResetRepository) Spend(ctx context.Context, reset *entities.PasswordReset, usedAt time.Time) (bool, error) {
+	affected, err := r.store.Exec(ctx, spendPasswordResetQuery, usedAt, reset.TokenHash)
+	if err != nil {
+		return false, err
+	}
+	if affected == 1 {
+		reset.UsedAt = &usedAt
+	}
+	return affected == 1, nil
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_PasswordResetRepository_GetByTokenHash(t *testing.T) {
+	ctx := context.Background()
+
+	store := &MockStore{}
+	store.On("FindOne", mock.Anything, &entities.PasswordReset{}, map[string]interface{}{FieldTokenHash: "known"}).
+		Return(true, nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*entities.PasswordReset).UserID = 7
+	})
+	store.On("FindOne", mock.Anything, &entities.PasswordReset{}, map[string]interface{}{FieldTokenHash: "unknown"}).
+		Return(false, nil)
+	repo := NewPasswordResetRepository(store)
+
+	reset, err := repo.GetByTokenHash(ctx, "known")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(7), reset.UserID)
+
+	reset, err = repo.GetByTokenHash(ctx, "unknown")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, (*entities.PasswordReset)(nil), reset)
+	store.AssertExpectations(t)
+}
+
+func Test_PasswordResetRepository_Spend(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		affected int64
+		expected bool
+	}{
+		{name: "the token was unused", affected: 1, expected: true},
+		{name: "another request used the token first", affected: 0, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &MockStore{}
+			store.On("Exec", mock.Anything, spendPasswordResetQuery, []interface{}{now, "hash"}).Return(tc.affected, nil)
+			repo := NewPasswordResetRepository(store)
+
+			reset := &entities.PasswordReset{TokenHash: "hash"}
+			spent, err := repo.Spend(ctx, reset, now)
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.expected, spent)
+			assert.Equal(t, tc.expected, reset.UsedAt != nil)
+			store.AssertExpectations(t)
+		})
+	}
+}
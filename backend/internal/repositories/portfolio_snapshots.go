@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+)
+
+type PortfolioSnapshotRepository struct {
+	store Store
+}
+
+func NewPortfolioSnapshotRepository(store Store) *PortfolioSnapshotRepository {
+	return &PortfolioSnapshotRepository{store: store}
+}
+
+// Upsert stores the snapshot of an asset for a day, replacing any snapshot already taken for it.
+func (r *PortfolioSnapshotRepository) Upsert(ctx context.Context, snapshot *entities.PortfolioSnapshot) error {
+	snapshot.Date = entities.PriceDate(snapshot.Date)
+
+	var existing entities.PortfolioSnapshot
+	condition := map[string]interface{}{FieldAssetID: snapshot.AssetID, FieldDate: snapshot.Date}
+	exists, err := r.store.FindOne(ctx, &existing, condition)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return r.store.Create(ctx, snapshot)
+	}
+
+	existing.Units = snapshot.Units
+	existing.InvestedTotal = snapshot.InvestedTotal
+	existing.MarketValue = snapshot.MarketValue
+	existing.Currency = snapshot.Currency
+	existing.UpdatedAt = time.Now()
+	if err := r.store.Update(ctx, &existing); err != nil {
+		return err
+	}
+	*snapshot = existing
+	return nil
+}
+
+// ListByUser returns every snapshot of a user, oldest first.
+func (r *PortfolioSnapshotRepository) ListByUser(ctx context.Context, userID uint64) ([]entities.PortfolioSnapshot, error) {
+	var snapshots []entities.PortfolioSnapshot
+	condition := map[string]interface{}{FieldUserID: userID}
+	if err := r.store.FindAll(ctx, &snapshots, condition); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Date.Before(snapshots[j].Date)
+	})
+	return snapshots, nil
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+)
+
+const FieldCodeHash = "code_hash"
+
+type RecoveryCodeRepository struct {
+	store Store
+}
+
+func NewRecoveryCodeRepository(store Store) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{store: store}
+}
+
+// ReplaceForUser deletes the codes of the user and stores the new ones.
+func (r *RecoveryCodeRepository) ReplaceForUser(ctx context.Context, userID uint64, codes []entities.RecoveryCode) error {
+	if err := r.DeleteByUser(ctx, userID); err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return r.store.Create(ctx, &codes)
+}
+
+func (r *RecoveryCodeRepository) GetByUserAndHash(ctx context.Context, userID uint64, codeHash string) (*entities.RecoveryCode, error) {
+	var code entities.RecoveryCode
+	condition := map[string]interface{}{FieldUserID: userID, FieldCodeHash: codeHash}
+	exists, err := r.store.FindOne(ctx, &code, condition)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	return &code, nil
+}
+
+const spendRecoveryCodeQuery = `UPDATE "RecoveryCodes" SET "used_at" = ? WHERE "id" = ? AND "used_at" IS NULL`
+
+// Spend marks the code used if it was not yet. false means another request
+// used the same code first.
+func (r *RecoveryCodeRepository) Spend(ctx context.Context, code *entities.RecoveryCode, usedAt time.Time) (bool, error) {
+	affected, err := r.store.Exec(ctx, spendRecoveryCodeQuery, usedAt, code.ID)
+	if err != nil {
+		return false, err
+	}
+	if affected == 1 {
+		code.UsedAt = &usedAt
+	}
+	return affected == 1, nil
+}
+
+func (r *RecoveryCodeRepository) DeleteByUser(ctx context.Context, userID uint64) error {
+	condition := map[string]interface{}{FieldUserID: userID}
+	return r.store.Delete(ctx, &entities.RecoveryCode{}, condition)
+}
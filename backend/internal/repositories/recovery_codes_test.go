@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_RecoveryCodeRepository_ReplaceForUser(t *testing.T) {
+	ctx := context.Background()
+
+	store := &MockStore{}
+	store.On("Delete", mock.Anything, &entities.RecoveryCode{}, map[string]interface{}{FieldUserID: uint64(7)}).Return(nil)
+	codes := []entities.RecoveryCode{{UserID: 7, CodeHash: "a"}, {UserID: 7, CodeHash: "b"}}
+	store.On("Create", mock.Anything, &codes).Return(nil)
+	repo := NewRecoveryCodeRepository(store)
+
+	// The previous codes are deleted before the new ones are stored.
+	err := repo.ReplaceForUser(ctx, 7, codes)
+	assert.Equal(t, nil, err)
+	store.AssertExpectations(t)
+	assert.Equal(t, "Delete", store.Calls[0].Method)
+	assert.Equal(t, "Create", store.Calls[1].Method)
+}
+
+func Test_RecoveryCodeRepository_Spend(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		affected int64
+		expected bool
+	}{
+		{name: "the code was unused", affected: 1, expected: true},
+		{name: "another request used the code first", affected: 0, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &MockStore{}
+			store.On("Exec", mock.Anything, spendRecoveryCodeQuery, []interface{}{now, uint64(3)}).Return(tc.affected, nil)
+			repo := NewRecoveryCodeRepository(store)
+
+			code := &entities.RecoveryCode{ID: 3, UserID: 7}
+			spent, err := repo.Spend(ctx, code, now)
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.expected, spent)
+			assert.Equal(t, tc.expected, code.UsedAt != nil)
+			store.AssertExpectations(t)
+		})
+	}
+}
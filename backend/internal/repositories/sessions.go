@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+)
+
+type SessionRepository struct {
+	store Store
+}
+
+func NewSessionRepository(store Store) *SessionRepository {
+	return &SessionRepository{store: store}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, session *entities.Session) error {
+	return r.store.Create(ctx, session)
+}
+
+func (r *SessionRepository) GetByCode(ctx context.Context, code uuid.UUID) (*entities.Session, error) {
+	var session entities.Session
+	condition := map[string]interface{}{FieldCode: code}
+	exists, err := r.store.FindOne(ctx, &session, condition)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// ListActiveByUser returns the sessions of a user that are neither revoked nor
+// expired, most recently used first.
+func (r *SessionRepository) ListActiveByUser(ctx context.Context, userID uint64, now time.Time) ([]entities.Session, error) {
+	var sessions []entities.Session
+	condition := map[string]interface{}{FieldUserID: userID}
+	if err := r.store.FindAll(ctx, &sessions, condition); err != nil {
+		return nil, err
+	}
+
+	active := sessions[:0]
+	for _, session := range sessions {
+		if session.IsActive(now) {
+			active = append(active, session)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].LastUsedAt.After(active[j].LastUsedAt)
+	})
+	return active, nil
+}
+
+func (r *SessionRepository) Update(ctx context.Context, session *entities.Session) error {
+	return r.store.Update(ctx, session)
+}
+
+const rotateSessionQuery = `UPDATE "Sessions" SET "refresh_token_id" = ?, "last_used_at" = ?, "expires_at" = ?
+	WHERE "id" = ? AND "refresh_token_id" = ? AND "revoked_at" IS NULL`
+
+// Rotate stores the new refresh token of a session if previous is still its
+// current one. false means another request rotated the same token first.
+func (r *SessionRepository) Rotate(ctx context.Context, session *entities.Session, previous uuid.UUID) (bool, error) {
+	affected, err := r.store.Exec(ctx, rotateSessionQuery,
+		session.RefreshTokenID, session.LastUsedAt, session.ExpiresAt, session.ID, previous)
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
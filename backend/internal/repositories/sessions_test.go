@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_SessionRepository_ListActiveByUser(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	revokedAt := now.Add(-time.Hour)
+	laptop, phone := uuid.New(), uuid.New()
+
+	store := &MockStore{}
+	store.On("FindAll",
+		mock.Anything,
+		mock.Anything,
+		map[string]interface{}{FieldUserID: uint64(1)},
+	).Return(nil).Run(func(args mock.Arguments) {
+		sessions := args.Get(1).(*[]entities.Session)
+		*sessions = []entities.Session{
+			{Code: laptop, LastUsedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(time.Hour)},
+			{Code: uuid.New(), LastUsedAt: now.Add(-time.Minute), ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt},
+			{Code: uuid.New(), LastUsedAt: now.Add(-48 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+			{Code: phone, LastUsedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)},
+		}
+	})
+	repo := NewSessionRepository(store)
+
+	sessions, err := repo.ListActiveByUser(ctx, 1, now)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(sessions))
+	assert.Equal(t, phone, sessions[0].Code)
+	assert.Equal(t, laptop, sessions[1].Code)
+	store.AssertExpectations(t)
+}
+
+func Test_SessionRepository_Rotate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	previous, next := uuid.New(), uuid.New()
+	session := &entities.Session{ID: 5, RefreshTokenID: next, LastUsedAt: now, ExpiresAt: now.Add(24 * time.Hour)}
+	args := []interface{}{next, now, now.Add(24 * time.Hour), uint64(5), previous}
+
+	testCases := []struct {
+		name     string
+		affected int64
+		expected bool
+	}{
+		{name: "the token was still current", affected: 1, expected: true},
+		{name: "another request rotated the token first", affected: 0, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &MockStore{}
+			store.On("Exec", mock.Anything, rotateSessionQuery, args).Return(tc.affected, nil)
+			repo := NewSessionRepository(store)
+
+			rotated, err := repo.Rotate(ctx, session, previous)
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.expected, rotated)
+			store.AssertExpectations(t)
+		})
+	}
+}
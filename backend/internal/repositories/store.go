@@ -0,0 +1,16 @@
+package repositories
+
+import "context"
+
+// Store persists the entities. Raw and Exec run the SQL the map conditions
+// cannot express, like row locks, upserts and ordered limits; Exec returns the
+// number of affected rows.
+type Store interface {
+	Create(ctx context.Context, destination interface{}) error
+	FindOne(ctx context.Context, destination interface{}, conditions interface{}) (bool, error)
+	FindAll(ctx context.Context, destination interface{}, conditions interface{}) error
+	Update(ctx context.Context, destination interface{}) error
+	Delete(ctx context.Context, destination interface{}, conditions interface{}) error
+	Raw(ctx context.Context, destination interface{}, query string, args ...interface{}) error
+	Exec(ctx context.Context, query string, args ...interface{}) (int64, error)
+}
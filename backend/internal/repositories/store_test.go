@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStore struct {
+	mock.Mock
+}
+
+func (m *MockStore) FindOne(ctx context.Context, destination interface{}, conditions interface{}) (bool, error) {
+	args := m.Called(ctx, destination, conditions)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStore) Create(ctx context.Context, destination interface{}) error {
+	args := m.Called(ctx, destination)
+	return args.Error(0)
+}
+
+func (m *MockStore) FindAll(ctx context.Context, destination interface{}, conditions interface{}) error {
+	args := m.Called(ctx, destination, conditions)
+	return args.Error(0)
+}
+
+func (m *MockStore) Update(ctx context.Context, destination interface{}) error {
+	args := m.Called(ctx, destination)
+	return args.Error(0)
+}
+
+func (m *MockStore) Delete(ctx context.Context, destination interface{}, conditions interface{}) error {
+	args := m.Called(ctx, destination, conditions)
+	return args.Error(0)
+}
+
+func (m *MockStore) Raw(ctx context.Context, destination interface{}, query string, args ...interface{}) error {
+	called := m.Called(ctx, destination, query, args)
+	return called.Error(0)
+}
+
+func (m *MockStore) Exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	called := m.Called(ctx, query, args)
+	return called.Get(0).(int64), called.Error(1)
+}
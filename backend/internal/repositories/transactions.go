@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+)
+
+const (
+	FieldAssetID = "asset_id"
+)
+
+type TransactionRepository struct {
+	store Store
+}
+
+func NewTransactionRepository(store Store) *TransactionRepository {
+	return &TransactionRepository{store: store}
+}
+
+func (r *TransactionRepository) Create(ctx context.Context, transaction *entities.Transaction) error {
+	return r.store.Create(ctx, transaction)
+}
+
+// ListByAsset returns the ledger of an asset ordered from oldest to newest.
+func (r *TransactionRepository) ListByAsset(ctx context.Context, assetID uint64) ([]entities.Transaction, error) {
+	var transactions []entities.Transaction
+	condition := map[string]interface{}{FieldAssetID: assetID}
+	if err := r.store.FindAll(ctx, &transactions, condition); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].CreatedAt.Before(transactions[j].CreatedAt)
+	})
+	return transactions, nil
+}
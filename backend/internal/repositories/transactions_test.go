@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_TransactionRepository_ListByAsset(t *testing.T) {
+	ctx := context.Background()
+	assetID := uint64(4)
+	now := time.Now()
+
+	testCase := []struct {
+		name          string
+		mockFunc      func(*MockStore)
+		expectedTypes []string
+		expectError   error
+	}{
+		{
+			name: "ledger is returned oldest first",
+			mockFunc: func(store *MockStore) {
+				store.On("FindAll",
+					mock.Anything,
+					mock.AnythingOfType("*[]entities.Transaction"),
+					map[string]interface{}{FieldAssetID: assetID},
+				).Return(nil).Run(func(args mock.Arguments) {
+					transactions := args.Get(1).(*[]entities.Transaction)
+					*transactions = []entities.Transaction{
+						{Type: entities.TransactionTypeSell, CreatedAt: now},
+						{Type: entities.TransactionTypeBuy, CreatedAt: now.Add(-time.Hour)},
+					}
+				})
+			},
+			expectedTypes: []string{entities.TransactionTypeBuy, entities.TransactionTypeSell},
+		},
+		{
+			name: "error listing transactions",
+			mockFunc: func(store *MockStore) {
+				store.On("FindAll", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("error listing transactions"))
+			},
+			expectError: errors.New("error listing transactions"),
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &MockStore{}
+			repo := NewTransactionRepository(store)
+
+			tc.mockFunc(store)
+			transactions, err := repo.ListByAsset(ctx, assetID)
+
+			if tc.expectError != nil {
+				assert.Equal(t, tc.expectError, err)
+			} else {
+				if err != nil {
+					t.Fatalf("Error listing transactions: %v", err)
+				}
+				types := make([]string, 0, len(transactions))
+				for _, transaction := range transactions {
+					types = append(types, transaction.Type)
+				}
+				assert.Equal(t, tc.expectedTypes, types)
+			}
+		})
+	}
+}
@@ -8,15 +8,12 @@ import (
 )
 
 const (
-	FieldCode  = "code"
-	FieldEmail = "email"
+	FieldID       = "id"
+	FieldCode     = "code"
+	FieldEmail    = "email"
+	FieldUsername = "username"
 )
 
-type Store interface {
-	Create(ctx context.Context, destination interface{}) error
-	FindOne(ctx context.Context, destination interface{}, conditions interface{}) (bool, error)
-}
-
 type UserRepository struct {
 	store Store
 }
@@ -33,6 +30,8 @@ func (r *UserRepository) Create(ctx context.Context, user *entities.User) error
 	return nil
 }
 
+// GetByCode, GetByID and GetByEmail do not find deleted users, who can no
+// longer log in while they wait to be purged.
 func (r *UserRepository) GetByCode(ctx context.Context, code uuid.UUID) (*entities.User, error) {
 	var user entities.User
 	condition := map[string]interface{}{FieldCode: code}
@@ -40,7 +39,20 @@ func (r *UserRepository) GetByCode(ctx context.Context, code uuid.UUID) (*entiti
 	if err != nil {
 		return nil, err
 	}
-	if !exists {
+	if !exists || user.IsDeleted() {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id uint64) (*entities.User, error) {
+	var user entities.User
+	condition := map[string]interface{}{FieldID: id}
+	exists, err := r.store.FindOne(ctx, &user, condition)
+	if err != nil {
+		return nil, err
+	}
+	if !exists || user.IsDeleted() {
 		return nil, nil
 	}
 	return &user, nil
@@ -53,8 +65,40 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*entitie
 	if err != nil {
 		return nil, err
 	}
-	if !exists {
+	if !exists || user.IsDeleted() {
 		return nil, nil
 	}
 	return &user, nil
 }
+
+func (r *UserRepository) Update(ctx context.Context, user *entities.User) error {
+	return r.store.Update(ctx, user)
+}
+
+const advanceMFAStepQuery = `UPDATE "Users" SET "mfa_last_step" = ? WHERE "id" = ? AND "mfa_last_step" < ?`
+
+// AdvanceMFAStep stores the TOTP step last used by the user if it is later
+// than the stored one. false means another request used the step first.
+func (r *UserRepository) AdvanceMFAStep(ctx context.Context, userID uint64, step int64) (bool, error) {
+	affected, err := r.store.Exec(ctx, advanceMFAStepQuery, step, userID, step)
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// EmailTaken reports whether a user has the email, deleted users included, as
+// their email is kept until they are purged.
+func (r *UserRepository) EmailTaken(ctx context.Context, email string) (bool, error) {
+	return r.store.FindOne(ctx, &entities.User{}, map[string]interface{}{FieldEmail: email})
+}
+
+// UsernameTaken reports whether a user has the username, deleted users
+// included.
+func (r *UserRepository) UsernameTaken(ctx context.Context, username string) (bool, error) {
+	return r.store.FindOne(ctx, &entities.User{}, map[string]interface{}{FieldUsername: username})
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id uint64) error {
+	return r.store.Delete(ctx, &entities.User{}, map[string]interface{}{FieldID: id})
+}
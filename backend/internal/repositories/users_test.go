@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/go-playground/assert/v2"
 	"github.com/google/uuid"
@@ -11,20 +12,6 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
-type MockStore struct {
-	mock.Mock
-}
-
-func (m *MockStore) FindOne(ctx context.Context, destination interface{}, conditions interface{}) (bool, error) {
-	args := m.Called(ctx, destination, conditions)
-	return args.Bool(0), args.Error(1)
-}
-
-func (m *MockStore) Create(ctx context.Context, destination interface{}) error {
-	args := m.Called(ctx, destination)
-	return args.Error(0)
-}
-
 func Test_UserRepository_GetByEmail(t *testing.T) {
 	ctx := context.Background()
 
@@ -138,6 +125,23 @@ func Test_UserRepository_GetByCode(t *testing.T) {
 			expectedUser: nil,
 			expectError:  nil,
 		},
+		{
+			name: "deleted user",
+			code: uuid.New(),
+			mockFunc: func(store *MockStore, code uuid.UUID) {
+				store.On("FindOne",
+					mock.Anything,
+					&entities.User{},
+					map[string]interface{}{FieldCode: code},
+				).Return(true, nil).Run(func(args mock.Arguments) {
+					user := args.Get(1).(*entities.User)
+					user.Code = code
+					user.DeletedAt = &time.Time{}
+				})
+			},
+			expectedUser: nil,
+			expectError:  nil,
+		},
 		{
 			name: "error finding user",
 			code: uuid.New(),
@@ -228,3 +232,30 @@ func Test_UserRepository_Create(t *testing.T) {
 		})
 	}
 }
+
+func Test_UserRepository_AdvanceMFAStep(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		name     string
+		affected int64
+		expected bool
+	}{
+		{name: "the step is later than the stored one", affected: 1, expected: true},
+		{name: "another request used the step first", affected: 0, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &MockStore{}
+			store.On("Exec", mock.Anything, advanceMFAStepQuery, []interface{}{int64(42), uint64(7), int64(42)}).Return(tc.affected, nil)
+			repo := NewUserRepository(store)
+
+			advanced, err := repo.AdvanceMFAStep(ctx, 7, 42)
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.expected, advanced)
+			store.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package account
+
+import (
+	"context"
+	libErrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/costbasis"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/crypto"
+)
+
+const (
+	invalidPasswordCode        = "INVALID_PASSWORD"
+	emailTakenCode             = "EMAIL_TAKEN"
+	usernameTakenCode          = "USERNAME_TAKEN"
+	invalidCostBasisMethodCode = "INVALID_COST_BASIS_METHOD"
+	passwordRequiredCode       = "PASSWORD_REQUIRED"
+	accountPurgeStep           = "account_purge"
+)
+
+type userRepository interface {
+	Update(ctx context.Context, user *entities.User) error
+	EmailTaken(ctx context.Context, email string) (bool, error)
+	UsernameTaken(ctx context.Context, username string) (bool, error)
+	Delete(ctx context.Context, id uint64) error
+}
+
+type deletionRepository interface {
+	Create(ctx context.Context, deletion *entities.AccountDeletion) error
+	ListDue(ctx context.Context, now time.Time) ([]entities.AccountDeletion, error)
+}
+
+type assetRepository interface {
+	ListByUser(ctx context.Context, userID uint64) ([]entities.Asset, error)
+	Delete(ctx context.Context, asset *entities.Asset) error
+}
+
+type emailVerifier interface {
+	SendVerification(ctx context.Context, user *entities.User) error
+}
+
+type sessionManager interface {
+	RevokeOtherSessions(ctx context.Context, user *entities.User, current uuid.UUID) error
+	InvalidateTokens(ctx context.Context, user *entities.User) error
+}
+
+type transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type service struct {
+	userRepository     userRepository
+	deletionRepository deletionRepository
+	assetRepository    assetRepository
+	verifier           emailVerifier
+	sessions           sessionManager
+	transactor         transactor
+	logger             log.Logger
+	gracePeriod        time.Duration
+	now                func() time.Time
+}
+
+func NewService(userRepo userRepository, deletionRepo deletionRepository, assetRepo assetRepository, verifier emailVerifier,
+	sessions sessionManager, transactor transactor, logger log.Logger, gracePeriod time.Duration) *service {
+	return &service{
+		userRepository:     userRepo,
+		deletionRepository: deletionRepo,
+		assetRepository:    assetRepo,
+		verifier:           verifier,
+		sessions:           sessions,
+		transactor:         transactor,
+		logger:             logger,
+		gracePeriod:        gracePeriod,
+		now:                time.Now,
+	}
+}
+
+// UpdateProfile changes the fields set in the request. A new email has to be
+// verified again. Amounts keep the currency of their asset and are converted
+// when read, so changing the base currency leaves them untouched.
+func (s *service) UpdateProfile(ctx context.Context, user *entities.User, req *request.UpdateUser) (*response.User, error) {
+	if req.UserName != nil && *req.UserName != user.Username {
+		taken, err := s.userRepository.UsernameTaken(ctx, *req.UserName)
+		if err != nil {
+			return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+		}
+		if taken {
+			return nil, errors.New(http.StatusConflict, usernameTakenCode, []string{"Username is already taken"})
+		}
+		user.Username = *req.UserName
+	}
+
+	emailChanged := false
+	if req.Email != nil {
+		email := strings.TrimSpace(*req.Email)
+		if !strings.EqualFold(email, user.Email) {
+			if err := s.confirmPassword(user, req.CurrentPassword); err != nil {
+				return nil, err
+			}
+			taken, err := s.userRepository.EmailTaken(ctx, email)
+			if err != nil {
+				return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+			}
+			if taken {
+				return nil, errors.New(http.StatusConflict, emailTakenCode, []string{"Email is already in use"})
+			}
+			user.Email = email
+			user.EmailVerifiedAt = nil
+			emailChanged = true
+		}
+	}
+
+	if req.Currency != nil {
+		user.Currency = strings.ToUpper(strings.TrimSpace(*req.Currency))
+	}
+
+	if req.CostBasisMethod != nil {
+		method, err := costbasis.ParseMethod(*req.CostBasisMethod)
+		if err != nil {
+			return nil, errors.New(http.StatusBadRequest, invalidCostBasisMethodCode, []string{"cost_basis_method must be one of: FIFO, LIFO, AVERAGE"})
+		}
+		user.CostBasisMethod = string(method)
+	}
+
+	user.UpdatedAt = s.now()
+	if err := s.userRepository.Update(ctx, user); err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	if emailChanged {
+		if err := s.verifier.SendVerification(ctx, user); err != nil {
+			s.logger.Error(ctx, "update_profile_verification", "error sending verification email", log.Field("user_code", user.Code), log.Field("error", err))
+		}
+	}
+
+	return response.ToUserResponse(user), nil
+}
+
+// ChangePassword sets a new password and ends every other session of the user,
+// keeping the current one.
+func (s *service) ChangePassword(ctx context.Context, user *entities.User, current uuid.UUID, req *request.ChangePassword) error {
+	if err := s.confirmPassword(user, req.CurrentPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := crypto.HashPassword(req.NewPassword)
+	if err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	user.PasswordHash = hashedPassword
+	user.PasswordSalt = ""
+	user.UpdatedAt = s.now()
+	if err := s.userRepository.Update(ctx, user); err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	return s.sessions.RevokeOtherSessions(ctx, user, current)
+}
+
+// DeleteAccount deletes the account at once, ending all of its sessions, and
+// schedules the purge of its data once the grace period ends.
+func (s *service) DeleteAccount(ctx context.Context, user *entities.User, req *request.DeleteAccount) (*response.AccountDeletion, error) {
+	if user.PasswordHash != "" {
+		if err := s.confirmPassword(user, req.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	now := s.now()
+	deletion := &entities.AccountDeletion{
+		UserID:      user.ID,
+		RequestedAt: now,
+		PurgeAfter:  now.Add(s.gracePeriod),
+	}
+	user.DeletedAt = &now
+	user.UpdatedAt = now
+
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepository.Update(ctx, user); err != nil {
+			return err
+		}
+		if err := s.deletionRepository.Create(ctx, deletion); err != nil {
+			return err
+		}
+		return s.sessions.InvalidateTokens(ctx, user)
+	})
+	if err != nil {
+		s.logger.Error(ctx, "delete_account", "error deleting account", log.Field("user_code", user.Code), log.Field("error", err))
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	return &response.AccountDeletion{DeletedAt: now, PurgeAfter: deletion.PurgeAfter}, nil
+}
+
+// PurgeDeletedAccounts removes for good the accounts whose grace period ended,
+// with their assets and, through them, their transactions, prices and
+// snapshots. A failed account is retried on the next run.
+func (s *service) PurgeDeletedAccounts(ctx context.Context) error {
+	deletions, err := s.deletionRepository.ListDue(ctx, s.now())
+	if err != nil {
+		return fmt.Errorf("listing account deletions: %w", err)
+	}
+
+	var errs []error
+	for _, deletion := range deletions {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+			return s.purge(ctx, deletion.UserID)
+		}); err != nil {
+			s.logger.Warning(ctx, accountPurgeStep, "error purging account", log.Field("user_id", deletion.UserID), log.Field("error", err))
+			errs = append(errs, fmt.Errorf("user %d: %w", deletion.UserID, err))
+		}
+	}
+	return libErrors.Join(errs...)
+}
+
+func (s *service) purge(ctx context.Context, userID uint64) error {
+	assets, err := s.assetRepository.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for i := range assets {
+		if err := s.assetRepository.Delete(ctx, &assets[i]); err != nil {
+			return err
+		}
+	}
+	return s.userRepository.Delete(ctx, userID)
+}
+
+// confirmPassword checks the password the user typed again for a sensitive
+// change. Users signed up with an identity provider have to set a password
+// with a password reset first.
+func (s *service) confirmPassword(user *entities.User, password string) error {
+	if user.PasswordHash == "" {
+		return errors.New(http.StatusForbidden, passwordRequiredCode, []string{"Set a password with a password reset first"})
+	}
+	if valid, _ := crypto.VerifyPassword(password, user.PasswordSalt, user.PasswordHash); !valid {
+		return errors.New(http.StatusForbidden, invalidPasswordCode, []string{"Current password is incorrect"})
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+package account
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/pointers"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const password = "password123"
+
+// memoryUsers keeps the users by ID, deleted ones included.
+type memoryUsers map[uint64]entities.User
+
+func (r memoryUsers) Update(ctx context.Context, user *entities.User) error {
+	r[user.ID] = *user
+	return nil
+}
+
+func (r memoryUsers) EmailTaken(ctx context.Context, email string) (bool, error) {
+	for _, user := range r {
+		if user.Email == email {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r memoryUsers) UsernameTaken(ctx context.Context, username string) (bool, error) {
+	for _, user := range r {
+		if user.Username == username {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r memoryUsers) Delete(ctx context.Context, id uint64) error {
+	delete(r, id)
+	return nil
+}
+
+type memoryDeletions struct {
+	deletions []entities.AccountDeletion
+}
+
+func (r *memoryDeletions) Create(ctx context.Context, deletion *entities.AccountDeletion) error {
+	r.deletions = append(r.deletions, *deletion)
+	return nil
+}
+
+func (r *memoryDeletions) ListDue(ctx context.Context, now time.Time) ([]entities.AccountDeletion, error) {
+	var due []entities.AccountDeletion
+	for _, deletion := range r.deletions {
+		if !deletion.PurgeAfter.After(now) {
+			due = append(due, deletion)
+		}
+	}
+	return due, nil
+}
+
+// memoryAssets keeps the assets by user.
+type memoryAssets map[uint64][]entities.Asset
+
+func (r memoryAssets) ListByUser(ctx context.Context, userID uint64) ([]entities.Asset, error) {
+	return r[userID], nil
+}
+
+func (r memoryAssets) Delete(ctx context.Context, asset *entities.Asset) error {
+	assets := r[asset.UserID]
+	for i := range assets {
+		if assets[i].Code == asset.Code {
+			r[asset.UserID] = append(assets[:i], assets[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+type fakeVerifier struct {
+	users []*entities.User
+}
+
+func (f *fakeVerifier) SendVerification(ctx context.Context, user *entities.User) error {
+	f.users = append(f.users, user)
+	return nil
+}
+
+// fakeSessions records the sessions kept and the users whose tokens were
+// invalidated. It does not save the users, so the tests catch callers relying
+// on the auth service doing it.
+type fakeSessions struct {
+	kept        *uuid.UUID
+	invalidated []uint64
+}
+
+func (f *fakeSessions) RevokeOtherSessions(ctx context.Context, user *entities.User, current uuid.UUID) error {
+	f.kept = &current
+	return nil
+}
+
+func (f *fakeSessions) InvalidateTokens(ctx context.Context, user *entities.User) error {
+	user.TokenVersion++
+	f.invalidated = append(f.invalidated, user.ID)
+	return nil
+}
+
+type inlineTransactor struct{}
+
+func (inlineTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type fixture struct {
+	service   *service
+	users     memoryUsers
+	deletions *memoryDeletions
+	assets    memoryAssets
+	verifier  *fakeVerifier
+	sessions  *fakeSessions
+	user      *entities.User
+	now       time.Time
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	hashedPassword, err := crypto.HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	user := entities.User{
+		ID:              1,
+		Code:            uuid.New(),
+		Username:        "ana",
+		Email:           "ana@example.com",
+		PasswordHash:    hashedPassword,
+		Currency:        "USD",
+		CostBasisMethod: "FIFO",
+		EmailVerifiedAt: &now,
+	}
+	logger := new(mocks.Logger)
+	logger.On("Warning", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	f := &fixture{
+		users:     memoryUsers{1: user, 2: {ID: 2, Username: "bob", Email: "bob@example.com"}},
+		deletions: &memoryDeletions{},
+		assets:    memoryAssets{},
+		verifier:  &fakeVerifier{},
+		user:      &user,
+		now:       now,
+	}
+	f.sessions = &fakeSessions{}
+	f.service = NewService(f.users, f.deletions, f.assets, f.verifier, f.sessions, inlineTransactor{}, logger, 30*24*time.Hour)
+	f.service.now = func() time.Time { return f.now }
+	return f
+}
+
+func assertErrorCode(t *testing.T, err error, httpCode int, code string) {
+	t.Helper()
+	errorResponse, ok := err.(*errors.ErrorResponse)
+	if assert.True(t, ok, "unexpected error %v", err) {
+		assert.Equal(t, httpCode, errorResponse.HttpCode)
+		assert.Equal(t, code, errorResponse.Code)
+	}
+}
+
+func Test_UpdateProfile(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("only the fields set are changed", func(t *testing.T) {
+		f := newFixture(t)
+		result, err := f.service.UpdateProfile(ctx, f.user, &request.UpdateUser{
+			Currency:        pointers.Pointer(" cop "),
+			CostBasisMethod: pointers.Pointer("average"),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "COP", result.Currency)
+		assert.Equal(t, "AVERAGE", result.CostBasisMethod)
+		assert.Equal(t, "ana", result.UserName)
+		assert.Equal(t, "COP", f.users[1].Currency)
+		assert.Empty(t, f.verifier.users)
+	})
+
+	t.Run("the username must be free", func(t *testing.T) {
+		f := newFixture(t)
+		_, err := f.service.UpdateProfile(ctx, f.user, &request.UpdateUser{UserName: pointers.Pointer("bob")})
+		assertErrorCode(t, err, http.StatusConflict, usernameTakenCode)
+	})
+
+	t.Run("a new email needs the password and is verified again", func(t *testing.T) {
+		f := newFixture(t)
+		_, err := f.service.UpdateProfile(ctx, f.user, &request.UpdateUser{Email: pointers.Pointer("ana@new.com"), CurrentPassword: "wrong"})
+		assertErrorCode(t, err, http.StatusForbidden, invalidPasswordCode)
+
+		_, err = f.service.UpdateProfile(ctx, f.user, &request.UpdateUser{Email: pointers.Pointer("bob@example.com"), CurrentPassword: password})
+		assertErrorCode(t, err, http.StatusConflict, emailTakenCode)
+
+		result, err := f.service.UpdateProfile(ctx, f.user, &request.UpdateUser{Email: pointers.Pointer("ana@new.com"), CurrentPassword: password})
+		assert.NoError(t, err)
+		assert.Equal(t, "ana@new.com", result.Email)
+		assert.False(t, result.EmailVerified)
+		assert.Len(t, f.verifier.users, 1)
+	})
+
+	t.Run("an invalid cost basis method is refused", func(t *testing.T) {
+		f := newFixture(t)
+		_, err := f.service.UpdateProfile(ctx, f.user, &request.UpdateUser{CostBasisMethod: pointers.Pointer("HIFO")})
+		assertErrorCode(t, err, http.StatusBadRequest, invalidCostBasisMethodCode)
+	})
+}
+
+func Test_ChangePassword(t *testing.T) {
+	ctx := context.Background()
+	current := uuid.New()
+
+	t.Run("the current password is required", func(t *testing.T) {
+		f := newFixture(t)
+		err := f.service.ChangePassword(ctx, f.user, current, &request.ChangePassword{CurrentPassword: "wrong", NewPassword: "newPassword1"})
+		assertErrorCode(t, err, http.StatusForbidden, invalidPasswordCode)
+		assert.Nil(t, f.sessions.kept)
+	})
+
+	t.Run("the other sessions are ended", func(t *testing.T) {
+		f := newFixture(t)
+		err := f.service.ChangePassword(ctx, f.user, current, &request.ChangePassword{CurrentPassword: password, NewPassword: "newPassword1"})
+		assert.NoError(t, err)
+		valid, _ := crypto.VerifyPassword("newPassword1", "", f.users[1].PasswordHash)
+		assert.True(t, valid)
+		assert.Equal(t, &current, f.sessions.kept)
+	})
+
+	t.Run("users without a password are sent to the password reset", func(t *testing.T) {
+		f := newFixture(t)
+		f.user.PasswordHash = ""
+		err := f.service.ChangePassword(ctx, f.user, current, &request.ChangePassword{CurrentPassword: password, NewPassword: "newPassword1"})
+		assertErrorCode(t, err, http.StatusForbidden, passwordRequiredCode)
+	})
+}
+
+func Test_DeleteAccount(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("the password confirms the deletion", func(t *testing.T) {
+		f := newFixture(t)
+		_, err := f.service.DeleteAccount(ctx, f.user, &request.DeleteAccount{Password: "wrong"})
+		assertErrorCode(t, err, http.StatusForbidden, invalidPasswordCode)
+		assert.False(t, f.users[1].IsDeleted())
+	})
+
+	t.Run("the account is deleted and purged after the grace period", func(t *testing.T) {
+		f := newFixture(t)
+		f.assets[1] = []entities.Asset{{UserID: 1, Code: uuid.New()}, {UserID: 1, Code: uuid.New()}}
+		f.assets[2] = []entities.Asset{{UserID: 2, Code: uuid.New()}}
+
+		result, err := f.service.DeleteAccount(ctx, f.user, &request.DeleteAccount{Password: password})
+		assert.NoError(t, err)
+		assert.Equal(t, f.now.Add(30*24*time.Hour), result.PurgeAfter)
+		assert.True(t, f.users[1].IsDeleted())
+		assert.Equal(t, []uint64{1}, f.sessions.invalidated)
+
+		f.now = f.now.Add(29 * 24 * time.Hour)
+		assert.NoError(t, f.service.PurgeDeletedAccounts(ctx))
+		assert.Contains(t, f.users, uint64(1))
+		assert.Len(t, f.assets[1], 2)
+
+		f.now = result.PurgeAfter
+		assert.NoError(t, f.service.PurgeDeletedAccounts(ctx))
+		assert.NotContains(t, f.users, uint64(1))
+		assert.Empty(t, f.assets[1])
+		assert.Len(t, f.assets[2], 1)
+	})
+}
@@ -0,0 +1,177 @@
+package apikeys
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/crypto"
+)
+
+const (
+	apiKeyNotFoundCode      = "API_KEY_NOT_FOUND"
+	apiKeyLimitCode         = "API_KEY_LIMIT_REACHED"
+	invalidAPIKeyExpiryCode = "INVALID_API_KEY_EXPIRY"
+
+	maxKeysPerUser = 20
+	// prefixLength is the part of the key shown in listings, the fixed prefix
+	// plus a few random characters.
+	prefixLength = len(entities.APIKeyPrefix) + 6
+	// lastUsedPrecision limits the writes of keys used by every request of a
+	// script to one per minute.
+	lastUsedPrecision = time.Minute
+)
+
+type apiKeyRepository interface {
+	Create(ctx context.Context, key *entities.APIKey) error
+	GetByCode(ctx context.Context, code uuid.UUID) (*entities.APIKey, error)
+	GetByKeyHash(ctx context.Context, keyHash string) (*entities.APIKey, error)
+	ListByUser(ctx context.Context, userID uint64) ([]entities.APIKey, error)
+	Update(ctx context.Context, key *entities.APIKey) error
+}
+
+type userRepository interface {
+	GetByID(ctx context.Context, id uint64) (*entities.User, error)
+}
+
+type service struct {
+	apiKeyRepository apiKeyRepository
+	userRepository   userRepository
+	logger           log.Logger
+	now              func() time.Time
+}
+
+func NewService(apiKeyRepo apiKeyRepository, userRepo userRepository, logger log.Logger) *service {
+	return &service{
+		apiKeyRepository: apiKeyRepo,
+		userRepository:   userRepo,
+		logger:           logger,
+		now:              time.Now,
+	}
+}
+
+// CreateAPIKey returns the new key of the user. It is not stored, so this is
+// the only time it can be read.
+func (s *service) CreateAPIKey(ctx context.Context, user *entities.User, req *request.CreateAPIKey) (*response.CreatedAPIKey, error) {
+	now := s.now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, errors.New(http.StatusBadRequest, invalidAPIKeyExpiryCode, []string{"expires_at must be in the future"})
+	}
+
+	keys, err := s.apiKeyRepository.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if len(keys) >= maxKeysPerUser {
+		return nil, errors.New(http.StatusConflict, apiKeyLimitCode, []string{"Revoke an API key before creating another one"})
+	}
+
+	secret, err := crypto.GenerateToken()
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	plainKey := entities.APIKeyPrefix + secret
+
+	key := &entities.APIKey{
+		Code:      uuid.New(),
+		UserID:    user.ID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    plainKey[:prefixLength],
+		KeyHash:   crypto.HashToken(plainKey),
+		Scopes:    strings.Join(uniqueScopes(req.Scopes), ","),
+		CreatedAt: now,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.apiKeyRepository.Create(ctx, key); err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	return &response.CreatedAPIKey{APIKey: response.ToAPIKeyResponse(key), Key: plainKey}, nil
+}
+
+// ListAPIKeys returns the keys of the user that were not revoked.
+func (s *service) ListAPIKeys(ctx context.Context, user *entities.User) ([]*response.APIKey, error) {
+	keys, err := s.apiKeyRepository.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	result := make([]*response.APIKey, 0, len(keys))
+	for i := range keys {
+		result = append(result, response.ToAPIKeyResponse(&keys[i]))
+	}
+	return result, nil
+}
+
+func (s *service) RevokeAPIKey(ctx context.Context, user *entities.User, code uuid.UUID) error {
+	key, err := s.apiKeyRepository.GetByCode(ctx, code)
+	if err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if key == nil || key.UserID != user.ID || key.RevokedAt != nil {
+		return errors.New(http.StatusNotFound, apiKeyNotFoundCode, []string{"API key not found"})
+	}
+
+	now := s.now()
+	key.RevokedAt = &now
+	if err := s.apiKeyRepository.Update(ctx, key); err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	return nil
+}
+
+// Authenticate returns the owner of an active key and the scopes granted to
+// it, recording when the key was last used.
+func (s *service) Authenticate(ctx context.Context, plainKey string) (*entities.User, []string, error) {
+	if !strings.HasPrefix(plainKey, entities.APIKeyPrefix) {
+		return nil, nil, invalidAPIKey()
+	}
+	key, err := s.apiKeyRepository.GetByKeyHash(ctx, crypto.HashToken(plainKey))
+	if err != nil {
+		return nil, nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	now := s.now()
+	if key == nil || !key.IsActive(now) {
+		return nil, nil, invalidAPIKey()
+	}
+
+	user, err := s.userRepository.GetByID(ctx, key.UserID)
+	if err != nil {
+		return nil, nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if user == nil {
+		return nil, nil, invalidAPIKey()
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedPrecision {
+		key.LastUsedAt = &now
+		// The request goes ahead, a stale last use is not worth failing it.
+		if err := s.apiKeyRepository.Update(ctx, key); err != nil {
+			s.logger.Warning(ctx, "api_key_last_used", "error recording the use of an API key", log.Field("api_key_code", key.Code), log.Field("error", err))
+		}
+	}
+
+	return user, key.ScopeList(), nil
+}
+
+func invalidAPIKey() error {
+	return errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Invalid API key"})
+}
+
+func uniqueScopes(scopes []string) []string {
+	unique := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !slices.Contains(unique, scope) {
+			unique = append(unique, scope)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,214 @@
+package apikeys
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// memoryAPIKeys keeps the keys by code, like the database would.
+type memoryAPIKeys map[uuid.UUID]entities.APIKey
+
+func (r memoryAPIKeys) Create(ctx context.Context, key *entities.APIKey) error {
+	r[key.Code] = *key
+	return nil
+}
+
+func (r memoryAPIKeys) GetByCode(ctx context.Context, code uuid.UUID) (*entities.APIKey, error) {
+	key, ok := r[code]
+	if !ok {
+		return nil, nil
+	}
+	return &key, nil
+}
+
+func (r memoryAPIKeys) GetByKeyHash(ctx context.Context, keyHash string) (*entities.APIKey, error) {
+	for _, key := range r {
+		if key.KeyHash == keyHash {
+			return &key, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r memoryAPIKeys) ListByUser(ctx context.Context, userID uint64) ([]entities.APIKey, error) {
+	var keys []entities.APIKey
+	for _, key := range r {
+		if key.UserID == userID && key.RevokedAt == nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (r memoryAPIKeys) Update(ctx context.Context, key *entities.APIKey) error {
+	r[key.Code] = *key
+	return nil
+}
+
+type fixture struct {
+	service *service
+	keys    memoryAPIKeys
+	user    *entities.User
+	now     time.Time
+}
+
+func newFixture() *fixture {
+	user := &entities.User{ID: 1, Code: uuid.New()}
+	userRepository := new(mocks.UserRepository)
+	userRepository.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	userRepository.On("GetByID", mock.Anything, mock.Anything).Return(nil, nil)
+
+	f := &fixture{
+		keys: memoryAPIKeys{},
+		user: user,
+		now:  time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC),
+	}
+	f.service = NewService(f.keys, userRepository, new(mocks.Logger))
+	f.service.now = func() time.Time { return f.now }
+	return f
+}
+
+func (f *fixture) create(t *testing.T, req *request.CreateAPIKey) string {
+	t.Helper()
+	created, err := f.service.CreateAPIKey(context.Background(), f.user, req)
+	if err != nil {
+		t.Fatalf("failed to create API key: %v", err)
+	}
+	return created.Key
+}
+
+func assertErrorCode(t *testing.T, err error, httpCode int, code string) {
+	t.Helper()
+	errorResponse, ok := err.(*errors.ErrorResponse)
+	if assert.True(t, ok, "unexpected error %v", err) {
+		assert.Equal(t, httpCode, errorResponse.HttpCode)
+		assert.Equal(t, code, errorResponse.Code)
+	}
+}
+
+func Test_CreateAPIKey(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("the key is shown once and stored hashed", func(t *testing.T) {
+		f := newFixture()
+		created, err := f.service.CreateAPIKey(ctx, f.user, &request.CreateAPIKey{
+			Name:   " Sheets ",
+			Scopes: []string{entities.APIKeyScopePortfolioRead, entities.APIKeyScopePortfolioRead},
+		})
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(created.Key, entities.APIKeyPrefix))
+		assert.True(t, strings.HasPrefix(created.Key, created.Prefix))
+		assert.Equal(t, []string{entities.APIKeyScopePortfolioRead}, created.Scopes)
+
+		stored := f.keys[created.Code]
+		assert.Equal(t, "Sheets", stored.Name)
+		assert.Equal(t, crypto.HashToken(created.Key), stored.KeyHash)
+
+		listed, err := f.service.ListAPIKeys(ctx, f.user)
+		assert.NoError(t, err)
+		assert.Len(t, listed, 1)
+		assert.Equal(t, created.Code, listed[0].Code)
+	})
+
+	t.Run("the expiry must be in the future", func(t *testing.T) {
+		f := newFixture()
+		past := f.now.Add(-time.Hour)
+		_, err := f.service.CreateAPIKey(ctx, f.user, &request.CreateAPIKey{
+			Name:      "Script",
+			Scopes:    []string{entities.APIKeyScopePortfolioRead},
+			ExpiresAt: &past,
+		})
+		assertErrorCode(t, err, http.StatusBadRequest, invalidAPIKeyExpiryCode)
+	})
+
+	t.Run("users have a limit of keys", func(t *testing.T) {
+		f := newFixture()
+		for range maxKeysPerUser {
+			f.create(t, &request.CreateAPIKey{Name: "Script", Scopes: []string{entities.APIKeyScopePortfolioRead}})
+		}
+		_, err := f.service.CreateAPIKey(ctx, f.user, &request.CreateAPIKey{Name: "Script", Scopes: []string{entities.APIKeyScopePortfolioRead}})
+		assertErrorCode(t, err, http.StatusConflict, apiKeyLimitCode)
+	})
+}
+
+func Test_Authenticate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("an active key returns its user and scopes", func(t *testing.T) {
+		f := newFixture()
+		key := f.create(t, &request.CreateAPIKey{
+			Name:   "Sheets",
+			Scopes: []string{entities.APIKeyScopePortfolioRead, entities.APIKeyScopeTransactionsWrite},
+		})
+
+		user, scopes, err := f.service.Authenticate(ctx, key)
+		assert.NoError(t, err)
+		assert.Equal(t, f.user.Code, user.Code)
+		assert.Equal(t, []string{entities.APIKeyScopePortfolioRead, entities.APIKeyScopeTransactionsWrite}, scopes)
+	})
+
+	t.Run("the last use is recorded once per minute", func(t *testing.T) {
+		f := newFixture()
+		key := f.create(t, &request.CreateAPIKey{Name: "Sheets", Scopes: []string{entities.APIKeyScopePortfolioRead}})
+		first := f.now
+
+		_, _, err := f.service.Authenticate(ctx, key)
+		assert.NoError(t, err)
+		f.now = f.now.Add(30 * time.Second)
+		_, _, err = f.service.Authenticate(ctx, key)
+		assert.NoError(t, err)
+		stored, _ := f.keys.GetByKeyHash(ctx, crypto.HashToken(key))
+		assert.Equal(t, first, *stored.LastUsedAt)
+
+		f.now = f.now.Add(time.Minute)
+		_, _, err = f.service.Authenticate(ctx, key)
+		assert.NoError(t, err)
+		stored, _ = f.keys.GetByKeyHash(ctx, crypto.HashToken(key))
+		assert.Equal(t, f.now, *stored.LastUsedAt)
+	})
+
+	t.Run("expired, revoked and unknown keys are refused", func(t *testing.T) {
+		f := newFixture()
+		expiresAt := f.now.Add(time.Hour)
+		expiring := f.create(t, &request.CreateAPIKey{Name: "Expiring", Scopes: []string{entities.APIKeyScopePortfolioRead}, ExpiresAt: &expiresAt})
+		revoked, err := f.service.CreateAPIKey(ctx, f.user, &request.CreateAPIKey{Name: "Revoked", Scopes: []string{entities.APIKeyScopePortfolioRead}})
+		assert.NoError(t, err)
+		assert.NoError(t, f.service.RevokeAPIKey(ctx, f.user, revoked.Code))
+
+		f.now = expiresAt
+		for _, key := range []string{expiring, revoked.Key, entities.APIKeyPrefix + "unknown", "not-an-api-key"} {
+			_, _, err := f.service.Authenticate(ctx, key)
+			assertErrorCode(t, err, http.StatusUnauthorized, errors.StatusUnauthorizedCode)
+		}
+	})
+}
+
+func Test_RevokeAPIKey(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	created, err := f.service.CreateAPIKey(ctx, f.user, &request.CreateAPIKey{Name: "Sheets", Scopes: []string{entities.APIKeyScopePortfolioRead}})
+	assert.NoError(t, err)
+
+	err = f.service.RevokeAPIKey(ctx, &entities.User{ID: 2}, created.Code)
+	assertErrorCode(t, err, http.StatusNotFound, apiKeyNotFoundCode)
+
+	assert.NoError(t, f.service.RevokeAPIKey(ctx, f.user, created.Code))
+	listed, err := f.service.ListAPIKeys(ctx, f.user)
+	assert.NoError(t, err)
+	assert.Empty(t, listed)
+
+	err = f.service.RevokeAPIKey(ctx, f.user, created.Code)
+	assertErrorCode(t, err, http.StatusNotFound, apiKeyNotFoundCode)
+}
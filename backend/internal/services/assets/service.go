@@ -0,0 +1,219 @@
+package assets
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	invalidAssetCode  = "INVALID_ASSET"
+	assetNotFoundCode = "ASSET_NOT_FOUND"
+)
+
+type assetRepository interface {
+	Create(ctx context.Context, asset *entities.Asset) error
+	GetByCode(ctx context.Context, userID uint64, code uuid.UUID) (*entities.Asset, error)
+	ListByUser(ctx context.Context, userID uint64) ([]entities.Asset, error)
+	Update(ctx context.Context, asset *entities.Asset) error
+	Delete(ctx context.Context, asset *entities.Asset) error
+}
+
+type priceRecorder interface {
+	RecordValuation(ctx context.Context, asset *entities.Asset) error
+}
+
+type service struct {
+	assetRepository assetRepository
+	priceRecorder   priceRecorder
+	logger          log.Logger
+}
+
+func NewService(assetRepo assetRepository, priceRecorder priceRecorder, logger log.Logger) *service {
+	return &service{
+		assetRepository: assetRepo,
+		priceRecorder:   priceRecorder,
+		logger:          logger,
+	}
+}
+
+func (s *service) CreateAsset(ctx context.Context, userID uint64, req *request.CreateAsset) (*response.Asset, error) {
+	autoPricing := true
+	if req.AutoPricingEnabled != nil {
+		autoPricing = *req.AutoPricingEnabled
+	}
+
+	newAsset := &entities.Asset{
+		Code:               uuid.New(),
+		UserID:             userID,
+		Name:               strings.TrimSpace(req.Name),
+		Symbol:             strings.ToUpper(strings.TrimSpace(req.Symbol)),
+		Ticker:             req.Ticker,
+		Currency:           strings.ToUpper(strings.TrimSpace(req.Currency)),
+		CategoryID:         req.CategoryID,
+		TotalUnits:         req.TotalUnits,
+		InvestedTotal:      req.InvestedTotal,
+		OpeningUnits:       req.TotalUnits,
+		OpeningCost:        req.InvestedTotal,
+		AutoPricingEnabled: autoPricing,
+		PriceSource:        req.PriceSource,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+	if req.CurrentValue != nil {
+		newAsset.CurrentValue = decimal.NewNullDecimal(*req.CurrentValue)
+	}
+
+	if messages := validateAsset(newAsset); len(messages) > 0 {
+		return nil, errors.New(http.StatusBadRequest, invalidAssetCode, messages)
+	}
+
+	if err := s.assetRepository.Create(ctx, newAsset); err != nil {
+		return nil, errors.New(http.StatusInternalServerError, "CREATE_ASSET_ERROR", []string{"Unable to create asset"})
+	}
+	if !newAsset.AutoPricingEnabled {
+		s.recordValuation(ctx, newAsset)
+	}
+
+	return response.ToAssetResponse(newAsset), nil
+}
+
+func (s *service) GetAsset(ctx context.Context, userID uint64, code uuid.UUID) (*response.Asset, error) {
+	asset, err := s.findAsset(ctx, userID, code)
+	if err != nil {
+		return nil, err
+	}
+	return response.ToAssetResponse(asset), nil
+}
+
+func (s *service) ListAssets(ctx context.Context, userID uint64) ([]*response.Asset, error) {
+	assets, err := s.assetRepository.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	return response.ToAssetsResponse(assets), nil
+}
+
+func (s *service) UpdateAsset(ctx context.Context, userID uint64, code uuid.UUID, req *request.UpdateAsset) (*response.Asset, error) {
+	asset, err := s.findAsset(ctx, userID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		asset.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Symbol != nil {
+		asset.Symbol = strings.ToUpper(strings.TrimSpace(*req.Symbol))
+	}
+	if req.Ticker != nil {
+		asset.Ticker = req.Ticker
+	}
+	if req.CategoryID != nil {
+		asset.CategoryID = *req.CategoryID
+	}
+	valueChanged := false
+	if req.CurrentValue != nil {
+		valueChanged = !asset.CurrentValue.Valid || !asset.CurrentValue.Decimal.Equal(*req.CurrentValue)
+		asset.CurrentValue = decimal.NewNullDecimal(*req.CurrentValue)
+	}
+	if req.AutoPricingEnabled != nil {
+		asset.AutoPricingEnabled = *req.AutoPricingEnabled
+	}
+	if req.PriceSource != nil {
+		asset.PriceSource = req.PriceSource
+	}
+
+	if messages := validateAsset(asset); len(messages) > 0 {
+		return nil, errors.New(http.StatusBadRequest, invalidAssetCode, messages)
+	}
+
+	asset.UpdatedAt = time.Now()
+	if err := s.assetRepository.Update(ctx, asset); err != nil {
+		return nil, errors.New(http.StatusInternalServerError, "UPDATE_ASSET_ERROR", []string{"Unable to update asset"})
+	}
+	if valueChanged && !asset.AutoPricingEnabled {
+		s.recordValuation(ctx, asset)
+	}
+
+	return response.ToAssetResponse(asset), nil
+}
+
+func (s *service) DeleteAsset(ctx context.Context, userID uint64, code uuid.UUID) error {
+	asset, err := s.findAsset(ctx, userID, code)
+	if err != nil {
+		return err
+	}
+
+	if err := s.assetRepository.Delete(ctx, asset); err != nil {
+		return errors.New(http.StatusInternalServerError, "DELETE_ASSET_ERROR", []string{"Unable to delete asset"})
+	}
+	return nil
+}
+
+// recordValuation adds a manual valuation to the price history. The asset is already
+// saved at this point, so a failure is logged instead of failing the request.
+func (s *service) recordValuation(ctx context.Context, asset *entities.Asset) {
+	if err := s.priceRecorder.RecordValuation(ctx, asset); err != nil {
+		s.logger.Error(ctx, "price_history_record", "error recording valuation", log.Field("asset_id", asset.ID), log.Field("error", err))
+	}
+}
+
+func (s *service) findAsset(ctx context.Context, userID uint64, code uuid.UUID) (*entities.Asset, error) {
+	asset, err := s.assetRepository.GetByCode(ctx, userID, code)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if asset == nil {
+		return nil, errors.New(http.StatusNotFound, assetNotFoundCode, []string{"Asset not found"})
+	}
+	return asset, nil
+}
+
+func validateAsset(asset *entities.Asset) []string {
+	var messages []string
+
+	if asset.Name == "" {
+		messages = append(messages, "name is required")
+	}
+	if asset.Symbol == "" {
+		messages = append(messages, "symbol is required")
+	}
+	if asset.Currency == "" || len(asset.Currency) > 3 {
+		messages = append(messages, "currency must have between 1 and 3 characters")
+	}
+	if _, ok := entities.CategoryName(asset.CategoryID); !ok {
+		messages = append(messages, "category_id is not a valid category")
+	}
+	if asset.TotalUnits.IsNegative() {
+		messages = append(messages, "total_units cannot be negative")
+	}
+	if asset.InvestedTotal.IsNegative() {
+		messages = append(messages, "invested_total cannot be negative")
+	}
+	if asset.CurrentValue.Valid && asset.CurrentValue.Decimal.IsNegative() {
+		messages = append(messages, "current_value cannot be negative")
+	}
+
+	if asset.AutoPricingEnabled {
+		if asset.Ticker == nil || strings.TrimSpace(*asset.Ticker) == "" {
+			messages = append(messages, "ticker is required when auto pricing is enabled")
+		}
+		if asset.PriceSource == nil || !entities.IsValidPriceSource(*asset.PriceSource) {
+			messages = append(messages, "price_source must be one of: yahoo, coingecko")
+		}
+	} else if !asset.CurrentValue.Valid {
+		messages = append(messages, "current_value is required when auto pricing is disabled")
+	}
+
+	return messages
+}
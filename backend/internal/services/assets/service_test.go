@@ -0,0 +1,288 @@
+package assets
+
+import (
+	"context"
+	libErrors "errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/pointers"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Create(ctx context.Context, asset *entities.Asset) error {
+	args := m.Called(ctx, asset)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetByCode(ctx context.Context, userID uint64, code uuid.UUID) (*entities.Asset, error) {
+	args := m.Called(ctx, userID, code)
+	if asset, ok := args.Get(0).(*entities.Asset); ok {
+		return asset, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRepository) ListByUser(ctx context.Context, userID uint64) ([]entities.Asset, error) {
+	args := m.Called(ctx, userID)
+	if assets, ok := args.Get(0).([]entities.Asset); ok {
+		return assets, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRepository) Update(ctx context.Context, asset *entities.Asset) error {
+	args := m.Called(ctx, asset)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, asset *entities.Asset) error {
+	args := m.Called(ctx, asset)
+	return args.Error(0)
+}
+
+type MockPriceRecorder struct {
+	mock.Mock
+}
+
+func (m *MockPriceRecorder) RecordValuation(ctx context.Context, asset *entities.Asset) error {
+	args := m.Called(ctx, asset)
+	return args.Error(0)
+}
+
+func Test_CreateAsset(t *testing.T) {
+	ctx := context.Background()
+	userID := uint64(1)
+
+	testCases := []struct {
+		name          string
+		req           *request.CreateAsset
+		expectedError *errors.ErrorResponse
+		mockFunc      func(*MockRepository, *MockPriceRecorder)
+	}{
+		{
+			name: "create auto priced asset",
+			req: &request.CreateAsset{
+				Name:        "NVIDIA",
+				Symbol:      "nvda",
+				Ticker:      pointers.Pointer("NVDA"),
+				Currency:    "usd",
+				CategoryID:  entities.CategoryStock,
+				TotalUnits:  decimal.RequireFromString("1.5"),
+				PriceSource: pointers.Pointer(entities.PriceSourceYahoo),
+			},
+			mockFunc: func(repo *MockRepository, recorder *MockPriceRecorder) {
+				repo.On("Create", mock.Anything, mock.MatchedBy(func(asset *entities.Asset) bool {
+					return asset.UserID == userID && asset.Symbol == "NVDA" && asset.Currency == "USD" && asset.AutoPricingEnabled &&
+						asset.OpeningUnits.Equal(decimal.RequireFromString("1.5"))
+				})).Return(nil)
+			},
+		},
+		{
+			name: "create manual asset",
+			req: &request.CreateAsset{
+				Name:               "CDT Bancolombia",
+				Symbol:             "CDT",
+				Currency:           "COP",
+				CategoryID:         entities.CategoryFixedIncome,
+				TotalUnits:         decimal.NewFromInt(1),
+				CurrentValue:       pointers.Pointer(decimal.NewFromInt(10000000)),
+				AutoPricingEnabled: pointers.Pointer(false),
+			},
+			mockFunc: func(repo *MockRepository, recorder *MockPriceRecorder) {
+				repo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Asset")).Return(nil)
+				recorder.On("RecordValuation", mock.Anything, mock.AnythingOfType("*entities.Asset")).Return(nil)
+			},
+		},
+		{
+			name: "manual asset without current value",
+			req: &request.CreateAsset{
+				Name:               "CDT",
+				Symbol:             "CDT",
+				Currency:           "COP",
+				CategoryID:         entities.CategoryFixedIncome,
+				AutoPricingEnabled: pointers.Pointer(false),
+			},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusBadRequest, Code: invalidAssetCode},
+			mockFunc:      func(repo *MockRepository, recorder *MockPriceRecorder) {},
+		},
+		{
+			name: "invalid category and negative units",
+			req: &request.CreateAsset{
+				Name:        "Bitcoin",
+				Symbol:      "BTC",
+				Ticker:      pointers.Pointer("bitcoin"),
+				Currency:    "USD",
+				CategoryID:  99,
+				TotalUnits:  decimal.NewFromInt(-1),
+				PriceSource: pointers.Pointer(entities.PriceSourceCoinGecko),
+			},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusBadRequest, Code: invalidAssetCode},
+			mockFunc:      func(repo *MockRepository, recorder *MockPriceRecorder) {},
+		},
+		{
+			name: "repository error",
+			req: &request.CreateAsset{
+				Name:        "Bitcoin",
+				Symbol:      "BTC",
+				Ticker:      pointers.Pointer("bitcoin"),
+				Currency:    "USD",
+				CategoryID:  entities.CategoryCrypto,
+				PriceSource: pointers.Pointer(entities.PriceSourceCoinGecko),
+			},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusInternalServerError, Code: "CREATE_ASSET_ERROR"},
+			mockFunc: func(repo *MockRepository, recorder *MockPriceRecorder) {
+				repo.On("Create", mock.Anything, mock.Anything).Return(libErrors.New("db error"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(MockRepository)
+			recorder := new(MockPriceRecorder)
+			tc.mockFunc(repo, recorder)
+			logger := new(mocks.Logger)
+			logger.On("Error", mock.Anything, "price_history_record", mock.Anything, mock.Anything).Return().Maybe()
+			svc := NewService(repo, recorder, logger)
+
+			resp, err := svc.CreateAsset(ctx, userID, tc.req)
+			if tc.expectedError != nil {
+				assert.Error(t, err)
+				errorResponse, ok := err.(*errors.ErrorResponse)
+				assert.True(t, ok)
+				assert.Equal(t, tc.expectedError.HttpCode, errorResponse.ErrorHTTPCode())
+				assert.Equal(t, tc.expectedError.Code, errorResponse.ErrorCode())
+			} else {
+				assert.NoError(t, err)
+				assert.NotEqual(t, uuid.Nil, resp.Code)
+				assert.True(t, tc.req.TotalUnits.Equal(resp.TotalUnits))
+			}
+			repo.AssertExpectations(t)
+			recorder.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_UpdateAsset(t *testing.T) {
+	ctx := context.Background()
+	userID := uint64(1)
+	code := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+
+	existing := func() *entities.Asset {
+		return &entities.Asset{
+			Code:               code,
+			UserID:             userID,
+			Name:               "NVIDIA",
+			Symbol:             "NVDA",
+			Ticker:             pointers.Pointer("NVDA"),
+			Currency:           "USD",
+			CategoryID:         entities.CategoryStock,
+			AutoPricingEnabled: true,
+			PriceSource:        pointers.Pointer(entities.PriceSourceYahoo),
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		req           *request.UpdateAsset
+		expectedError *errors.ErrorResponse
+		mockFunc      func(*MockRepository, *MockPriceRecorder)
+	}{
+		{
+			name: "switch to manual valuation",
+			req: &request.UpdateAsset{
+				AutoPricingEnabled: pointers.Pointer(false),
+				CurrentValue:       pointers.Pointer(decimal.NewFromInt(500)),
+			},
+			mockFunc: func(repo *MockRepository, recorder *MockPriceRecorder) {
+				repo.On("GetByCode", mock.Anything, userID, code).Return(existing(), nil)
+				repo.On("Update", mock.Anything, mock.MatchedBy(func(asset *entities.Asset) bool {
+					return !asset.AutoPricingEnabled && asset.CurrentValue.Decimal.Equal(decimal.NewFromInt(500))
+				})).Return(nil)
+				recorder.On("RecordValuation", mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name: "valuation history errors do not fail the update",
+			req: &request.UpdateAsset{
+				AutoPricingEnabled: pointers.Pointer(false),
+				CurrentValue:       pointers.Pointer(decimal.NewFromInt(500)),
+			},
+			mockFunc: func(repo *MockRepository, recorder *MockPriceRecorder) {
+				repo.On("GetByCode", mock.Anything, userID, code).Return(existing(), nil)
+				repo.On("Update", mock.Anything, mock.Anything).Return(nil)
+				recorder.On("RecordValuation", mock.Anything, mock.Anything).Return(libErrors.New("db error"))
+			},
+		},
+		{
+			name: "switch to manual valuation without current value",
+			req: &request.UpdateAsset{
+				AutoPricingEnabled: pointers.Pointer(false),
+			},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusBadRequest, Code: invalidAssetCode},
+			mockFunc: func(repo *MockRepository, recorder *MockPriceRecorder) {
+				repo.On("GetByCode", mock.Anything, userID, code).Return(existing(), nil)
+			},
+		},
+		{
+			name:          "asset of another user is not found",
+			req:           &request.UpdateAsset{Name: pointers.Pointer("Other")},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusNotFound, Code: assetNotFoundCode},
+			mockFunc: func(repo *MockRepository, recorder *MockPriceRecorder) {
+				repo.On("GetByCode", mock.Anything, userID, code).Return(nil, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(MockRepository)
+			recorder := new(MockPriceRecorder)
+			tc.mockFunc(repo, recorder)
+			logger := new(mocks.Logger)
+			logger.On("Error", mock.Anything, "price_history_record", mock.Anything, mock.Anything).Return().Maybe()
+			svc := NewService(repo, recorder, logger)
+
+			_, err := svc.UpdateAsset(ctx, userID, code, tc.req)
+			if tc.expectedError != nil {
+				assert.Error(t, err)
+				errorResponse, ok := err.(*errors.ErrorResponse)
+				assert.True(t, ok)
+				assert.Equal(t, tc.expectedError.HttpCode, errorResponse.ErrorHTTPCode())
+				assert.Equal(t, tc.expectedError.Code, errorResponse.ErrorCode())
+			} else {
+				assert.NoError(t, err)
+			}
+			repo.AssertExpectations(t)
+			recorder.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_DeleteAsset(t *testing.T) {
+	ctx := context.Background()
+	userID := uint64(1)
+	code := uuid.New()
+
+	repo := new(MockRepository)
+	asset := &entities.Asset{Code: code, UserID: userID}
+	repo.On("GetByCode", mock.Anything, userID, code).Return(asset, nil)
+	repo.On("Delete", mock.Anything, asset).Return(nil)
+
+	err := NewService(repo, new(MockPriceRecorder), new(mocks.Logger)).DeleteAsset(ctx, userID, code)
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
@@ -2,130 +2,419 @@ package auth
 
 import (
 	"context"
-	"fmt"
+	libErrors "errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	utilCache "github.com/juanMaAV92/go-utils/cache"
 	"github.com/juanMaAV92/go-utils/errors"
-	"github.com/juanMaAV92/go-utils/jwt"
 	"github.com/juanMaAV92/go-utils/log"
 	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
 	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
 	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
 	"github.com/juanMaAV92/zenith-financial/backend/utils/crypto"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/lockout"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/tokens"
 )
 
 type userRepository interface {
 	GetByEmail(ctx context.Context, email string) (*entities.User, error)
+	GetByCode(ctx context.Context, code uuid.UUID) (*entities.User, error)
+	Update(ctx context.Context, user *entities.User) error
 }
 
-type cache interface {
-	Set(ctx context.Context, key string, value interface{}, opts ...utilCache.SetOption) error
-	Delete(ctx context.Context, key string) error
+type sessionRepository interface {
+	Create(ctx context.Context, session *entities.Session) error
+	GetByCode(ctx context.Context, code uuid.UUID) (*entities.Session, error)
+	ListActiveByUser(ctx context.Context, userID uint64, now time.Time) ([]entities.Session, error)
+	Update(ctx context.Context, session *entities.Session) error
+	Rotate(ctx context.Context, session *entities.Session, previous uuid.UUID) (bool, error)
 }
 
+type tokenIssuer interface {
+	GenerateAccessToken(userCode, sessionID uuid.UUID, version int) (string, error)
+	GenerateRefreshToken(userCode, sessionID uuid.UUID, version int) (string, uuid.UUID, error)
+	ParseAccessToken(token string) (*tokens.Claims, error)
+	ParseRefreshToken(token string) (*tokens.Claims, error)
+	GenerateMFAToken(userCode uuid.UUID) (string, error)
+	ParseMFAToken(token string) (*tokens.Claims, error)
+	AccessTTL() time.Duration
+	RefreshTTL() time.Duration
+}
+
+type tokenDenylist interface {
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error
+	IsRevoked(ctx context.Context, tokenID, sessionID string) (bool, error)
+}
+
+type mfaVerifier interface {
+	Verify(ctx context.Context, user *entities.User, code string) error
+}
+
+type loginGuard interface {
+	Check(ctx context.Context, email, ip string) (time.Duration, bool, error)
+	RecordFailure(ctx context.Context, email, ip string) error
+	Reset(ctx context.Context, email string) error
+}
+
+const (
+	bearerPrefix = "Bearer "
+
+	emailNotVerifiedCode = "EMAIL_NOT_VERIFIED"
+	accountLockedCode    = "ACCOUNT_LOCKED"
+	tooManyAttemptsCode  = "TOO_MANY_ATTEMPTS"
+)
+
 type service struct {
-	userRepository userRepository
-	cache          cache
-	logger         log.Logger
+	userRepository       userRepository
+	sessionRepository    sessionRepository
+	tokens               tokenIssuer
+	denylist             tokenDenylist
+	mfa                  mfaVerifier
+	guard                loginGuard
+	logger               log.Logger
+	requireVerifiedEmail bool
+	now                  func() time.Time
 }
 
-func NewService(userRepo userRepository, cache cache, logger log.Logger) *service {
+// NewService builds the auth service. With requireVerifiedEmail users cannot
+// log in until they verify their email.
+func NewService(userRepo userRepository, sessionRepo sessionRepository, tokens tokenIssuer, denylist tokenDenylist, mfa mfaVerifier,
+	guard loginGuard, logger log.Logger, requireVerifiedEmail bool) *service {
 	return &service{
-		userRepository: userRepo,
-		cache:          cache,
-		logger:         logger,
+		userRepository:       userRepo,
+		sessionRepository:    sessionRepo,
+		tokens:               tokens,
+		denylist:             denylist,
+		mfa:                  mfa,
+		guard:                guard,
+		logger:               logger,
+		requireVerifiedEmail: requireVerifiedEmail,
+		now:                  time.Now,
 	}
 }
 
+// Login starts a new session for the device. Sessions on other devices are not
+// affected. Users with two-factor authentication get a short-lived MFA token
+// instead, to be exchanged with a code in LoginMFA. Failed attempts are
+// throttled per email and per IP.
 func (s *service) Login(ctx context.Context, req *request.UserLogin) (*response.UserLogin, error) {
+	if err := s.checkAttempts(ctx, req.Email, req.IPAddress); err != nil {
+		return nil, err
+	}
+
 	var userFound *entities.User
 	userFound, err := s.userRepository.GetByEmail(ctx, req.Email)
 	if userFound == nil || err != nil {
+		s.recordFailure(ctx, req.Email, req.IPAddress)
 		return nil, errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Invalid email or password"})
 	}
 
-	isValidPassword := crypto.ValidatePassword(req.Password, userFound.PasswordSalt, userFound.PasswordHash)
+	isValidPassword, needsRehash := crypto.VerifyPassword(req.Password, userFound.PasswordSalt, userFound.PasswordHash)
 	if !isValidPassword {
+		s.recordFailure(ctx, req.Email, req.IPAddress)
 		return nil, errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Invalid email or password"})
 	}
+	if needsRehash {
+		s.rehashPassword(ctx, userFound, req.Password)
+	}
+	if s.requireVerifiedEmail && userFound.EmailVerifiedAt == nil {
+		return nil, errors.New(http.StatusForbidden, emailNotVerifiedCode, []string{"Email address has not been verified"})
+	}
+
+	if userFound.MFAEnabled() {
+		return s.mfaChallenge(userFound)
+	}
+
+	s.resetAttempts(ctx, userFound)
+	return s.startSession(ctx, userFound, req.DeviceLabel, req.UserAgent, req.IPAddress)
+}
+
+// LoginExternal starts a session for a user authenticated by an OpenID Connect
+// provider. As in Login, users with two-factor authentication get an MFA token
+// to complete the login with instead.
+func (s *service) LoginExternal(ctx context.Context, user *entities.User, deviceLabel, userAgent, ipAddress string) (*response.UserLogin, error) {
+	if user.MFAEnabled() {
+		return s.mfaChallenge(user)
+	}
+	return s.startSession(ctx, user, deviceLabel, userAgent, ipAddress)
+}
+
+func (s *service) mfaChallenge(user *entities.User) (*response.UserLogin, error) {
+	mfaToken, err := s.tokens.GenerateMFAToken(user.Code)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Failed to generate MFA token"})
+	}
+	return &response.UserLogin{MFARequired: true, MFAToken: mfaToken}, nil
+}
+
+// LoginMFA completes the login of a user with two-factor authentication. The
+// MFA token is spent on success so it cannot start a second session.
+func (s *service) LoginMFA(ctx context.Context, req *request.MFALogin) (*response.UserLogin, error) {
+	claims, err := s.tokens.ParseMFAToken(req.MFAToken)
+	if err != nil {
+		return nil, errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Invalid MFA token"})
+	}
+	revoked, err := s.denylist.IsRevoked(ctx, claims.ID, "")
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if revoked {
+		return nil, errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Invalid MFA token"})
+	}
+
+	userCode, err := uuid.Parse(claims.UserCode)
+	if err != nil {
+		return nil, errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Invalid MFA token"})
+	}
+	user, err := s.userRepository.GetByCode(ctx, userCode)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if user == nil || !user.MFAEnabled() {
+		return nil, errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Invalid MFA token"})
+	}
+
+	if err := s.checkAttempts(ctx, user.Email, req.IPAddress); err != nil {
+		return nil, err
+	}
+	if err := s.mfa.Verify(ctx, user, req.Code); err != nil {
+		var errorResponse *errors.ErrorResponse
+		if libErrors.As(err, &errorResponse) && errorResponse.ErrorHTTPCode() == http.StatusUnauthorized {
+			s.recordFailure(ctx, user.Email, req.IPAddress)
+		}
+		return nil, err
+	}
+
+	if claims.ExpiresAt != nil {
+		if err := s.denylist.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			s.logger.Error(ctx, "login_mfa_token_revoke", "error denylisting MFA token", log.Field("user_code", user.Code), log.Field("error", err))
+		}
+	}
+
+	s.resetAttempts(ctx, user)
+	return s.startSession(ctx, user, req.DeviceLabel, req.UserAgent, req.IPAddress)
+}
+
+// rehashPassword moves the password of the user to the current hash format,
+// while the plain password is at hand. Failures leave the old hash, which
+// still works, and are only logged.
+func (s *service) rehashPassword(ctx context.Context, user *entities.User, password string) {
+	hashedPassword, err := crypto.HashPassword(password)
+	if err != nil {
+		s.logger.Error(ctx, "login_password_rehash", "error hashing password", log.Field("user_code", user.Code), log.Field("error", err))
+		return
+	}
+
+	previousHash, previousSalt := user.PasswordHash, user.PasswordSalt
+	user.PasswordHash = hashedPassword
+	user.PasswordSalt = ""
+	if err := s.userRepository.Update(ctx, user); err != nil {
+		user.PasswordHash, user.PasswordSalt = previousHash, previousSalt
+		s.logger.Error(ctx, "login_password_rehash", "error saving rehashed password", log.Field("user_code", user.Code), log.Field("error", err))
+	}
+}
 
-	accessToken, err := jwt.GenerateAccessToken(userFound.Code)
+// checkAttempts rejects logins of an email or IP that is locked or has to wait
+// after its last failures.
+func (s *service) checkAttempts(ctx context.Context, email, ip string) error {
+	retryAfter, locked, err := s.guard.Check(ctx, email, ip)
+	if err != nil {
+		s.logger.Error(ctx, "login_attempts_check", "error checking failed logins", log.Field("error", err))
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if retryAfter <= 0 {
+		return nil
+	}
+	if locked {
+		return &lockout.RetryError{
+			RetryAfter: retryAfter,
+			Err:        errors.New(http.StatusTooManyRequests, accountLockedCode, []string{"Too many failed login attempts, the account is temporarily locked"}),
+		}
+	}
+	return &lockout.RetryError{
+		RetryAfter: retryAfter,
+		Err:        errors.New(http.StatusTooManyRequests, tooManyAttemptsCode, []string{"Too many failed login attempts, try again later"}),
+	}
+}
+
+func (s *service) recordFailure(ctx context.Context, email, ip string) {
+	if err := s.guard.RecordFailure(ctx, email, ip); err != nil {
+		s.logger.Error(ctx, "login_failure_record", "error recording failed login", log.Field("error", err))
+	}
+}
+
+func (s *service) resetAttempts(ctx context.Context, user *entities.User) {
+	if err := s.guard.Reset(ctx, user.Email); err != nil {
+		s.logger.Error(ctx, "login_attempts_reset", "error resetting failed logins", log.Field("user_code", user.Code), log.Field("error", err))
+	}
+}
+
+// startSession creates the session of a login and issues its tokens.
+func (s *service) startSession(ctx context.Context, user *entities.User, deviceLabel, userAgent, ipAddress string) (*response.UserLogin, error) {
+	now := s.now()
+	session := &entities.Session{
+		Code:        uuid.New(),
+		UserID:      user.ID,
+		DeviceLabel: truncate(deviceLabel, maxDeviceLabelLength),
+		UserAgent:   userAgent,
+		IPAddress:   ipAddress,
+		CreatedAt:   now,
+		LastUsedAt:  now,
+		ExpiresAt:   now.Add(s.tokens.RefreshTTL()),
+	}
+
+	accessToken, err := s.tokens.GenerateAccessToken(user.Code, session.Code, user.TokenVersion)
 	if err != nil {
 		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Failed to generate access token"})
 	}
 
-	refreshToken, err := jwt.GenerateRefreshToken(userFound.Code)
+	refreshToken, tokenID, err := s.tokens.GenerateRefreshToken(user.Code, session.Code, user.TokenVersion)
 	if err != nil {
 		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Failed to generate refresh token"})
 	}
 
-	key := fmt.Sprintf("user_refresh_token:%s", userFound.Code)
-	if err := s.cache.Set(ctx, key, refreshToken, utilCache.WithTTL(7*24*time.Hour)); err != nil {
-		s.logger.Error(ctx, "login_cache_set", "error setting cache", log.Field("user_code", userFound.Code), log.Field("error", err))
+	session.RefreshTokenID = tokenID
+	if err := s.sessionRepository.Create(ctx, session); err != nil {
+		s.logger.Error(ctx, "login_session_create", "error creating session", log.Field("user_code", user.Code), log.Field("error", err))
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Failed to create session"})
 	}
 
 	return &response.UserLogin{
-		User: response.ToUserResponse(userFound),
+		User: response.ToUserResponse(user),
 		TokensResponse: &response.TokensResponse{
 			AccessToken:  accessToken,
 			RefreshToken: refreshToken,
 		},
 	}, nil
-
 }
 
+// Logout denylists the access token and ends the session it belongs to.
 func (s *service) Logout(ctx context.Context, authHeader string) error {
 
-	claims, _, err := jwt.ParseClaims(authHeader)
+	claims, err := s.tokens.ParseAccessToken(strings.TrimPrefix(authHeader, bearerPrefix))
 	if err != nil {
 		return errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Invalid token"})
 	}
 
-	key := fmt.Sprintf("user_refresh_token:%s", claims["user_code"])
-	if err := s.cache.Delete(ctx, key); err != nil {
-		s.logger.Error(ctx, "logout_cache_delete", "error deleting cache", log.Field("user_code", claims["user_code"]), log.Field("error", err))
+	if claims.ExpiresAt != nil {
+		if err := s.denylist.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			s.logger.Error(ctx, "logout_token_revoke", "error denylisting access token", log.Field("user_code", claims.UserCode), log.Field("error", err))
+		}
+	}
+
+	sessionCode, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return nil
+	}
+
+	if err := s.revokeSession(ctx, sessionCode); err != nil {
+		s.logger.Error(ctx, "logout_session_revoke", "error revoking session", log.Field("user_code", claims.UserCode), log.Field("error", err))
 	}
 
 	return nil
 }
 
-func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*response.TokensResponse, error) {
-	claims, isValid, err := jwt.ParseClaims(refreshToken)
+// LogoutSession ends the session of a refresh token. Browsers hold the refresh
+// cookie longer than the access one, so logout falls back to it once the access
+// token is gone.
+func (s *service) LogoutSession(ctx context.Context, refreshToken string) error {
+	claims, err := s.tokens.ParseRefreshToken(refreshToken)
 	if err != nil {
-		return nil, errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Invalid refresh token"})
+		return errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Invalid refresh token"})
+	}
+	sessionCode, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Invalid refresh token"})
 	}
 
-	userCode := claims["user_code"].(string)
+	if err := s.revokeSession(ctx, sessionCode); err != nil {
+		s.logger.Error(ctx, "logout_session_revoke", "error revoking session", log.Field("user_code", claims.UserCode), log.Field("error", err))
+	}
 
-	if claims["type"] != "refresh" || !isValid {
-		if err := s.cache.Delete(ctx, fmt.Sprintf("user_refresh_token:%s", userCode)); err != nil {
-			s.logger.Error(ctx, "refresh_token_cache_delete", "error deleting cache", log.Field("user_code", userCode), log.Field("error", err))
+	return nil
+}
+
+// RefreshToken rotates the refresh token of a session. Presenting a token that
+// was already rotated means it leaked, so the whole session is revoked.
+func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*response.TokensResponse, error) {
+	claims, err := s.tokens.ParseRefreshToken(refreshToken)
+	if libErrors.Is(err, tokens.ErrInvalidTokenType) {
+		if sessionCode, err := uuid.Parse(claims.SessionID); err == nil {
+			if err := s.revokeSession(ctx, sessionCode); err != nil {
+				s.logger.Error(ctx, "refresh_token_session_revoke", "error revoking session", log.Field("user_code", claims.UserCode), log.Field("error", err))
+			}
 		}
-		s.logger.Error(ctx, "refresh_token_invalid_type", "expected refresh token type", log.Field("type", claims["type"]))
+		s.logger.Error(ctx, "refresh_token_invalid_type", "expected refresh token type", log.Field("type", claims.Type))
 		return nil, errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Invalid refresh token type"})
 	}
+	if err != nil {
+		return nil, errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Invalid refresh token"})
+	}
 
-	user, err := uuid.Parse(userCode)
+	userCode, err := uuid.Parse(claims.UserCode)
 	if err != nil {
 		return nil, errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Invalid user code in refresh token"})
 	}
+	sessionCode, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return nil, errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Invalid refresh token"})
+	}
+
+	session, err := s.sessionRepository.GetByCode(ctx, sessionCode)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	now := s.now()
+	if session == nil || !session.IsActive(now) {
+		return nil, errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Session has been revoked"})
+	}
+	if session.RefreshTokenID.String() != claims.ID {
+		s.logger.Error(ctx, "refresh_token_reuse", "rotated refresh token presented again, revoking its session",
+			log.Field("user_code", claims.UserCode), log.Field("session_code", session.Code), log.Field("token_id", claims.ID))
+		if err := s.endSession(ctx, session, now); err != nil {
+			s.logger.Error(ctx, "refresh_token_session_revoke", "error revoking session", log.Field("user_code", claims.UserCode), log.Field("error", err))
+		}
+		return nil, errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Session has been revoked"})
+	}
 
-	newAccessToken, err := jwt.GenerateAccessToken(user)
+	user, err := s.userRepository.GetByCode(ctx, userCode)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if user == nil || claims.TokenVersion < user.TokenVersion {
+		return nil, errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Session has been revoked"})
+	}
+
+	newAccessToken, err := s.tokens.GenerateAccessToken(user.Code, session.Code, user.TokenVersion)
 	if err != nil {
 		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Failed to generate new access token"})
 	}
 
-	newRefreshToken, err := jwt.GenerateRefreshToken(user)
+	newRefreshToken, tokenID, err := s.tokens.GenerateRefreshToken(user.Code, session.Code, user.TokenVersion)
 	if err != nil {
 		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Failed to generate new refresh token"})
 	}
 
-	key := fmt.Sprintf("user_refresh_token:%s", userCode)
-	if err := s.cache.Set(ctx, key, newRefreshToken, utilCache.WithTTL(7*24*time.Hour)); err != nil {
-		s.logger.Error(ctx, "refresh_token_cache_set", "error setting cache", log.Field("user_code", userCode), log.Field("error", err))
+	previousTokenID := session.RefreshTokenID
+	session.RefreshTokenID = tokenID
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(s.tokens.RefreshTTL())
+	rotated, err := s.sessionRepository.Rotate(ctx, session, previousTokenID)
+	if err != nil {
+		s.logger.Error(ctx, "refresh_token_session_update", "error updating session", log.Field("user_code", claims.UserCode), log.Field("error", err))
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Failed to generate new refresh token"})
+	}
+	if !rotated {
+		// A concurrent request presented the same token and rotated it first.
+		s.logger.Error(ctx, "refresh_token_reuse", "refresh token rotated concurrently, revoking its session",
+			log.Field("user_code", claims.UserCode), log.Field("session_code", session.Code), log.Field("token_id", claims.ID))
+		if err := s.revokeSession(ctx, session.Code); err != nil {
+			s.logger.Error(ctx, "refresh_token_session_revoke", "error revoking session", log.Field("user_code", claims.UserCode), log.Field("error", err))
+		}
+		return nil, errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Session has been revoked"})
 	}
 
 	return &response.TokensResponse{
@@ -3,8 +3,8 @@ package auth
 import (
 	"context"
 	libErrors "errors"
-	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,6 +16,8 @@ import (
 	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
 	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
 	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/lockout"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/tokens"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -28,6 +30,17 @@ var jwtConfig = jwtUtils.JwtConfig{
 	SigningMethod:   jwt.SigningMethodHS256,
 }
 
+// newIssuer signs the tokens with the shared secret of jwtConfig, so the go-utils
+// jwt package can read them too.
+func newIssuer(t *testing.T) *tokens.Issuer {
+	t.Helper()
+	keys, err := tokens.NewKeySet("", tokens.NewSecretKey(jwtConfig.SecretKey, jwtConfig.SigningMethod))
+	if err != nil {
+		t.Fatalf("failed to build key set: %v", err)
+	}
+	return tokens.NewIssuer(&jwtConfig, keys)
+}
+
 func Test_Login(t *testing.T) {
 	ctx := context.Background()
 	jwtUtils.InitJWTConfig(&jwtConfig)
@@ -37,11 +50,14 @@ func Test_Login(t *testing.T) {
 		req              *request.UserLogin
 		expectedResponse *response.UserLogin
 		expectedError    *errors.ErrorResponse
-		mockFunc         func(*mocks.UserRepository, *mocks.Cache, *mocks.Logger)
+		mockFunc         func(*mocks.UserRepository, *mocks.SessionRepository, *mocks.Logger)
 	}{
 		{
 			name: "valid login",
-			req:  &request.UserLogin{Email: "test@example.com", Password: "12345677"},
+			req: &request.UserLogin{
+				Email: "test@example.com", Password: "12345677",
+				DeviceLabel: "Laptop", UserAgent: "Firefox", IPAddress: "10.0.0.1",
+			},
 			expectedResponse: &response.UserLogin{
 				User: &response.User{
 					Code:      uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"),
@@ -55,7 +71,7 @@ func Test_Login(t *testing.T) {
 					RefreshToken: "refresh_token",
 				},
 			},
-			mockFunc: func(repo *mocks.UserRepository, cache *mocks.Cache, logger *mocks.Logger) {
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
 				repo.On("GetByEmail", mock.Anything, "test@example.com").Return(
 					&entities.User{
 						ID:           1,
@@ -68,7 +84,14 @@ func Test_Login(t *testing.T) {
 						CreatedAt:    time.Now(),
 						UpdatedAt:    time.Now(),
 					}, nil)
-				cache.On("Set", mock.Anything, "user_refresh_token:123e4567-e89b-12d3-a456-426614174000", mock.Anything, mock.Anything).Return(nil)
+				// The legacy bcrypt hash is replaced on login.
+				repo.On("Update", mock.Anything, mock.MatchedBy(func(user *entities.User) bool {
+					return strings.HasPrefix(user.PasswordHash, "$argon2id$") && user.PasswordSalt == ""
+				})).Return(nil)
+				sessions.On("Create", mock.Anything, mock.MatchedBy(func(session *entities.Session) bool {
+					return session.UserID == 1 && session.DeviceLabel == "Laptop" && session.UserAgent == "Firefox" &&
+						session.IPAddress == "10.0.0.1" && session.RefreshTokenID != uuid.Nil
+				})).Return(nil)
 			},
 		},
 		{
@@ -79,7 +102,7 @@ func Test_Login(t *testing.T) {
 				Code:     errors.StatusUnauthorizedCode,
 				Messages: []string{"Invalid email or password"},
 			},
-			mockFunc: func(repo *mocks.UserRepository, cache *mocks.Cache, logger *mocks.Logger) {
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
 				repo.On("GetByEmail", mock.Anything, "test1@example.com").Return(nilUser, nil)
 			},
 		},
@@ -91,7 +114,7 @@ func Test_Login(t *testing.T) {
 				Code:     errors.StatusUnauthorizedCode,
 				Messages: []string{"Invalid email or password"},
 			},
-			mockFunc: func(repo *mocks.UserRepository, cache *mocks.Cache, logger *mocks.Logger) {
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
 				repo.On("GetByEmail", mock.Anything, "test2@example.com").Return(
 					&entities.User{
 						ID:           1,
@@ -107,22 +130,14 @@ func Test_Login(t *testing.T) {
 			},
 		},
 		{
-			name: "valid login - cache error",
+			name: "error creating the session",
 			req:  &request.UserLogin{Email: "test@example.com", Password: "12345677"},
-			expectedResponse: &response.UserLogin{
-				User: &response.User{
-					Code:      uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"),
-					Email:     "test@example.com",
-					UserName:  "testuser",
-					Currency:  "USD",
-					CreatedAt: time.Now(),
-				},
-				TokensResponse: &response.TokensResponse{
-					AccessToken:  "access_token",
-					RefreshToken: "refresh_token",
-				},
+			expectedError: &errors.ErrorResponse{
+				HttpCode: http.StatusInternalServerError,
+				Code:     errors.StatusInternalServerErrorCode,
+				Messages: []string{"Failed to create session"},
 			},
-			mockFunc: func(repo *mocks.UserRepository, cache *mocks.Cache, logger *mocks.Logger) {
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
 				repo.On("GetByEmail", mock.Anything, "test@example.com").Return(
 					&entities.User{
 						ID:           1,
@@ -135,8 +150,9 @@ func Test_Login(t *testing.T) {
 						CreatedAt:    time.Now(),
 						UpdatedAt:    time.Now(),
 					}, nil)
-				cache.On("Set", mock.Anything, "user_refresh_token:123e4567-e89b-12d3-a456-426614174000", mock.Anything, mock.Anything).Return(libErrors.New("cache error"))
-				logger.On("Error", mock.Anything, "login_cache_set", "error setting cache", mock.Anything, mock.Anything).Return()
+				repo.On("Update", mock.Anything, mock.Anything).Return(nil)
+				sessions.On("Create", mock.Anything, mock.Anything).Return(libErrors.New("database error"))
+				logger.On("Error", mock.Anything, "login_session_create", "error creating session", mock.Anything, mock.Anything).Return()
 			},
 		},
 	}
@@ -144,11 +160,11 @@ func Test_Login(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			userRepository := new(mocks.UserRepository)
-			cache := new(mocks.Cache)
+			sessionRepository := new(mocks.SessionRepository)
 			logger := new(mocks.Logger)
-			service := NewService(userRepository, cache, logger)
+			service := NewService(userRepository, sessionRepository, newIssuer(t), newMemoryDenylist(), nil, newMemoryGuard(0), logger, false)
 
-			tc.mockFunc(userRepository, cache, logger)
+			tc.mockFunc(userRepository, sessionRepository, logger)
 
 			resp, err := service.Login(ctx, tc.req)
 			if tc.expectedError != nil {
@@ -166,7 +182,7 @@ func Test_Login(t *testing.T) {
 				assert.NotEqual(t, "", resp.TokensResponse.AccessToken)
 				assert.NotEqual(t, "", resp.TokensResponse.RefreshToken)
 			}
-			cache.AssertExpectations(t)
+			sessionRepository.AssertExpectations(t)
 			userRepository.AssertExpectations(t)
 			logger.AssertExpectations(t)
 
@@ -178,24 +194,47 @@ func Test_Login(t *testing.T) {
 func Test_Logout(t *testing.T) {
 	ctx := context.Background()
 	userCode := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	sessionCode := uuid.New()
 
 	jwtUtils.InitJWTConfig(&jwtConfig)
-	token, err := jwtUtils.GenerateAccessToken(userCode)
+	token, err := newIssuer(t).GenerateAccessToken(userCode, sessionCode, 0)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	claims, _, err := jwtUtils.ParseClaims(token)
+	if err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	tokenID := claims["jti"].(string)
+	legacyToken, err := jwtUtils.GenerateAccessToken(userCode)
 	if err != nil {
 		t.Fatalf("failed to generate token: %v", err)
 	}
 
 	testCases := []struct {
-		name          string
-		token         string
-		mockFunc      func(*mocks.UserRepository, *mocks.Cache, *mocks.Logger)
-		expectedError *errors.ErrorResponse
+		name           string
+		token          string
+		mockFunc       func(*mocks.UserRepository, *mocks.SessionRepository, *mocks.Logger)
+		expectedError  *errors.ErrorResponse
+		tokenRevoked   bool
+		sessionRevoked bool
 	}{
 		{
-			name:  "valid logout",
-			token: token,
-			mockFunc: func(repo *mocks.UserRepository, cache *mocks.Cache, logger *mocks.Logger) {
-				cache.On("Delete", mock.Anything, "user_refresh_token:"+userCode.String()).Return(nil)
+			name:           "valid logout ends the current session",
+			token:          token,
+			tokenRevoked:   true,
+			sessionRevoked: true,
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
+				sessions.On("GetByCode", mock.Anything, sessionCode).Return(&entities.Session{Code: sessionCode, UserID: 1}, nil)
+				sessions.On("Update", mock.Anything, mock.MatchedBy(func(session *entities.Session) bool {
+					return session.Code == sessionCode && session.RevokedAt != nil
+				})).Return(nil)
+			},
+		},
+		{
+			name:  "token without session",
+			token: legacyToken,
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
 			},
 		},
 		{
@@ -206,15 +245,17 @@ func Test_Logout(t *testing.T) {
 				Code:     errors.StatusUnauthorizedCode,
 				Messages: []string{"Invalid token"},
 			},
-			mockFunc: func(repo *mocks.UserRepository, cache *mocks.Cache, logger *mocks.Logger) {
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
 			},
 		},
 		{
-			name:  "failed delete from cache",
-			token: token,
-			mockFunc: func(repo *mocks.UserRepository, cache *mocks.Cache, logger *mocks.Logger) {
-				cache.On("Delete", mock.Anything, "user_refresh_token:"+userCode.String()).Return(libErrors.New("cache delete error"))
-				logger.On("Error", mock.Anything, "logout_cache_delete", "error deleting cache", mock.Anything, mock.Anything).Return()
+			name:         "failed to revoke the session",
+			token:        token,
+			tokenRevoked: true,
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
+				sessions.On("GetByCode", mock.Anything, sessionCode).Return(&entities.Session{Code: sessionCode, UserID: 1}, nil)
+				sessions.On("Update", mock.Anything, mock.Anything).Return(libErrors.New("database error"))
+				logger.On("Error", mock.Anything, "logout_session_revoke", "error revoking session", mock.Anything, mock.Anything).Return()
 			},
 		},
 	}
@@ -222,11 +263,12 @@ func Test_Logout(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			userRepository := new(mocks.UserRepository)
-			cache := new(mocks.Cache)
+			sessionRepository := new(mocks.SessionRepository)
 			logger := new(mocks.Logger)
-			service := NewService(userRepository, cache, logger)
+			denylist := newMemoryDenylist()
+			service := NewService(userRepository, sessionRepository, newIssuer(t), denylist, nil, newMemoryGuard(0), logger, false)
 
-			tc.mockFunc(userRepository, cache, logger)
+			tc.mockFunc(userRepository, sessionRepository, logger)
 			err = service.Logout(ctx, tc.token)
 			if tc.expectedError != nil {
 				assert.Error(t, err)
@@ -234,8 +276,12 @@ func Test_Logout(t *testing.T) {
 				assert.True(t, ok)
 				assert.Equal(t, tc.expectedError.HttpCode, errorResponse.ErrorHTTPCode())
 				assert.Equal(t, tc.expectedError.Code, errorResponse.ErrorCode())
+			} else {
+				assert.NoError(t, err)
 			}
-			cache.AssertExpectations(t)
+			assert.Equal(t, tc.tokenRevoked, denylist.tokens[tokenID])
+			assert.Equal(t, tc.sessionRevoked, denylist.sessions[sessionCode.String()])
+			sessionRepository.AssertExpectations(t)
 			userRepository.AssertExpectations(t)
 			logger.AssertExpectations(t)
 		})
@@ -246,24 +292,39 @@ func Test_Logout(t *testing.T) {
 func Test_RefreshToken(t *testing.T) {
 	ctx := context.Background()
 	userCode := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	sessionCode := uuid.New()
 
-	jwtUtils.InitJWTConfig(&jwtConfig)
-	accessToken, err := jwtUtils.GenerateAccessToken(userCode)
+	issuer := newIssuer(t)
+	accessToken, err := issuer.GenerateAccessToken(userCode, sessionCode, 0)
 	if err != nil {
 		t.Fatalf("failed to generate token: %v", err)
 	}
-
-	refreshToken, err := jwtUtils.GenerateRefreshToken(userCode)
+	refreshToken, tokenID, err := issuer.GenerateRefreshToken(userCode, sessionCode, 0)
 	if err != nil {
 		t.Fatalf("failed to generate token: %v", err)
 	}
+	_, rotatedTokenID, err := issuer.GenerateRefreshToken(userCode, sessionCode, 0)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	activeSession := func(currentTokenID uuid.UUID) *entities.Session {
+		return &entities.Session{
+			Code:           sessionCode,
+			UserID:         1,
+			RefreshTokenID: currentTokenID,
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+	}
+	revokedAt := time.Now().Add(-time.Minute)
+	user := &entities.User{ID: 1, Code: userCode}
 
 	testCases := []struct {
 		name             string
 		token            string
 		expectedResponse *response.TokensResponse
 		expectedError    *errors.ErrorResponse
-		mockFunc         func(*mocks.UserRepository, *mocks.Cache, *mocks.Logger)
+		mockFunc         func(*mocks.UserRepository, *mocks.SessionRepository, *mocks.Logger)
 	}{
 		{
 			name:  "error parsing token",
@@ -273,7 +334,7 @@ func Test_RefreshToken(t *testing.T) {
 				Code:     errors.StatusUnauthorizedCode,
 				Messages: []string{"Invalid refreshtoken"},
 			},
-			mockFunc: func(repo *mocks.UserRepository, cache *mocks.Cache, logger *mocks.Logger) {
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
 			},
 		},
 		{
@@ -284,8 +345,11 @@ func Test_RefreshToken(t *testing.T) {
 				Code:     errors.StatusUnauthorizedCode,
 				Messages: []string{"Invalid refresh token type"},
 			},
-			mockFunc: func(repo *mocks.UserRepository, cache *mocks.Cache, logger *mocks.Logger) {
-				cache.On("Delete", mock.Anything, fmt.Sprintf("user_refresh_token:%s", userCode.String())).Return(nil)
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
+				sessions.On("GetByCode", mock.Anything, sessionCode).Return(activeSession(tokenID), nil)
+				sessions.On("Update", mock.Anything, mock.MatchedBy(func(session *entities.Session) bool {
+					return session.RevokedAt != nil
+				})).Return(nil)
 				logger.On("Error", mock.Anything, "refresh_token_invalid_type", mock.Anything, mock.Anything, mock.Anything).Return()
 			},
 		},
@@ -296,8 +360,113 @@ func Test_RefreshToken(t *testing.T) {
 				AccessToken:  accessToken,
 				RefreshToken: refreshToken,
 			},
-			mockFunc: func(repo *mocks.UserRepository, cache *mocks.Cache, logger *mocks.Logger) {
-				cache.On("Set", mock.Anything, fmt.Sprintf("user_refresh_token:%s", userCode.String()), mock.Anything, mock.Anything).Return(nil)
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
+				repo.On("GetByCode", mock.Anything, userCode).Return(user, nil)
+				sessions.On("GetByCode", mock.Anything, sessionCode).Return(activeSession(tokenID), nil)
+				sessions.On("Rotate", mock.Anything, mock.MatchedBy(func(session *entities.Session) bool {
+					return session.RefreshTokenID != tokenID && session.RevokedAt == nil && !session.LastUsedAt.IsZero()
+				}), tokenID).Return(true, nil)
+			},
+		},
+		{
+			name:  "concurrent refresh with the same token revokes the session",
+			token: refreshToken,
+			expectedError: &errors.ErrorResponse{
+				HttpCode: http.StatusUnauthorized,
+				Code:     errors.StatusUnauthorizedCode,
+				Messages: []string{"Session has been revoked"},
+			},
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
+				repo.On("GetByCode", mock.Anything, userCode).Return(user, nil)
+				sessions.On("GetByCode", mock.Anything, sessionCode).Return(activeSession(tokenID), nil)
+				sessions.On("Rotate", mock.Anything, mock.Anything, tokenID).Return(false, nil)
+				sessions.On("Update", mock.Anything, mock.MatchedBy(func(session *entities.Session) bool {
+					return session.RevokedAt != nil
+				})).Return(nil).Once()
+				logger.On("Error", mock.Anything, "refresh_token_reuse", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+			},
+		},
+		{
+			name:  "token issued before the token version was bumped",
+			token: refreshToken,
+			expectedError: &errors.ErrorResponse{
+				HttpCode: http.StatusUnauthorized,
+				Code:     errors.StatusUnauthorizedCode,
+				Messages: []string{"Session has been revoked"},
+			},
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
+				repo.On("GetByCode", mock.Anything, userCode).Return(&entities.User{ID: 1, Code: userCode, TokenVersion: 1}, nil)
+				sessions.On("GetByCode", mock.Anything, sessionCode).Return(activeSession(tokenID), nil)
+			},
+		},
+		{
+			name:  "user no longer exists",
+			token: refreshToken,
+			expectedError: &errors.ErrorResponse{
+				HttpCode: http.StatusUnauthorized,
+				Code:     errors.StatusUnauthorizedCode,
+				Messages: []string{"Session has been revoked"},
+			},
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
+				repo.On("GetByCode", mock.Anything, userCode).Return(nil, nil)
+				sessions.On("GetByCode", mock.Anything, sessionCode).Return(activeSession(tokenID), nil)
+			},
+		},
+		{
+			name:  "replay of a rotated token revokes the session",
+			token: refreshToken,
+			expectedError: &errors.ErrorResponse{
+				HttpCode: http.StatusUnauthorized,
+				Code:     errors.StatusUnauthorizedCode,
+				Messages: []string{"Session has been revoked"},
+			},
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
+				sessions.On("GetByCode", mock.Anything, sessionCode).Return(activeSession(rotatedTokenID), nil)
+				sessions.On("Update", mock.Anything, mock.MatchedBy(func(session *entities.Session) bool {
+					return session.RevokedAt != nil && session.RefreshTokenID == rotatedTokenID
+				})).Return(nil)
+				logger.On("Error", mock.Anything, "refresh_token_reuse", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+			},
+		},
+		{
+			name:  "revoked session",
+			token: refreshToken,
+			expectedError: &errors.ErrorResponse{
+				HttpCode: http.StatusUnauthorized,
+				Code:     errors.StatusUnauthorizedCode,
+				Messages: []string{"Session has been revoked"},
+			},
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
+				session := activeSession(tokenID)
+				session.RevokedAt = &revokedAt
+				sessions.On("GetByCode", mock.Anything, sessionCode).Return(session, nil)
+			},
+		},
+		{
+			name:  "unknown session",
+			token: refreshToken,
+			expectedError: &errors.ErrorResponse{
+				HttpCode: http.StatusUnauthorized,
+				Code:     errors.StatusUnauthorizedCode,
+				Messages: []string{"Session has been revoked"},
+			},
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
+				sessions.On("GetByCode", mock.Anything, sessionCode).Return((*entities.Session)(nil), nil)
+			},
+		},
+		{
+			name:  "error storing the rotated token",
+			token: refreshToken,
+			expectedError: &errors.ErrorResponse{
+				HttpCode: http.StatusInternalServerError,
+				Code:     errors.StatusInternalServerErrorCode,
+				Messages: []string{"Failed to generate new refresh token"},
+			},
+			mockFunc: func(repo *mocks.UserRepository, sessions *mocks.SessionRepository, logger *mocks.Logger) {
+				repo.On("GetByCode", mock.Anything, userCode).Return(user, nil)
+				sessions.On("GetByCode", mock.Anything, sessionCode).Return(activeSession(tokenID), nil)
+				sessions.On("Rotate", mock.Anything, mock.Anything, tokenID).Return(false, libErrors.New("database error"))
+				logger.On("Error", mock.Anything, "refresh_token_session_update", "error updating session", mock.Anything, mock.Anything).Return()
 			},
 		},
 	}
@@ -305,11 +474,11 @@ func Test_RefreshToken(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			userRepository := new(mocks.UserRepository)
-			cache := new(mocks.Cache)
+			sessionRepository := new(mocks.SessionRepository)
 			logger := new(mocks.Logger)
-			service := NewService(userRepository, cache, logger)
+			service := NewService(userRepository, sessionRepository, issuer, newMemoryDenylist(), nil, newMemoryGuard(0), logger, false)
 
-			tc.mockFunc(userRepository, cache, logger)
+			tc.mockFunc(userRepository, sessionRepository, logger)
 			response, err := service.RefreshToken(ctx, tc.token)
 			if tc.expectedError != nil {
 				assert.Error(t, err)
@@ -323,9 +492,376 @@ func Test_RefreshToken(t *testing.T) {
 				assert.NotEqual(t, "", response.AccessToken)
 				assert.NotEqual(t, "", response.RefreshToken)
 			}
-			cache.AssertExpectations(t)
+			sessionRepository.AssertExpectations(t)
 			userRepository.AssertExpectations(t)
 			logger.AssertExpectations(t)
 		})
 	}
 }
+
+// memorySessionRepository keeps sessions in memory, like the database would.
+type memorySessionRepository map[uuid.UUID]entities.Session
+
+func (r memorySessionRepository) Create(ctx context.Context, session *entities.Session) error {
+	r[session.Code] = *session
+	return nil
+}
+
+func (r memorySessionRepository) GetByCode(ctx context.Context, code uuid.UUID) (*entities.Session, error) {
+	session, ok := r[code]
+	if !ok {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (r memorySessionRepository) ListActiveByUser(ctx context.Context, userID uint64, now time.Time) ([]entities.Session, error) {
+	var sessions []entities.Session
+	for _, session := range r {
+		if session.UserID == userID && session.IsActive(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r memorySessionRepository) Update(ctx context.Context, session *entities.Session) error {
+	r[session.Code] = *session
+	return nil
+}
+
+func (r memorySessionRepository) Rotate(ctx context.Context, session *entities.Session, previous uuid.UUID) (bool, error) {
+	stored, ok := r[session.Code]
+	if !ok || stored.RefreshTokenID != previous || stored.RevokedAt != nil {
+		return false, nil
+	}
+	r[session.Code] = *session
+	return true, nil
+}
+
+// memoryDenylist records the access tokens and sessions denylisted.
+type memoryDenylist struct {
+	tokens   map[string]bool
+	sessions map[string]bool
+}
+
+func newMemoryDenylist() *memoryDenylist {
+	return &memoryDenylist{tokens: map[string]bool{}, sessions: map[string]bool{}}
+}
+
+func (d *memoryDenylist) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	d.tokens[tokenID] = true
+	return nil
+}
+
+func (d *memoryDenylist) RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error {
+	d.sessions[sessionID] = true
+	return nil
+}
+
+func (d *memoryDenylist) IsRevoked(ctx context.Context, tokenID, sessionID string) (bool, error) {
+	return d.tokens[tokenID] || d.sessions[sessionID], nil
+}
+
+// memoryGuard counts the failed logins per email, locking them after max
+// failures when max is set.
+type memoryGuard struct {
+	failures map[string]int
+	max      int
+}
+
+func newMemoryGuard(max int) *memoryGuard {
+	return &memoryGuard{failures: map[string]int{}, max: max}
+}
+
+func (g *memoryGuard) Check(ctx context.Context, email, ip string) (time.Duration, bool, error) {
+	if g.max > 0 && g.failures[email] >= g.max {
+		return time.Minute, true, nil
+	}
+	return 0, false, nil
+}
+
+func (g *memoryGuard) RecordFailure(ctx context.Context, email, ip string) error {
+	g.failures[email]++
+	return nil
+}
+
+func (g *memoryGuard) Reset(ctx context.Context, email string) error {
+	delete(g.failures, email)
+	return nil
+}
+
+// fakeMFA accepts a single code.
+type fakeMFA struct {
+	code string
+}
+
+func (f fakeMFA) Verify(ctx context.Context, user *entities.User, code string) error {
+	if code != f.code {
+		return errors.New(http.StatusUnauthorized, "INVALID_MFA_CODE", []string{"Invalid two-factor code"})
+	}
+	return nil
+}
+
+func newLoginService(t *testing.T) (*service, *entities.User, *mocks.Logger) {
+	t.Helper()
+	jwtUtils.InitJWTConfig(&jwtConfig)
+
+	user := &entities.User{
+		ID:           1,
+		Code:         uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"),
+		Email:        "test@example.com",
+		PasswordHash: "$2a$12$mXOO6awNuioYxS2DLxmIZeQVadom64q3xP0MBiCHTljiKAwDLYLTO",
+		PasswordSalt: "7da8aa7388bbe6e878064f084ac736a4",
+	}
+	userRepository := new(mocks.UserRepository)
+	userRepository.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+	userRepository.On("GetByCode", mock.Anything, user.Code).Return(user, nil)
+	userRepository.On("Update", mock.Anything, user).Return(nil)
+	logger := new(mocks.Logger)
+	logger.On("Error", mock.Anything, "refresh_token_reuse", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	return NewService(userRepository, memorySessionRepository{}, newIssuer(t), newMemoryDenylist(), nil, newMemoryGuard(0), logger, false), user, logger
+}
+
+func Test_RefreshToken_ReplayOfOldToken(t *testing.T) {
+	ctx := context.Background()
+	service, _, logger := newLoginService(t)
+
+	login, err := service.Login(ctx, &request.UserLogin{Email: "test@example.com", Password: "12345677"})
+	assert.NoError(t, err)
+	oldToken := login.TokensResponse.RefreshToken
+
+	rotated, err := service.RefreshToken(ctx, oldToken)
+	assert.NoError(t, err)
+	assert.NotEqual(t, oldToken, rotated.RefreshToken)
+
+	// The attacker replays the old token: it is rejected and the session revoked.
+	_, err = service.RefreshToken(ctx, oldToken)
+	assert.Error(t, err)
+	logger.AssertCalled(t, "Error", mock.Anything, "refresh_token_reuse", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// The legitimate holder of the newest token has to log in again.
+	_, err = service.RefreshToken(ctx, rotated.RefreshToken)
+	errorResponse, ok := err.(*errors.ErrorResponse)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusUnauthorized, errorResponse.ErrorHTTPCode())
+	}
+
+	// Another login starts a new session that keeps working.
+	login, err = service.Login(ctx, &request.UserLogin{Email: "test@example.com", Password: "12345677"})
+	assert.NoError(t, err)
+	_, err = service.RefreshToken(ctx, login.TokensResponse.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func Test_Login_KeepsOtherDevices(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := newLoginService(t)
+
+	laptop, err := service.Login(ctx, &request.UserLogin{Email: "test@example.com", Password: "12345677", DeviceLabel: "Laptop"})
+	assert.NoError(t, err)
+	phone, err := service.Login(ctx, &request.UserLogin{Email: "test@example.com", Password: "12345677", DeviceLabel: "Phone"})
+	assert.NoError(t, err)
+
+	// Logging out on the phone leaves the laptop session working.
+	assert.NoError(t, service.Logout(ctx, phone.TokensResponse.AccessToken))
+	_, err = service.RefreshToken(ctx, phone.TokensResponse.RefreshToken)
+	assert.Error(t, err)
+	_, err = service.RefreshToken(ctx, laptop.TokensResponse.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func Test_LogoutEverywhere_RejectsOlderTokens(t *testing.T) {
+	ctx := context.Background()
+	service, user, _ := newLoginService(t)
+
+	laptop, err := service.Login(ctx, &request.UserLogin{Email: "test@example.com", Password: "12345677", DeviceLabel: "Laptop"})
+	assert.NoError(t, err)
+	phone, err := service.Login(ctx, &request.UserLogin{Email: "test@example.com", Password: "12345677", DeviceLabel: "Phone"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, service.LogoutEverywhere(ctx, user))
+	assert.Equal(t, 1, user.TokenVersion)
+	_, err = service.RefreshToken(ctx, laptop.TokensResponse.RefreshToken)
+	assert.Error(t, err)
+	_, err = service.RefreshToken(ctx, phone.TokensResponse.RefreshToken)
+	assert.Error(t, err)
+
+	// Tokens issued afterwards carry the new version.
+	login, err := service.Login(ctx, &request.UserLogin{Email: "test@example.com", Password: "12345677"})
+	assert.NoError(t, err)
+	refreshed, err := service.RefreshToken(ctx, login.TokensResponse.RefreshToken)
+	assert.NoError(t, err)
+	claims, _, err := jwtUtils.ParseClaims(refreshed.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), claims["token_version"])
+}
+
+func Test_LogoutSession_EndsTheSessionOfTheRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := newLoginService(t)
+
+	login, err := service.Login(ctx, &request.UserLogin{Email: "test@example.com", Password: "12345677"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, service.LogoutSession(ctx, login.TokensResponse.RefreshToken))
+	_, err = service.RefreshToken(ctx, login.TokensResponse.RefreshToken)
+	assert.Error(t, err)
+
+	err = service.LogoutSession(ctx, "invalid_token")
+	assert.Equal(t, errors.New(http.StatusUnauthorized, errors.StatusUnauthorizedCode, []string{"Invalid refresh token"}), err)
+}
+
+func Test_Login_RequiresVerifiedEmail(t *testing.T) {
+	ctx := context.Background()
+	service, user, _ := newLoginService(t)
+	service.requireVerifiedEmail = true
+	req := &request.UserLogin{Email: "test@example.com", Password: "12345677"}
+
+	_, err := service.Login(ctx, req)
+	errorResponse, ok := err.(*errors.ErrorResponse)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusForbidden, errorResponse.ErrorHTTPCode())
+		assert.Equal(t, emailNotVerifiedCode, errorResponse.ErrorCode())
+	}
+
+	verifiedAt := time.Now()
+	user.EmailVerifiedAt = &verifiedAt
+	_, err = service.Login(ctx, req)
+	assert.NoError(t, err)
+}
+
+func Test_LoginExternal(t *testing.T) {
+	ctx := context.Background()
+	service, user, _ := newLoginService(t)
+
+	login, err := service.LoginExternal(ctx, user, "Laptop", "agent", "127.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, user.Code, login.User.Code)
+	_, err = service.RefreshToken(ctx, login.TokensResponse.RefreshToken)
+	assert.NoError(t, err)
+
+	// The provider does not replace the second factor.
+	enabledAt := time.Now()
+	user.MFAEnabledAt = &enabledAt
+	login, err = service.LoginExternal(ctx, user, "Laptop", "agent", "127.0.0.1")
+	assert.NoError(t, err)
+	assert.True(t, login.MFARequired)
+	assert.NotEmpty(t, login.MFAToken)
+	assert.Nil(t, login.TokensResponse)
+}
+
+func Test_LoginMFA(t *testing.T) {
+	ctx := context.Background()
+	service, user, logger := newLoginService(t)
+	logger.On("Error", mock.Anything, "refresh_token_invalid_type", mock.Anything, mock.Anything).Return()
+	service.mfa = fakeMFA{code: "123456"}
+	enabledAt := time.Now()
+	user.MFAEnabledAt = &enabledAt
+
+	login, err := service.Login(ctx, &request.UserLogin{Email: "test@example.com", Password: "12345677"})
+	assert.NoError(t, err)
+	assert.True(t, login.MFARequired)
+	assert.NotEmpty(t, login.MFAToken)
+	assert.Nil(t, login.TokensResponse)
+
+	// The MFA token is not accepted as an access or refresh token.
+	_, err = service.RefreshToken(ctx, login.MFAToken)
+	assert.Error(t, err)
+
+	_, err = service.LoginMFA(ctx, &request.MFALogin{MFAToken: login.MFAToken, Code: "000000"})
+	errorResponse, ok := err.(*errors.ErrorResponse)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusUnauthorized, errorResponse.ErrorHTTPCode())
+	}
+
+	result, err := service.LoginMFA(ctx, &request.MFALogin{MFAToken: login.MFAToken, Code: "123456", DeviceLabel: "Laptop"})
+	assert.NoError(t, err)
+	if assert.NotNil(t, result.TokensResponse) {
+		_, err = service.RefreshToken(ctx, result.TokensResponse.RefreshToken)
+		assert.NoError(t, err)
+	}
+
+	// The MFA token cannot start a second session.
+	_, err = service.LoginMFA(ctx, &request.MFALogin{MFAToken: login.MFAToken, Code: "123456"})
+	assert.Error(t, err)
+}
+
+func Test_Login_LocksAfterFailures(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := newLoginService(t)
+	guard := newMemoryGuard(3)
+	service.guard = guard
+	wrong := &request.UserLogin{Email: "test@example.com", Password: "wrong-password"}
+
+	for range 3 {
+		_, err := service.Login(ctx, wrong)
+		errorResponse, ok := err.(*errors.ErrorResponse)
+		if assert.True(t, ok) {
+			assert.Equal(t, http.StatusUnauthorized, errorResponse.ErrorHTTPCode())
+		}
+	}
+
+	// Even the right password is rejected while locked.
+	_, err := service.Login(ctx, &request.UserLogin{Email: "test@example.com", Password: "12345677"})
+	var retryError *lockout.RetryError
+	if assert.True(t, libErrors.As(err, &retryError)) {
+		assert.Equal(t, time.Minute, retryError.RetryAfter)
+		errorResponse, ok := retryError.Err.(*errors.ErrorResponse)
+		if assert.True(t, ok) {
+			assert.Equal(t, http.StatusTooManyRequests, errorResponse.ErrorHTTPCode())
+			assert.Equal(t, accountLockedCode, errorResponse.ErrorCode())
+		}
+	}
+
+	// A successful login clears the failures.
+	guard.failures[wrong.Email] = 2
+	_, err = service.Login(ctx, &request.UserLogin{Email: "test@example.com", Password: "12345677"})
+	assert.NoError(t, err)
+	assert.Zero(t, guard.failures[wrong.Email])
+}
+
+func Test_LoginMFA_CountsWrongCodes(t *testing.T) {
+	ctx := context.Background()
+	service, user, _ := newLoginService(t)
+	guard := newMemoryGuard(2)
+	service.guard = guard
+	service.mfa = fakeMFA{code: "123456"}
+	enabledAt := time.Now()
+	user.MFAEnabledAt = &enabledAt
+
+	// A correct password does not clear the failures of the second factor.
+	for range 2 {
+		login, err := service.Login(ctx, &request.UserLogin{Email: "test@example.com", Password: "12345677"})
+		assert.NoError(t, err)
+		_, err = service.LoginMFA(ctx, &request.MFALogin{MFAToken: login.MFAToken, Code: "000000"})
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 2, guard.failures[user.Email])
+
+	_, err := service.Login(ctx, &request.UserLogin{Email: "test@example.com", Password: "12345677"})
+	var retryError *lockout.RetryError
+	assert.True(t, libErrors.As(err, &retryError))
+}
+
+func Test_Login_RehashesLegacyPasswords(t *testing.T) {
+	ctx := context.Background()
+	service, user, _ := newLoginService(t)
+	userRepository := service.userRepository.(*mocks.UserRepository)
+
+	_, err := service.Login(ctx, &request.UserLogin{Email: "test@example.com", Password: "12345677"})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(user.PasswordHash, "$argon2id$"))
+	assert.Empty(t, user.PasswordSalt)
+	userRepository.AssertNumberOfCalls(t, "Update", 1)
+
+	// The new hash is current, so it is not rehashed again.
+	_, err = service.Login(ctx, &request.UserLogin{Email: "test@example.com", Password: "12345677"})
+	assert.NoError(t, err)
+	userRepository.AssertNumberOfCalls(t, "Update", 1)
+
+	_, err = service.Login(ctx, &request.UserLogin{Email: "test@example.com", Password: "wrong-password"})
+	assert.Error(t, err)
+}
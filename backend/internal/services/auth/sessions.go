@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+)
+
+const (
+	sessionNotFoundCode = "SESSION_NOT_FOUND"
+
+	maxDeviceLabelLength = 255
+)
+
+// ListSessions returns the active sessions of the user, flagging the one the
+// request was made with.
+func (s *service) ListSessions(ctx context.Context, user *entities.User, current uuid.UUID) ([]*response.Session, error) {
+	sessions, err := s.sessionRepository.ListActiveByUser(ctx, user.ID, s.now())
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	result := make([]*response.Session, 0, len(sessions))
+	for i := range sessions {
+		result = append(result, response.ToSessionResponse(&sessions[i], current))
+	}
+	return result, nil
+}
+
+// RevokeSession ends one of the sessions of the user.
+func (s *service) RevokeSession(ctx context.Context, user *entities.User, code uuid.UUID) error {
+	session, err := s.sessionRepository.GetByCode(ctx, code)
+	if err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	now := s.now()
+	if session == nil || session.UserID != user.ID || !session.IsActive(now) {
+		return errors.New(http.StatusNotFound, sessionNotFoundCode, []string{"Session not found"})
+	}
+
+	if err := s.endSession(ctx, session, now); err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	return nil
+}
+
+// RevokeOtherSessions ends every session of the user except the current one.
+func (s *service) RevokeOtherSessions(ctx context.Context, user *entities.User, current uuid.UUID) error {
+	now := s.now()
+	sessions, err := s.sessionRepository.ListActiveByUser(ctx, user.ID, now)
+	if err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	for i := range sessions {
+		if sessions[i].Code == current {
+			continue
+		}
+		if err := s.endSession(ctx, &sessions[i], now); err != nil {
+			return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+		}
+	}
+	return nil
+}
+
+// LogoutEverywhere invalidates every token of the user, the one the request was
+// made with included.
+func (s *service) LogoutEverywhere(ctx context.Context, user *entities.User) error {
+	if err := s.InvalidateTokens(ctx, user); err != nil {
+		s.logger.Error(ctx, "logout_everywhere", "error invalidating tokens", log.Field("user_code", user.Code), log.Field("error", err))
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	return nil
+}
+
+// InvalidateTokens saves the user with its token version bumped, which rejects
+// at once every token issued before, and ends all of their sessions. Callers
+// changing the credentials set them on the user first so both are stored
+// together.
+func (s *service) InvalidateTokens(ctx context.Context, user *entities.User) error {
+	user.TokenVersion++
+	if err := s.userRepository.Update(ctx, user); err != nil {
+		return err
+	}
+
+	now := s.now()
+	sessions, err := s.sessionRepository.ListActiveByUser(ctx, user.ID, now)
+	if err != nil {
+		return err
+	}
+	for i := range sessions {
+		sessions[i].RevokedAt = &now
+		if err := s.sessionRepository.Update(ctx, &sessions[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeSession ends a session whatever its owner, for callers that already
+// proved they hold one of its tokens.
+func (s *service) revokeSession(ctx context.Context, code uuid.UUID) error {
+	session, err := s.sessionRepository.GetByCode(ctx, code)
+	if err != nil || session == nil || session.RevokedAt != nil {
+		return err
+	}
+	return s.endSession(ctx, session, s.now())
+}
+
+// endSession revokes a session and denylists the access tokens issued for it,
+// which would otherwise remain valid until they expire.
+func (s *service) endSession(ctx context.Context, session *entities.Session, now time.Time) error {
+	session.RevokedAt = &now
+	if err := s.sessionRepository.Update(ctx, session); err != nil {
+		return err
+	}
+	if err := s.denylist.RevokeSession(ctx, session.Code.String(), s.tokens.AccessTTL()); err != nil {
+		s.logger.Error(ctx, "session_token_revoke", "error denylisting the access tokens of the session", log.Field("session_code", session.Code), log.Field("error", err))
+	}
+	return nil
+}
+
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value
+	}
+	return string(runes[:length])
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Sessions(t *testing.T) {
+	ctx := context.Background()
+	user := &entities.User{ID: 1}
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	laptop := entities.Session{Code: uuid.New(), UserID: 1, DeviceLabel: "Laptop", ExpiresAt: now.Add(time.Hour)}
+	phone := entities.Session{Code: uuid.New(), UserID: 1, DeviceLabel: "Phone", ExpiresAt: now.Add(time.Hour)}
+	tablet := entities.Session{Code: uuid.New(), UserID: 1, DeviceLabel: "Tablet", ExpiresAt: now.Add(time.Hour)}
+
+	newService := func() (*service, *mocks.SessionRepository, *memoryDenylist) {
+		sessionRepository := new(mocks.SessionRepository)
+		denylist := newMemoryDenylist()
+		svc := NewService(new(mocks.UserRepository), sessionRepository, newIssuer(t), denylist, nil, newMemoryGuard(0), new(mocks.Logger), false)
+		svc.now = func() time.Time { return now }
+		return svc, sessionRepository, denylist
+	}
+	revoked := func(code uuid.UUID) interface{} {
+		return mock.MatchedBy(func(session *entities.Session) bool {
+			return session.Code == code && session.RevokedAt != nil && session.RevokedAt.Equal(now)
+		})
+	}
+
+	t.Run("list flags the current session", func(t *testing.T) {
+		svc, sessionRepository, _ := newService()
+		sessionRepository.On("ListActiveByUser", mock.Anything, user.ID, now).Return([]entities.Session{phone, laptop}, nil)
+
+		result, err := svc.ListSessions(ctx, user, laptop.Code)
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, "Phone", result[0].DeviceLabel)
+		assert.False(t, result[0].Current)
+		assert.True(t, result[1].Current)
+	})
+
+	t.Run("revoke one session", func(t *testing.T) {
+		svc, sessionRepository, denylist := newService()
+		sessionRepository.On("GetByCode", mock.Anything, phone.Code).Return(&phone, nil)
+		sessionRepository.On("Update", mock.Anything, revoked(phone.Code)).Return(nil)
+
+		assert.NoError(t, svc.RevokeSession(ctx, user, phone.Code))
+		sessionRepository.AssertExpectations(t)
+		// Its access tokens stop working before they expire.
+		assert.True(t, denylist.sessions[phone.Code.String()])
+	})
+
+	t.Run("sessions of other users are not found", func(t *testing.T) {
+		svc, sessionRepository, _ := newService()
+		foreign := entities.Session{Code: uuid.New(), UserID: 2, ExpiresAt: now.Add(time.Hour)}
+		sessionRepository.On("GetByCode", mock.Anything, foreign.Code).Return(&foreign, nil)
+
+		err := svc.RevokeSession(ctx, user, foreign.Code)
+		errorResponse, ok := err.(*errors.ErrorResponse)
+		if assert.True(t, ok) {
+			assert.Equal(t, http.StatusNotFound, errorResponse.ErrorHTTPCode())
+			assert.Equal(t, sessionNotFoundCode, errorResponse.ErrorCode())
+		}
+		sessionRepository.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("revoke all other sessions", func(t *testing.T) {
+		svc, sessionRepository, denylist := newService()
+		sessionRepository.On("ListActiveByUser", mock.Anything, user.ID, now).Return([]entities.Session{laptop, phone, tablet}, nil)
+		sessionRepository.On("Update", mock.Anything, revoked(phone.Code)).Return(nil)
+		sessionRepository.On("Update", mock.Anything, revoked(tablet.Code)).Return(nil)
+
+		assert.NoError(t, svc.RevokeOtherSessions(ctx, user, laptop.Code))
+		sessionRepository.AssertExpectations(t)
+		sessionRepository.AssertNumberOfCalls(t, "Update", 2)
+		assert.False(t, denylist.sessions[laptop.Code.String()])
+	})
+
+	t.Run("logout everywhere bumps the token version", func(t *testing.T) {
+		svc, sessionRepository, _ := newService()
+		userRepository := new(mocks.UserRepository)
+		svc.userRepository = userRepository
+		account := &entities.User{ID: 1, TokenVersion: 2}
+		userRepository.On("Update", mock.Anything, mock.MatchedBy(func(user *entities.User) bool {
+			return user.TokenVersion == 3
+		})).Return(nil)
+		sessionRepository.On("ListActiveByUser", mock.Anything, account.ID, now).Return([]entities.Session{laptop, phone}, nil)
+		sessionRepository.On("Update", mock.Anything, revoked(laptop.Code)).Return(nil)
+		sessionRepository.On("Update", mock.Anything, revoked(phone.Code)).Return(nil)
+
+		assert.NoError(t, svc.LogoutEverywhere(ctx, account))
+		userRepository.AssertExpectations(t)
+		sessionRepository.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,138 @@
+package costbasis
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/costbasis"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/pricing"
+)
+
+const (
+	assetNotFoundCode      = "ASSET_NOT_FOUND"
+	invalidMethodCode      = "INVALID_COST_BASIS_METHOD"
+	inconsistentLedgerCode = "INCONSISTENT_LEDGER"
+)
+
+type assetRepository interface {
+	GetByCode(ctx context.Context, userID uint64, code uuid.UUID) (*entities.Asset, error)
+}
+
+type transactionRepository interface {
+	ListByAsset(ctx context.Context, assetID uint64) ([]entities.Transaction, error)
+}
+
+type assetValuer interface {
+	ValueAsset(ctx context.Context, asset *entities.Asset) (*pricing.Valuation, error)
+}
+
+type service struct {
+	assetRepository       assetRepository
+	transactionRepository transactionRepository
+	assetValuer           assetValuer
+}
+
+func NewService(assetRepo assetRepository, transactionRepo transactionRepository, valuer assetValuer) *service {
+	return &service{
+		assetRepository:       assetRepo,
+		transactionRepository: transactionRepo,
+		assetValuer:           valuer,
+	}
+}
+
+// GetAssetCostBasis replays the asset ledger with the requested method, falling back
+// to the user's preferred method when none is given.
+func (s *service) GetAssetCostBasis(ctx context.Context, user *entities.User, assetCode uuid.UUID, methodName string) (*response.CostBasis, error) {
+	method, err := resolveMethod(user, methodName)
+	if err != nil {
+		return nil, err
+	}
+
+	asset, err := s.assetRepository.GetByCode(ctx, user.ID, assetCode)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if asset == nil {
+		return nil, errors.New(http.StatusNotFound, assetNotFoundCode, []string{"Asset not found"})
+	}
+
+	transactions, err := s.transactionRepository.ListByAsset(ctx, asset.ID)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	result, err := costbasis.Replay(method, costbasis.Ledger(asset, transactions))
+	if err != nil {
+		return nil, errors.New(http.StatusUnprocessableEntity, inconsistentLedgerCode, []string{err.Error()})
+	}
+
+	costBasis := toCostBasisResponse(asset, result)
+	if valuation, err := s.assetValuer.ValueAsset(ctx, asset); err == nil {
+		marketValue := valuation.MarketValue
+		if asset.AutoPricingEnabled {
+			marketValue = result.MarketValue(valuation.UnitPrice)
+		}
+		unrealizedGain := marketValue.Sub(result.TotalCost)
+		costBasis.MarketValue = &marketValue
+		costBasis.UnrealizedGain = &unrealizedGain
+	}
+
+	return costBasis, nil
+}
+
+func resolveMethod(user *entities.User, methodName string) (costbasis.Method, error) {
+	if methodName == "" {
+		methodName = user.CostBasisMethod
+	}
+	if methodName == "" {
+		return costbasis.DefaultMethod, nil
+	}
+
+	method, err := costbasis.ParseMethod(methodName)
+	if err != nil {
+		return "", errors.New(http.StatusBadRequest, invalidMethodCode, []string{"method must be one of: FIFO, LIFO, AVERAGE"})
+	}
+	return method, nil
+}
+
+func toCostBasisResponse(asset *entities.Asset, result *costbasis.Result) *response.CostBasis {
+	lots := make([]*response.TaxLot, 0, len(result.Lots))
+	for _, lot := range result.Lots {
+		lots = append(lots, &response.TaxLot{
+			TransactionCode: lot.TransactionCode,
+			AcquiredAt:      lot.AcquiredAt,
+			Units:           lot.Units,
+			Cost:            lot.Cost,
+			UnitCost:        lot.UnitCost(),
+		})
+	}
+
+	sales := make([]*response.RealizedSale, 0, len(result.Sales))
+	for _, sale := range result.Sales {
+		sales = append(sales, &response.RealizedSale{
+			TransactionCode: sale.TransactionCode,
+			SoldAt:          sale.SoldAt,
+			Units:           sale.Units,
+			Proceeds:        sale.Proceeds,
+			Fee:             sale.Fee,
+			CostBasis:       sale.CostBasis,
+			RealizedGain:    sale.RealizedGain,
+		})
+	}
+
+	return &response.CostBasis{
+		AssetCode:       asset.Code,
+		Method:          string(result.Method),
+		Currency:        asset.Currency,
+		TotalUnits:      result.TotalUnits,
+		TotalCost:       result.TotalCost,
+		AverageUnitCost: result.AverageUnitCost(),
+		RealizedGain:    result.RealizedGain,
+		Lots:            lots,
+		Sales:           sales,
+	}
+}
@@ -0,0 +1,178 @@
+package costbasis
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/pricing"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAssetRepository struct {
+	mock.Mock
+}
+
+func (m *MockAssetRepository) GetByCode(ctx context.Context, userID uint64, code uuid.UUID) (*entities.Asset, error) {
+	args := m.Called(ctx, userID, code)
+	if asset, ok := args.Get(0).(*entities.Asset); ok {
+		return asset, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockTransactionRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransactionRepository) ListByAsset(ctx context.Context, assetID uint64) ([]entities.Transaction, error) {
+	args := m.Called(ctx, assetID)
+	if transactions, ok := args.Get(0).([]entities.Transaction); ok {
+		return transactions, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockValuer struct {
+	mock.Mock
+}
+
+func (m *MockValuer) ValueAsset(ctx context.Context, asset *entities.Asset) (*pricing.Valuation, error) {
+	args := m.Called(ctx, asset)
+	if valuation, ok := args.Get(0).(*pricing.Valuation); ok {
+		return valuation, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func Test_GetAssetCostBasis(t *testing.T) {
+	ctx := context.Background()
+	assetCode := uuid.New()
+	now := time.Now()
+
+	ledger := []entities.Transaction{
+		{Type: entities.TransactionTypeBuy, Units: decimal.NewFromInt(1), Total: decimal.NewFromInt(100), CreatedAt: now.Add(-2 * time.Hour)},
+		{Type: entities.TransactionTypeBuy, Units: decimal.NewFromInt(1), Total: decimal.NewFromInt(300), CreatedAt: now.Add(-time.Hour)},
+		{Type: entities.TransactionTypeSell, Units: decimal.NewFromInt(1), Total: decimal.NewFromInt(250), CreatedAt: now},
+	}
+
+	testCases := []struct {
+		name                   string
+		user                   *entities.User
+		method                 string
+		asset                  *entities.Asset
+		expectedMethod         string
+		expectedRealized       decimal.Decimal
+		expectedUnrealizedGain *decimal.Decimal
+		valuation              *pricing.Valuation
+		expectedError          *errors.ErrorResponse
+	}{
+		{
+			name:             "uses the user's preferred method",
+			user:             &entities.User{ID: 1, CostBasisMethod: "LIFO"},
+			asset:            &entities.Asset{ID: 5, Code: assetCode, AutoPricingEnabled: true},
+			expectedMethod:   "LIFO",
+			expectedRealized: decimal.NewFromInt(-50),
+		},
+		{
+			name:             "query method overrides the preference",
+			user:             &entities.User{ID: 1, CostBasisMethod: "LIFO"},
+			method:           "fifo",
+			asset:            &entities.Asset{ID: 5, Code: assetCode, AutoPricingEnabled: true},
+			expectedMethod:   "FIFO",
+			expectedRealized: decimal.NewFromInt(150),
+		},
+		{
+			name:   "the opening balance of the asset is its first lot",
+			user:   &entities.User{ID: 1},
+			method: "fifo",
+			asset: &entities.Asset{ID: 5, Code: assetCode, AutoPricingEnabled: true,
+				OpeningUnits: decimal.NewFromInt(2), OpeningCost: decimal.NewFromInt(50), CreatedAt: now.Add(-3 * time.Hour)},
+			expectedMethod:   "FIFO",
+			expectedRealized: decimal.NewFromInt(225),
+		},
+		{
+			name:                   "auto priced asset reports unrealized gain from market price",
+			user:                   &entities.User{ID: 1},
+			asset:                  &entities.Asset{ID: 5, Code: assetCode, AutoPricingEnabled: true},
+			valuation:              &pricing.Valuation{UnitPrice: decimal.NewFromInt(400)},
+			expectedMethod:         "FIFO",
+			expectedRealized:       decimal.NewFromInt(150),
+			expectedUnrealizedGain: func() *decimal.Decimal { v := decimal.NewFromInt(100); return &v }(),
+		},
+		{
+			name:   "manual asset reports unrealized gain from current value",
+			user:   &entities.User{ID: 1},
+			method: "average",
+			asset: &entities.Asset{
+				ID:           5,
+				Code:         assetCode,
+				CurrentValue: decimal.NewNullDecimal(decimal.NewFromInt(260)),
+			},
+			expectedMethod:         "AVERAGE",
+			expectedRealized:       decimal.NewFromInt(50),
+			expectedUnrealizedGain: func() *decimal.Decimal { v := decimal.NewFromInt(60); return &v }(),
+		},
+		{
+			name:          "invalid method",
+			user:          &entities.User{ID: 1},
+			method:        "HIFO",
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusBadRequest, Code: invalidMethodCode},
+		},
+		{
+			name:          "asset not found",
+			user:          &entities.User{ID: 1},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusNotFound, Code: assetNotFoundCode},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assetRepository := new(MockAssetRepository)
+			transactionRepository := new(MockTransactionRepository)
+			if tc.expectedError == nil || tc.expectedError.HttpCode == http.StatusNotFound {
+				assetRepository.On("GetByCode", mock.Anything, tc.user.ID, assetCode).Return(tc.asset, nil)
+			}
+			valuer := new(MockValuer)
+			if tc.asset != nil {
+				transactionRepository.On("ListByAsset", mock.Anything, tc.asset.ID).Return(ledger, nil)
+				if tc.asset.AutoPricingEnabled && tc.valuation == nil {
+					valuer.On("ValueAsset", mock.Anything, tc.asset).Return(nil, pricing.ErrPriceNotFound)
+				} else if tc.asset.AutoPricingEnabled {
+					valuer.On("ValueAsset", mock.Anything, tc.asset).Return(tc.valuation, nil)
+				} else {
+					valuer.On("ValueAsset", mock.Anything, tc.asset).Return(pricing.ManualValuation(tc.asset), nil)
+				}
+			}
+
+			svc := NewService(assetRepository, transactionRepository, valuer)
+			resp, err := svc.GetAssetCostBasis(ctx, tc.user, assetCode, tc.method)
+
+			if tc.expectedError != nil {
+				assert.Error(t, err)
+				errorResponse, ok := err.(*errors.ErrorResponse)
+				assert.True(t, ok)
+				assert.Equal(t, tc.expectedError.HttpCode, errorResponse.ErrorHTTPCode())
+				assert.Equal(t, tc.expectedError.Code, errorResponse.ErrorCode())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedMethod, resp.Method)
+				assert.True(t, tc.expectedRealized.Equal(resp.RealizedGain), "realized: %s", resp.RealizedGain)
+				if tc.expectedUnrealizedGain != nil {
+					assert.True(t, tc.expectedUnrealizedGain.Equal(*resp.UnrealizedGain), "unrealized: %s", resp.UnrealizedGain)
+				} else {
+					assert.Nil(t, resp.UnrealizedGain)
+				}
+			}
+			assetRepository.AssertExpectations(t)
+			transactionRepository.AssertExpectations(t)
+			valuer.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package emailverification
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/platform/mailer"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/crypto"
+)
+
+const (
+	invalidVerificationTokenCode = "INVALID_VERIFICATION_TOKEN"
+	tooManyRequestsCode          = "TOO_MANY_REQUESTS"
+
+	resendKeyPrefix = "email_verification_resend:"
+
+	verifyEmailPath = "/verify-email"
+	verifySubject   = "Verify your Zenith Financial email"
+	verifyBody      = `Welcome to Zenith Financial!
+
+Open this link to verify your email address, it expires in %d hours:
+%s
+
+If you did not create an account, ignore this email.`
+)
+
+type userRepository interface {
+	GetByEmail(ctx context.Context, email string) (*entities.User, error)
+	GetByID(ctx context.Context, id uint64) (*entities.User, error)
+	Update(ctx context.Context, user *entities.User) error
+}
+
+type verificationRepository interface {
+	Create(ctx context.Context, verification *entities.EmailVerification) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entities.EmailVerification, error)
+	Update(ctx context.Context, verification *entities.EmailVerification) error
+}
+
+type cache interface {
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+}
+
+type service struct {
+	userRepository         userRepository
+	verificationRepository verificationRepository
+	cache                  cache
+	mailer                 mailer.Mailer
+	logger                 log.Logger
+	frontendURL            string
+	ttl                    time.Duration
+	resendCooldown         time.Duration
+	now                    func() time.Time
+}
+
+func NewService(userRepo userRepository, verificationRepo verificationRepository, cache cache, mailer mailer.Mailer, logger log.Logger,
+	frontendURL string, ttl, resendCooldown time.Duration) *service {
+	return &service{
+		userRepository:         userRepo,
+		verificationRepository: verificationRepo,
+		cache:                  cache,
+		mailer:                 mailer,
+		logger:                 logger,
+		frontendURL:            strings.TrimRight(frontendURL, "/"),
+		ttl:                    ttl,
+		resendCooldown:         resendCooldown,
+		now:                    time.Now,
+	}
+}
+
+// SendVerification emails a verification link to the user.
+func (s *service) SendVerification(ctx context.Context, user *entities.User) error {
+	token, err := crypto.GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	verification := &entities.EmailVerification{
+		UserID:    user.ID,
+		TokenHash: crypto.HashToken(token),
+		Email:     user.Email,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if err := s.verificationRepository.Create(ctx, verification); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s%s?token=%s", s.frontendURL, verifyEmailPath, token)
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: verifySubject,
+		Body:    fmt.Sprintf(verifyBody, int(s.ttl.Hours()), link),
+	})
+}
+
+// ResendVerification emails a new link to an unverified user. It answers the
+// same whatever the email, and throttles the requests per address so it cannot
+// be used to flood an inbox.
+func (s *service) ResendVerification(ctx context.Context, req *request.ResendVerification) error {
+	email := strings.TrimSpace(req.Email)
+	if email == "" {
+		return errors.New(http.StatusBadRequest, errors.StatusBadRequestCode, []string{"email is required"})
+	}
+
+	allowed, err := s.cache.SetNX(ctx, resendKeyPrefix+crypto.HashToken(strings.ToLower(email)), true, s.resendCooldown)
+	if err != nil {
+		s.logger.Error(ctx, "email_verification_resend", "error throttling resend", log.Field("error", err))
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if !allowed {
+		return errors.New(http.StatusTooManyRequests, tooManyRequestsCode, []string{"Please wait before requesting another verification email"})
+	}
+
+	user, err := s.userRepository.GetByEmail(ctx, email)
+	if err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if user == nil || user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	if err := s.SendVerification(ctx, user); err != nil {
+		s.logger.Error(ctx, "email_verification_resend", "error sending verification email", log.Field("user_code", user.Code), log.Field("error", err))
+	}
+	return nil
+}
+
+// VerifyEmail marks the email of the user as verified. Each link can only be
+// used once, and only while the user still has the email it was sent to.
+func (s *service) VerifyEmail(ctx context.Context, req *request.VerifyEmail) error {
+	if req.Token == "" {
+		return errors.New(http.StatusBadRequest, errors.StatusBadRequestCode, []string{"token is required"})
+	}
+
+	verification, err := s.verificationRepository.GetByTokenHash(ctx, crypto.HashToken(req.Token))
+	if err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	now := s.now()
+	if verification == nil || !verification.IsUsable(now) {
+		return invalidVerificationToken()
+	}
+
+	user, err := s.userRepository.GetByID(ctx, verification.UserID)
+	if err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if user == nil || !strings.EqualFold(verification.Email, user.Email) {
+		return invalidVerificationToken()
+	}
+
+	verification.UsedAt = &now
+	if err := s.verificationRepository.Update(ctx, verification); err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	user.EmailVerifiedAt = &now
+	user.UpdatedAt = now
+	if err := s.userRepository.Update(ctx, user); err != nil {
+		s.logger.Error(ctx, "email_verification", "error saving verified email", log.Field("user_code", user.Code), log.Field("error", err))
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	return nil
+}
+
+func invalidVerificationToken() error {
+	return errors.New(http.StatusBadRequest, invalidVerificationTokenCode, []string{"Invalid or expired verification token"})
+}
@@ -0,0 +1,199 @@
+package emailverification
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/platform/mailer"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var linkToken = regexp.MustCompile(`https://app\.zenith\.test/verify-email\?token=([A-Za-z0-9_-]+)`)
+
+// memoryVerificationRepository keeps the verifications in memory, like the
+// database would.
+type memoryVerificationRepository map[string]entities.EmailVerification
+
+func (r memoryVerificationRepository) Create(ctx context.Context, verification *entities.EmailVerification) error {
+	r[verification.TokenHash] = *verification
+	return nil
+}
+
+func (r memoryVerificationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.EmailVerification, error) {
+	verification, ok := r[tokenHash]
+	if !ok {
+		return nil, nil
+	}
+	return &verification, nil
+}
+
+func (r memoryVerificationRepository) Update(ctx context.Context, verification *entities.EmailVerification) error {
+	r[verification.TokenHash] = *verification
+	return nil
+}
+
+// memoryCache implements SET NX, ignoring the TTL.
+type memoryCache map[string]bool
+
+func (c memoryCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if c[key] {
+		return false, nil
+	}
+	c[key] = true
+	return true, nil
+}
+
+type fakeMailer struct {
+	sent []mailer.Message
+}
+
+func (m *fakeMailer) Send(ctx context.Context, message mailer.Message) error {
+	m.sent = append(m.sent, message)
+	return nil
+}
+
+type fixture struct {
+	service        *service
+	verifications  memoryVerificationRepository
+	cache          memoryCache
+	mailer         *fakeMailer
+	userRepository *mocks.UserRepository
+	now            time.Time
+}
+
+func newFixture(user *entities.User) *fixture {
+	userRepository := new(mocks.UserRepository)
+	userRepository.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+	userRepository.On("GetByEmail", mock.Anything, mock.Anything).Return((*entities.User)(nil), nil)
+	userRepository.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	userRepository.On("Update", mock.Anything, user).Return(nil)
+
+	f := &fixture{
+		verifications:  memoryVerificationRepository{},
+		cache:          memoryCache{},
+		mailer:         &fakeMailer{},
+		userRepository: userRepository,
+		now:            time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC),
+	}
+	f.service = NewService(userRepository, f.verifications, f.cache, f.mailer, new(mocks.Logger), "https://app.zenith.test", 48*time.Hour, time.Minute)
+	f.service.now = func() time.Time { return f.now }
+	return f
+}
+
+// lastToken returns the token of the last link emailed.
+func (f *fixture) lastToken(t *testing.T) string {
+	t.Helper()
+	if !assert.NotEmpty(t, f.mailer.sent) {
+		t.FailNow()
+	}
+	match := linkToken.FindStringSubmatch(f.mailer.sent[len(f.mailer.sent)-1].Body)
+	if !assert.Len(t, match, 2) {
+		t.FailNow()
+	}
+	return match[1]
+}
+
+func assertErrorCode(t *testing.T, err error, httpCode int, code string) {
+	t.Helper()
+	errorResponse, ok := err.(*errors.ErrorResponse)
+	if assert.True(t, ok, "unexpected error %v", err) {
+		assert.Equal(t, httpCode, errorResponse.ErrorHTTPCode())
+		assert.Equal(t, code, errorResponse.ErrorCode())
+	}
+}
+
+func Test_EmailVerification(t *testing.T) {
+	ctx := context.Background()
+	newUser := func() *entities.User {
+		return &entities.User{ID: 1, Code: uuid.New(), Email: "ana@example.com"}
+	}
+
+	t.Run("verifies the email once", func(t *testing.T) {
+		user := newUser()
+		f := newFixture(user)
+
+		assert.NoError(t, f.service.SendVerification(ctx, user))
+		assert.Equal(t, user.Email, f.mailer.sent[0].To)
+		assert.Contains(t, f.mailer.sent[0].Body, "48 hours")
+		token := f.lastToken(t)
+		assert.Contains(t, f.verifications, crypto.HashToken(token))
+
+		assert.NoError(t, f.service.VerifyEmail(ctx, &request.VerifyEmail{Token: token}))
+		if assert.NotNil(t, user.EmailVerifiedAt) {
+			assert.Equal(t, f.now, *user.EmailVerifiedAt)
+		}
+
+		err := f.service.VerifyEmail(ctx, &request.VerifyEmail{Token: token})
+		assertErrorCode(t, err, http.StatusBadRequest, invalidVerificationTokenCode)
+		f.userRepository.AssertNumberOfCalls(t, "Update", 1)
+	})
+
+	t.Run("expired links are rejected", func(t *testing.T) {
+		user := newUser()
+		f := newFixture(user)
+		assert.NoError(t, f.service.SendVerification(ctx, user))
+
+		f.now = f.now.Add(49 * time.Hour)
+		err := f.service.VerifyEmail(ctx, &request.VerifyEmail{Token: f.lastToken(t)})
+		assertErrorCode(t, err, http.StatusBadRequest, invalidVerificationTokenCode)
+		assert.Nil(t, user.EmailVerifiedAt)
+	})
+
+	t.Run("links sent to a previous email are rejected", func(t *testing.T) {
+		user := newUser()
+		f := newFixture(user)
+		assert.NoError(t, f.service.SendVerification(ctx, user))
+		token := f.lastToken(t)
+
+		user.Email = "ana@new.example.com"
+		err := f.service.VerifyEmail(ctx, &request.VerifyEmail{Token: token})
+		assertErrorCode(t, err, http.StatusBadRequest, invalidVerificationTokenCode)
+		assert.Nil(t, user.EmailVerifiedAt)
+
+		// The link sent to the new email verifies it.
+		assert.NoError(t, f.service.SendVerification(ctx, user))
+		assert.NoError(t, f.service.VerifyEmail(ctx, &request.VerifyEmail{Token: f.lastToken(t)}))
+		assert.NotNil(t, user.EmailVerifiedAt)
+	})
+
+	t.Run("resend is throttled per address", func(t *testing.T) {
+		user := newUser()
+		f := newFixture(user)
+
+		assert.NoError(t, f.service.ResendVerification(ctx, &request.ResendVerification{Email: user.Email}))
+		assert.Len(t, f.mailer.sent, 1)
+
+		err := f.service.ResendVerification(ctx, &request.ResendVerification{Email: "ANA@example.com"})
+		assertErrorCode(t, err, http.StatusTooManyRequests, tooManyRequestsCode)
+		assert.Len(t, f.mailer.sent, 1)
+	})
+
+	t.Run("resend answers the same for unknown emails", func(t *testing.T) {
+		f := newFixture(newUser())
+
+		assert.NoError(t, f.service.ResendVerification(ctx, &request.ResendVerification{Email: "nobody@example.com"}))
+		err := f.service.ResendVerification(ctx, &request.ResendVerification{Email: "nobody@example.com"})
+		assertErrorCode(t, err, http.StatusTooManyRequests, tooManyRequestsCode)
+		assert.Empty(t, f.mailer.sent)
+	})
+
+	t.Run("verified users are not emailed again", func(t *testing.T) {
+		user := newUser()
+		verifiedAt := time.Now()
+		user.EmailVerifiedAt = &verifiedAt
+		f := newFixture(user)
+
+		assert.NoError(t, f.service.ResendVerification(ctx, &request.ResendVerification{Email: user.Email}))
+		assert.Empty(t, f.mailer.sent)
+	})
+}
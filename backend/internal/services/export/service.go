@@ -0,0 +1,324 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	libErrors "errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	utilCache "github.com/juanMaAV92/go-utils/cache"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/export"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/pricing"
+)
+
+const (
+	StatusPending = "PENDING"
+	StatusReady   = "READY"
+	StatusFailed  = "FAILED"
+
+	exportNotFoundCode = "EXPORT_NOT_FOUND"
+	exportNotReadyCode = "EXPORT_NOT_READY"
+	exportTooLargeCode = "EXPORT_TOO_LARGE"
+
+	exportKeyPrefix = "export:"
+	exportStep      = "export_build"
+	filenameLayout  = "20060102"
+	saveTimeout     = 5 * time.Second
+)
+
+var errArchiveTooLarge = libErrors.New("archive exceeds the maximum size")
+
+type assetRepository interface {
+	ListByUser(ctx context.Context, userID uint64) ([]entities.Asset, error)
+}
+
+type transactionRepository interface {
+	ListByAsset(ctx context.Context, assetID uint64) ([]entities.Transaction, error)
+}
+
+type priceRepository interface {
+	ListByAsset(ctx context.Context, assetID uint64, from, to time.Time) ([]entities.AssetPrice, error)
+}
+
+type cache interface {
+	Set(ctx context.Context, key string, value interface{}, opts ...utilCache.SetOption) error
+	Get(ctx context.Context, key string, destination interface{}) (bool, error)
+}
+
+type archiveStore interface {
+	Save(ctx context.Context, name string, content []byte) error
+	Load(ctx context.Context, name string) ([]byte, bool, error)
+	Purge(ctx context.Context, cutoff time.Time) error
+}
+
+// Limits configures the exports, see config.ExportConfig.
+type Limits struct {
+	SyncAssetLimit int
+	Timeout        time.Duration
+	LinkTTL        time.Duration
+	MaxArchiveSize int64
+}
+
+// job is a background export, kept in Redis until the link expires. Its
+// archive is kept in the archive store, which is purged of the expired ones.
+type job struct {
+	Code      uuid.UUID  `json:"code"`
+	UserID    uint64     `json:"user_id"`
+	Status    string     `json:"status"`
+	Filename  string     `json:"filename"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type service struct {
+	assetRepository       assetRepository
+	transactionRepository transactionRepository
+	priceRepository       priceRepository
+	cache                 cache
+	archives              archiveStore
+	logger                log.Logger
+	limits                Limits
+	downloadPath          string
+	now                   func() time.Time
+	// async runs the background exports, synchronously in tests.
+	async func(fn func())
+}
+
+// NewService builds the export service. downloadPath is the path of the export
+// routes, the download links being downloadPath/<code>/download.
+func NewService(assetRepo assetRepository, transactionRepo transactionRepository, priceRepo priceRepository, cache cache,
+	archives archiveStore, logger log.Logger, limits Limits, downloadPath string) *service {
+	return &service{
+		assetRepository:       assetRepo,
+		transactionRepository: transactionRepo,
+		priceRepository:       priceRepo,
+		cache:                 cache,
+		archives:              archives,
+		logger:                logger,
+		limits:                limits,
+		downloadPath:          downloadPath,
+		now:                   time.Now,
+		async:                 func(fn func()) { go fn() },
+	}
+}
+
+// Export returns the archive of the user's data when the account is small
+// enough to build it in the request. Larger accounts are exported in the
+// background, and the export to poll for its download link is returned
+// instead.
+func (s *service) Export(ctx context.Context, user *entities.User) (*response.ExportArchive, *response.Export, error) {
+	assets, err := s.assetRepository.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, nil, internalError()
+	}
+
+	now := s.now()
+	filename := "zenith-financial-export-" + now.UTC().Format(filenameLayout) + ".zip"
+	if len(assets) <= s.limits.SyncAssetLimit {
+		content, err := s.build(ctx, user, assets, now)
+		if libErrors.Is(err, errArchiveTooLarge) {
+			return nil, nil, tooLarge()
+		}
+		if err != nil {
+			s.logger.Error(ctx, exportStep, "error building export", log.Field("user_code", user.Code), log.Field("error", err))
+			return nil, nil, internalError()
+		}
+		return &response.ExportArchive{Filename: filename, Content: content}, nil, nil
+	}
+
+	pending := &job{
+		Code:      uuid.New(),
+		UserID:    user.ID,
+		Status:    StatusPending,
+		Filename:  filename,
+		CreatedAt: now,
+	}
+	if err := s.save(ctx, pending, s.limits.Timeout+s.limits.LinkTTL); err != nil {
+		return nil, nil, internalError()
+	}
+
+	// The export outlives the request that started it.
+	background := context.WithoutCancel(ctx)
+	owner := *user
+	s.async(func() { s.run(background, &owner, assets, pending) })
+
+	return nil, s.toResponse(pending), nil
+}
+
+// GetExport returns a background export of the user.
+func (s *service) GetExport(ctx context.Context, user *entities.User, code uuid.UUID) (*response.Export, error) {
+	found, err := s.load(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(found), nil
+}
+
+// Download returns the archive of a ready background export of the user, until
+// its link expires.
+func (s *service) Download(ctx context.Context, user *entities.User, code uuid.UUID) (*response.ExportArchive, error) {
+	found, err := s.load(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if found.Status != StatusReady {
+		return nil, errors.New(http.StatusConflict, exportNotReadyCode, []string{"Export is not ready"})
+	}
+	if found.ExpiresAt != nil && !s.now().Before(*found.ExpiresAt) {
+		return nil, notFound()
+	}
+
+	content, ok, err := s.archives.Load(ctx, archiveName(code))
+	if err != nil {
+		return nil, internalError()
+	}
+	if !ok {
+		return nil, notFound()
+	}
+	return &response.ExportArchive{Filename: found.Filename, Content: content}, nil
+}
+
+// run builds a background export. Nothing else would recover it, so a panic is
+// recovered here rather than crash the server, and fails the export.
+func (s *service) run(ctx context.Context, user *entities.User, assets []entities.Asset, exportJob *job) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error(ctx, exportStep, "export panicked", log.Field("export_code", exportJob.Code), log.Field("panic", fmt.Sprint(r)))
+			exportJob.Status = StatusFailed
+			s.finish(ctx, exportJob)
+		}
+	}()
+
+	buildCtx, cancel := context.WithTimeout(ctx, s.limits.Timeout)
+	defer cancel()
+
+	content, err := s.build(buildCtx, user, assets, exportJob.CreatedAt)
+	if err == nil {
+		err = s.archives.Save(buildCtx, archiveName(exportJob.Code), content)
+	}
+	if err != nil {
+		s.logger.Error(ctx, exportStep, "error building export", log.Field("export_code", exportJob.Code), log.Field("error", err))
+		exportJob.Status = StatusFailed
+	} else {
+		exportJob.Status = StatusReady
+		expiresAt := s.now().Add(s.limits.LinkTTL)
+		exportJob.ExpiresAt = &expiresAt
+	}
+	s.finish(ctx, exportJob)
+}
+
+// finish saves the final status of a background export. A build that timed out
+// used up its context, so the save gets one of its own.
+func (s *service) finish(ctx context.Context, exportJob *job) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), saveTimeout)
+	defer cancel()
+
+	if err := s.save(ctx, exportJob, s.limits.LinkTTL); err != nil {
+		s.logger.Error(ctx, exportStep, "error saving export", log.Field("export_code", exportJob.Code), log.Field("error", err))
+	}
+}
+
+// PurgeExpiredArchives removes the archives of the exports whose link expired.
+func (s *service) PurgeExpiredArchives(ctx context.Context) error {
+	return s.archives.Purge(ctx, s.now().Add(-s.limits.LinkTTL))
+}
+
+// build reads the data of the user's assets and writes it as an archive of at
+// most MaxArchiveSize bytes. Only the prices set by the user are exported, the
+// others come from the providers.
+func (s *service) build(ctx context.Context, user *entities.User, assets []entities.Asset, now time.Time) ([]byte, error) {
+	data := make([]export.AssetData, 0, len(assets))
+	for _, asset := range assets {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		transactions, err := s.transactionRepository.ListByAsset(ctx, asset.ID)
+		if err != nil {
+			return nil, err
+		}
+		prices, err := s.priceRepository.ListByAsset(ctx, asset.ID, time.Time{}, now)
+		if err != nil {
+			return nil, err
+		}
+		manual := make([]entities.AssetPrice, 0, len(prices))
+		for _, price := range prices {
+			if price.Source == pricing.ManualSource {
+				manual = append(manual, price)
+			}
+		}
+		data = append(data, export.AssetData{Asset: asset, Transactions: transactions, Prices: manual})
+	}
+
+	buffer := &boundedBuffer{limit: s.limits.MaxArchiveSize}
+	if err := export.WriteArchive(buffer, export.NewDocument(user, data, now)); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// boundedBuffer fails the writes that would take it past its limit.
+type boundedBuffer struct {
+	bytes.Buffer
+	limit int64
+}
+
+func (b *boundedBuffer) Write(p []byte) (int, error) {
+	if int64(b.Len()+len(p)) > b.limit {
+		return 0, errArchiveTooLarge
+	}
+	return b.Buffer.Write(p)
+}
+
+// load returns the export of the user with the code. Those of other users are
+// not found.
+func (s *service) load(ctx context.Context, user *entities.User, code uuid.UUID) (*job, error) {
+	var found job
+	ok, err := s.cache.Get(ctx, exportKeyPrefix+code.String(), &found)
+	if err != nil {
+		return nil, internalError()
+	}
+	if !ok || found.UserID != user.ID {
+		return nil, notFound()
+	}
+	return &found, nil
+}
+
+func (s *service) save(ctx context.Context, exportJob *job, ttl time.Duration) error {
+	return s.cache.Set(ctx, exportKeyPrefix+exportJob.Code.String(), exportJob, utilCache.WithTTL(ttl))
+}
+
+func (s *service) toResponse(exportJob *job) *response.Export {
+	result := &response.Export{
+		Code:      exportJob.Code,
+		Status:    exportJob.Status,
+		CreatedAt: exportJob.CreatedAt,
+		ExpiresAt: exportJob.ExpiresAt,
+	}
+	if exportJob.Status == StatusReady {
+		result.DownloadURL = s.downloadPath + "/" + exportJob.Code.String() + "/download"
+	}
+	return result
+}
+
+func archiveName(code uuid.UUID) string {
+	return code.String() + ".zip"
+}
+
+func notFound() error {
+	return errors.New(http.StatusNotFound, exportNotFoundCode, []string{"Export not found"})
+}
+
+func tooLarge() error {
+	return errors.New(http.StatusUnprocessableEntity, exportTooLargeCode, []string{"The export exceeds the maximum archive size"})
+}
+
+func internalError() error {
+	return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+}
@@ -0,0 +1,344 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	libErrors "errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	utilCache "github.com/juanMaAV92/go-utils/cache"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/export"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/pricing"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// memoryCache stores the values as JSON, like Redis does, ignoring their TTL.
+// Like Redis, it fails once the context is done.
+type memoryCache map[string][]byte
+
+func (c memoryCache) Set(ctx context.Context, key string, value interface{}, opts ...utilCache.SetOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c[key] = data
+	return nil
+}
+
+func (c memoryCache) Get(ctx context.Context, key string, destination interface{}) (bool, error) {
+	data, ok := c[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, destination)
+}
+
+// memoryArchives keeps the archives with the time they were saved.
+type memoryArchives struct {
+	content map[string][]byte
+	savedAt map[string]time.Time
+	now     func() time.Time
+}
+
+func (r *memoryArchives) Save(ctx context.Context, name string, content []byte) error {
+	r.content[name] = content
+	r.savedAt[name] = r.now()
+	return nil
+}
+
+func (r *memoryArchives) Load(ctx context.Context, name string) ([]byte, bool, error) {
+	content, ok := r.content[name]
+	return content, ok, nil
+}
+
+func (r *memoryArchives) Purge(ctx context.Context, cutoff time.Time) error {
+	for name, savedAt := range r.savedAt {
+		if savedAt.Before(cutoff) {
+			delete(r.content, name)
+			delete(r.savedAt, name)
+		}
+	}
+	return nil
+}
+
+type memoryAssets []entities.Asset
+
+func (r memoryAssets) ListByUser(ctx context.Context, userID uint64) ([]entities.Asset, error) {
+	var assets []entities.Asset
+	for _, asset := range r {
+		if asset.UserID == userID {
+			assets = append(assets, asset)
+		}
+	}
+	return assets, nil
+}
+
+type memoryTransactions map[uint64][]entities.Transaction
+
+func (r memoryTransactions) ListByAsset(ctx context.Context, assetID uint64) ([]entities.Transaction, error) {
+	return r[assetID], nil
+}
+
+type memoryPrices struct {
+	prices map[uint64][]entities.AssetPrice
+	err    error
+	panic  bool
+}
+
+func (r *memoryPrices) ListByAsset(ctx context.Context, assetID uint64, from, to time.Time) ([]entities.AssetPrice, error) {
+	if r.panic {
+		panic("prices unavailable")
+	}
+	return r.prices[assetID], r.err
+}
+
+type fixture struct {
+	service  *service
+	cache    memoryCache
+	archives *memoryArchives
+	prices   *memoryPrices
+	logger   *mocks.Logger
+	user     *entities.User
+	pending  []func()
+}
+
+func newFixture(assetCount int) *fixture {
+	user := &entities.User{ID: 1, Code: uuid.New(), Email: "ana@example.com", Currency: "USD", CostBasisMethod: "FIFO"}
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	var assets memoryAssets
+	transactions := memoryTransactions{}
+	prices := &memoryPrices{prices: map[uint64][]entities.AssetPrice{}}
+	for i := range assetCount {
+		id := uint64(i + 1)
+		assets = append(assets, entities.Asset{ID: id, Code: uuid.New(), UserID: user.ID, Currency: "USD"})
+		transactions[id] = []entities.Transaction{{Code: uuid.New(), AssetID: id, Type: entities.TransactionTypeBuy,
+			Units: decimal.NewFromInt(1), Total: decimal.NewFromInt(100)}}
+		prices.prices[id] = []entities.AssetPrice{
+			{AssetID: id, Date: now.AddDate(0, 0, -2), Price: decimal.NewFromInt(101), Source: pricing.ManualSource},
+			{AssetID: id, Date: now.AddDate(0, 0, -1), Price: decimal.NewFromInt(102), Source: "provider"},
+		}
+	}
+	// Assets of other users are never exported.
+	assets = append(assets, entities.Asset{ID: 99, Code: uuid.New(), UserID: 2})
+
+	f := &fixture{
+		cache:  memoryCache{},
+		prices: prices,
+		logger: new(mocks.Logger),
+		user:   user,
+	}
+	limits := Limits{SyncAssetLimit: 2, Timeout: time.Minute, LinkTTL: time.Hour, MaxArchiveSize: 1 << 20}
+	f.archives = &memoryArchives{content: map[string][]byte{}, savedAt: map[string]time.Time{}}
+	f.service = NewService(assets, transactions, prices, f.cache, f.archives, f.logger, limits, "/zenith-financial/v1/users/me/export")
+	f.service.now = func() time.Time { return now }
+	f.archives.now = func() time.Time { return f.service.now() }
+	f.service.async = func(fn func()) { f.pending = append(f.pending, fn) }
+	return f
+}
+
+// runPending runs the background exports started so far.
+func (f *fixture) runPending() {
+	for _, fn := range f.pending {
+		fn()
+	}
+	f.pending = nil
+}
+
+func Test_Export(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("small accounts are exported in the request", func(t *testing.T) {
+		f := newFixture(2)
+
+		archive, pending, err := f.service.Export(ctx, f.user)
+		assert.NoError(t, err)
+		assert.Nil(t, pending)
+		if !assert.NotNil(t, archive) {
+			return
+		}
+		assert.Equal(t, "zenith-financial-export-20250310.zip", archive.Filename)
+
+		doc, err := export.ReadArchive(archive.Content)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, f.user.Email, doc.Profile.Email)
+		assert.Len(t, doc.Assets, 2)
+		assert.Len(t, doc.Transactions, 2)
+		// Only the prices set by the user are exported.
+		assert.Len(t, doc.Prices, 2)
+		for _, price := range doc.Prices {
+			assert.True(t, price.Price.Equal(decimal.NewFromInt(101)))
+		}
+	})
+
+	t.Run("large accounts are exported in the background", func(t *testing.T) {
+		f := newFixture(3)
+
+		archive, pending, err := f.service.Export(ctx, f.user)
+		assert.NoError(t, err)
+		assert.Nil(t, archive)
+		if !assert.NotNil(t, pending) {
+			return
+		}
+		assert.Equal(t, StatusPending, pending.Status)
+		assert.Empty(t, pending.DownloadURL)
+
+		_, err = f.service.Download(ctx, f.user, pending.Code)
+		assertErrorCode(t, err, http.StatusConflict, exportNotReadyCode)
+
+		f.runPending()
+
+		ready, err := f.service.GetExport(ctx, f.user, pending.Code)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusReady, ready.Status)
+		assert.Equal(t, "/zenith-financial/v1/users/me/export/"+pending.Code.String()+"/download", ready.DownloadURL)
+		if assert.NotNil(t, ready.ExpiresAt) {
+			assert.Equal(t, f.service.now().Add(time.Hour), *ready.ExpiresAt)
+		}
+
+		downloaded, err := f.service.Download(ctx, f.user, pending.Code)
+		if !assert.NoError(t, err) {
+			return
+		}
+		doc, err := export.ReadArchive(downloaded.Content)
+		assert.NoError(t, err)
+		assert.Len(t, doc.Assets, 3)
+	})
+
+	t.Run("the exports of other users are not found", func(t *testing.T) {
+		f := newFixture(3)
+
+		_, pending, err := f.service.Export(ctx, f.user)
+		assert.NoError(t, err)
+		f.runPending()
+
+		other := &entities.User{ID: 2}
+		_, err = f.service.GetExport(ctx, other, pending.Code)
+		assertErrorCode(t, err, http.StatusNotFound, exportNotFoundCode)
+		_, err = f.service.Download(ctx, other, pending.Code)
+		assertErrorCode(t, err, http.StatusNotFound, exportNotFoundCode)
+	})
+
+	t.Run("expired archives are not found and purged", func(t *testing.T) {
+		f := newFixture(3)
+
+		_, pending, err := f.service.Export(ctx, f.user)
+		assert.NoError(t, err)
+		f.runPending()
+		// Only the job is kept in Redis, the archive is in the archive store.
+		assert.Len(t, f.cache, 1)
+		assert.Len(t, f.archives.content, 1)
+
+		now := f.service.now()
+		f.service.now = func() time.Time { return now.Add(time.Hour) }
+		_, err = f.service.Download(ctx, f.user, pending.Code)
+		assertErrorCode(t, err, http.StatusNotFound, exportNotFoundCode)
+
+		assert.NoError(t, f.service.PurgeExpiredArchives(ctx))
+		assert.Len(t, f.archives.content, 1)
+		f.service.now = func() time.Time { return now.Add(time.Hour + time.Second) }
+		assert.NoError(t, f.service.PurgeExpiredArchives(ctx))
+		assert.Empty(t, f.archives.content)
+	})
+
+	t.Run("missing archives are not found", func(t *testing.T) {
+		f := newFixture(3)
+
+		_, pending, err := f.service.Export(ctx, f.user)
+		assert.NoError(t, err)
+		f.runPending()
+		delete(f.archives.content, archiveName(pending.Code))
+
+		_, err = f.service.Download(ctx, f.user, pending.Code)
+		assertErrorCode(t, err, http.StatusNotFound, exportNotFoundCode)
+	})
+
+	t.Run("archives over the maximum size fail", func(t *testing.T) {
+		f := newFixture(2)
+		f.service.limits.MaxArchiveSize = 512
+
+		_, _, err := f.service.Export(ctx, f.user)
+		assertErrorCode(t, err, http.StatusUnprocessableEntity, exportTooLargeCode)
+
+		f = newFixture(3)
+		f.service.limits.MaxArchiveSize = 512
+		f.logger.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+		_, pending, err := f.service.Export(ctx, f.user)
+		assert.NoError(t, err)
+		f.runPending()
+
+		failed, err := f.service.GetExport(ctx, f.user, pending.Code)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusFailed, failed.Status)
+		assert.Empty(t, f.archives.content)
+	})
+
+	t.Run("failed background exports are reported", func(t *testing.T) {
+		f := newFixture(3)
+		f.prices.err = libErrors.New("connection reset")
+		f.logger.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+		_, pending, err := f.service.Export(ctx, f.user)
+		assert.NoError(t, err)
+		f.runPending()
+
+		failed, err := f.service.GetExport(ctx, f.user, pending.Code)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusFailed, failed.Status)
+		assert.Empty(t, failed.DownloadURL)
+		f.logger.AssertNumberOfCalls(t, "Error", 1)
+	})
+
+	t.Run("panicking background exports are saved as failed", func(t *testing.T) {
+		f := newFixture(3)
+		f.prices.panic = true
+		f.logger.On("Error", mock.Anything, exportStep, "export panicked", mock.Anything).Return()
+
+		_, pending, err := f.service.Export(ctx, f.user)
+		assert.NoError(t, err)
+		assert.NotPanics(t, f.runPending)
+
+		failed, err := f.service.GetExport(ctx, f.user, pending.Code)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusFailed, failed.Status)
+		f.logger.AssertExpectations(t)
+	})
+
+	t.Run("timed out background exports are saved as failed", func(t *testing.T) {
+		f := newFixture(3)
+		// A timeout in the past times the build out at once.
+		f.service.limits.Timeout = -time.Second
+		f.logger.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+		_, pending, err := f.service.Export(ctx, f.user)
+		assert.NoError(t, err)
+		f.runPending()
+
+		failed, err := f.service.GetExport(ctx, f.user, pending.Code)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusFailed, failed.Status)
+		f.logger.AssertNumberOfCalls(t, "Error", 1)
+	})
+}
+
+func assertErrorCode(t *testing.T, err error, httpCode int, code string) {
+	t.Helper()
+	errorResponse, ok := err.(*errors.ErrorResponse)
+	if assert.True(t, ok, "unexpected error %v", err) {
+		assert.Equal(t, httpCode, errorResponse.HttpCode)
+		assert.Equal(t, code, errorResponse.Code)
+	}
+}
@@ -0,0 +1,97 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ExchangeRateSource         = "exchangerate"
+	exchangeRateDefaultBaseURL = "https://open.er-api.com"
+)
+
+type exchangeRateProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewExchangeRateProvider uses the open ExchangeRate-API, which publishes daily
+// rates for fiat currencies, COP included, without an API key.
+func NewExchangeRateProvider(baseURL string, client *http.Client) *exchangeRateProvider {
+	if baseURL == "" {
+		baseURL = exchangeRateDefaultBaseURL
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &exchangeRateProvider{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+func (p *exchangeRateProvider) Name() string {
+	return ExchangeRateSource
+}
+
+type exchangeRateResponse struct {
+	Result             string                 `json:"result"`
+	ErrorType          string                 `json:"error-type"`
+	BaseCode           string                 `json:"base_code"`
+	TimeLastUpdateUnix int64                  `json:"time_last_update_unix"`
+	Rates              map[string]json.Number `json:"rates"`
+}
+
+func (p *exchangeRateProvider) GetRates(ctx context.Context, base string) (*Rates, error) {
+	endpoint := fmt.Sprintf("%s/v6/latest/%s", p.baseURL, strings.ToUpper(base))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("exchangerate: unexpected status %d", resp.StatusCode)
+	}
+
+	var body exchangeRateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("exchangerate: decoding response: %w", err)
+	}
+	if body.Result != "success" {
+		if body.ErrorType == "unsupported-code" {
+			return nil, ErrUnsupportedCurrency
+		}
+		return nil, fmt.Errorf("exchangerate: %s", body.ErrorType)
+	}
+
+	rates := make(map[string]decimal.Decimal, len(body.Rates))
+	for currency, rawRate := range body.Rates {
+		rate, err := decimal.NewFromString(rawRate.String())
+		if err != nil {
+			return nil, fmt.Errorf("exchangerate: parsing %s rate: %w", currency, err)
+		}
+		rates[strings.ToUpper(currency)] = rate
+	}
+
+	asOf := time.Now()
+	if body.TimeLastUpdateUnix > 0 {
+		asOf = time.Unix(body.TimeLastUpdateUnix, 0)
+	}
+	return &Rates{
+		Base:   strings.ToUpper(body.BaseCode),
+		Rates:  rates,
+		Source: p.Name(),
+		AsOf:   asOf,
+	}, nil
+}
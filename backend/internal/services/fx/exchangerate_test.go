@@ -0,0 +1,39 @@
+package fx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExchangeRateProvider_GetRates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v6/latest/USD":
+			w.Write([]byte(`{"result":"success","base_code":"USD","time_last_update_unix":1735689600,"rates":{"USD":1,"COP":4405.1234,"EUR":0.9612}}`))
+		case "/v6/latest/XXX":
+			w.Write([]byte(`{"result":"error","error-type":"unsupported-code"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewExchangeRateProvider(server.URL, server.Client())
+
+	rates, err := provider.GetRates(context.Background(), "usd")
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", rates.Base)
+	assert.True(t, decimal.RequireFromString("4405.1234").Equal(rates.Rates["COP"]))
+	assert.Equal(t, int64(1735689600), rates.AsOf.Unix())
+
+	_, err = provider.GetRates(context.Background(), "XXX")
+	assert.ErrorIs(t, err, ErrUnsupportedCurrency)
+
+	_, err = provider.GetRates(context.Background(), "EUR")
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+package fx
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FakeProvider is an in-memory RateProvider used by tests and local development.
+type FakeProvider struct {
+	mu    sync.Mutex
+	rates map[string]map[string]decimal.Decimal
+	err   error
+	calls int
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{rates: map[string]map[string]decimal.Decimal{}}
+}
+
+func (f *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (f *FakeProvider) SetRate(base, quote string, rate decimal.Decimal) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	if f.rates[base] == nil {
+		f.rates[base] = map[string]decimal.Decimal{base: decimal.NewFromInt(1)}
+	}
+	f.rates[base][quote] = rate
+}
+
+func (f *FakeProvider) SetError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+func (f *FakeProvider) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func (f *FakeProvider) GetRates(ctx context.Context, base string) (*Rates, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	base = strings.ToUpper(base)
+	rates, ok := f.rates[base]
+	if !ok {
+		return nil, ErrUnsupportedCurrency
+	}
+	copied := make(map[string]decimal.Decimal, len(rates))
+	for currency, rate := range rates {
+		copied[currency] = rate
+	}
+	return &Rates{Base: base, Rates: copied, Source: f.Name(), AsOf: time.Now()}, nil
+}
@@ -0,0 +1,28 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrRateNotFound        = errors.New("fx rate not found")
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+)
+
+// Rates are the units of each currency that one unit of Base buys.
+type Rates struct {
+	Base   string
+	Rates  map[string]decimal.Decimal
+	Source string
+	AsOf   time.Time
+}
+
+// RateProvider fetches the latest exchange rates from an external source.
+type RateProvider interface {
+	Name() string
+	GetRates(ctx context.Context, base string) (*Rates, error)
+}
@@ -0,0 +1,221 @@
+package fx
+
+import (
+	"context"
+	libErrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	DefaultPivotCurrency = "USD"
+
+	invalidConversionCode = "INVALID_CONVERSION"
+	rateNotFoundCode      = "FX_RATE_NOT_FOUND"
+)
+
+type rateRepository interface {
+	Upsert(ctx context.Context, rate *entities.FxRate) error
+	FindLatest(ctx context.Context, base, quote string, at time.Time) (*entities.FxRate, error)
+}
+
+// service converts amounts between currencies. Rates are stored against a pivot
+// currency and any other pair is triangulated through it.
+type service struct {
+	provider       RateProvider
+	rateRepository rateRepository
+	pivot          string
+	logger         log.Logger
+	now            func() time.Time
+}
+
+func NewService(provider RateProvider, rateRepo rateRepository, pivot string, logger log.Logger) *service {
+	if pivot == "" {
+		pivot = DefaultPivotCurrency
+	}
+	return &service{
+		provider:       provider,
+		rateRepository: rateRepo,
+		pivot:          strings.ToUpper(pivot),
+		logger:         logger,
+		now:            time.Now,
+	}
+}
+
+// Convert converts amount from one currency to another with the rate of the day of at.
+func (s *service) Convert(ctx context.Context, amount decimal.Decimal, from, to string, at time.Time) (decimal.Decimal, error) {
+	rate, err := s.Rate(ctx, from, to, at)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return amount.Mul(rate), nil
+}
+
+// Rate returns the units of to that one unit of from buys on the day of at.
+// Days without a stored rate use the most recent earlier one.
+func (s *service) Rate(ctx context.Context, from, to string, at time.Time) (decimal.Decimal, error) {
+	from, to = strings.ToUpper(strings.TrimSpace(from)), strings.ToUpper(strings.TrimSpace(to))
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+
+	fromRate, err := s.pivotRate(ctx, from, at)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	toRate, err := s.pivotRate(ctx, to, at)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return toRate.Div(fromRate), nil
+}
+
+// RefreshRates fetches the latest rates and stores today's rate of every currency.
+func (s *service) RefreshRates(ctx context.Context, currencies []string) error {
+	rates, err := s.provider.GetRates(ctx, s.pivot)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, currency := range currencies {
+		currency = strings.ToUpper(currency)
+		if currency == s.pivot {
+			continue
+		}
+		rate, ok := rates.Rates[currency]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %w", currency, ErrUnsupportedCurrency))
+			continue
+		}
+		if err := s.storeRate(ctx, currency, rate, rates.Source); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", currency, err))
+		}
+	}
+	return libErrors.Join(errs...)
+}
+
+func (s *service) ConvertAmount(ctx context.Context, req *request.ConvertAmount) (*response.Conversion, error) {
+	from, to := strings.ToUpper(strings.TrimSpace(req.From)), strings.ToUpper(strings.TrimSpace(req.To))
+
+	var messages []string
+	if !isCurrencyCode(from) {
+		messages = append(messages, "from must be a 3 letter currency code")
+	}
+	if !isCurrencyCode(to) {
+		messages = append(messages, "to must be a 3 letter currency code")
+	}
+	at := s.now()
+	if req.Date != "" {
+		date, err := time.Parse(time.DateOnly, req.Date)
+		if err != nil {
+			messages = append(messages, "date must have the format YYYY-MM-DD")
+		}
+		at = date
+	}
+	if len(messages) > 0 {
+		return nil, errors.New(http.StatusBadRequest, invalidConversionCode, messages)
+	}
+
+	rate, err := s.Rate(ctx, from, to, at)
+	if err != nil {
+		if libErrors.Is(err, ErrRateNotFound) || libErrors.Is(err, ErrUnsupportedCurrency) {
+			return nil, errors.New(http.StatusNotFound, rateNotFoundCode, []string{"Exchange rate not available"})
+		}
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	return &response.Conversion{
+		Amount:          req.Amount,
+		From:            from,
+		To:              to,
+		Date:            entities.PriceDate(at).Format(time.DateOnly),
+		Rate:            rate,
+		ConvertedAmount: req.Amount.Mul(rate),
+	}, nil
+}
+
+// pivotRate returns the units of currency one pivot unit buys. Only the latest
+// rates can be fetched, so past days rely on the stored history.
+func (s *service) pivotRate(ctx context.Context, currency string, at time.Time) (decimal.Decimal, error) {
+	if currency == s.pivot {
+		return decimal.NewFromInt(1), nil
+	}
+
+	today := entities.PriceDate(s.now())
+	day := entities.PriceDate(at)
+	if day.After(today) {
+		day = today
+	}
+
+	stored, err := s.rateRepository.FindLatest(ctx, s.pivot, currency, day)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if stored != nil && (day.Before(today) || entities.PriceDate(stored.Date).Equal(today)) {
+		return stored.Rate, nil
+	}
+	if day.Before(today) {
+		return decimal.Zero, fmt.Errorf("%s/%s on %s: %w", s.pivot, currency, day.Format(time.DateOnly), ErrRateNotFound)
+	}
+
+	rate, err := s.fetchRate(ctx, currency)
+	if err != nil {
+		if stored != nil {
+			s.logger.Warning(ctx, "fx_rate_fetch", "using previous rate", log.Field("currency", currency), log.Field("error", err))
+			return stored.Rate, nil
+		}
+		return decimal.Zero, err
+	}
+	return rate, nil
+}
+
+func (s *service) fetchRate(ctx context.Context, currency string) (decimal.Decimal, error) {
+	rates, err := s.provider.GetRates(ctx, s.pivot)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	rate, ok := rates.Rates[currency]
+	if !ok || !rate.IsPositive() {
+		return decimal.Zero, fmt.Errorf("%s: %w", currency, ErrUnsupportedCurrency)
+	}
+
+	if err := s.storeRate(ctx, currency, rate, rates.Source); err != nil {
+		s.logger.Error(ctx, "fx_rate_store", "error storing rate", log.Field("currency", currency), log.Field("error", err))
+	}
+	return rate, nil
+}
+
+func (s *service) storeRate(ctx context.Context, currency string, rate decimal.Decimal, source string) error {
+	now := s.now()
+	return s.rateRepository.Upsert(ctx, &entities.FxRate{
+		Base:      s.pivot,
+		Quote:     currency,
+		Date:      entities.PriceDate(now),
+		Rate:      rate,
+		Source:    source,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,268 @@
+package fx
+
+import (
+	"context"
+	libErrors "errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// memoryRateRepository keeps rates in memory with the same lookup rules as the
+// database repository.
+type memoryRateRepository struct {
+	rates   []entities.FxRate
+	upserts int
+}
+
+func (r *memoryRateRepository) Upsert(ctx context.Context, rate *entities.FxRate) error {
+	r.upserts++
+	for i := range r.rates {
+		if r.rates[i].Base == rate.Base && r.rates[i].Quote == rate.Quote && r.rates[i].Date.Equal(rate.Date) {
+			r.rates[i].Rate = rate.Rate
+			return nil
+		}
+	}
+	r.rates = append(r.rates, *rate)
+	return nil
+}
+
+func (r *memoryRateRepository) FindLatest(ctx context.Context, base, quote string, at time.Time) (*entities.FxRate, error) {
+	var latest *entities.FxRate
+	for i := range r.rates {
+		rate := &r.rates[i]
+		if rate.Base != base || rate.Quote != quote || rate.Date.After(at) {
+			continue
+		}
+		if latest == nil || rate.Date.After(latest.Date) {
+			latest = rate
+		}
+	}
+	return latest, nil
+}
+
+var today = time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC)
+
+func day(offset int) time.Time {
+	return entities.PriceDate(today).AddDate(0, 0, offset)
+}
+
+func storedRate(quote string, offset int, rate string) entities.FxRate {
+	return entities.FxRate{Base: "USD", Quote: quote, Date: day(offset), Rate: decimal.RequireFromString(rate)}
+}
+
+func newTestService(provider *FakeProvider, repo *memoryRateRepository) *service {
+	logger := new(mocks.Logger)
+	logger.On("Warning", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+	logger.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+	svc := NewService(provider, repo, "usd", logger)
+	svc.now = func() time.Time { return today }
+	return svc
+}
+
+func Test_Convert(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		name          string
+		amount        string
+		from          string
+		to            string
+		at            time.Time
+		stored        []entities.FxRate
+		providerRates map[string]string
+		providerErr   error
+		expected      string
+		expectedCalls int
+		expectedError error
+	}{
+		{
+			name:     "same currency",
+			amount:   "125.5",
+			from:     "cop",
+			to:       "COP",
+			at:       today,
+			expected: "125.5",
+		},
+		{
+			name:     "pivot to quote with stored rate of the day",
+			amount:   "10",
+			from:     "USD",
+			to:       "COP",
+			at:       today,
+			stored:   []entities.FxRate{storedRate("COP", 0, "4000")},
+			expected: "40000",
+		},
+		{
+			name:     "quote to pivot divides by the rate",
+			amount:   "8000",
+			from:     "COP",
+			to:       "USD",
+			at:       today,
+			stored:   []entities.FxRate{storedRate("COP", 0, "4000")},
+			expected: "2",
+		},
+		{
+			name:     "triangulates through the pivot",
+			amount:   "100",
+			from:     "EUR",
+			to:       "COP",
+			at:       today,
+			stored:   []entities.FxRate{storedRate("COP", 0, "4000"), storedRate("EUR", 0, "0.8")},
+			expected: "500000",
+		},
+		{
+			name:     "past day uses the most recent earlier rate",
+			amount:   "1",
+			from:     "USD",
+			to:       "COP",
+			at:       day(-3),
+			stored:   []entities.FxRate{storedRate("COP", -10, "3900"), storedRate("COP", -5, "3950"), storedRate("COP", -1, "4100")},
+			expected: "3950",
+		},
+		{
+			name:          "past day before any stored rate",
+			amount:        "1",
+			from:          "USD",
+			to:            "COP",
+			at:            day(-30),
+			stored:        []entities.FxRate{storedRate("COP", -5, "3950")},
+			expectedError: ErrRateNotFound,
+		},
+		{
+			name:          "missing rate of today is fetched and stored",
+			amount:        "2",
+			from:          "USD",
+			to:            "COP",
+			at:            today,
+			stored:        []entities.FxRate{storedRate("COP", -1, "3950")},
+			providerRates: map[string]string{"COP": "4010"},
+			expected:      "8020",
+			expectedCalls: 1,
+		},
+		{
+			name:          "provider errors fall back to the previous rate",
+			amount:        "1",
+			from:          "USD",
+			to:            "COP",
+			at:            today,
+			stored:        []entities.FxRate{storedRate("COP", -1, "3950")},
+			providerErr:   libErrors.New("timeout"),
+			expected:      "3950",
+			expectedCalls: 1,
+		},
+		{
+			name:          "currency unknown to the provider",
+			amount:        "1",
+			from:          "USD",
+			to:            "BTC",
+			at:            today,
+			providerRates: map[string]string{"COP": "4010"},
+			expectedCalls: 1,
+			expectedError: ErrUnsupportedCurrency,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewFakeProvider()
+			for currency, rate := range tc.providerRates {
+				provider.SetRate("USD", currency, decimal.RequireFromString(rate))
+			}
+			provider.SetError(tc.providerErr)
+			repo := &memoryRateRepository{rates: tc.stored}
+			svc := newTestService(provider, repo)
+
+			converted, err := svc.Convert(ctx, decimal.RequireFromString(tc.amount), tc.from, tc.to, tc.at)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, decimal.RequireFromString(tc.expected).Equal(converted), "converted: %s", converted)
+			}
+			assert.Equal(t, tc.expectedCalls, provider.Calls())
+		})
+	}
+}
+
+func Test_Convert_StoresFetchedRate(t *testing.T) {
+	provider := NewFakeProvider()
+	provider.SetRate("USD", "COP", decimal.NewFromInt(4000))
+	repo := &memoryRateRepository{}
+	svc := newTestService(provider, repo)
+
+	for i := 0; i < 3; i++ {
+		_, err := svc.Convert(context.Background(), decimal.NewFromInt(1), "USD", "COP", today)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, provider.Calls())
+	assert.Len(t, repo.rates, 1)
+	assert.Equal(t, day(0), repo.rates[0].Date)
+}
+
+func Test_RefreshRates(t *testing.T) {
+	provider := NewFakeProvider()
+	provider.SetRate("USD", "COP", decimal.NewFromInt(4000))
+	provider.SetRate("USD", "EUR", decimal.RequireFromString("0.9"))
+	repo := &memoryRateRepository{}
+	svc := newTestService(provider, repo)
+
+	err := svc.RefreshRates(context.Background(), []string{"cop", "EUR", "USD", "XAU"})
+
+	assert.ErrorIs(t, err, ErrUnsupportedCurrency)
+	assert.Equal(t, 2, repo.upserts)
+	assert.Equal(t, 1, provider.Calls())
+}
+
+func Test_ConvertAmount(t *testing.T) {
+	repo := &memoryRateRepository{rates: []entities.FxRate{storedRate("COP", -2, "4000")}}
+	svc := newTestService(NewFakeProvider(), repo)
+
+	testCases := []struct {
+		name          string
+		req           *request.ConvertAmount
+		expected      string
+		expectedError *errors.ErrorResponse
+	}{
+		{
+			name:     "converts with the rate of the date",
+			req:      &request.ConvertAmount{Amount: decimal.NewFromInt(12000), From: "cop", To: "usd", Date: day(-1).Format(time.DateOnly)},
+			expected: "3",
+		},
+		{
+			name:          "invalid currency and date",
+			req:           &request.ConvertAmount{Amount: decimal.NewFromInt(1), From: "PESOS", To: "USD", Date: "14/03/2025"},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusBadRequest, Code: invalidConversionCode},
+		},
+		{
+			name:          "rate not available",
+			req:           &request.ConvertAmount{Amount: decimal.NewFromInt(1), From: "COP", To: "USD", Date: day(-20).Format(time.DateOnly)},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusNotFound, Code: rateNotFoundCode},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := svc.ConvertAmount(context.Background(), tc.req)
+			if tc.expectedError != nil {
+				assert.Error(t, err)
+				errorResponse, ok := err.(*errors.ErrorResponse)
+				assert.True(t, ok)
+				assert.Equal(t, tc.expectedError.HttpCode, errorResponse.ErrorHTTPCode())
+				assert.Equal(t, tc.expectedError.Code, errorResponse.ErrorCode())
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, decimal.RequireFromString(tc.expected).Equal(resp.ConvertedAmount), "converted: %s", resp.ConvertedAmount)
+			}
+		})
+	}
+}
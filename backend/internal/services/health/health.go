@@ -1,13 +1,22 @@
 package health
 
-type service struct{}
+import "github.com/juanMaAV92/zenith-financial/backend/platform/scheduler"
 
-func NewService() *service {
-	return &service{}
+type jobStatusProvider interface {
+	Status() []scheduler.JobStatus
+}
+
+type service struct {
+	jobs jobStatusProvider
+}
+
+func NewService(jobs jobStatusProvider) *service {
+	return &service{jobs: jobs}
 }
 
 func (s *service) Check() HealthResponse {
 	return HealthResponse{
 		Status: "OK",
+		Jobs:   s.jobs.Status(),
 	}
 }
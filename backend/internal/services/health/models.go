@@ -1,5 +1,8 @@
 package health
 
+import "github.com/juanMaAV92/zenith-financial/backend/platform/scheduler"
+
 type HealthResponse struct {
-	Status string `json:"status"`
+	Status string                `json:"status"`
+	Jobs   []scheduler.JobStatus `json:"jobs,omitempty"`
 }
@@ -0,0 +1,256 @@
+package mfa
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/crypto"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/totp"
+)
+
+const (
+	mfaAlreadyEnabledCode = "MFA_ALREADY_ENABLED"
+	mfaNotEnrolledCode    = "MFA_NOT_ENROLLED"
+	mfaNotEnabledCode     = "MFA_NOT_ENABLED"
+	invalidMFACodeCode    = "INVALID_MFA_CODE"
+
+	issuer = "Zenith Financial"
+	// skew accepts the codes of the previous and next steps, for clocks that
+	// drifted a little.
+	skew = 1
+
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type userRepository interface {
+	Update(ctx context.Context, user *entities.User) error
+	AdvanceMFAStep(ctx context.Context, userID uint64, step int64) (bool, error)
+}
+
+type recoveryCodeRepository interface {
+	ReplaceForUser(ctx context.Context, userID uint64, codes []entities.RecoveryCode) error
+	GetByUserAndHash(ctx context.Context, userID uint64, codeHash string) (*entities.RecoveryCode, error)
+	Spend(ctx context.Context, code *entities.RecoveryCode, usedAt time.Time) (bool, error)
+	DeleteByUser(ctx context.Context, userID uint64) error
+}
+
+type secretCipher interface {
+	Encrypt(plaintext string) (string, error)
+	Decrypt(ciphertext string) (string, error)
+}
+
+type service struct {
+	userRepository         userRepository
+	recoveryCodeRepository recoveryCodeRepository
+	cipher                 secretCipher
+	logger                 log.Logger
+	now                    func() time.Time
+}
+
+func NewService(userRepo userRepository, recoveryCodeRepo recoveryCodeRepository, cipher secretCipher, logger log.Logger) *service {
+	return &service{
+		userRepository:         userRepo,
+		recoveryCodeRepository: recoveryCodeRepo,
+		cipher:                 cipher,
+		logger:                 logger,
+		now:                    time.Now,
+	}
+}
+
+// Enroll generates a new TOTP secret for the user. It is not required to log
+// in until it is confirmed with a code.
+func (s *service) Enroll(ctx context.Context, user *entities.User) (*response.MFAEnrollment, error) {
+	if user.MFAEnabled() {
+		return nil, errors.New(http.StatusConflict, mfaAlreadyEnabledCode, []string{"Two-factor authentication is already enabled"})
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	encrypted, err := s.cipher.Encrypt(secret)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	user.MFASecret = encrypted
+	user.MFALastStep = 0
+	user.UpdatedAt = s.now()
+	if err := s.userRepository.Update(ctx, user); err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	return &response.MFAEnrollment{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables the second factor with a code of the enrolled secret and
+// returns the recovery codes, which are not shown again.
+func (s *service) Confirm(ctx context.Context, user *entities.User, req *request.MFACode) (*response.MFARecoveryCodes, error) {
+	if user.MFAEnabled() {
+		return nil, errors.New(http.StatusConflict, mfaAlreadyEnabledCode, []string{"Two-factor authentication is already enabled"})
+	}
+	if user.MFASecret == "" {
+		return nil, errors.New(http.StatusBadRequest, mfaNotEnrolledCode, []string{"Two-factor authentication has not been enrolled"})
+	}
+
+	now := s.now()
+	step, ok, err := s.validateTOTP(user, normalize(req.Code), now)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if !ok {
+		return nil, errors.New(http.StatusBadRequest, invalidMFACodeCode, []string{"Invalid two-factor code"})
+	}
+	advanced, err := s.userRepository.AdvanceMFAStep(ctx, user.ID, step)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if !advanced {
+		return nil, errors.New(http.StatusBadRequest, invalidMFACodeCode, []string{"Invalid two-factor code"})
+	}
+
+	codes, records, err := generateRecoveryCodes(user.ID, now)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if err := s.recoveryCodeRepository.ReplaceForUser(ctx, user.ID, records); err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	user.MFAEnabledAt = &now
+	user.MFALastStep = step
+	user.UpdatedAt = now
+	if err := s.userRepository.Update(ctx, user); err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	return &response.MFARecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// Disable removes the second factor, which takes a current code as well.
+func (s *service) Disable(ctx context.Context, user *entities.User, req *request.MFACode) error {
+	if !user.MFAEnabled() {
+		return errors.New(http.StatusBadRequest, mfaNotEnabledCode, []string{"Two-factor authentication is not enabled"})
+	}
+	if err := s.Verify(ctx, user, req.Code); err != nil {
+		return err
+	}
+
+	user.MFASecret = ""
+	user.MFAEnabledAt = nil
+	user.MFALastStep = 0
+	user.UpdatedAt = s.now()
+	if err := s.userRepository.Update(ctx, user); err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if err := s.recoveryCodeRepository.DeleteByUser(ctx, user.ID); err != nil {
+		s.logger.Error(ctx, "mfa_disable", "error deleting recovery codes", log.Field("user_code", user.Code), log.Field("error", err))
+	}
+	return nil
+}
+
+// Verify checks the second factor of the user with a TOTP code or one of their
+// recovery codes. Codes cannot be used twice: a TOTP code is only accepted for
+// a later step than the last one, and recovery codes are spent.
+func (s *service) Verify(ctx context.Context, user *entities.User, code string) error {
+	code = normalize(code)
+	now := s.now()
+
+	if len(code) == recoveryCodeLength {
+		return s.useRecoveryCode(ctx, user, code, now)
+	}
+
+	step, ok, err := s.validateTOTP(user, code, now)
+	if err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if !ok {
+		return invalidCode()
+	}
+
+	// The step is only stored if it is still later than the stored one, so
+	// concurrent requests cannot both use the same code.
+	advanced, err := s.userRepository.AdvanceMFAStep(ctx, user.ID, step)
+	if err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if !advanced {
+		return invalidCode()
+	}
+	user.MFALastStep = step
+	return nil
+}
+
+func (s *service) validateTOTP(user *entities.User, code string, now time.Time) (int64, bool, error) {
+	secret, err := s.cipher.Decrypt(user.MFASecret)
+	if err != nil {
+		return 0, false, err
+	}
+	step, ok := totp.Validate(secret, code, now, skew)
+	if !ok || step <= user.MFALastStep {
+		return 0, false, nil
+	}
+	return step, true, nil
+}
+
+func (s *service) useRecoveryCode(ctx context.Context, user *entities.User, code string, now time.Time) error {
+	record, err := s.recoveryCodeRepository.GetByUserAndHash(ctx, user.ID, crypto.HashToken(code))
+	if err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if record == nil || record.UsedAt != nil {
+		return invalidCode()
+	}
+
+	spent, err := s.recoveryCodeRepository.Spend(ctx, record, now)
+	if err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if !spent {
+		return invalidCode()
+	}
+	s.logger.Warning(ctx, "mfa_recovery_code", "recovery code used", log.Field("user_code", user.Code))
+	return nil
+}
+
+// generateRecoveryCodes returns the codes to show, formatted as xxxxx-xxxxx,
+// and the records with their hashes.
+func generateRecoveryCodes(userID uint64, now time.Time) ([]string, []entities.RecoveryCode, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]entities.RecoveryCode, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		bytes := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(bytes); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryEncoding.EncodeToString(bytes))[:recoveryCodeLength]
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		records = append(records, entities.RecoveryCode{UserID: userID, CodeHash: crypto.HashToken(code), CreatedAt: now})
+	}
+	return codes, records, nil
+}
+
+// normalize lets codes be typed with spaces, dashes or in upper case.
+func normalize(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
+
+func invalidCode() error {
+	return errors.New(http.StatusUnauthorized, invalidMFACodeCode, []string{"Invalid two-factor code"})
+}
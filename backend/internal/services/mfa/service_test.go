@@ -0,0 +1,279 @@
+package mfa
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/crypto"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// memoryRecoveryCodes keeps the recovery codes by hash, like the database would.
+type memoryRecoveryCodes map[string]entities.RecoveryCode
+
+func (r memoryRecoveryCodes) ReplaceForUser(ctx context.Context, userID uint64, codes []entities.RecoveryCode) error {
+	_ = r.DeleteByUser(ctx, userID)
+	for _, code := range codes {
+		r[code.CodeHash] = code
+	}
+	return nil
+}
+
+func (r memoryRecoveryCodes) GetByUserAndHash(ctx context.Context, userID uint64, codeHash string) (*entities.RecoveryCode, error) {
+	code, ok := r[codeHash]
+	if !ok || code.UserID != userID {
+		return nil, nil
+	}
+	return &code, nil
+}
+
+func (r memoryRecoveryCodes) Spend(ctx context.Context, code *entities.RecoveryCode, usedAt time.Time) (bool, error) {
+	stored, ok := r[code.CodeHash]
+	if !ok || stored.UsedAt != nil {
+		return false, nil
+	}
+	stored.UsedAt = &usedAt
+	r[code.CodeHash] = stored
+	code.UsedAt = &usedAt
+	return true, nil
+}
+
+func (r memoryRecoveryCodes) DeleteByUser(ctx context.Context, userID uint64) error {
+	for hash, code := range r {
+		if code.UserID == userID {
+			delete(r, hash)
+		}
+	}
+	return nil
+}
+
+// staleRecoveryCodes reads the codes as they were before any was used, like a
+// request that read a code while another one was spending it.
+type staleRecoveryCodes struct {
+	memoryRecoveryCodes
+}
+
+func (r staleRecoveryCodes) GetByUserAndHash(ctx context.Context, userID uint64, codeHash string) (*entities.RecoveryCode, error) {
+	code, err := r.memoryRecoveryCodes.GetByUserAndHash(ctx, userID, codeHash)
+	if code != nil {
+		code.UsedAt = nil
+	}
+	return code, err
+}
+
+// memoryUsers keeps the last TOTP step of the users, like the database would.
+type memoryUsers map[uint64]int64
+
+func (r memoryUsers) Update(ctx context.Context, user *entities.User) error {
+	r[user.ID] = user.MFALastStep
+	return nil
+}
+
+func (r memoryUsers) AdvanceMFAStep(ctx context.Context, userID uint64, step int64) (bool, error) {
+	if step <= r[userID] {
+		return false, nil
+	}
+	r[userID] = step
+	return true, nil
+}
+
+type fixture struct {
+	service *service
+	codes   memoryRecoveryCodes
+	now     time.Time
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	cipher, err := crypto.NewCipher([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := new(mocks.Logger)
+	logger.On("Warning", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	f := &fixture{
+		codes: memoryRecoveryCodes{},
+		now:   time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC),
+	}
+	f.service = NewService(memoryUsers{}, f.codes, cipher, logger)
+	f.service.now = func() time.Time { return f.now }
+	return f
+}
+
+// code returns the TOTP code of the secret at the current time of the fixture.
+func (f *fixture) code(t *testing.T, secret string) string {
+	t.Helper()
+	code, err := totp.Code(secret, totp.Step(f.now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// enable enrolls and confirms the user, returning the secret and recovery codes.
+func (f *fixture) enable(t *testing.T, user *entities.User) (string, []string) {
+	t.Helper()
+	enrollment, err := f.service.Enroll(context.Background(), user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovery, err := f.service.Confirm(context.Background(), user, &request.MFACode{Code: f.code(t, enrollment.Secret)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enrollment.Secret, recovery.RecoveryCodes
+}
+
+func assertErrorCode(t *testing.T, err error, httpCode int, code string) {
+	t.Helper()
+	errorResponse, ok := err.(*errors.ErrorResponse)
+	if assert.True(t, ok, "unexpected error %v", err) {
+		assert.Equal(t, httpCode, errorResponse.ErrorHTTPCode())
+		assert.Equal(t, code, errorResponse.ErrorCode())
+	}
+}
+
+func Test_MFA(t *testing.T) {
+	ctx := context.Background()
+	newUser := func() *entities.User {
+		return &entities.User{ID: 1, Code: uuid.New(), Email: "ana@example.com"}
+	}
+
+	t.Run("enrollment stores the secret encrypted", func(t *testing.T) {
+		f := newFixture(t)
+		user := newUser()
+
+		enrollment, err := f.service.Enroll(ctx, user)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, enrollment.Secret)
+		assert.NotContains(t, user.MFASecret, enrollment.Secret)
+		assert.Contains(t, enrollment.OTPAuthURI, "otpauth://totp/")
+		assert.Contains(t, enrollment.OTPAuthURI, "secret="+enrollment.Secret)
+		// It is not enabled until confirmed.
+		assert.False(t, user.MFAEnabled())
+	})
+
+	t.Run("confirmation enables it and returns recovery codes", func(t *testing.T) {
+		f := newFixture(t)
+		user := newUser()
+
+		_, codes := f.enable(t, user)
+		assert.True(t, user.MFAEnabled())
+		assert.Equal(t, f.now, *user.MFAEnabledAt)
+		assert.Len(t, codes, recoveryCodeCount)
+		assert.Len(t, f.codes, recoveryCodeCount)
+		for _, code := range codes {
+			assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, code)
+			_, ok := f.codes[crypto.HashToken(strings.ReplaceAll(code, "-", ""))]
+			assert.True(t, ok)
+		}
+
+		_, err := f.service.Enroll(ctx, user)
+		assertErrorCode(t, err, http.StatusConflict, mfaAlreadyEnabledCode)
+	})
+
+	t.Run("confirmation rejects a wrong code", func(t *testing.T) {
+		f := newFixture(t)
+		user := newUser()
+
+		_, err := f.service.Confirm(ctx, user, &request.MFACode{Code: "123456"})
+		assertErrorCode(t, err, http.StatusBadRequest, mfaNotEnrolledCode)
+
+		_, err = f.service.Enroll(ctx, user)
+		assert.NoError(t, err)
+		_, err = f.service.Confirm(ctx, user, &request.MFACode{Code: "000000"})
+		assertErrorCode(t, err, http.StatusBadRequest, invalidMFACodeCode)
+		assert.False(t, user.MFAEnabled())
+	})
+
+	t.Run("a TOTP code cannot be used twice", func(t *testing.T) {
+		f := newFixture(t)
+		user := newUser()
+		secret, _ := f.enable(t, user)
+
+		// The code of the confirmation was already spent.
+		assertErrorCode(t, f.service.Verify(ctx, user, f.code(t, secret)), http.StatusUnauthorized, invalidMFACodeCode)
+
+		f.now = f.now.Add(30 * time.Second)
+		code := f.code(t, secret)
+		assert.NoError(t, f.service.Verify(ctx, user, code))
+		assertErrorCode(t, f.service.Verify(ctx, user, code), http.StatusUnauthorized, invalidMFACodeCode)
+	})
+
+	t.Run("a TOTP code used by two requests at once is accepted once", func(t *testing.T) {
+		f := newFixture(t)
+		user := newUser()
+		secret, _ := f.enable(t, user)
+		f.now = f.now.Add(30 * time.Second)
+
+		// Both requests loaded the user before either stored the step.
+		stale := *user
+		code := f.code(t, secret)
+		assert.NoError(t, f.service.Verify(ctx, user, code))
+		assertErrorCode(t, f.service.Verify(ctx, &stale, code), http.StatusUnauthorized, invalidMFACodeCode)
+	})
+
+	t.Run("codes of the next and previous steps are accepted", func(t *testing.T) {
+		f := newFixture(t)
+		user := newUser()
+		secret, _ := f.enable(t, user)
+
+		f.now = f.now.Add(time.Minute)
+		next, err := totp.Code(secret, totp.Step(f.now)+1)
+		assert.NoError(t, err)
+		assert.NoError(t, f.service.Verify(ctx, user, next))
+
+		f.now = f.now.Add(5 * time.Minute)
+		stale, err := totp.Code(secret, totp.Step(f.now)-2)
+		assert.NoError(t, err)
+		assertErrorCode(t, f.service.Verify(ctx, user, stale), http.StatusUnauthorized, invalidMFACodeCode)
+	})
+
+	t.Run("recovery codes work once", func(t *testing.T) {
+		f := newFixture(t)
+		user := newUser()
+		_, codes := f.enable(t, user)
+
+		assert.NoError(t, f.service.Verify(ctx, user, strings.ToUpper(codes[0])))
+		assertErrorCode(t, f.service.Verify(ctx, user, codes[0]), http.StatusUnauthorized, invalidMFACodeCode)
+		assert.NoError(t, f.service.Verify(ctx, user, codes[1]))
+	})
+
+	t.Run("a recovery code used by two requests at once is accepted once", func(t *testing.T) {
+		f := newFixture(t)
+		user := newUser()
+		_, codes := f.enable(t, user)
+		f.service.recoveryCodeRepository = staleRecoveryCodes{f.codes}
+
+		assert.NoError(t, f.service.Verify(ctx, user, codes[0]))
+		assertErrorCode(t, f.service.Verify(ctx, user, codes[0]), http.StatusUnauthorized, invalidMFACodeCode)
+	})
+
+	t.Run("disable requires a code and removes the recovery codes", func(t *testing.T) {
+		f := newFixture(t)
+		user := newUser()
+		secret, _ := f.enable(t, user)
+		f.now = f.now.Add(30 * time.Second)
+
+		assertErrorCode(t, f.service.Disable(ctx, user, &request.MFACode{Code: "000000"}), http.StatusUnauthorized, invalidMFACodeCode)
+		assert.True(t, user.MFAEnabled())
+
+		assert.NoError(t, f.service.Disable(ctx, user, &request.MFACode{Code: f.code(t, secret)}))
+		assert.False(t, user.MFAEnabled())
+		assert.Empty(t, user.MFASecret)
+		assert.Empty(t, f.codes)
+
+		assertErrorCode(t, f.service.Disable(ctx, user, &request.MFACode{Code: "123456"}), http.StatusBadRequest, mfaNotEnabledCode)
+	})
+}
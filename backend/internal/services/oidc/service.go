@@ -0,0 +1,304 @@
+package oidc
+
+import (
+	"context"
+	libErrors "errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	utilCache "github.com/juanMaAV92/go-utils/cache"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/costbasis"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/crypto"
+	oidcProvider "github.com/juanMaAV92/zenith-financial/backend/utils/oidc"
+)
+
+const (
+	stateKeyPrefix = "oidc_state:"
+
+	unknownProviderCode     = "UNKNOWN_OIDC_PROVIDER"
+	providerUnavailableCode = "OIDC_PROVIDER_UNAVAILABLE"
+	invalidStateCode        = "INVALID_OIDC_STATE"
+	loginFailedCode         = "OIDC_LOGIN_FAILED"
+	emailNotVerifiedCode    = "OIDC_EMAIL_NOT_VERIFIED"
+	accountDeletedCode      = "ACCOUNT_DELETED"
+
+	maxUsernameBaseLength = 20
+	usernameSuffixLength  = 8
+)
+
+type providerRegistry interface {
+	AuthCodeURL(ctx context.Context, provider, state, nonce, challenge string) (string, error)
+	Exchange(ctx context.Context, provider, code, verifier, nonce string) (*oidcProvider.Claims, error)
+}
+
+type userRepository interface {
+	Create(ctx context.Context, user *entities.User) error
+	GetByID(ctx context.Context, id uint64) (*entities.User, error)
+	GetByEmail(ctx context.Context, email string) (*entities.User, error)
+	EmailTaken(ctx context.Context, email string) (bool, error)
+}
+
+type identityRepository interface {
+	Create(ctx context.Context, identity *entities.ExternalIdentity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*entities.ExternalIdentity, error)
+}
+
+type apiKeyRepository interface {
+	RevokeByUser(ctx context.Context, userID uint64, revokedAt time.Time) error
+}
+
+type recoveryCodeRepository interface {
+	DeleteByUser(ctx context.Context, userID uint64) error
+}
+
+type cache interface {
+	Set(ctx context.Context, key string, value interface{}, opts ...utilCache.SetOption) error
+	Get(ctx context.Context, key string, destination interface{}) (bool, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type loginManager interface {
+	LoginExternal(ctx context.Context, user *entities.User, deviceLabel, userAgent, ipAddress string) (*response.UserLogin, error)
+	InvalidateTokens(ctx context.Context, user *entities.User) error
+}
+
+// pendingLogin is kept between Authorize and Callback, by the hash of its
+// state. The PKCE verifier never leaves the server.
+type pendingLogin struct {
+	Provider string `json:"provider"`
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+}
+
+type service struct {
+	providers              providerRegistry
+	userRepository         userRepository
+	identityRepository     identityRepository
+	apiKeyRepository       apiKeyRepository
+	recoveryCodeRepository recoveryCodeRepository
+	cache                  cache
+	logins                 loginManager
+	logger                 log.Logger
+	stateTTL               time.Duration
+	now                    func() time.Time
+}
+
+// NewService builds the OpenID Connect login service. A login has stateTTL to
+// come back from the provider.
+func NewService(providers providerRegistry, userRepo userRepository, identityRepo identityRepository, apiKeyRepo apiKeyRepository,
+	recoveryCodeRepo recoveryCodeRepository, cache cache, logins loginManager, logger log.Logger, stateTTL time.Duration) *service {
+	return &service{
+		providers:              providers,
+		userRepository:         userRepo,
+		identityRepository:     identityRepo,
+		apiKeyRepository:       apiKeyRepo,
+		recoveryCodeRepository: recoveryCodeRepo,
+		cache:                  cache,
+		logins:                 logins,
+		logger:                 logger,
+		stateTTL:               stateTTL,
+		now:                    time.Now,
+	}
+}
+
+// Authorize starts a login with the authorization code flow and PKCE, and
+// returns the URL of the provider to send the user to.
+func (s *service) Authorize(ctx context.Context, provider string) (*response.OIDCAuthorization, error) {
+	state, err := crypto.GenerateToken()
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	nonce, err := crypto.GenerateToken()
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	verifier, challenge, err := oidcProvider.NewPKCE()
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	authURL, err := s.providers.AuthCodeURL(ctx, provider, state, nonce, challenge)
+	if libErrors.Is(err, oidcProvider.ErrUnknownProvider) {
+		return nil, errors.New(http.StatusNotFound, unknownProviderCode, []string{"Unknown identity provider"})
+	}
+	if err != nil {
+		s.logger.Error(ctx, "oidc_authorize", "error reaching identity provider", log.Field("provider", provider), log.Field("error", err))
+		return nil, errors.New(http.StatusBadGateway, providerUnavailableCode, []string{"Identity provider is unavailable"})
+	}
+
+	pending := pendingLogin{Provider: provider, Verifier: verifier, Nonce: nonce}
+	if err := s.cache.Set(ctx, stateKey(state), pending, utilCache.WithTTL(s.stateTTL)); err != nil {
+		s.logger.Error(ctx, "oidc_authorize", "error storing login state", log.Field("provider", provider), log.Field("error", err))
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	return &response.OIDCAuthorization{AuthorizationURL: authURL}, nil
+}
+
+// Callback completes a login started by Authorize. The state is spent on first
+// use. The identity is linked to the user with its verified email, who is
+// created on their first login.
+func (s *service) Callback(ctx context.Context, req *request.OIDCCallback) (*response.UserLogin, error) {
+	var pending pendingLogin
+	found, err := s.cache.Get(ctx, stateKey(req.State), &pending)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if !found || pending.Provider != req.Provider {
+		return nil, errors.New(http.StatusBadRequest, invalidStateCode, []string{"Invalid or expired login state"})
+	}
+	if err := s.cache.Delete(ctx, stateKey(req.State)); err != nil {
+		s.logger.Error(ctx, "oidc_state_delete", "error deleting login state", log.Field("provider", req.Provider), log.Field("error", err))
+	}
+
+	claims, err := s.providers.Exchange(ctx, req.Provider, req.Code, pending.Verifier, pending.Nonce)
+	if err != nil {
+		s.logger.Error(ctx, "oidc_exchange", "error verifying identity", log.Field("provider", req.Provider), log.Field("error", err))
+		return nil, errors.New(http.StatusUnauthorized, loginFailedCode, []string{"Unable to verify the identity with the provider"})
+	}
+
+	user, err := s.resolveUser(ctx, req.Provider, claims)
+	if err != nil {
+		return nil, err
+	}
+	return s.logins.LoginExternal(ctx, user, req.DeviceLabel, req.UserAgent, req.IPAddress)
+}
+
+// resolveUser returns the user linked to the identity, linking it first to the
+// user with its email, or to a new user, when the provider verified the email.
+func (s *service) resolveUser(ctx context.Context, provider string, claims *oidcProvider.Claims) (*entities.User, error) {
+	identity, err := s.identityRepository.GetByProviderSubject(ctx, provider, claims.Subject)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if identity != nil {
+		user, err := s.userRepository.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+		}
+		if user == nil {
+			return nil, errors.New(http.StatusUnauthorized, loginFailedCode, []string{"Unable to verify the identity with the provider"})
+		}
+		return user, nil
+	}
+
+	email := strings.TrimSpace(claims.Email)
+	if email == "" || !claims.EmailVerified {
+		return nil, errors.New(http.StatusForbidden, emailNotVerifiedCode, []string{"The identity provider has not verified the email address"})
+	}
+
+	user, err := s.userRepository.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if user == nil {
+		user, err = s.createUser(ctx, provider, claims.Subject, email)
+	} else if user.EmailVerifiedAt == nil {
+		err = s.claimUnverifiedUser(ctx, user)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	identity = &entities.ExternalIdentity{
+		UserID:    user.ID,
+		Provider:  provider,
+		Subject:   claims.Subject,
+		Email:     email,
+		CreatedAt: s.now(),
+	}
+	if err := s.identityRepository.Create(ctx, identity); err != nil {
+		s.logger.Error(ctx, "oidc_identity_link", "error linking identity", log.Field("user_code", user.Code), log.Field("error", err))
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	return user, nil
+}
+
+// createUser registers the user of a first login. They have no password until
+// they set one with a password reset. A deleted account keeps its email until
+// it is purged, so the email cannot be registered again before then.
+func (s *service) createUser(ctx context.Context, provider, subject, email string) (*entities.User, error) {
+	taken, err := s.userRepository.EmailTaken(ctx, email)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if taken {
+		return nil, errors.New(http.StatusConflict, accountDeletedCode, []string{"The account with this email is pending deletion"})
+	}
+
+	now := s.now()
+	user := &entities.User{
+		Code:            uuid.New(),
+		Username:        username(provider, subject, email),
+		Email:           email,
+		CostBasisMethod: string(costbasis.DefaultMethod),
+		EmailVerifiedAt: &now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.userRepository.Create(ctx, user); err != nil {
+		s.logger.Error(ctx, "oidc_user_create", "error creating user", log.Field("provider", provider), log.Field("error", err))
+		return nil, errors.New(http.StatusInternalServerError, "CREATE_USER_ERROR", []string{"Unable to create user"})
+	}
+	return user, nil
+}
+
+// claimUnverifiedUser hands an account whose email was never verified to the
+// owner the provider vouches for. Whoever registered it may not own the email,
+// so every way in they could have set up is removed: the password, the second
+// factor with its recovery codes, the API keys and the sessions.
+func (s *service) claimUnverifiedUser(ctx context.Context, user *entities.User) error {
+	now := s.now()
+	// The keys and codes go before the email is verified, as a verified
+	// account is not claimed again if a later step fails.
+	if err := s.apiKeyRepository.RevokeByUser(ctx, user.ID, now); err != nil {
+		s.logger.Error(ctx, "oidc_user_claim", "error revoking the API keys of unverified user", log.Field("user_code", user.Code), log.Field("error", err))
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if err := s.recoveryCodeRepository.DeleteByUser(ctx, user.ID); err != nil {
+		s.logger.Error(ctx, "oidc_user_claim", "error deleting the recovery codes of unverified user", log.Field("user_code", user.Code), log.Field("error", err))
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	user.EmailVerifiedAt = &now
+	user.PasswordHash = ""
+	user.PasswordSalt = ""
+	user.MFASecret = ""
+	user.MFAEnabledAt = nil
+	user.MFALastStep = 0
+	user.UpdatedAt = now
+	if err := s.logins.InvalidateTokens(ctx, user); err != nil {
+		s.logger.Error(ctx, "oidc_user_claim", "error securing unverified user", log.Field("user_code", user.Code), log.Field("error", err))
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	return nil
+}
+
+// username derives a username from the email, with a suffix of the identity
+// so users with the same email name do not collide.
+func username(provider, subject, email string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 0x80 && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_') {
+			return r
+		}
+		return -1
+	}, strings.SplitN(email, "@", 2)[0])
+	if name == "" {
+		name = "user"
+	}
+	if len(name) > maxUsernameBaseLength {
+		name = name[:maxUsernameBaseLength]
+	}
+	return name + "-" + crypto.HashToken(provider + ":" + subject)[:usernameSuffixLength]
+}
+
+func stateKey(state string) string {
+	return stateKeyPrefix + crypto.HashToken(state)
+}
@@ -0,0 +1,321 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	utilCache "github.com/juanMaAV92/go-utils/cache"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	oidcProvider "github.com/juanMaAV92/zenith-financial/backend/utils/oidc"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const providerName = "test"
+
+// memoryCache stores the values as JSON, like Redis does, ignoring their TTL.
+type memoryCache map[string][]byte
+
+func (c memoryCache) Set(ctx context.Context, key string, value interface{}, opts ...utilCache.SetOption) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c[key] = data
+	return nil
+}
+
+func (c memoryCache) Get(ctx context.Context, key string, destination interface{}) (bool, error) {
+	data, ok := c[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, destination)
+}
+
+func (c memoryCache) Delete(ctx context.Context, key string) error {
+	delete(c, key)
+	return nil
+}
+
+// memoryUsers keeps the users by ID, like the database would, and hides the
+// deleted ones but from EmailTaken.
+type memoryUsers map[uint64]*entities.User
+
+func (r memoryUsers) Create(ctx context.Context, user *entities.User) error {
+	user.ID = uint64(len(r) + 1)
+	r[user.ID] = user
+	return nil
+}
+
+func (r memoryUsers) GetByID(ctx context.Context, id uint64) (*entities.User, error) {
+	if user := r[id]; user != nil && !user.IsDeleted() {
+		return user, nil
+	}
+	return nil, nil
+}
+
+func (r memoryUsers) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+	for _, user := range r {
+		if user.Email == email && !user.IsDeleted() {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r memoryUsers) EmailTaken(ctx context.Context, email string) (bool, error) {
+	for _, user := range r {
+		if user.Email == email {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type memoryIdentities []entities.ExternalIdentity
+
+func (r *memoryIdentities) Create(ctx context.Context, identity *entities.ExternalIdentity) error {
+	*r = append(*r, *identity)
+	return nil
+}
+
+func (r *memoryIdentities) GetByProviderSubject(ctx context.Context, provider, subject string) (*entities.ExternalIdentity, error) {
+	for _, identity := range *r {
+		if identity.Provider == provider && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, nil
+}
+
+// fakeLogins records the user a session was started for and bumps the token
+// version of those whose tokens are invalidated.
+type fakeLogins struct {
+	user *entities.User
+}
+
+func (f *fakeLogins) LoginExternal(ctx context.Context, user *entities.User, deviceLabel, userAgent, ipAddress string) (*response.UserLogin, error) {
+	f.user = user
+	return &response.UserLogin{User: response.ToUserResponse(user)}, nil
+}
+
+func (f *fakeLogins) InvalidateTokens(ctx context.Context, user *entities.User) error {
+	user.TokenVersion++
+	return nil
+}
+
+// fakeCredentials records the users whose API keys were revoked and whose
+// recovery codes were deleted.
+type fakeCredentials struct {
+	revokedKeys  []uint64
+	deletedCodes []uint64
+}
+
+func (f *fakeCredentials) RevokeByUser(ctx context.Context, userID uint64, revokedAt time.Time) error {
+	f.revokedKeys = append(f.revokedKeys, userID)
+	return nil
+}
+
+func (f *fakeCredentials) DeleteByUser(ctx context.Context, userID uint64) error {
+	f.deletedCodes = append(f.deletedCodes, userID)
+	return nil
+}
+
+type fixture struct {
+	server      *oidctest.Server
+	service     *service
+	users       memoryUsers
+	identities  *memoryIdentities
+	credentials *fakeCredentials
+	logins      *fakeLogins
+	logger      *mocks.Logger
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	server := oidctest.NewServer()
+	t.Cleanup(server.Close)
+
+	registry := oidcProvider.NewRegistry(map[string]oidcProvider.Config{
+		providerName: {
+			Issuer:       server.Issuer(),
+			ClientID:     oidctest.ClientID,
+			ClientSecret: oidctest.ClientSecret,
+			RedirectURL:  "http://localhost:3000/auth/callback/test",
+		},
+	}, http.DefaultClient)
+
+	f := &fixture{
+		server:      server,
+		users:       memoryUsers{},
+		identities:  &memoryIdentities{},
+		credentials: &fakeCredentials{},
+		logins:      &fakeLogins{},
+		logger:      new(mocks.Logger),
+	}
+	f.service = NewService(registry, f.users, f.identities, f.credentials, f.credentials, memoryCache{}, f.logins, f.logger, 10*time.Minute)
+	return f
+}
+
+// authorize runs the flow up to the redirect back from the provider, as the
+// frontend and the user would, and returns the callback request.
+func (f *fixture) authorize(t *testing.T, identity oidctest.Identity) *request.OIDCCallback {
+	t.Helper()
+	authorization, err := f.service.Authorize(context.Background(), providerName)
+	assert.NoError(t, err)
+	code, state, err := f.server.Authorize(authorization.AuthorizationURL, identity)
+	assert.NoError(t, err)
+	return &request.OIDCCallback{Provider: providerName, Code: code, State: state}
+}
+
+func assertErrorCode(t *testing.T, err error, httpCode int, code string) {
+	t.Helper()
+	errorResponse, ok := err.(*errors.ErrorResponse)
+	if assert.True(t, ok, err) {
+		assert.Equal(t, httpCode, errorResponse.ErrorHTTPCode())
+		assert.Equal(t, code, errorResponse.ErrorCode())
+	}
+}
+
+func Test_Callback_CreatesUserOnFirstLogin(t *testing.T) {
+	f := newFixture(t)
+	identity := oidctest.Identity{Subject: "sub-1", Email: "juan.ma@mail.com", EmailVerified: true}
+
+	_, err := f.service.Callback(context.Background(), f.authorize(t, identity))
+	assert.NoError(t, err)
+
+	assert.Len(t, f.users, 1)
+	user := f.users[1]
+	assert.Equal(t, "juan.ma@mail.com", user.Email)
+	assert.Regexp(t, `^juan\.ma-[0-9a-f]{8}$`, user.Username)
+	assert.NotNil(t, user.EmailVerifiedAt)
+	assert.Empty(t, user.PasswordHash)
+	assert.Equal(t, user, f.logins.user)
+	assert.Equal(t, []entities.ExternalIdentity{{UserID: 1, Provider: providerName, Subject: "sub-1", Email: "juan.ma@mail.com", CreatedAt: (*f.identities)[0].CreatedAt}}, []entities.ExternalIdentity(*f.identities))
+
+	// The next login finds the user by the identity, even if the email changed.
+	identity.Email = "other@mail.com"
+	_, err = f.service.Callback(context.Background(), f.authorize(t, identity))
+	assert.NoError(t, err)
+	assert.Len(t, f.users, 1)
+	assert.Len(t, *f.identities, 1)
+	assert.Equal(t, user, f.logins.user)
+}
+
+func Test_Callback_LinksUserByVerifiedEmail(t *testing.T) {
+	f := newFixture(t)
+	verifiedAt := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	existing := &entities.User{Email: "juan@mail.com", PasswordHash: "hash", EmailVerifiedAt: &verifiedAt}
+	_ = f.users.Create(context.Background(), existing)
+
+	_, err := f.service.Callback(context.Background(), f.authorize(t, oidctest.Identity{Subject: "sub-1", Email: "juan@mail.com", EmailVerified: true}))
+	assert.NoError(t, err)
+
+	assert.Len(t, f.users, 1)
+	assert.Equal(t, existing, f.logins.user)
+	assert.Equal(t, "hash", existing.PasswordHash)
+	assert.Equal(t, existing.ID, (*f.identities)[0].UserID)
+}
+
+func Test_Callback_ClaimsUnverifiedUser(t *testing.T) {
+	f := newFixture(t)
+	enabledAt := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	existing := &entities.User{Email: "juan@mail.com", PasswordHash: "hash", PasswordSalt: "salt", TokenVersion: 2,
+		MFASecret: "secret", MFAEnabledAt: &enabledAt, MFALastStep: 42}
+	_ = f.users.Create(context.Background(), existing)
+
+	_, err := f.service.Callback(context.Background(), f.authorize(t, oidctest.Identity{Subject: "sub-1", Email: "juan@mail.com", EmailVerified: true}))
+	assert.NoError(t, err)
+
+	assert.Equal(t, existing, f.logins.user)
+	assert.NotNil(t, existing.EmailVerifiedAt)
+	assert.Empty(t, existing.PasswordHash)
+	assert.Empty(t, existing.PasswordSalt)
+	assert.Equal(t, 3, existing.TokenVersion)
+	// The second factor and the API keys of whoever registered it go too.
+	assert.False(t, existing.MFAEnabled())
+	assert.Empty(t, existing.MFASecret)
+	assert.Zero(t, existing.MFALastStep)
+	assert.Equal(t, []uint64{existing.ID}, f.credentials.deletedCodes)
+	assert.Equal(t, []uint64{existing.ID}, f.credentials.revokedKeys)
+}
+
+func Test_Callback_RejectsEmailOfDeletedAccount(t *testing.T) {
+	f := newFixture(t)
+	deletedAt := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	_ = f.users.Create(context.Background(), &entities.User{Email: "juan@mail.com", DeletedAt: &deletedAt})
+
+	_, err := f.service.Callback(context.Background(), f.authorize(t, oidctest.Identity{Subject: "sub-1", Email: "juan@mail.com", EmailVerified: true}))
+	assertErrorCode(t, err, http.StatusConflict, accountDeletedCode)
+	assert.Len(t, f.users, 1)
+	assert.Empty(t, *f.identities)
+	assert.Nil(t, f.logins.user)
+}
+
+func Test_Callback_RequiresVerifiedEmail(t *testing.T) {
+	f := newFixture(t)
+	_ = f.users.Create(context.Background(), &entities.User{Email: "juan@mail.com"})
+
+	_, err := f.service.Callback(context.Background(), f.authorize(t, oidctest.Identity{Subject: "sub-1", Email: "juan@mail.com"}))
+	assertErrorCode(t, err, http.StatusForbidden, emailNotVerifiedCode)
+	assert.Empty(t, *f.identities)
+	assert.Nil(t, f.logins.user)
+}
+
+func Test_Callback_RejectsInvalidState(t *testing.T) {
+	f := newFixture(t)
+	identity := oidctest.Identity{Subject: "sub-1", Email: "juan@mail.com", EmailVerified: true}
+
+	t.Run("unknown state", func(t *testing.T) {
+		req := f.authorize(t, identity)
+		req.State = "forged"
+		_, err := f.service.Callback(context.Background(), req)
+		assertErrorCode(t, err, http.StatusBadRequest, invalidStateCode)
+	})
+
+	t.Run("state of another provider", func(t *testing.T) {
+		req := f.authorize(t, identity)
+		req.Provider = "other"
+		_, err := f.service.Callback(context.Background(), req)
+		assertErrorCode(t, err, http.StatusBadRequest, invalidStateCode)
+	})
+
+	t.Run("replayed state", func(t *testing.T) {
+		req := f.authorize(t, identity)
+		_, err := f.service.Callback(context.Background(), req)
+		assert.NoError(t, err)
+		_, err = f.service.Callback(context.Background(), req)
+		assertErrorCode(t, err, http.StatusBadRequest, invalidStateCode)
+	})
+}
+
+func Test_Callback_RejectsCodeOfAnotherLogin(t *testing.T) {
+	f := newFixture(t)
+	f.logger.On("Error", mock.Anything, "oidc_exchange", mock.Anything, mock.Anything).Return()
+	identity := oidctest.Identity{Subject: "sub-1", Email: "juan@mail.com", EmailVerified: true}
+
+	// The code was issued for the PKCE challenge of the first login, so the
+	// verifier of the second one cannot redeem it.
+	first := f.authorize(t, identity)
+	second := f.authorize(t, identity)
+	second.Code = first.Code
+
+	_, err := f.service.Callback(context.Background(), second)
+	assertErrorCode(t, err, http.StatusUnauthorized, loginFailedCode)
+	assert.Empty(t, f.users)
+}
+
+func Test_Authorize_UnknownProvider(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.service.Authorize(context.Background(), "missing")
+	assertErrorCode(t, err, http.StatusNotFound, unknownProviderCode)
+}
@@ -0,0 +1,188 @@
+package passwordreset
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/platform/mailer"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/crypto"
+)
+
+const (
+	invalidResetTokenCode = "INVALID_RESET_TOKEN"
+
+	resetPasswordPath = "/reset-password"
+	resetSubject      = "Reset your Zenith Financial password"
+	resetBody         = `Someone asked to reset the password of your Zenith Financial account.
+
+Open this link to choose a new one, it expires in %d minutes:
+%s
+
+If it was not you, ignore this email: your password has not changed.`
+)
+
+type userRepository interface {
+	GetByEmail(ctx context.Context, email string) (*entities.User, error)
+	GetByID(ctx context.Context, id uint64) (*entities.User, error)
+}
+
+type resetRepository interface {
+	Create(ctx context.Context, reset *entities.PasswordReset) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entities.PasswordReset, error)
+	Spend(ctx context.Context, reset *entities.PasswordReset, usedAt time.Time) (bool, error)
+}
+
+type tokenInvalidator interface {
+	InvalidateTokens(ctx context.Context, user *entities.User) error
+}
+
+type loginUnlocker interface {
+	Reset(ctx context.Context, email string) error
+}
+
+type service struct {
+	userRepository  userRepository
+	resetRepository resetRepository
+	tokens          tokenInvalidator
+	unlocker        loginUnlocker
+	mailer          mailer.Mailer
+	logger          log.Logger
+	frontendURL     string
+	ttl             time.Duration
+	now             func() time.Time
+	// async sends the reset links, synchronously in tests.
+	async func(fn func())
+}
+
+func NewService(userRepo userRepository, resetRepo resetRepository, tokens tokenInvalidator, unlocker loginUnlocker, mailer mailer.Mailer,
+	logger log.Logger, frontendURL string, ttl time.Duration) *service {
+	return &service{
+		userRepository:  userRepo,
+		resetRepository: resetRepo,
+		tokens:          tokens,
+		unlocker:        unlocker,
+		mailer:          mailer,
+		logger:          logger,
+		frontendURL:     strings.TrimRight(frontendURL, "/"),
+		ttl:             ttl,
+		now:             time.Now,
+		async:           func(fn func()) { go fn() },
+	}
+}
+
+// ForgotPassword emails a reset link when the email belongs to a user. The
+// result is the same whether it does or not: the link is sent in the
+// background, so the answer takes as long either way, and its failures are
+// only logged.
+func (s *service) ForgotPassword(ctx context.Context, req *request.ForgotPassword) error {
+	if strings.TrimSpace(req.Email) == "" {
+		return errors.New(http.StatusBadRequest, errors.StatusBadRequestCode, []string{"email is required"})
+	}
+
+	user, err := s.userRepository.GetByEmail(ctx, req.Email)
+	if err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if user == nil {
+		return nil
+	}
+
+	background := context.WithoutCancel(ctx)
+	s.async(func() { s.sendResetLink(background, user) })
+	return nil
+}
+
+func (s *service) sendResetLink(ctx context.Context, user *entities.User) {
+	token, err := crypto.GenerateToken()
+	if err != nil {
+		s.logger.Error(ctx, "password_reset_request", "error generating reset token", log.Field("user_code", user.Code), log.Field("error", err))
+		return
+	}
+
+	now := s.now()
+	reset := &entities.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: crypto.HashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if err := s.resetRepository.Create(ctx, reset); err != nil {
+		s.logger.Error(ctx, "password_reset_request", "error storing reset token", log.Field("user_code", user.Code), log.Field("error", err))
+		return
+	}
+
+	link := fmt.Sprintf("%s%s?token=%s", s.frontendURL, resetPasswordPath, token)
+	message := mailer.Message{
+		To:      user.Email,
+		Subject: resetSubject,
+		Body:    fmt.Sprintf(resetBody, int(s.ttl.Minutes()), link),
+	}
+	if err := s.mailer.Send(ctx, message); err != nil {
+		s.logger.Error(ctx, "password_reset_request", "error sending reset email", log.Field("user_code", user.Code), log.Field("error", err))
+	}
+}
+
+// ResetPassword sets a new password with a reset token. The token can only be
+// used once, every session of the user is ended and a login lockout of the
+// account is lifted.
+func (s *service) ResetPassword(ctx context.Context, req *request.ResetPassword) error {
+	if req.Token == "" || req.Password == "" {
+		return errors.New(http.StatusBadRequest, errors.StatusBadRequestCode, []string{"token and password are required"})
+	}
+
+	reset, err := s.resetRepository.GetByTokenHash(ctx, crypto.HashToken(req.Token))
+	if err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	now := s.now()
+	if reset == nil || !reset.IsUsable(now) {
+		return invalidResetToken()
+	}
+
+	user, err := s.userRepository.GetByID(ctx, reset.UserID)
+	if err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if user == nil {
+		return invalidResetToken()
+	}
+
+	// Spent before the password changes, a failure below asks for a new link
+	// rather than leaving the token usable. Of concurrent requests with the
+	// same token only the first one spends it.
+	spent, err := s.resetRepository.Spend(ctx, reset, now)
+	if err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if !spent {
+		return invalidResetToken()
+	}
+
+	hashedPassword, err := crypto.HashPassword(req.Password)
+	if err != nil {
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	user.PasswordHash = hashedPassword
+	user.PasswordSalt = ""
+	user.UpdatedAt = now
+	if err := s.tokens.InvalidateTokens(ctx, user); err != nil {
+		s.logger.Error(ctx, "password_reset", "error saving the new password", log.Field("user_code", user.Code), log.Field("error", err))
+		return errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if err := s.unlocker.Reset(ctx, user.Email); err != nil {
+		s.logger.Error(ctx, "password_reset", "error lifting the login lockout", log.Field("user_code", user.Code), log.Field("error", err))
+	}
+	return nil
+}
+
+func invalidResetToken() error {
+	return errors.New(http.StatusBadRequest, invalidResetTokenCode, []string{"Invalid or expired reset token"})
+}
@@ -0,0 +1,256 @@
+package passwordreset
+
+import (
+	"context"
+	libErrors "errors"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/platform/mailer"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var linkToken = regexp.MustCompile(`https://app\.zenith\.test/reset-password\?token=([A-Za-z0-9_-]+)`)
+
+// memoryResetRepository keeps the resets in memory, like the database would.
+type memoryResetRepository map[string]entities.PasswordReset
+
+func (r memoryResetRepository) Create(ctx context.Context, reset *entities.PasswordReset) error {
+	r[reset.TokenHash] = *reset
+	return nil
+}
+
+func (r memoryResetRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.PasswordReset, error) {
+	reset, ok := r[tokenHash]
+	if !ok {
+		return nil, nil
+	}
+	return &reset, nil
+}
+
+func (r memoryResetRepository) Spend(ctx context.Context, reset *entities.PasswordReset, usedAt time.Time) (bool, error) {
+	stored, ok := r[reset.TokenHash]
+	if !ok || stored.UsedAt != nil {
+		return false, nil
+	}
+	stored.UsedAt = &usedAt
+	r[reset.TokenHash] = stored
+	reset.UsedAt = &usedAt
+	return true, nil
+}
+
+// racingResetRepository lets another request use the token right after it is
+// read.
+type racingResetRepository struct {
+	memoryResetRepository
+}
+
+func (r racingResetRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.PasswordReset, error) {
+	reset, err := r.memoryResetRepository.GetByTokenHash(ctx, tokenHash)
+	if reset != nil {
+		if _, err := r.Spend(ctx, &entities.PasswordReset{TokenHash: tokenHash}, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	return reset, err
+}
+
+type fakeMailer struct {
+	sent []mailer.Message
+	err  error
+}
+
+func (m *fakeMailer) Send(ctx context.Context, message mailer.Message) error {
+	m.sent = append(m.sent, message)
+	return m.err
+}
+
+// fakeInvalidator records the users whose tokens were invalidated.
+type fakeInvalidator struct {
+	users []entities.User
+}
+
+func (f *fakeInvalidator) InvalidateTokens(ctx context.Context, user *entities.User) error {
+	f.users = append(f.users, *user)
+	return nil
+}
+
+// fakeUnlocker records the emails unlocked.
+type fakeUnlocker struct {
+	emails []string
+}
+
+func (f *fakeUnlocker) Reset(ctx context.Context, email string) error {
+	f.emails = append(f.emails, email)
+	return nil
+}
+
+type fixture struct {
+	service     *service
+	resets      memoryResetRepository
+	mailer      *fakeMailer
+	invalidator *fakeInvalidator
+	unlocker    *fakeUnlocker
+	logger      *mocks.Logger
+	now         time.Time
+}
+
+func newFixture(user *entities.User) *fixture {
+	userRepository := new(mocks.UserRepository)
+	userRepository.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+	userRepository.On("GetByEmail", mock.Anything, mock.Anything).Return((*entities.User)(nil), nil)
+	userRepository.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	f := &fixture{
+		resets:      memoryResetRepository{},
+		mailer:      &fakeMailer{},
+		invalidator: &fakeInvalidator{},
+		unlocker:    &fakeUnlocker{},
+		logger:      new(mocks.Logger),
+		now:         time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC),
+	}
+	f.service = NewService(userRepository, f.resets, f.invalidator, f.unlocker, f.mailer, f.logger, "https://app.zenith.test/", 30*time.Minute)
+	f.service.now = func() time.Time { return f.now }
+	f.service.async = func(fn func()) { fn() }
+	return f
+}
+
+// requestToken asks for a reset and returns the token of the link emailed.
+func (f *fixture) requestToken(t *testing.T, email string) string {
+	t.Helper()
+	assert.NoError(t, f.service.ForgotPassword(context.Background(), &request.ForgotPassword{Email: email}))
+	if !assert.NotEmpty(t, f.mailer.sent) {
+		t.FailNow()
+	}
+	match := linkToken.FindStringSubmatch(f.mailer.sent[len(f.mailer.sent)-1].Body)
+	if !assert.Len(t, match, 2) {
+		t.FailNow()
+	}
+	return match[1]
+}
+
+func assertErrorCode(t *testing.T, err error, httpCode int, code string) {
+	t.Helper()
+	errorResponse, ok := err.(*errors.ErrorResponse)
+	if assert.True(t, ok, "unexpected error %v", err) {
+		assert.Equal(t, httpCode, errorResponse.ErrorHTTPCode())
+		assert.Equal(t, code, errorResponse.ErrorCode())
+	}
+}
+
+func Test_PasswordReset(t *testing.T) {
+	ctx := context.Background()
+	newUser := func() *entities.User {
+		return &entities.User{ID: 1, Code: uuid.New(), Email: "ana@example.com", PasswordHash: "old-hash", PasswordSalt: "old-salt"}
+	}
+
+	t.Run("emails a single-use token and stores only its hash", func(t *testing.T) {
+		user := newUser()
+		f := newFixture(user)
+
+		token := f.requestToken(t, user.Email)
+		assert.Equal(t, user.Email, f.mailer.sent[0].To)
+		assert.Contains(t, f.mailer.sent[0].Body, "30 minutes")
+		reset, ok := f.resets[crypto.HashToken(token)]
+		if assert.True(t, ok) {
+			assert.Equal(t, user.ID, reset.UserID)
+			assert.Equal(t, f.now.Add(30*time.Minute), reset.ExpiresAt)
+		}
+
+		assert.NoError(t, f.service.ResetPassword(ctx, &request.ResetPassword{Token: token, Password: "n3w-Passw0rd"}))
+		if assert.Len(t, f.invalidator.users, 1) {
+			saved := f.invalidator.users[0]
+			valid, needsRehash := crypto.VerifyPassword("n3w-Passw0rd", saved.PasswordSalt, saved.PasswordHash)
+			assert.True(t, valid)
+			assert.False(t, needsRehash)
+			assert.Empty(t, saved.PasswordSalt)
+		}
+		assert.Equal(t, []string{user.Email}, f.unlocker.emails)
+
+		err := f.service.ResetPassword(ctx, &request.ResetPassword{Token: token, Password: "another-one"})
+		assertErrorCode(t, err, http.StatusBadRequest, invalidResetTokenCode)
+		assert.Len(t, f.invalidator.users, 1)
+	})
+
+	t.Run("unknown emails get the same answer and no email", func(t *testing.T) {
+		f := newFixture(newUser())
+
+		assert.NoError(t, f.service.ForgotPassword(ctx, &request.ForgotPassword{Email: "nobody@example.com"}))
+		assert.Empty(t, f.mailer.sent)
+		assert.Empty(t, f.resets)
+	})
+
+	t.Run("the link is sent after the answer", func(t *testing.T) {
+		user := newUser()
+		f := newFixture(user)
+		var pending []func()
+		f.service.async = func(fn func()) { pending = append(pending, fn) }
+
+		assert.NoError(t, f.service.ForgotPassword(ctx, &request.ForgotPassword{Email: user.Email}))
+		assert.Empty(t, f.mailer.sent)
+		assert.Empty(t, f.resets)
+
+		if assert.Len(t, pending, 1) {
+			pending[0]()
+		}
+		assert.Len(t, f.mailer.sent, 1)
+		assert.Len(t, f.resets, 1)
+	})
+
+	t.Run("mailer failures are not reported", func(t *testing.T) {
+		user := newUser()
+		f := newFixture(user)
+		f.mailer.err = libErrors.New("smtp down")
+		f.logger.On("Error", mock.Anything, "password_reset_request", "error sending reset email", mock.Anything).Return()
+
+		assert.NoError(t, f.service.ForgotPassword(ctx, &request.ForgotPassword{Email: user.Email}))
+		f.logger.AssertExpectations(t)
+	})
+
+	t.Run("expired tokens are rejected", func(t *testing.T) {
+		user := newUser()
+		f := newFixture(user)
+		token := f.requestToken(t, user.Email)
+
+		f.now = f.now.Add(31 * time.Minute)
+		err := f.service.ResetPassword(ctx, &request.ResetPassword{Token: token, Password: "n3w-Passw0rd"})
+		assertErrorCode(t, err, http.StatusBadRequest, invalidResetTokenCode)
+		assert.Empty(t, f.invalidator.users)
+	})
+
+	t.Run("a token used by a concurrent request is rejected", func(t *testing.T) {
+		user := newUser()
+		f := newFixture(user)
+		token := f.requestToken(t, user.Email)
+		f.service.resetRepository = racingResetRepository{f.resets}
+
+		err := f.service.ResetPassword(ctx, &request.ResetPassword{Token: token, Password: "n3w-Passw0rd"})
+		assertErrorCode(t, err, http.StatusBadRequest, invalidResetTokenCode)
+		assert.Empty(t, f.invalidator.users)
+	})
+
+	t.Run("unknown tokens are rejected", func(t *testing.T) {
+		f := newFixture(newUser())
+
+		err := f.service.ResetPassword(ctx, &request.ResetPassword{Token: "made-up", Password: "n3w-Passw0rd"})
+		assertErrorCode(t, err, http.StatusBadRequest, invalidResetTokenCode)
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		f := newFixture(newUser())
+
+		err := f.service.ForgotPassword(ctx, &request.ForgotPassword{Email: " "})
+		assertErrorCode(t, err, http.StatusBadRequest, errors.StatusBadRequestCode)
+		err = f.service.ResetPassword(ctx, &request.ResetPassword{Token: "token"})
+		assertErrorCode(t, err, http.StatusBadRequest, errors.StatusBadRequestCode)
+	})
+}
@@ -0,0 +1,135 @@
+package performance
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/pricehistory"
+	"github.com/shopspring/decimal"
+)
+
+var errPriceUnavailable = errors.New("price unavailable")
+
+// position is the history of one asset, rebuilt from its ledger and its price
+// history.
+type position struct {
+	asset        *entities.Asset
+	transactions []entities.Transaction
+	prices       []entities.AssetPrice
+}
+
+func (p *position) unitsAt(day time.Time) decimal.Decimal {
+	units := decimal.Zero
+	for _, transaction := range p.transactions {
+		if entities.PriceDate(transaction.CreatedAt).After(day) {
+			break
+		}
+		if transaction.IsInflow() {
+			units = units.Add(transaction.Units)
+		} else {
+			units = units.Sub(transaction.Units)
+		}
+	}
+	return units
+}
+
+// priceAt returns the last known unit price on or before the day. Without a
+// price history the price paid in the last transaction is used instead.
+func (p *position) priceAt(day time.Time) (decimal.Decimal, string, bool) {
+	if price, ok := pricehistory.PriceAt(p.prices, day); ok {
+		return price.Price, price.Currency, true
+	}
+
+	var last *entities.Transaction
+	for i := range p.transactions {
+		if entities.PriceDate(p.transactions[i].CreatedAt).After(day) {
+			break
+		}
+		if p.transactions[i].Units.IsPositive() {
+			last = &p.transactions[i]
+		}
+	}
+	if last == nil {
+		return decimal.Zero, "", false
+	}
+	return last.Total.Div(last.Units), last.Currency, true
+}
+
+// cashFlow is the money that moved into (positive) or out of (negative) the
+// position. Purchases include their fees and sales are net of them.
+func cashFlow(transaction entities.Transaction) decimal.Decimal {
+	if transaction.IsInflow() {
+		return transaction.Total.Add(transaction.FeeTotal)
+	}
+	return transaction.Total.Sub(transaction.FeeTotal).Neg()
+}
+
+// rates memoizes the exchange rates to the base currency by currency and day.
+type rates struct {
+	converter    converter
+	baseCurrency string
+	cache        map[string]decimal.Decimal
+}
+
+func (r *rates) rate(ctx context.Context, currency string, day time.Time) (decimal.Decimal, error) {
+	key := currency + day.Format(time.DateOnly)
+	if rate, ok := r.cache[key]; ok {
+		return rate, nil
+	}
+	rate, err := r.converter.Convert(ctx, decimal.NewFromInt(1), currency, r.baseCurrency, day)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	r.cache[key] = rate
+	return rate, nil
+}
+
+// history holds the value of a position at the end of each day of interest and
+// the cash flows of those days, in the base currency.
+type history struct {
+	values map[time.Time]decimal.Decimal
+	flows  map[time.Time]decimal.Decimal
+}
+
+// buildHistory values the position on every day. Flows are only kept after the
+// first day, they are part of the starting value.
+func (p *position) buildHistory(ctx context.Context, rates *rates, days []time.Time) (*history, error) {
+	result := &history{
+		values: make(map[time.Time]decimal.Decimal, len(days)),
+		flows:  map[time.Time]decimal.Decimal{},
+	}
+
+	for _, day := range days {
+		units := p.unitsAt(day)
+		if units.IsZero() {
+			result.values[day] = decimal.Zero
+			continue
+		}
+		price, currency, ok := p.priceAt(day)
+		if !ok {
+			return nil, errPriceUnavailable
+		}
+		rate, err := rates.rate(ctx, currency, day)
+		if err != nil {
+			return nil, err
+		}
+		result.values[day] = units.Mul(price).Mul(rate)
+	}
+
+	first, last := days[0], days[len(days)-1]
+	for _, transaction := range p.transactions {
+		day := entities.PriceDate(transaction.CreatedAt)
+		if !day.After(first) || day.After(last) {
+			continue
+		}
+		rate, err := rates.rate(ctx, transaction.Currency, day)
+		if err != nil {
+			return nil, err
+		}
+		result.flows[day] = result.flows[day].Add(cashFlow(transaction).Mul(rate))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,328 @@
+package performance
+
+import (
+	"context"
+	libErrors "errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/costbasis"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/performance"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	GroupByPortfolio = "portfolio"
+	GroupByCategory  = "category"
+	GroupByAsset     = "asset"
+
+	invalidGroupByCode = "INVALID_GROUP_BY"
+	invalidRangeCode   = "INVALID_DATE_RANGE"
+
+	defaultBaseCurrency = "USD"
+	defaultRangeDays    = 365
+	maxRangeDays        = 366 * 5
+
+	reasonPriceUnavailable = "price unavailable"
+	reasonRateUnavailable  = "exchange rate unavailable"
+
+	noteNoCapital      = "time-weighted return needs capital invested during the period"
+	noteNoCashFlows    = "money-weighted return needs both contributions and a value to compare against"
+	noteNoConvergence  = "money-weighted return did not converge for these cash flows"
+	noteNotAnnualized  = "returns are annualized only for periods of a year or more"
+	portfolioGroupName = "Portfolio"
+)
+
+type assetRepository interface {
+	ListByUser(ctx context.Context, userID uint64) ([]entities.Asset, error)
+}
+
+type transactionRepository interface {
+	ListByAsset(ctx context.Context, assetID uint64) ([]entities.Transaction, error)
+}
+
+type priceRepository interface {
+	ListByAsset(ctx context.Context, assetID uint64, from, to time.Time) ([]entities.AssetPrice, error)
+}
+
+type converter interface {
+	Convert(ctx context.Context, amount decimal.Decimal, from, to string, at time.Time) (decimal.Decimal, error)
+}
+
+type service struct {
+	assetRepository       assetRepository
+	transactionRepository transactionRepository
+	priceRepository       priceRepository
+	converter             converter
+	logger                log.Logger
+	now                   func() time.Time
+}
+
+func NewService(assetRepo assetRepository, transactionRepo transactionRepository, priceRepo priceRepository, converter converter, logger log.Logger) *service {
+	return &service{
+		assetRepository:       assetRepo,
+		transactionRepository: transactionRepo,
+		priceRepository:       priceRepo,
+		converter:             converter,
+		logger:                logger,
+		now:                   time.Now,
+	}
+}
+
+// group is a set of positions whose values and flows are added together.
+type group struct {
+	key     string
+	name    string
+	history []*history
+}
+
+// GetPerformance computes the time-weighted and money-weighted returns of the
+// user's assets over a period, using the ledger as cash flows and the price
+// history for valuations. Assets that cannot be valued are reported apart.
+func (s *service) GetPerformance(ctx context.Context, user *entities.User, req *request.PortfolioPerformance) (*response.PortfolioPerformance, error) {
+	groupBy := strings.ToLower(strings.TrimSpace(req.GroupBy))
+	if groupBy == "" {
+		groupBy = GroupByPortfolio
+	}
+	if groupBy != GroupByPortfolio && groupBy != GroupByCategory && groupBy != GroupByAsset {
+		return nil, errors.New(http.StatusBadRequest, invalidGroupByCode, []string{"group_by must be one of portfolio, category or asset"})
+	}
+
+	from, to, err := parseRange(req.From, req.To, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	baseCurrency := user.Currency
+	if baseCurrency == "" {
+		baseCurrency = defaultBaseCurrency
+	}
+
+	positions, err := s.loadPositions(ctx, user.ID, from, to)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	result := &response.PortfolioPerformance{
+		Currency:       baseCurrency,
+		From:           from.Format(time.DateOnly),
+		To:             to.Format(time.DateOnly),
+		GroupBy:        groupBy,
+		Groups:         []*response.PerformanceGroup{},
+		UnvaluedAssets: []*response.UnvaluedAsset{},
+	}
+
+	days := valuationDays(positions, from, to)
+	rates := &rates{converter: s.converter, baseCurrency: baseCurrency, cache: map[string]decimal.Decimal{}}
+	groups := map[string]*group{}
+	var keys []string
+
+	for _, position := range positions {
+		history, err := position.buildHistory(ctx, rates, days)
+		if err != nil {
+			result.UnvaluedAssets = append(result.UnvaluedAssets, s.unvalued(ctx, position.asset, err))
+			continue
+		}
+
+		key, name := groupKey(position.asset, groupBy)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{key: key, name: name}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.history = append(g.history, history)
+	}
+
+	if groupBy == GroupByPortfolio && len(groups) == 0 {
+		groups[GroupByPortfolio] = &group{key: GroupByPortfolio, name: portfolioGroupName}
+		keys = append(keys, GroupByPortfolio)
+	}
+
+	for _, key := range keys {
+		result.Groups = append(result.Groups, groupPerformance(groups[key], days))
+	}
+	sort.SliceStable(result.Groups, func(i, j int) bool {
+		return result.Groups[i].EndValue.GreaterThan(result.Groups[j].EndValue)
+	})
+
+	return result, nil
+}
+
+func (s *service) loadPositions(ctx context.Context, userID uint64, from, to time.Time) ([]*position, error) {
+	assets, err := s.assetRepository.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]*position, 0, len(assets))
+	for i := range assets {
+		transactions, err := s.transactionRepository.ListByAsset(ctx, assets[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		prices, err := s.priceRepository.ListByAsset(ctx, assets[i].ID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		// Units the asset was created with have no transaction of their own.
+		ledger := costbasis.Ledger(&assets[i], transactions)
+		positions = append(positions, &position{asset: &assets[i], transactions: ledger, prices: prices})
+	}
+	return positions, nil
+}
+
+func (s *service) unvalued(ctx context.Context, asset *entities.Asset, err error) *response.UnvaluedAsset {
+	s.logger.Warning(ctx, "portfolio_performance", "error valuing asset history", log.Field("asset_id", asset.ID), log.Field("error", err))
+	reason := reasonRateUnavailable
+	if libErrors.Is(err, errPriceUnavailable) {
+		reason = reasonPriceUnavailable
+	}
+	return &response.UnvaluedAsset{Code: asset.Code, Name: asset.Name, Reason: reason}
+}
+
+// valuationDays are the start and end of the period plus every day with a
+// transaction in between, the boundaries of the time-weighted sub-periods.
+func valuationDays(positions []*position, from, to time.Time) []time.Time {
+	seen := map[time.Time]bool{from: true, to: true}
+	days := []time.Time{from, to}
+	for _, position := range positions {
+		for _, transaction := range position.transactions {
+			day := entities.PriceDate(transaction.CreatedAt)
+			if seen[day] || day.Before(from) || day.After(to) {
+				continue
+			}
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+func groupKey(asset *entities.Asset, groupBy string) (string, string) {
+	switch groupBy {
+	case GroupByCategory:
+		category, _ := entities.CategoryName(asset.CategoryID)
+		return category, category
+	case GroupByAsset:
+		return asset.Code.String(), asset.Name
+	}
+	return GroupByPortfolio, portfolioGroupName
+}
+
+func groupPerformance(g *group, days []time.Time) *response.PerformanceGroup {
+	valuations := make([]performance.Valuation, len(days))
+	for i, day := range days {
+		valuations[i].Date = day
+		for _, h := range g.history {
+			valuations[i].Value = valuations[i].Value.Add(h.values[day])
+			valuations[i].NetFlow = valuations[i].NetFlow.Add(h.flows[day])
+		}
+	}
+
+	first, last := valuations[0], valuations[len(valuations)-1]
+	result := &response.PerformanceGroup{
+		Key:        g.key,
+		Name:       g.name,
+		StartValue: first.Value,
+		EndValue:   last.Value,
+	}
+	for _, valuation := range valuations[1:] {
+		result.NetFlows = result.NetFlows.Add(valuation.NetFlow)
+	}
+	result.Gain = result.EndValue.Sub(result.StartValue).Sub(result.NetFlows)
+
+	periodDays := int(last.Date.Sub(first.Date).Hours() / 24)
+	twr, err := performance.TimeWeightedReturn(valuations)
+	if err != nil {
+		result.Notes = append(result.Notes, noteNoCapital)
+	} else {
+		result.TimeWeightedReturn = percentage(twr)
+		if periodDays >= defaultRangeDays {
+			if annualized, err := performance.Annualize(twr, periodDays); err == nil {
+				result.AnnualizedTimeWeightedReturn = percentage(annualized)
+			}
+		} else {
+			result.Notes = append(result.Notes, noteNotAnnualized)
+		}
+	}
+
+	mwr, err := performance.XIRR(investorFlows(valuations))
+	switch {
+	case err == nil:
+		result.MoneyWeightedReturn = percentage(mwr)
+	case libErrors.Is(err, performance.ErrNoConvergence):
+		result.Notes = append(result.Notes, noteNoConvergence)
+	default:
+		result.Notes = append(result.Notes, noteNoCashFlows)
+	}
+
+	return result
+}
+
+// investorFlows turns the valuations into the investor's cash flows: the
+// starting value and every contribution are paid in, the final value is received.
+func investorFlows(valuations []performance.Valuation) []performance.CashFlow {
+	var flows []performance.CashFlow
+	first, last := valuations[0], valuations[len(valuations)-1]
+	if !first.Value.IsZero() {
+		flows = append(flows, performance.CashFlow{Date: first.Date, Amount: first.Value.Neg()})
+	}
+	for _, valuation := range valuations[1:] {
+		if !valuation.NetFlow.IsZero() {
+			flows = append(flows, performance.CashFlow{Date: valuation.Date, Amount: valuation.NetFlow.Neg()})
+		}
+	}
+	if !last.Value.IsZero() {
+		flows = append(flows, performance.CashFlow{Date: last.Date, Amount: last.Value})
+	}
+	return flows
+}
+
+func parseRange(from, to string, now time.Time) (time.Time, time.Time, error) {
+	var messages []string
+
+	end := entities.PriceDate(now)
+	if to != "" {
+		parsed, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			messages = append(messages, "to must have the format YYYY-MM-DD")
+		}
+		end = parsed
+	}
+
+	start := end.AddDate(0, 0, -defaultRangeDays)
+	if from != "" {
+		parsed, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			messages = append(messages, "from must have the format YYYY-MM-DD")
+		}
+		start = parsed
+	}
+
+	if len(messages) == 0 {
+		if !start.Before(end) {
+			messages = append(messages, "from must be before to")
+		} else if end.Sub(start) > maxRangeDays*24*time.Hour {
+			messages = append(messages, "date range cannot exceed 5 years")
+		}
+	}
+	if len(messages) > 0 {
+		return time.Time{}, time.Time{}, errors.New(http.StatusBadRequest, invalidRangeCode, messages)
+	}
+	return start, end, nil
+}
+
+// percentage converts a rate into a percentage rounded to two decimals.
+func percentage(rate decimal.Decimal) *decimal.Decimal {
+	value := rate.Mul(decimal.NewFromInt(100)).Round(2)
+	return &value
+}
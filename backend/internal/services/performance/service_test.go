@@ -0,0 +1,264 @@
+package performance
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/fx"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAssetRepository struct {
+	mock.Mock
+}
+
+func (m *MockAssetRepository) ListByUser(ctx context.Context, userID uint64) ([]entities.Asset, error) {
+	args := m.Called(ctx, userID)
+	if assets, ok := args.Get(0).([]entities.Asset); ok {
+		return assets, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type fakeTransactionRepository map[uint64][]entities.Transaction
+
+func (r fakeTransactionRepository) ListByAsset(ctx context.Context, assetID uint64) ([]entities.Transaction, error) {
+	return r[assetID], nil
+}
+
+type fakePriceRepository map[uint64][]entities.AssetPrice
+
+// ListByAsset returns the prices in the range, after the latest one before it,
+// like the repository does. The prices are kept oldest first.
+func (r fakePriceRepository) ListByAsset(ctx context.Context, assetID uint64, from, to time.Time) ([]entities.AssetPrice, error) {
+	var prices []entities.AssetPrice
+	for _, price := range r[assetID] {
+		switch {
+		case price.Date.Before(from):
+			prices = []entities.AssetPrice{price}
+		case !price.Date.After(to):
+			prices = append(prices, price)
+		}
+	}
+	return prices, nil
+}
+
+// fakeConverter converts with fixed rates into USD.
+type fakeConverter struct{}
+
+func (fakeConverter) Convert(ctx context.Context, amount decimal.Decimal, from, to string, at time.Time) (decimal.Decimal, error) {
+	rates := map[string]decimal.Decimal{"USD": decimal.NewFromInt(1), "EUR": d("1.1")}
+	rate, ok := rates[from]
+	if !ok || to != "USD" {
+		return decimal.Zero, fx.ErrUnsupportedCurrency
+	}
+	return amount.Mul(rate), nil
+}
+
+func d(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func day(value string) time.Time {
+	parsed, _ := time.Parse(time.DateOnly, value)
+	return parsed
+}
+
+func buy(assetID uint64, on, units, total, currency string) entities.Transaction {
+	return entities.Transaction{
+		AssetID:   assetID,
+		Type:      entities.TransactionTypeBuy,
+		Units:     d(units),
+		Total:     d(total),
+		Currency:  currency,
+		CreatedAt: day(on).Add(15 * time.Hour),
+	}
+}
+
+func price(on, value string) entities.AssetPrice {
+	return entities.AssetPrice{Date: day(on), Price: d(value), Currency: "USD"}
+}
+
+func assertPercentage(t *testing.T, expected string, actual *decimal.Decimal) {
+	t.Helper()
+	if assert.NotNil(t, actual) {
+		assert.True(t, d(expected).Equal(*actual), "expected %s, got %s", expected, actual)
+	}
+}
+
+func findGroup(groups []*response.PerformanceGroup, key string) *response.PerformanceGroup {
+	for _, group := range groups {
+		if group.Key == key {
+			return group
+		}
+	}
+	return nil
+}
+
+func newTestService(assets []entities.Asset) *service {
+	user := &entities.User{ID: 1}
+	repo := new(MockAssetRepository)
+	repo.On("ListByUser", mock.Anything, user.ID).Return(assets, nil)
+	logger := new(mocks.Logger)
+	logger.On("Warning", mock.Anything, "portfolio_performance", mock.Anything, mock.Anything).Return()
+
+	transactions := fakeTransactionRepository{
+		// 10 units before the period, 10 more at 120 in the middle of it.
+		1: {buy(1, "2024-12-01", "10", "1000", "USD"), buy(1, "2025-07-01", "10", "1200", "USD")},
+		// Without price history, valued at the price of its purchase.
+		2: {buy(2, "2025-03-01", "1", "100", "EUR")},
+		3: {buy(3, "2025-02-01", "1", "100", "GBP")},
+	}
+	prices := fakePriceRepository{
+		1: {price("2025-01-01", "100"), price("2025-07-01", "120"), price("2025-12-31", "132"), price("2026-01-05", "140")},
+		// Created with its units, it has no ledger.
+		4: {price("2024-06-01", "1000"), price("2025-12-31", "1100")},
+	}
+
+	svc := NewService(repo, transactions, prices, fakeConverter{}, logger)
+	svc.now = func() time.Time { return day("2025-12-31").Add(20 * time.Hour) }
+	return svc
+}
+
+func Test_GetPerformance(t *testing.T) {
+	ctx := context.Background()
+	user := &entities.User{ID: 1}
+	stock := entities.Asset{ID: 1, Code: uuid.New(), Name: "NVIDIA", Currency: "USD", CategoryID: entities.CategoryStock}
+	crypto := entities.Asset{ID: 2, Code: uuid.New(), Name: "Bitcoin", Currency: "EUR", CategoryID: entities.CategoryCrypto}
+	pound := entities.Asset{ID: 3, Code: uuid.New(), Name: "Pound account", Currency: "GBP", CategoryID: entities.CategoryCash}
+
+	t.Run("portfolio returns net out the contributions", func(t *testing.T) {
+		svc := newTestService([]entities.Asset{stock})
+		result, err := svc.GetPerformance(ctx, user, &request.PortfolioPerformance{From: "2025-01-01"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "USD", result.Currency)
+		assert.Equal(t, "2025-01-01", result.From)
+		assert.Equal(t, "2025-12-31", result.To)
+		assert.Equal(t, GroupByPortfolio, result.GroupBy)
+		assert.Len(t, result.Groups, 1)
+
+		group := result.Groups[0]
+		assert.Equal(t, GroupByPortfolio, group.Key)
+		assert.True(t, d("1000").Equal(group.StartValue), "start value: %s", group.StartValue)
+		assert.True(t, d("2640").Equal(group.EndValue), "end value: %s", group.EndValue)
+		assert.True(t, d("1200").Equal(group.NetFlows), "net flows: %s", group.NetFlows)
+		assert.True(t, d("440").Equal(group.Gain), "gain: %s", group.Gain)
+		// 1000 -> 1200 before the purchase, 2400 -> 2640 after it.
+		assertPercentage(t, "32", group.TimeWeightedReturn)
+		assertPercentage(t, "28.18", group.MoneyWeightedReturn)
+		assert.Nil(t, group.AnnualizedTimeWeightedReturn)
+		assert.Contains(t, group.Notes, noteNotAnnualized)
+	})
+
+	t.Run("group by category reports unvalued assets apart", func(t *testing.T) {
+		svc := newTestService([]entities.Asset{stock, crypto, pound})
+		result, err := svc.GetPerformance(ctx, user, &request.PortfolioPerformance{From: "2025-01-01", To: "2025-12-31", GroupBy: "Category"})
+		assert.NoError(t, err)
+
+		assert.Len(t, result.Groups, 2)
+		assert.Equal(t, "STOCK", result.Groups[0].Key)
+		cryptoGroup := findGroup(result.Groups, "CRYPTO")
+		if assert.NotNil(t, cryptoGroup) {
+			assert.True(t, cryptoGroup.StartValue.IsZero())
+			assert.True(t, d("110").Equal(cryptoGroup.EndValue), "end value: %s", cryptoGroup.EndValue)
+			assertPercentage(t, "0", cryptoGroup.TimeWeightedReturn)
+			assertPercentage(t, "0", cryptoGroup.MoneyWeightedReturn)
+		}
+
+		assert.Len(t, result.UnvaluedAssets, 1)
+		assert.Equal(t, pound.Code, result.UnvaluedAssets[0].Code)
+		assert.Equal(t, reasonRateUnavailable, result.UnvaluedAssets[0].Reason)
+	})
+
+	t.Run("group by asset annualizes periods of a year or more", func(t *testing.T) {
+		svc := newTestService([]entities.Asset{stock})
+		result, err := svc.GetPerformance(ctx, user, &request.PortfolioPerformance{From: "2024-12-31", To: "2025-12-31", GroupBy: GroupByAsset})
+		assert.NoError(t, err)
+
+		group := findGroup(result.Groups, stock.Code.String())
+		if assert.NotNil(t, group) {
+			assert.Equal(t, stock.Name, group.Name)
+			assert.NotNil(t, group.AnnualizedTimeWeightedReturn)
+			assert.Equal(t, *group.TimeWeightedReturn, *group.AnnualizedTimeWeightedReturn)
+		}
+	})
+
+	t.Run("the opening balance of an asset is valued", func(t *testing.T) {
+		deposit := entities.Asset{ID: 4, Code: uuid.New(), Name: "CDT", Currency: "USD", CategoryID: entities.CategoryCash,
+			OpeningUnits: d("1"), OpeningCost: d("1000"), CreatedAt: day("2024-06-01")}
+		svc := newTestService([]entities.Asset{deposit})
+		result, err := svc.GetPerformance(ctx, user, &request.PortfolioPerformance{From: "2025-01-01", To: "2025-12-31"})
+		assert.NoError(t, err)
+
+		group := result.Groups[0]
+		assert.True(t, d("1000").Equal(group.StartValue), "start value: %s", group.StartValue)
+		assert.True(t, d("1100").Equal(group.EndValue), "end value: %s", group.EndValue)
+		assert.True(t, group.NetFlows.IsZero(), "net flows: %s", group.NetFlows)
+		assertPercentage(t, "10", group.TimeWeightedReturn)
+	})
+
+	t.Run("no assets has no returns", func(t *testing.T) {
+		svc := newTestService(nil)
+		result, err := svc.GetPerformance(ctx, user, &request.PortfolioPerformance{})
+		assert.NoError(t, err)
+
+		assert.Len(t, result.Groups, 1)
+		group := result.Groups[0]
+		assert.Nil(t, group.TimeWeightedReturn)
+		assert.Nil(t, group.MoneyWeightedReturn)
+		assert.Contains(t, group.Notes, noteNoCapital)
+		assert.Contains(t, group.Notes, noteNoCashFlows)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		testCases := []struct {
+			name          string
+			request       *request.PortfolioPerformance
+			expectedError *errors.ErrorResponse
+		}{
+			{
+				name:          "unknown group",
+				request:       &request.PortfolioPerformance{GroupBy: "currency"},
+				expectedError: &errors.ErrorResponse{HttpCode: http.StatusBadRequest, Code: invalidGroupByCode},
+			},
+			{
+				name:          "malformed date",
+				request:       &request.PortfolioPerformance{From: "01/01/2025"},
+				expectedError: &errors.ErrorResponse{HttpCode: http.StatusBadRequest, Code: invalidRangeCode},
+			},
+			{
+				name:          "empty period",
+				request:       &request.PortfolioPerformance{From: "2025-03-01", To: "2025-03-01"},
+				expectedError: &errors.ErrorResponse{HttpCode: http.StatusBadRequest, Code: invalidRangeCode},
+			},
+			{
+				name:          "period too long",
+				request:       &request.PortfolioPerformance{From: "2015-01-01", To: "2025-01-01"},
+				expectedError: &errors.ErrorResponse{HttpCode: http.StatusBadRequest, Code: invalidRangeCode},
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				svc := newTestService(nil)
+				_, err := svc.GetPerformance(ctx, user, tc.request)
+				errorResponse, ok := err.(*errors.ErrorResponse)
+				if assert.True(t, ok) {
+					assert.Equal(t, tc.expectedError.HttpCode, errorResponse.HttpCode)
+					assert.Equal(t, tc.expectedError.Code, errorResponse.Code)
+				}
+			})
+		}
+	})
+}
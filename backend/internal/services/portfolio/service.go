@@ -0,0 +1,178 @@
+package portfolio
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/pricing"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultBaseCurrency = "USD"
+
+	reasonPriceUnavailable = "price unavailable"
+	reasonRateUnavailable  = "exchange rate unavailable"
+)
+
+type assetRepository interface {
+	ListByUser(ctx context.Context, userID uint64) ([]entities.Asset, error)
+}
+
+type assetValuer interface {
+	ValueAsset(ctx context.Context, asset *entities.Asset) (*pricing.Valuation, error)
+}
+
+type converter interface {
+	Convert(ctx context.Context, amount decimal.Decimal, from, to string, at time.Time) (decimal.Decimal, error)
+}
+
+type service struct {
+	assetRepository assetRepository
+	valuer          assetValuer
+	converter       converter
+	logger          log.Logger
+	now             func() time.Time
+}
+
+func NewService(assetRepo assetRepository, valuer assetValuer, converter converter, logger log.Logger) *service {
+	return &service{
+		assetRepository: assetRepo,
+		valuer:          valuer,
+		converter:       converter,
+		logger:          logger,
+		now:             time.Now,
+	}
+}
+
+// GetSummary values every asset of the user in the user's base currency. Assets
+// that cannot be valued are reported apart instead of failing the summary.
+func (s *service) GetSummary(ctx context.Context, user *entities.User) (*response.PortfolioSummary, error) {
+	assets, err := s.assetRepository.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	baseCurrency := user.Currency
+	if baseCurrency == "" {
+		baseCurrency = defaultBaseCurrency
+	}
+	now := s.now()
+
+	summary := &response.PortfolioSummary{
+		Currency:       baseCurrency,
+		AsOf:           now,
+		ByAsset:        []*response.AssetAllocation{},
+		UnvaluedAssets: []*response.UnvaluedAsset{},
+	}
+	byCategory := map[string]*response.Allocation{}
+	byCurrency := map[string]*response.Allocation{}
+
+	for i := range assets {
+		asset := &assets[i]
+		allocation, reason := s.valueAsset(ctx, asset, baseCurrency, now)
+		if allocation == nil {
+			summary.UnvaluedAssets = append(summary.UnvaluedAssets, &response.UnvaluedAsset{
+				Code:   asset.Code,
+				Name:   asset.Name,
+				Reason: reason,
+			})
+			continue
+		}
+
+		summary.ByAsset = append(summary.ByAsset, allocation)
+		summary.NetWorth = summary.NetWorth.Add(allocation.Value)
+		summary.InvestedTotal = summary.InvestedTotal.Add(allocation.InvestedTotal)
+		addTo(byCategory, allocation.Category, allocation.Value)
+		addTo(byCurrency, asset.Currency, allocation.Value)
+	}
+
+	summary.UnrealizedGain = summary.NetWorth.Sub(summary.InvestedTotal)
+	summary.UnrealizedGainPercentage = percentage(summary.UnrealizedGain, summary.InvestedTotal)
+	for _, allocation := range summary.ByAsset {
+		allocation.Percentage = percentage(allocation.Value, summary.NetWorth)
+	}
+	sort.SliceStable(summary.ByAsset, func(i, j int) bool {
+		return summary.ByAsset[i].Value.GreaterThan(summary.ByAsset[j].Value)
+	})
+	summary.ByCategory = sortedAllocations(byCategory, summary.NetWorth)
+	summary.ByCurrency = sortedAllocations(byCurrency, summary.NetWorth)
+
+	return summary, nil
+}
+
+func (s *service) valueAsset(ctx context.Context, asset *entities.Asset, baseCurrency string, now time.Time) (*response.AssetAllocation, string) {
+	valuation, err := s.valuer.ValueAsset(ctx, asset)
+	if err != nil {
+		s.logger.Warning(ctx, "portfolio_summary", "error valuing asset", log.Field("asset_id", asset.ID), log.Field("error", err))
+		return nil, reasonPriceUnavailable
+	}
+
+	value, err := s.converter.Convert(ctx, valuation.MarketValue, valuation.Currency, baseCurrency, now)
+	if err != nil {
+		return nil, s.rateError(ctx, asset, err)
+	}
+	invested, err := s.converter.Convert(ctx, asset.InvestedTotal, asset.Currency, baseCurrency, now)
+	if err != nil {
+		return nil, s.rateError(ctx, asset, err)
+	}
+
+	category, _ := entities.CategoryName(asset.CategoryID)
+	return &response.AssetAllocation{
+		Code:           asset.Code,
+		Name:           asset.Name,
+		Symbol:         asset.Symbol,
+		Category:       category,
+		AssetCurrency:  asset.Currency,
+		TotalUnits:     asset.TotalUnits,
+		UnitPrice:      valuation.UnitPrice,
+		PriceSource:    valuation.Source,
+		Value:          value,
+		InvestedTotal:  invested,
+		UnrealizedGain: value.Sub(invested),
+	}, ""
+}
+
+func (s *service) rateError(ctx context.Context, asset *entities.Asset, err error) string {
+	s.logger.Warning(ctx, "portfolio_summary", "error converting asset value", log.Field("asset_id", asset.ID), log.Field("error", err))
+	return reasonRateUnavailable
+}
+
+func addTo(allocations map[string]*response.Allocation, name string, value decimal.Decimal) {
+	allocation, ok := allocations[name]
+	if !ok {
+		allocation = &response.Allocation{Name: name}
+		allocations[name] = allocation
+	}
+	allocation.Value = allocation.Value.Add(value)
+	allocation.Count++
+}
+
+func sortedAllocations(allocations map[string]*response.Allocation, total decimal.Decimal) []*response.Allocation {
+	result := make([]*response.Allocation, 0, len(allocations))
+	for _, allocation := range allocations {
+		allocation.Percentage = percentage(allocation.Value, total)
+		result = append(result, allocation)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Value.Equal(result[j].Value) {
+			return result[i].Value.GreaterThan(result[j].Value)
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// percentage returns part as a percentage of total rounded to two decimals.
+func percentage(part, total decimal.Decimal) decimal.Decimal {
+	if total.IsZero() {
+		return decimal.Zero
+	}
+	return part.Div(total).Mul(decimal.NewFromInt(100)).Round(2)
+}
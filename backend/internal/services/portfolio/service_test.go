@@ -0,0 +1,157 @@
+package portfolio
+
+import (
+	"context"
+	libErrors "errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/fx"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/pricing"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAssetRepository struct {
+	mock.Mock
+}
+
+func (m *MockAssetRepository) ListByUser(ctx context.Context, userID uint64) ([]entities.Asset, error) {
+	args := m.Called(ctx, userID)
+	if assets, ok := args.Get(0).([]entities.Asset); ok {
+		return assets, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// fakeValuer values auto priced assets with fixed unit prices and manual assets
+// with their current value.
+type fakeValuer struct {
+	prices map[uint64]decimal.Decimal
+}
+
+func (v *fakeValuer) ValueAsset(ctx context.Context, asset *entities.Asset) (*pricing.Valuation, error) {
+	if !asset.AutoPricingEnabled {
+		return pricing.ManualValuation(asset), nil
+	}
+	price, ok := v.prices[asset.ID]
+	if !ok {
+		return nil, pricing.ErrPriceNotFound
+	}
+	return &pricing.Valuation{
+		UnitPrice:   price,
+		MarketValue: asset.TotalUnits.Mul(price),
+		Currency:    asset.Currency,
+		Source:      "fake",
+	}, nil
+}
+
+// fakeConverter converts with fixed rates into COP.
+type fakeConverter struct{}
+
+func (fakeConverter) Convert(ctx context.Context, amount decimal.Decimal, from, to string, at time.Time) (decimal.Decimal, error) {
+	rates := map[string]decimal.Decimal{"COP": decimal.NewFromInt(1), "USD": decimal.NewFromInt(4000)}
+	rate, ok := rates[from]
+	if !ok || to != "COP" {
+		return decimal.Zero, fx.ErrUnsupportedCurrency
+	}
+	return amount.Mul(rate), nil
+}
+
+func d(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func Test_GetSummary(t *testing.T) {
+	ctx := context.Background()
+	user := &entities.User{ID: 1, Currency: "COP"}
+
+	assets := []entities.Asset{
+		{
+			ID: 1, Code: uuid.New(), Name: "NVIDIA", Currency: "USD", CategoryID: entities.CategoryStock,
+			TotalUnits: d("2"), InvestedTotal: d("200"), AutoPricingEnabled: true,
+		},
+		{
+			ID: 2, Code: uuid.New(), Name: "Bitcoin", Currency: "USD", CategoryID: entities.CategoryCrypto,
+			TotalUnits: d("0.01"), InvestedTotal: d("500"), AutoPricingEnabled: true,
+		},
+		{
+			ID: 3, Code: uuid.New(), Name: "CDT", Currency: "COP", CategoryID: entities.CategoryFixedIncome,
+			TotalUnits: d("1"), InvestedTotal: d("1000000"), CurrentValue: decimal.NewNullDecimal(d("1100000")),
+		},
+		{
+			ID: 4, Code: uuid.New(), Name: "Unknown ticker", Currency: "USD", CategoryID: entities.CategoryStock,
+			TotalUnits: d("1"), InvestedTotal: d("10"), AutoPricingEnabled: true,
+		},
+		{
+			ID: 5, Code: uuid.New(), Name: "Euro account", Currency: "EUR", CategoryID: entities.CategoryCash,
+			TotalUnits: d("1"), CurrentValue: decimal.NewNullDecimal(d("100")),
+		},
+	}
+
+	repo := new(MockAssetRepository)
+	repo.On("ListByUser", mock.Anything, user.ID).Return(assets, nil)
+	logger := new(mocks.Logger)
+	logger.On("Warning", mock.Anything, "portfolio_summary", mock.Anything, mock.Anything).Return()
+	valuer := &fakeValuer{prices: map[uint64]decimal.Decimal{1: d("150"), 2: d("60000")}}
+
+	svc := NewService(repo, valuer, fakeConverter{}, logger)
+	summary, err := svc.GetSummary(ctx, user)
+	assert.NoError(t, err)
+
+	// NVIDIA 300 USD, Bitcoin 600 USD, CDT 1.100.000 COP.
+	assert.Equal(t, "COP", summary.Currency)
+	assert.True(t, d("4700000").Equal(summary.NetWorth), "net worth: %s", summary.NetWorth)
+	assert.True(t, d("3800000").Equal(summary.InvestedTotal), "invested: %s", summary.InvestedTotal)
+	assert.True(t, d("900000").Equal(summary.UnrealizedGain), "gain: %s", summary.UnrealizedGain)
+	assert.True(t, d("23.68").Equal(summary.UnrealizedGainPercentage), "gain pct: %s", summary.UnrealizedGainPercentage)
+
+	assert.Len(t, summary.ByAsset, 3)
+	assert.Equal(t, "Bitcoin", summary.ByAsset[0].Name)
+	assert.True(t, d("51.06").Equal(summary.ByAsset[0].Percentage), "bitcoin pct: %s", summary.ByAsset[0].Percentage)
+
+	assert.Len(t, summary.ByCategory, 3)
+	assert.Equal(t, "CRYPTO", summary.ByCategory[0].Name)
+	assert.Equal(t, "STOCK", summary.ByCategory[1].Name)
+	assert.Equal(t, 1, summary.ByCategory[1].Count)
+
+	assert.Len(t, summary.ByCurrency, 2)
+	assert.Equal(t, "USD", summary.ByCurrency[0].Name)
+	assert.Equal(t, 2, summary.ByCurrency[0].Count)
+	assert.True(t, d("76.6").Equal(summary.ByCurrency[0].Percentage), "usd pct: %s", summary.ByCurrency[0].Percentage)
+
+	assert.Len(t, summary.UnvaluedAssets, 2)
+	assert.Equal(t, reasonPriceUnavailable, summary.UnvaluedAssets[0].Reason)
+	assert.Equal(t, reasonRateUnavailable, summary.UnvaluedAssets[1].Reason)
+	repo.AssertExpectations(t)
+}
+
+func Test_GetSummary_EmptyPortfolio(t *testing.T) {
+	repo := new(MockAssetRepository)
+	repo.On("ListByUser", mock.Anything, uint64(1)).Return([]entities.Asset{}, nil)
+
+	summary, err := NewService(repo, &fakeValuer{}, fakeConverter{}, new(mocks.Logger)).GetSummary(context.Background(), &entities.User{ID: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, defaultBaseCurrency, summary.Currency)
+	assert.True(t, summary.NetWorth.IsZero())
+	assert.True(t, summary.UnrealizedGainPercentage.IsZero())
+	assert.Empty(t, summary.ByCategory)
+}
+
+func Test_GetSummary_RepositoryError(t *testing.T) {
+	repo := new(MockAssetRepository)
+	repo.On("ListByUser", mock.Anything, uint64(1)).Return(nil, libErrors.New("db error"))
+
+	_, err := NewService(repo, &fakeValuer{}, fakeConverter{}, new(mocks.Logger)).GetSummary(context.Background(), &entities.User{ID: 1})
+
+	errorResponse, ok := err.(*errors.ErrorResponse)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusInternalServerError, errorResponse.ErrorHTTPCode())
+}
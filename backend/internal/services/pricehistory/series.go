@@ -0,0 +1,64 @@
+package pricehistory
+
+import (
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+)
+
+// Point is a daily price. Filled points carry the last known price forward.
+type Point struct {
+	Date     time.Time
+	Price    decimal.Decimal
+	Currency string
+	Source   string
+	Filled   bool
+}
+
+// FillGaps builds one point per day between from and to (inclusive) from a price
+// history ordered oldest first. Days before the first known price are omitted.
+func FillGaps(prices []entities.AssetPrice, from, to time.Time) []Point {
+	from, to = entities.PriceDate(from), entities.PriceDate(to)
+	if to.Before(from) {
+		return nil
+	}
+
+	var points []Point
+	var last *entities.AssetPrice
+	next := 0
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		filled := true
+		for next < len(prices) && !entities.PriceDate(prices[next].Date).After(day) {
+			filled = !entities.PriceDate(prices[next].Date).Equal(day)
+			last = &prices[next]
+			next++
+		}
+		if last == nil {
+			continue
+		}
+		points = append(points, Point{
+			Date:     day,
+			Price:    last.Price,
+			Currency: last.Currency,
+			Source:   last.Source,
+			Filled:   filled,
+		})
+	}
+
+	return points
+}
+
+// PriceAt returns the last known price on or before the given day.
+func PriceAt(prices []entities.AssetPrice, at time.Time) (*entities.AssetPrice, bool) {
+	at = entities.PriceDate(at)
+	var found *entities.AssetPrice
+	for i := range prices {
+		if entities.PriceDate(prices[i].Date).After(at) {
+			break
+		}
+		found = &prices[i]
+	}
+	return found, found != nil
+}
@@ -0,0 +1,84 @@
+package pricehistory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func day(d int) time.Time {
+	return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC)
+}
+
+func price(d int, value int64) entities.AssetPrice {
+	return entities.AssetPrice{Date: day(d), Price: decimal.NewFromInt(value), Currency: "USD", Source: "yahoo"}
+}
+
+func Test_FillGaps(t *testing.T) {
+	history := []entities.AssetPrice{price(1, 100), price(4, 110), price(5, 120)}
+
+	testCases := []struct {
+		name           string
+		from           time.Time
+		to             time.Time
+		expectedPrices []int64
+		expectedFilled []bool
+	}{
+		{
+			name:           "carries the last known price forward",
+			from:           day(1),
+			to:             day(6),
+			expectedPrices: []int64{100, 100, 100, 110, 120, 120},
+			expectedFilled: []bool{false, true, true, false, false, true},
+		},
+		{
+			name:           "range starting between prices uses the previous price",
+			from:           day(3),
+			to:             day(4),
+			expectedPrices: []int64{100, 110},
+			expectedFilled: []bool{true, false},
+		},
+		{
+			name:           "days before the first price are omitted",
+			from:           time.Date(2025, 2, 27, 0, 0, 0, 0, time.UTC),
+			to:             day(2),
+			expectedPrices: []int64{100, 100},
+			expectedFilled: []bool{false, true},
+		},
+		{
+			name: "inverted range",
+			from: day(5),
+			to:   day(1),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			points := FillGaps(history, tc.from, tc.to)
+
+			assert.Len(t, points, len(tc.expectedPrices))
+			for i, expected := range tc.expectedPrices {
+				assert.True(t, decimal.NewFromInt(expected).Equal(points[i].Price), "point %d price: %s", i, points[i].Price)
+				assert.Equal(t, tc.expectedFilled[i], points[i].Filled, "point %d filled", i)
+			}
+		})
+	}
+}
+
+func Test_PriceAt(t *testing.T) {
+	history := []entities.AssetPrice{price(1, 100), price(4, 110)}
+
+	found, ok := PriceAt(history, day(3).Add(20*time.Hour))
+	assert.True(t, ok)
+	assert.True(t, decimal.NewFromInt(100).Equal(found.Price))
+
+	found, ok = PriceAt(history, day(4))
+	assert.True(t, ok)
+	assert.True(t, decimal.NewFromInt(110).Equal(found.Price))
+
+	_, ok = PriceAt(history, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+}
@@ -0,0 +1,186 @@
+package pricehistory
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/pricing"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	invalidPriceCode  = "INVALID_PRICE"
+	invalidRangeCode  = "INVALID_DATE_RANGE"
+	assetNotFoundCode = "ASSET_NOT_FOUND"
+
+	defaultRangeDays = 30
+	maxRangeDays     = 366 * 5
+)
+
+type assetRepository interface {
+	GetByCode(ctx context.Context, userID uint64, code uuid.UUID) (*entities.Asset, error)
+}
+
+type priceRepository interface {
+	Upsert(ctx context.Context, price *entities.AssetPrice) error
+	ListByAsset(ctx context.Context, assetID uint64, from, to time.Time) ([]entities.AssetPrice, error)
+}
+
+type service struct {
+	assetRepository assetRepository
+	priceRepository priceRepository
+}
+
+func NewService(assetRepo assetRepository, priceRepo priceRepository) *service {
+	return &service{
+		assetRepository: assetRepo,
+		priceRepository: priceRepo,
+	}
+}
+
+// RecordPrice stores the unit price of an asset for the day of at. Recording the
+// same day twice keeps the latest price.
+func (s *service) RecordPrice(ctx context.Context, assetID uint64, at time.Time, price decimal.Decimal, currency, source string) error {
+	now := time.Now()
+	return s.priceRepository.Upsert(ctx, &entities.AssetPrice{
+		AssetID:   assetID,
+		Date:      entities.PriceDate(at),
+		Price:     price,
+		Currency:  strings.ToUpper(currency),
+		Source:    source,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// RecordValuation stores the unit price implied by the current_value of a manually
+// valued asset. Positions without units have no unit price and are skipped.
+func (s *service) RecordValuation(ctx context.Context, asset *entities.Asset) error {
+	if !asset.CurrentValue.Valid || !asset.TotalUnits.IsPositive() {
+		return nil
+	}
+	unitPrice := asset.CurrentValue.Decimal.Div(asset.TotalUnits)
+	return s.RecordPrice(ctx, asset.ID, asset.UpdatedAt, unitPrice, asset.Currency, pricing.ManualSource)
+}
+
+func (s *service) AddPrice(ctx context.Context, userID uint64, assetCode uuid.UUID, req *request.CreateAssetPrice) (*response.PricePoint, error) {
+	var messages []string
+	date, err := time.Parse(time.DateOnly, req.Date)
+	if err != nil {
+		messages = append(messages, "date must have the format YYYY-MM-DD")
+	} else if date.After(time.Now()) {
+		messages = append(messages, "date cannot be in the future")
+	}
+	if !req.Price.IsPositive() {
+		messages = append(messages, "price must be greater than zero")
+	}
+	if len(messages) > 0 {
+		return nil, errors.New(http.StatusBadRequest, invalidPriceCode, messages)
+	}
+
+	asset, err := s.findAsset(ctx, userID, assetCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.RecordPrice(ctx, asset.ID, date, req.Price, asset.Currency, pricing.ManualSource); err != nil {
+		return nil, errors.New(http.StatusInternalServerError, "CREATE_PRICE_ERROR", []string{"Unable to record price"})
+	}
+
+	return &response.PricePoint{
+		Date:     date.Format(time.DateOnly),
+		Price:    req.Price,
+		Currency: asset.Currency,
+		Source:   pricing.ManualSource,
+	}, nil
+}
+
+// GetPriceSeries returns one price per day in [from, to], carrying the last known
+// price forward over days without a recorded price. Empty bounds default to the
+// last 30 days.
+func (s *service) GetPriceSeries(ctx context.Context, userID uint64, assetCode uuid.UUID, from, to string) (*response.PriceSeries, error) {
+	start, end, err := parseRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	asset, err := s.findAsset(ctx, userID, assetCode)
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := s.priceRepository.ListByAsset(ctx, asset.ID, start, end)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	points := FillGaps(prices, start, end)
+	series := &response.PriceSeries{
+		AssetCode: asset.Code,
+		From:      start.Format(time.DateOnly),
+		To:        end.Format(time.DateOnly),
+		Points:    make([]*response.PricePoint, 0, len(points)),
+	}
+	for _, point := range points {
+		series.Points = append(series.Points, &response.PricePoint{
+			Date:     point.Date.Format(time.DateOnly),
+			Price:    point.Price,
+			Currency: point.Currency,
+			Source:   point.Source,
+			Filled:   point.Filled,
+		})
+	}
+	return series, nil
+}
+
+func (s *service) findAsset(ctx context.Context, userID uint64, code uuid.UUID) (*entities.Asset, error) {
+	asset, err := s.assetRepository.GetByCode(ctx, userID, code)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if asset == nil {
+		return nil, errors.New(http.StatusNotFound, assetNotFoundCode, []string{"Asset not found"})
+	}
+	return asset, nil
+}
+
+func parseRange(from, to string) (time.Time, time.Time, error) {
+	var messages []string
+
+	end := entities.PriceDate(time.Now())
+	if to != "" {
+		parsed, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			messages = append(messages, "to must have the format YYYY-MM-DD")
+		}
+		end = parsed
+	}
+
+	start := end.AddDate(0, 0, -defaultRangeDays)
+	if from != "" {
+		parsed, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			messages = append(messages, "from must have the format YYYY-MM-DD")
+		}
+		start = parsed
+	}
+
+	if len(messages) == 0 {
+		if start.After(end) {
+			messages = append(messages, "from cannot be after to")
+		} else if end.Sub(start) > maxRangeDays*24*time.Hour {
+			messages = append(messages, "date range cannot exceed 5 years")
+		}
+	}
+	if len(messages) > 0 {
+		return time.Time{}, time.Time{}, errors.New(http.StatusBadRequest, invalidRangeCode, messages)
+	}
+	return start, end, nil
+}
@@ -0,0 +1,204 @@
+package pricehistory
+
+import (
+	"context"
+	libErrors "errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAssetRepository struct {
+	mock.Mock
+}
+
+func (m *MockAssetRepository) GetByCode(ctx context.Context, userID uint64, code uuid.UUID) (*entities.Asset, error) {
+	args := m.Called(ctx, userID, code)
+	if asset, ok := args.Get(0).(*entities.Asset); ok {
+		return asset, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockPriceRepository struct {
+	mock.Mock
+}
+
+func (m *MockPriceRepository) Upsert(ctx context.Context, price *entities.AssetPrice) error {
+	args := m.Called(ctx, price)
+	return args.Error(0)
+}
+
+func (m *MockPriceRepository) ListByAsset(ctx context.Context, assetID uint64, from, to time.Time) ([]entities.AssetPrice, error) {
+	args := m.Called(ctx, assetID, from, to)
+	if prices, ok := args.Get(0).([]entities.AssetPrice); ok {
+		return prices, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func Test_GetPriceSeries(t *testing.T) {
+	ctx := context.Background()
+	userID := uint64(1)
+	assetCode := uuid.New()
+	asset := &entities.Asset{ID: 3, Code: assetCode, Currency: "USD"}
+
+	testCases := []struct {
+		name           string
+		from           string
+		to             string
+		expectedPoints int
+		expectedError  *errors.ErrorResponse
+		mockFunc       func(*MockAssetRepository, *MockPriceRepository)
+	}{
+		{
+			name:           "gap filled series",
+			from:           "2025-03-01",
+			to:             "2025-03-06",
+			expectedPoints: 6,
+			mockFunc: func(assets *MockAssetRepository, prices *MockPriceRepository) {
+				assets.On("GetByCode", mock.Anything, userID, assetCode).Return(asset, nil)
+				prices.On("ListByAsset", mock.Anything, uint64(3), day(1), day(6)).Return([]entities.AssetPrice{price(1, 100), price(4, 110)}, nil)
+			},
+		},
+		{
+			name:          "invalid date",
+			from:          "01/03/2025",
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusBadRequest, Code: invalidRangeCode},
+			mockFunc:      func(assets *MockAssetRepository, prices *MockPriceRepository) {},
+		},
+		{
+			name:          "from after to",
+			from:          "2025-03-06",
+			to:            "2025-03-01",
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusBadRequest, Code: invalidRangeCode},
+			mockFunc:      func(assets *MockAssetRepository, prices *MockPriceRepository) {},
+		},
+		{
+			name:          "asset not found",
+			from:          "2025-03-01",
+			to:            "2025-03-06",
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusNotFound, Code: assetNotFoundCode},
+			mockFunc: func(assets *MockAssetRepository, prices *MockPriceRepository) {
+				assets.On("GetByCode", mock.Anything, userID, assetCode).Return(nil, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assetRepository := new(MockAssetRepository)
+			priceRepository := new(MockPriceRepository)
+			tc.mockFunc(assetRepository, priceRepository)
+			svc := NewService(assetRepository, priceRepository)
+
+			resp, err := svc.GetPriceSeries(ctx, userID, assetCode, tc.from, tc.to)
+			if tc.expectedError != nil {
+				assert.Error(t, err)
+				errorResponse, ok := err.(*errors.ErrorResponse)
+				assert.True(t, ok)
+				assert.Equal(t, tc.expectedError.HttpCode, errorResponse.ErrorHTTPCode())
+				assert.Equal(t, tc.expectedError.Code, errorResponse.ErrorCode())
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, resp.Points, tc.expectedPoints)
+				assert.Equal(t, tc.from, resp.From)
+				assert.Equal(t, tc.to, resp.To)
+			}
+			assetRepository.AssertExpectations(t)
+			priceRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_AddPrice(t *testing.T) {
+	ctx := context.Background()
+	userID := uint64(1)
+	assetCode := uuid.New()
+
+	testCases := []struct {
+		name          string
+		req           *request.CreateAssetPrice
+		expectedError *errors.ErrorResponse
+		mockFunc      func(*MockAssetRepository, *MockPriceRepository)
+	}{
+		{
+			name: "backfills a manual price",
+			req:  &request.CreateAssetPrice{Date: "2025-01-15", Price: decimal.NewFromInt(1050)},
+			mockFunc: func(assets *MockAssetRepository, prices *MockPriceRepository) {
+				assets.On("GetByCode", mock.Anything, userID, assetCode).Return(&entities.Asset{ID: 3, Currency: "COP"}, nil)
+				prices.On("Upsert", mock.Anything, mock.MatchedBy(func(price *entities.AssetPrice) bool {
+					return price.AssetID == 3 && price.Currency == "COP" && price.Source == "manual" &&
+						price.Date.Equal(time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC))
+				})).Return(nil)
+			},
+		},
+		{
+			name:          "future date and zero price",
+			req:           &request.CreateAssetPrice{Date: time.Now().AddDate(0, 0, 2).Format(time.DateOnly)},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusBadRequest, Code: invalidPriceCode},
+			mockFunc:      func(assets *MockAssetRepository, prices *MockPriceRepository) {},
+		},
+		{
+			name:          "repository error",
+			req:           &request.CreateAssetPrice{Date: "2025-01-15", Price: decimal.NewFromInt(1050)},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusInternalServerError, Code: "CREATE_PRICE_ERROR"},
+			mockFunc: func(assets *MockAssetRepository, prices *MockPriceRepository) {
+				assets.On("GetByCode", mock.Anything, userID, assetCode).Return(&entities.Asset{ID: 3, Currency: "COP"}, nil)
+				prices.On("Upsert", mock.Anything, mock.Anything).Return(libErrors.New("db error"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assetRepository := new(MockAssetRepository)
+			priceRepository := new(MockPriceRepository)
+			tc.mockFunc(assetRepository, priceRepository)
+			svc := NewService(assetRepository, priceRepository)
+
+			_, err := svc.AddPrice(ctx, userID, assetCode, tc.req)
+			if tc.expectedError != nil {
+				assert.Error(t, err)
+				errorResponse, ok := err.(*errors.ErrorResponse)
+				assert.True(t, ok)
+				assert.Equal(t, tc.expectedError.HttpCode, errorResponse.ErrorHTTPCode())
+				assert.Equal(t, tc.expectedError.Code, errorResponse.ErrorCode())
+			} else {
+				assert.NoError(t, err)
+			}
+			assetRepository.AssertExpectations(t)
+			priceRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_RecordValuation(t *testing.T) {
+	ctx := context.Background()
+	priceRepository := new(MockPriceRepository)
+	priceRepository.On("Upsert", mock.Anything, mock.MatchedBy(func(price *entities.AssetPrice) bool {
+		return price.Price.Equal(decimal.NewFromInt(2500)) && price.Source == "manual"
+	})).Return(nil)
+	svc := NewService(new(MockAssetRepository), priceRepository)
+
+	err := svc.RecordValuation(ctx, &entities.Asset{
+		ID:           3,
+		Currency:     "COP",
+		TotalUnits:   decimal.NewFromInt(4),
+		CurrentValue: decimal.NewNullDecimal(decimal.NewFromInt(10000)),
+		UpdatedAt:    time.Now(),
+	})
+	assert.NoError(t, err)
+
+	err = svc.RecordValuation(ctx, &entities.Asset{ID: 4, CurrentValue: decimal.NewNullDecimal(decimal.NewFromInt(1))})
+	assert.NoError(t, err)
+	priceRepository.AssertExpectations(t)
+}
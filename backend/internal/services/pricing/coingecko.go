@@ -0,0 +1,88 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+)
+
+const coinGeckoDefaultBaseURL = "https://api.coingecko.com"
+
+type coinGeckoProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewCoinGeckoProvider(baseURL, apiKey string, client *http.Client) *coinGeckoProvider {
+	if baseURL == "" {
+		baseURL = coinGeckoDefaultBaseURL
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &coinGeckoProvider{baseURL: strings.TrimRight(baseURL, "/"), apiKey: apiKey, client: client}
+}
+
+func (p *coinGeckoProvider) Name() string {
+	return entities.PriceSourceCoinGecko
+}
+
+// GetPrice expects the CoinGecko coin id (e.g. "bitcoin") as ticker.
+func (p *coinGeckoProvider) GetPrice(ctx context.Context, ticker, currency string) (*Quote, error) {
+	coinID := strings.ToLower(ticker)
+	vsCurrency := strings.ToLower(currency)
+
+	query := url.Values{}
+	query.Set("ids", coinID)
+	query.Set("vs_currencies", vsCurrency)
+	endpoint := fmt.Sprintf("%s/api/v3/simple/price?%s", p.baseURL, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("x-cg-demo-api-key", p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("coingecko: unexpected status %d", resp.StatusCode)
+	}
+
+	var body map[string]map[string]json.Number
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("coingecko: decoding response: %w", err)
+	}
+
+	rawPrice, ok := body[coinID][vsCurrency]
+	if !ok {
+		return nil, ErrPriceNotFound
+	}
+	price, err := decimal.NewFromString(rawPrice.String())
+	if err != nil {
+		return nil, fmt.Errorf("coingecko: parsing price: %w", err)
+	}
+
+	return &Quote{
+		Ticker:   ticker,
+		Price:    price,
+		Currency: strings.ToUpper(currency),
+		Source:   p.Name(),
+		AsOf:     time.Now(),
+	}, nil
+}
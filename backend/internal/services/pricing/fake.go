@@ -0,0 +1,93 @@
+package pricing
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FakeProvider is an in-memory PriceProvider used by tests and local development.
+type FakeProvider struct {
+	mu     sync.Mutex
+	name   string
+	quotes map[string]Quote
+	err    error
+	delay  time.Duration
+	calls  int
+}
+
+func NewFakeProvider(name string) *FakeProvider {
+	return &FakeProvider{name: name, quotes: map[string]Quote{}}
+}
+
+func (f *FakeProvider) Name() string {
+	return f.name
+}
+
+func (f *FakeProvider) SetPrice(ticker, currency string, price decimal.Decimal) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.quotes[fakeKey(ticker, currency)] = Quote{
+		Ticker:   ticker,
+		Price:    price,
+		Currency: strings.ToUpper(currency),
+		Source:   f.name,
+	}
+}
+
+// SetQuote stores the quote returned when the ticker is requested in currency,
+// which lets tests reproduce providers answering in the listing currency.
+func (f *FakeProvider) SetQuote(ticker, currency string, quote Quote) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.quotes[fakeKey(ticker, currency)] = quote
+}
+
+func (f *FakeProvider) SetError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+func (f *FakeProvider) SetDelay(delay time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.delay = delay
+}
+
+func (f *FakeProvider) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func (f *FakeProvider) GetPrice(ctx context.Context, ticker, currency string) (*Quote, error) {
+	f.mu.Lock()
+	f.calls++
+	quote, ok := f.quotes[fakeKey(ticker, currency)]
+	err, delay := f.err, f.delay
+	f.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrPriceNotFound
+	}
+	quote.AsOf = time.Now()
+	return &quote, nil
+}
+
+func fakeKey(ticker, currency string) string {
+	return strings.ToUpper(ticker) + ":" + strings.ToUpper(currency)
+}
@@ -0,0 +1,28 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown price source")
+	ErrPriceNotFound   = errors.New("price not found")
+)
+
+type Quote struct {
+	Ticker   string          `json:"ticker"`
+	Price    decimal.Decimal `json:"price"`
+	Currency string          `json:"currency"`
+	Source   string          `json:"source"`
+	AsOf     time.Time       `json:"as_of"`
+}
+
+// PriceProvider fetches the latest unit price of a ticker from an external market data source.
+type PriceProvider interface {
+	Name() string
+	GetPrice(ctx context.Context, ticker, currency string) (*Quote, error)
+}
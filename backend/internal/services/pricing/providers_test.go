@@ -0,0 +1,63 @@
+package pricing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_YahooProvider_GetPrice(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v8/finance/chart/NVDA":
+			w.Write([]byte(`{"chart":{"result":[{"meta":{"currency":"USD","symbol":"NVDA","regularMarketPrice":121.44,"regularMarketTime":1735689600}}],"error":null}}`))
+		case "/v8/finance/chart/EMPTY":
+			w.Write([]byte(`{"chart":{"result":[],"error":null}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewYahooProvider(server.URL, server.Client())
+
+	quote, err := provider.GetPrice(context.Background(), "NVDA", "USD")
+	assert.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("121.44").Equal(quote.Price))
+	assert.Equal(t, "USD", quote.Currency)
+	assert.Equal(t, int64(1735689600), quote.AsOf.Unix())
+
+	_, err = provider.GetPrice(context.Background(), "EMPTY", "USD")
+	assert.ErrorIs(t, err, ErrPriceNotFound)
+
+	_, err = provider.GetPrice(context.Background(), "UNKNOWN", "USD")
+	assert.ErrorIs(t, err, ErrPriceNotFound)
+}
+
+func Test_CoinGeckoProvider_GetPrice(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/simple/price", r.URL.Path)
+		assert.Equal(t, "demo-key", r.Header.Get("x-cg-demo-api-key"))
+		if r.URL.Query().Get("ids") == "bitcoin" && r.URL.Query().Get("vs_currencies") == "cop" {
+			w.Write([]byte(`{"bitcoin":{"cop":410000000.5}}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	provider := NewCoinGeckoProvider(server.URL, "demo-key", server.Client())
+
+	quote, err := provider.GetPrice(context.Background(), "Bitcoin", "COP")
+	assert.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("410000000.5").Equal(quote.Price))
+	assert.Equal(t, "COP", quote.Currency)
+	assert.Equal(t, "coingecko", quote.Source)
+
+	_, err = provider.GetPrice(context.Background(), "dogecoin", "COP")
+	assert.ErrorIs(t, err, ErrPriceNotFound)
+}
@@ -0,0 +1,74 @@
+package pricing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type ProviderOptions struct {
+	Timeout           time.Duration
+	RequestsPerMinute int
+}
+
+// Registry resolves price providers by the price_source stored on each asset.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]PriceProvider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{providers: map[string]PriceProvider{}}
+}
+
+// Register adds a provider wrapped with its own rate limiter and request timeout.
+func (r *Registry) Register(provider PriceProvider, opts ProviderOptions) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[provider.Name()] = newLimitedProvider(provider, opts)
+}
+
+func (r *Registry) Provider(source string) (PriceProvider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	provider, ok := r.providers[source]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+type limitedProvider struct {
+	PriceProvider
+	limiter *rate.Limiter
+	timeout time.Duration
+}
+
+func newLimitedProvider(provider PriceProvider, opts ProviderOptions) *limitedProvider {
+	limit := rate.Inf
+	burst := 1
+	if opts.RequestsPerMinute > 0 {
+		limit = rate.Every(time.Minute / time.Duration(opts.RequestsPerMinute))
+		burst = opts.RequestsPerMinute
+	}
+	return &limitedProvider{
+		PriceProvider: provider,
+		limiter:       rate.NewLimiter(limit, burst),
+		timeout:       opts.Timeout,
+	}
+}
+
+func (p *limitedProvider) GetPrice(ctx context.Context, ticker, currency string) (*Quote, error) {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	if err := p.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return p.PriceProvider.GetPrice(ctx, ticker, currency)
+}
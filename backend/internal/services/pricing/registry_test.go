@@ -0,0 +1,56 @@
+package pricing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Registry_Provider(t *testing.T) {
+	registry := NewRegistry()
+	fake := NewFakeProvider("fake")
+	fake.SetPrice("NVDA", "USD", decimal.NewFromInt(120))
+	registry.Register(fake, ProviderOptions{})
+
+	provider, err := registry.Provider("fake")
+	assert.NoError(t, err)
+
+	quote, err := provider.GetPrice(context.Background(), "nvda", "usd")
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(120).Equal(quote.Price))
+	assert.Equal(t, "fake", quote.Source)
+
+	_, err = registry.Provider("bloomberg")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
+
+func Test_Registry_Timeout(t *testing.T) {
+	registry := NewRegistry()
+	fake := NewFakeProvider("slow")
+	fake.SetPrice("BTC", "USD", decimal.NewFromInt(1))
+	fake.SetDelay(time.Second)
+	registry.Register(fake, ProviderOptions{Timeout: 20 * time.Millisecond})
+
+	provider, _ := registry.Provider("slow")
+	_, err := provider.GetPrice(context.Background(), "BTC", "USD")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func Test_Registry_RateLimit(t *testing.T) {
+	registry := NewRegistry()
+	fake := NewFakeProvider("limited")
+	fake.SetPrice("BTC", "USD", decimal.NewFromInt(1))
+	registry.Register(fake, ProviderOptions{Timeout: 50 * time.Millisecond, RequestsPerMinute: 1})
+
+	provider, _ := registry.Provider("limited")
+	_, err := provider.GetPrice(context.Background(), "BTC", "USD")
+	assert.NoError(t, err)
+
+	_, err = provider.GetPrice(context.Background(), "BTC", "USD")
+	assert.Error(t, err)
+	assert.Equal(t, 1, fake.Calls())
+}
@@ -0,0 +1,199 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	utilCache "github.com/juanMaAV92/go-utils/cache"
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ManualSource = "manual"
+
+	defaultCacheTTL = 5 * time.Minute
+)
+
+type cache interface {
+	Get(ctx context.Context, key string, destination interface{}) (bool, error)
+	Set(ctx context.Context, key string, value interface{}, opts ...utilCache.SetOption) error
+}
+
+type priceRecorder interface {
+	RecordPrice(ctx context.Context, assetID uint64, at time.Time, price decimal.Decimal, currency, source string) error
+}
+
+type converter interface {
+	Convert(ctx context.Context, amount decimal.Decimal, from, to string, at time.Time) (decimal.Decimal, error)
+}
+
+// Valuation is the value of an asset position in the asset currency.
+type Valuation struct {
+	UnitPrice   decimal.Decimal
+	MarketValue decimal.Decimal
+	Currency    string
+	Source      string
+	AsOf        time.Time
+}
+
+type service struct {
+	registry  *Registry
+	cache     cache
+	recorder  priceRecorder
+	converter converter
+	logger    log.Logger
+	cacheTTL  time.Duration
+}
+
+func NewService(registry *Registry, cache cache, recorder priceRecorder, converter converter, logger log.Logger, cacheTTL time.Duration) *service {
+	if cacheTTL <= 0 {
+		cacheTTL = defaultCacheTTL
+	}
+	return &service{
+		registry:  registry,
+		cache:     cache,
+		recorder:  recorder,
+		converter: converter,
+		logger:    logger,
+		cacheTTL:  cacheTTL,
+	}
+}
+
+// GetQuote returns the latest price of a ticker, served from the cache while it is fresh.
+func (s *service) GetQuote(ctx context.Context, source, ticker, currency string) (*Quote, error) {
+	quote, _, err := s.getQuote(ctx, source, ticker, currency)
+	return quote, err
+}
+
+// getQuote also reports whether the quote was fetched from the provider rather
+// than served from the cache.
+func (s *service) getQuote(ctx context.Context, source, ticker, currency string) (*Quote, bool, error) {
+	provider, err := s.registry.Provider(source)
+	if err != nil {
+		return nil, false, err
+	}
+
+	key := quoteCacheKey(source, ticker, currency)
+	var cached Quote
+	found, err := s.cache.Get(ctx, key, &cached)
+	if err != nil {
+		s.logger.Error(ctx, "price_cache_get", "error getting cache", log.Field("key", key), log.Field("error", err))
+	}
+	if found && err == nil {
+		return &cached, false, nil
+	}
+
+	quote, err := s.fetchQuote(ctx, provider, key, ticker, currency)
+	if err != nil {
+		return nil, false, err
+	}
+	return quote, true, nil
+}
+
+// fetchQuote gets a quote from the provider and caches it.
+func (s *service) fetchQuote(ctx context.Context, provider PriceProvider, key, ticker, currency string) (*Quote, error) {
+	quote, err := provider.GetPrice(ctx, ticker, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.Set(ctx, key, quote, utilCache.WithTTL(s.cacheTTL)); err != nil {
+		s.logger.Error(ctx, "price_cache_set", "error setting cache", log.Field("key", key), log.Field("error", err))
+	}
+	return quote, nil
+}
+
+// ValueAsset values an asset with its market price, or with current_value when
+// auto pricing is disabled. Freshly fetched prices are added to the price history
+// and quotes in another currency are converted to the asset currency.
+func (s *service) ValueAsset(ctx context.Context, asset *entities.Asset) (*Valuation, error) {
+	if !asset.AutoPricingEnabled {
+		return ManualValuation(asset), nil
+	}
+	if asset.Ticker == nil || asset.PriceSource == nil {
+		return nil, ErrUnknownProvider
+	}
+
+	quote, fetched, err := s.getQuote(ctx, *asset.PriceSource, *asset.Ticker, asset.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if fetched {
+		s.recordQuote(ctx, asset, quote)
+	}
+	return s.quoteValuation(ctx, asset, quote)
+}
+
+// RefreshAsset fetches the market price of an auto priced asset skipping the
+// cache, then caches it and adds it to the price history.
+func (s *service) RefreshAsset(ctx context.Context, asset *entities.Asset) (*Valuation, error) {
+	if !asset.AutoPricingEnabled || asset.Ticker == nil || asset.PriceSource == nil {
+		return nil, ErrUnknownProvider
+	}
+	provider, err := s.registry.Provider(*asset.PriceSource)
+	if err != nil {
+		return nil, err
+	}
+
+	key := quoteCacheKey(*asset.PriceSource, *asset.Ticker, asset.Currency)
+	quote, err := s.fetchQuote(ctx, provider, key, *asset.Ticker, asset.Currency)
+	if err != nil {
+		return nil, err
+	}
+	s.recordQuote(ctx, asset, quote)
+	return s.quoteValuation(ctx, asset, quote)
+}
+
+func (s *service) recordQuote(ctx context.Context, asset *entities.Asset, quote *Quote) {
+	if err := s.recorder.RecordPrice(ctx, asset.ID, quote.AsOf, quote.Price, quote.Currency, quote.Source); err != nil {
+		s.logger.Error(ctx, "price_history_record", "error recording price", log.Field("asset_id", asset.ID), log.Field("error", err))
+	}
+}
+
+func (s *service) quoteValuation(ctx context.Context, asset *entities.Asset, quote *Quote) (*Valuation, error) {
+	unitPrice, currency := quote.Price, quote.Currency
+	if currency != "" && asset.Currency != "" && !strings.EqualFold(currency, asset.Currency) {
+		converted, err := s.converter.Convert(ctx, unitPrice, currency, asset.Currency, quote.AsOf)
+		if err != nil {
+			return nil, err
+		}
+		unitPrice, currency = converted, asset.Currency
+	}
+
+	return &Valuation{
+		UnitPrice:   unitPrice,
+		MarketValue: asset.TotalUnits.Mul(unitPrice),
+		Currency:    currency,
+		Source:      quote.Source,
+		AsOf:        quote.AsOf,
+	}, nil
+}
+
+// ManualValuation treats current_value as the value of the whole position.
+func ManualValuation(asset *entities.Asset) *Valuation {
+	marketValue := decimal.Zero
+	if asset.CurrentValue.Valid {
+		marketValue = asset.CurrentValue.Decimal
+	}
+
+	unitPrice := decimal.Zero
+	if asset.TotalUnits.IsPositive() {
+		unitPrice = marketValue.Div(asset.TotalUnits)
+	}
+
+	return &Valuation{
+		UnitPrice:   unitPrice,
+		MarketValue: marketValue,
+		Currency:    asset.Currency,
+		Source:      ManualSource,
+		AsOf:        asset.UpdatedAt,
+	}
+}
+
+func quoteCacheKey(source, ticker, currency string) string {
+	return fmt.Sprintf("price_quote:%s:%s:%s", source, strings.ToUpper(ticker), strings.ToUpper(currency))
+}
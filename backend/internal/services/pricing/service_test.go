@@ -0,0 +1,227 @@
+package pricing
+
+import (
+	"context"
+	libErrors "errors"
+	"testing"
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPriceRecorder struct {
+	mock.Mock
+}
+
+func (m *MockPriceRecorder) RecordPrice(ctx context.Context, assetID uint64, at time.Time, price decimal.Decimal, currency, source string) error {
+	args := m.Called(ctx, assetID, at, price, currency, source)
+	return args.Error(0)
+}
+
+type MockConverter struct {
+	mock.Mock
+}
+
+func (m *MockConverter) Convert(ctx context.Context, amount decimal.Decimal, from, to string, at time.Time) (decimal.Decimal, error) {
+	args := m.Called(ctx, amount, from, to, at)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
+}
+
+func Test_ValueAsset(t *testing.T) {
+	ctx := context.Background()
+	ticker := "NVDA"
+	source := "fake"
+
+	testCases := []struct {
+		name                string
+		asset               *entities.Asset
+		mockFunc            func(*FakeProvider, *mocks.Cache, *MockPriceRecorder, *mocks.Logger)
+		expectedMarketValue decimal.Decimal
+		expectedSource      string
+		expectedCalls       int
+		expectedError       error
+	}{
+		{
+			name: "manual asset uses current value",
+			asset: &entities.Asset{
+				Currency:     "COP",
+				TotalUnits:   decimal.NewFromInt(2),
+				CurrentValue: decimal.NewNullDecimal(decimal.NewFromInt(5000000)),
+			},
+			mockFunc:            func(provider *FakeProvider, cache *mocks.Cache, recorder *MockPriceRecorder, logger *mocks.Logger) {},
+			expectedMarketValue: decimal.NewFromInt(5000000),
+			expectedSource:      ManualSource,
+		},
+		{
+			name: "cache miss fetches from provider and caches the quote",
+			asset: &entities.Asset{
+				ID:                 7,
+				Currency:           "USD",
+				Ticker:             &ticker,
+				PriceSource:        &source,
+				TotalUnits:         decimal.NewFromInt(3),
+				AutoPricingEnabled: true,
+			},
+			mockFunc: func(provider *FakeProvider, cache *mocks.Cache, recorder *MockPriceRecorder, logger *mocks.Logger) {
+				provider.SetPrice("NVDA", "USD", decimal.NewFromInt(100))
+				cache.On("Get", mock.Anything, "price_quote:fake:NVDA:USD", mock.Anything).Return(false, nil)
+				cache.On("Set", mock.Anything, "price_quote:fake:NVDA:USD", mock.Anything, mock.Anything).Return(nil)
+				recorder.On("RecordPrice", mock.Anything, uint64(7), mock.Anything, decimal.NewFromInt(100), "USD", "fake").Return(nil)
+			},
+			expectedMarketValue: decimal.NewFromInt(300),
+			expectedSource:      "fake",
+			expectedCalls:       1,
+		},
+		{
+			name: "cache hit skips the provider",
+			asset: &entities.Asset{
+				Currency:           "USD",
+				Ticker:             &ticker,
+				PriceSource:        &source,
+				TotalUnits:         decimal.NewFromInt(3),
+				AutoPricingEnabled: true,
+			},
+			mockFunc: func(provider *FakeProvider, cache *mocks.Cache, recorder *MockPriceRecorder, logger *mocks.Logger) {
+				cache.On("Get", mock.Anything, "price_quote:fake:NVDA:USD", mock.Anything).Return(true, nil).Run(func(args mock.Arguments) {
+					quote := args.Get(2).(*Quote)
+					quote.Price = decimal.NewFromInt(90)
+					quote.Source = "fake"
+				})
+			},
+			expectedMarketValue: decimal.NewFromInt(270),
+			expectedSource:      "fake",
+		},
+		{
+			name: "cache errors fall back to the provider",
+			asset: &entities.Asset{
+				Currency:           "USD",
+				Ticker:             &ticker,
+				PriceSource:        &source,
+				TotalUnits:         decimal.NewFromInt(1),
+				AutoPricingEnabled: true,
+			},
+			mockFunc: func(provider *FakeProvider, cache *mocks.Cache, recorder *MockPriceRecorder, logger *mocks.Logger) {
+				provider.SetPrice("NVDA", "USD", decimal.NewFromInt(100))
+				cache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(false, libErrors.New("redis down"))
+				cache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(libErrors.New("redis down"))
+				logger.On("Error", mock.Anything, "price_cache_get", mock.Anything, mock.Anything).Return()
+				logger.On("Error", mock.Anything, "price_cache_set", mock.Anything, mock.Anything).Return()
+				recorder.On("RecordPrice", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(libErrors.New("db down"))
+				logger.On("Error", mock.Anything, "price_history_record", mock.Anything, mock.Anything).Return()
+			},
+			expectedMarketValue: decimal.NewFromInt(100),
+			expectedSource:      "fake",
+			expectedCalls:       1,
+		},
+		{
+			name: "provider error",
+			asset: &entities.Asset{
+				Currency:           "USD",
+				Ticker:             &ticker,
+				PriceSource:        &source,
+				AutoPricingEnabled: true,
+			},
+			mockFunc: func(provider *FakeProvider, cache *mocks.Cache, recorder *MockPriceRecorder, logger *mocks.Logger) {
+				cache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+			},
+			expectedCalls: 1,
+			expectedError: ErrPriceNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewFakeProvider(source)
+			cache := new(mocks.Cache)
+			recorder := new(MockPriceRecorder)
+			logger := new(mocks.Logger)
+			tc.mockFunc(provider, cache, recorder, logger)
+
+			registry := NewRegistry()
+			registry.Register(provider, ProviderOptions{Timeout: time.Second})
+			svc := NewService(registry, cache, recorder, new(MockConverter), logger, time.Minute)
+
+			valuation, err := svc.ValueAsset(ctx, tc.asset)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, tc.expectedMarketValue.Equal(valuation.MarketValue), "market value: %s", valuation.MarketValue)
+				assert.Equal(t, tc.expectedSource, valuation.Source)
+			}
+			assert.Equal(t, tc.expectedCalls, provider.Calls())
+			cache.AssertExpectations(t)
+			recorder.AssertExpectations(t)
+			logger.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_RefreshAsset_SkipsCache(t *testing.T) {
+	ctx := context.Background()
+	ticker := "bitcoin"
+	source := "fake"
+
+	provider := NewFakeProvider(source)
+	provider.SetPrice("bitcoin", "USD", decimal.NewFromInt(60000))
+	cache := new(mocks.Cache)
+	cache.On("Set", mock.Anything, "price_quote:fake:BITCOIN:USD", mock.Anything, mock.Anything).Return(nil)
+	recorder := new(MockPriceRecorder)
+	recorder.On("RecordPrice", mock.Anything, uint64(4), mock.Anything, decimal.NewFromInt(60000), "USD", "fake").Return(nil)
+
+	registry := NewRegistry()
+	registry.Register(provider, ProviderOptions{Timeout: time.Second})
+	svc := NewService(registry, cache, recorder, new(MockConverter), new(mocks.Logger), time.Minute)
+
+	valuation, err := svc.RefreshAsset(ctx, &entities.Asset{
+		ID:                 4,
+		Currency:           "USD",
+		Ticker:             &ticker,
+		PriceSource:        &source,
+		TotalUnits:         decimal.RequireFromString("0.5"),
+		AutoPricingEnabled: true,
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(30000).Equal(valuation.MarketValue))
+	assert.Equal(t, 1, provider.Calls())
+	cache.AssertExpectations(t)
+	recorder.AssertExpectations(t)
+}
+
+func Test_ValueAsset_ConvertsQuoteCurrency(t *testing.T) {
+	ctx := context.Background()
+	ticker := "EC"
+	source := "fake"
+
+	provider := NewFakeProvider(source)
+	provider.SetQuote("EC", "USD", Quote{Ticker: "EC", Price: decimal.NewFromInt(40000), Currency: "COP", Source: source})
+	cache := new(mocks.Cache)
+	cache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+	cache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	recorder := new(MockPriceRecorder)
+	recorder.On("RecordPrice", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	converter := new(MockConverter)
+	converter.On("Convert", mock.Anything, decimal.NewFromInt(40000), "COP", "USD", mock.Anything).Return(decimal.NewFromInt(10), nil)
+
+	registry := NewRegistry()
+	registry.Register(provider, ProviderOptions{Timeout: time.Second})
+	svc := NewService(registry, cache, recorder, converter, new(mocks.Logger), time.Minute)
+
+	valuation, err := svc.ValueAsset(ctx, &entities.Asset{
+		Currency:           "USD",
+		Ticker:             &ticker,
+		PriceSource:        &source,
+		TotalUnits:         decimal.NewFromInt(3),
+		AutoPricingEnabled: true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", valuation.Currency)
+	assert.True(t, decimal.NewFromInt(30).Equal(valuation.MarketValue))
+	converter.AssertExpectations(t)
+}
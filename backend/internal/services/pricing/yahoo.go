@@ -0,0 +1,100 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+)
+
+const yahooDefaultBaseURL = "https://query1.finance.yahoo.com"
+
+type yahooProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+type yahooChartResponse struct {
+	Chart struct {
+		Result []struct {
+			Meta struct {
+				Currency           string      `json:"currency"`
+				Symbol             string      `json:"symbol"`
+				RegularMarketPrice json.Number `json:"regularMarketPrice"`
+				RegularMarketTime  int64       `json:"regularMarketTime"`
+			} `json:"meta"`
+		} `json:"result"`
+	} `json:"chart"`
+}
+
+func NewYahooProvider(baseURL string, client *http.Client) *yahooProvider {
+	if baseURL == "" {
+		baseURL = yahooDefaultBaseURL
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &yahooProvider{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+func (p *yahooProvider) Name() string {
+	return entities.PriceSourceYahoo
+}
+
+// GetPrice returns the regular market price in the listing currency of the ticker;
+// the requested currency is not used because Yahoo quotes each symbol in a single currency.
+func (p *yahooProvider) GetPrice(ctx context.Context, ticker, currency string) (*Quote, error) {
+	endpoint := fmt.Sprintf("%s/v8/finance/chart/%s?range=1d&interval=1d", p.baseURL, url.PathEscape(ticker))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; zenith-financial)")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrPriceNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("yahoo: unexpected status %d", resp.StatusCode)
+	}
+
+	var body yahooChartResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("yahoo: decoding response: %w", err)
+	}
+	if len(body.Chart.Result) == 0 || body.Chart.Result[0].Meta.RegularMarketPrice == "" {
+		return nil, ErrPriceNotFound
+	}
+
+	meta := body.Chart.Result[0].Meta
+	price, err := decimal.NewFromString(meta.RegularMarketPrice.String())
+	if err != nil {
+		return nil, fmt.Errorf("yahoo: parsing price: %w", err)
+	}
+
+	asOf := time.Now()
+	if meta.RegularMarketTime > 0 {
+		asOf = time.Unix(meta.RegularMarketTime, 0)
+	}
+
+	return &Quote{
+		Ticker:   ticker,
+		Price:    price,
+		Currency: strings.ToUpper(meta.Currency),
+		Source:   p.Name(),
+		AsOf:     asOf,
+	}, nil
+}
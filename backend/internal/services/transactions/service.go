@@ -0,0 +1,238 @@
+package transactions
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/costbasis"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	invalidTransactionCode = "INVALID_TRANSACTION"
+	insufficientUnitsCode  = "INSUFFICIENT_UNITS"
+	assetNotFoundCode      = "ASSET_NOT_FOUND"
+)
+
+type assetRepository interface {
+	GetByCode(ctx context.Context, userID uint64, code uuid.UUID) (*entities.Asset, error)
+	GetByCodeForUpdate(ctx context.Context, userID uint64, code uuid.UUID) (*entities.Asset, error)
+	Update(ctx context.Context, asset *entities.Asset) error
+}
+
+type transactionRepository interface {
+	Create(ctx context.Context, transaction *entities.Transaction) error
+	ListByAsset(ctx context.Context, assetID uint64) ([]entities.Transaction, error)
+}
+
+type transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type service struct {
+	assetRepository       assetRepository
+	transactionRepository transactionRepository
+	transactor            transactor
+}
+
+func NewService(assetRepo assetRepository, transactionRepo transactionRepository, transactor transactor) *service {
+	return &service{
+		assetRepository:       assetRepo,
+		transactionRepository: transactionRepo,
+		transactor:            transactor,
+	}
+}
+
+func (s *service) CreateTransaction(ctx context.Context, userID uint64, assetCode uuid.UUID, req *request.CreateTransaction) (*response.TransactionResult, error) {
+	transactionType := strings.ToUpper(strings.TrimSpace(req.Type))
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+
+	if messages := validateTransaction(transactionType, currency, req); len(messages) > 0 {
+		return nil, errors.New(http.StatusBadRequest, invalidTransactionCode, messages)
+	}
+
+	var asset *entities.Asset
+	var transaction *entities.Transaction
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		// The row stays locked until the commit, so concurrent sells of the
+		// asset see the units left by each other.
+		found, err := s.assetRepository.GetByCodeForUpdate(ctx, userID, assetCode)
+		asset, err = checkAsset(found, err)
+		if err != nil {
+			return err
+		}
+
+		executedAt := time.Now()
+		if req.ExecutedAt != nil {
+			executedAt = *req.ExecutedAt
+		}
+		// Totals are added to invested_total as they are, so they have to be
+		// in the currency of the asset.
+		if currency == "" {
+			currency = asset.Currency
+		}
+		if currency != asset.Currency {
+			return errors.New(http.StatusBadRequest, invalidTransactionCode, []string{"currency must be the currency of the asset, " + asset.Currency})
+		}
+
+		transaction = &entities.Transaction{
+			Code:      uuid.New(),
+			AssetID:   asset.ID,
+			Type:      transactionType,
+			Units:     req.Units,
+			Total:     req.Total,
+			FeeTotal:  req.FeeTotal,
+			Currency:  currency,
+			Note:      req.Note,
+			CreatedAt: executedAt,
+		}
+
+		if err := ApplyTransaction(asset, transaction); err != nil {
+			return err
+		}
+		if !transaction.IsInflow() {
+			ledger, err := s.transactionRepository.ListByAsset(ctx, asset.ID)
+			if err != nil {
+				return err
+			}
+			if !coveredFrom(costbasis.Ledger(asset, ledger), transaction) {
+				return insufficientUnits()
+			}
+		}
+		asset.UpdatedAt = time.Now()
+
+		if err := s.transactionRepository.Create(ctx, transaction); err != nil {
+			return errors.New(http.StatusInternalServerError, "CREATE_TRANSACTION_ERROR", []string{"Unable to create transaction"})
+		}
+		if err := s.assetRepository.Update(ctx, asset); err != nil {
+			return errors.New(http.StatusInternalServerError, "UPDATE_ASSET_ERROR", []string{"Unable to update asset"})
+		}
+		return nil
+	})
+	if err != nil {
+		if _, ok := err.(*errors.ErrorResponse); ok {
+			return nil, err
+		}
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+
+	return &response.TransactionResult{
+		Transaction: response.ToTransactionResponse(transaction),
+		Asset:       response.ToAssetResponse(asset),
+	}, nil
+}
+
+func (s *service) ListTransactions(ctx context.Context, userID uint64, assetCode uuid.UUID) ([]*response.Transaction, error) {
+	asset, err := s.findAsset(ctx, userID, assetCode)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.transactionRepository.ListByAsset(ctx, asset.ID)
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	return response.ToTransactionsResponse(transactions), nil
+}
+
+// ApplyTransaction updates the asset holdings with the effect of the transaction.
+// Outflows reduce invested_total proportionally to the units leaving the position.
+func ApplyTransaction(asset *entities.Asset, transaction *entities.Transaction) error {
+	if transaction.IsInflow() {
+		asset.TotalUnits = asset.TotalUnits.Add(transaction.Units)
+		asset.InvestedTotal = asset.InvestedTotal.Add(transaction.Total).Add(transaction.FeeTotal)
+		return nil
+	}
+
+	if transaction.Units.GreaterThan(asset.TotalUnits) {
+		return insufficientUnits()
+	}
+
+	remainingUnits := asset.TotalUnits.Sub(transaction.Units)
+	if remainingUnits.IsZero() {
+		asset.InvestedTotal = decimal.Zero
+	} else {
+		costRemoved := asset.InvestedTotal.Mul(transaction.Units).Div(asset.TotalUnits)
+		asset.InvestedTotal = asset.InvestedTotal.Sub(costRemoved)
+	}
+	asset.TotalUnits = remainingUnits
+	return nil
+}
+
+// coveredFrom reports whether the units held never go negative from the
+// transaction on, once it is inserted in the ledger at its date. A backdated
+// outflow has to be covered by the units held at the time, and must not leave
+// the later outflows uncovered.
+func coveredFrom(ledger []entities.Transaction, transaction *entities.Transaction) bool {
+	ordered := append(slices.Clone(ledger), *transaction)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].CreatedAt.Before(ordered[j].CreatedAt)
+	})
+
+	units := decimal.Zero
+	reached := false
+	for _, entry := range ordered {
+		if entry.IsInflow() {
+			units = units.Add(entry.Units)
+		} else {
+			units = units.Sub(entry.Units)
+		}
+		reached = reached || entry.Code == transaction.Code
+		if reached && units.IsNegative() {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *service) findAsset(ctx context.Context, userID uint64, code uuid.UUID) (*entities.Asset, error) {
+	return checkAsset(s.assetRepository.GetByCode(ctx, userID, code))
+}
+
+func checkAsset(asset *entities.Asset, err error) (*entities.Asset, error) {
+	if err != nil {
+		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+	}
+	if asset == nil {
+		return nil, errors.New(http.StatusNotFound, assetNotFoundCode, []string{"Asset not found"})
+	}
+	return asset, nil
+}
+
+func insufficientUnits() error {
+	return errors.New(http.StatusUnprocessableEntity, insufficientUnitsCode, []string{"Not enough units to complete the operation"})
+}
+
+func validateTransaction(transactionType, currency string, req *request.CreateTransaction) []string {
+	var messages []string
+
+	if !entities.IsValidTransactionType(transactionType) {
+		messages = append(messages, "type must be one of: BUY, SELL, DEPOSIT, WITHDRAW")
+	}
+	if !req.Units.IsPositive() {
+		messages = append(messages, "units must be greater than zero")
+	}
+	if req.Total.IsNegative() {
+		messages = append(messages, "total cannot be negative")
+	}
+	if req.FeeTotal.IsNegative() {
+		messages = append(messages, "fee_total cannot be negative")
+	}
+	if len(currency) > 3 {
+		messages = append(messages, "currency must have at most 3 characters")
+	}
+	if req.ExecutedAt != nil && req.ExecutedAt.After(time.Now()) {
+		messages = append(messages, "executed_at cannot be in the future")
+	}
+
+	return messages
+}
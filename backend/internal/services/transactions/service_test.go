@@ -0,0 +1,308 @@
+package transactions
+
+import (
+	"context"
+	libErrors "errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/pointers"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAssetRepository struct {
+	mock.Mock
+}
+
+func (m *MockAssetRepository) GetByCode(ctx context.Context, userID uint64, code uuid.UUID) (*entities.Asset, error) {
+	args := m.Called(ctx, userID, code)
+	if asset, ok := args.Get(0).(*entities.Asset); ok {
+		return asset, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAssetRepository) GetByCodeForUpdate(ctx context.Context, userID uint64, code uuid.UUID) (*entities.Asset, error) {
+	args := m.Called(ctx, userID, code)
+	if asset, ok := args.Get(0).(*entities.Asset); ok {
+		return asset, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAssetRepository) Update(ctx context.Context, asset *entities.Asset) error {
+	args := m.Called(ctx, asset)
+	return args.Error(0)
+}
+
+type MockTransactionRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransactionRepository) Create(ctx context.Context, transaction *entities.Transaction) error {
+	args := m.Called(ctx, transaction)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) ListByAsset(ctx context.Context, assetID uint64) ([]entities.Transaction, error) {
+	args := m.Called(ctx, assetID)
+	if transactions, ok := args.Get(0).([]entities.Transaction); ok {
+		return transactions, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockTransactor struct {
+	mock.Mock
+}
+
+func (m *MockTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.Called(ctx)
+	return fn(ctx)
+}
+
+func Test_CreateTransaction(t *testing.T) {
+	ctx := context.Background()
+	userID := uint64(1)
+	assetCode := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+
+	now := time.Now()
+	ledger := []entities.Transaction{
+		{Code: uuid.New(), Type: entities.TransactionTypeBuy, Units: decimal.NewFromInt(10), Total: decimal.NewFromInt(1000), CreatedAt: now.Add(-5 * time.Hour)},
+	}
+
+	holding := func() *entities.Asset {
+		return &entities.Asset{
+			ID:            10,
+			Code:          assetCode,
+			UserID:        userID,
+			Currency:      "USD",
+			TotalUnits:    decimal.NewFromInt(10),
+			InvestedTotal: decimal.NewFromInt(1000),
+		}
+	}
+
+	testCases := []struct {
+		name                  string
+		req                   *request.CreateTransaction
+		expectedUnits         decimal.Decimal
+		expectedInvestedTotal decimal.Decimal
+		expectedError         *errors.ErrorResponse
+		mockFunc              func(*MockAssetRepository, *MockTransactionRepository, *MockTransactor)
+	}{
+		{
+			name: "buy adds units and invested total including fees",
+			req: &request.CreateTransaction{
+				Type:     "buy",
+				Units:    decimal.NewFromInt(5),
+				Total:    decimal.NewFromInt(600),
+				FeeTotal: decimal.NewFromInt(2),
+			},
+			expectedUnits:         decimal.NewFromInt(15),
+			expectedInvestedTotal: decimal.NewFromInt(1602),
+			mockFunc: func(assets *MockAssetRepository, transactions *MockTransactionRepository, tx *MockTransactor) {
+				tx.On("WithTransaction", mock.Anything)
+				assets.On("GetByCodeForUpdate", mock.Anything, userID, assetCode).Return(holding(), nil)
+				transactions.On("Create", mock.Anything, mock.MatchedBy(func(transaction *entities.Transaction) bool {
+					return transaction.Type == entities.TransactionTypeBuy && transaction.Currency == "USD" && transaction.AssetID == 10
+				})).Return(nil)
+				assets.On("Update", mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name: "sell removes units at average cost",
+			req: &request.CreateTransaction{
+				Type:  entities.TransactionTypeSell,
+				Units: decimal.NewFromInt(4),
+				Total: decimal.NewFromInt(800),
+			},
+			expectedUnits:         decimal.NewFromInt(6),
+			expectedInvestedTotal: decimal.NewFromInt(600),
+			mockFunc: func(assets *MockAssetRepository, transactions *MockTransactionRepository, tx *MockTransactor) {
+				tx.On("WithTransaction", mock.Anything)
+				assets.On("GetByCodeForUpdate", mock.Anything, userID, assetCode).Return(holding(), nil)
+				transactions.On("ListByAsset", mock.Anything, uint64(10)).Return(ledger, nil)
+				transactions.On("Create", mock.Anything, mock.Anything).Return(nil)
+				assets.On("Update", mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name: "withdraw everything resets invested total",
+			req: &request.CreateTransaction{
+				Type:  entities.TransactionTypeWithdraw,
+				Units: decimal.NewFromInt(10),
+				Total: decimal.NewFromInt(1100),
+			},
+			expectedUnits:         decimal.Zero,
+			expectedInvestedTotal: decimal.Zero,
+			mockFunc: func(assets *MockAssetRepository, transactions *MockTransactionRepository, tx *MockTransactor) {
+				tx.On("WithTransaction", mock.Anything)
+				assets.On("GetByCodeForUpdate", mock.Anything, userID, assetCode).Return(holding(), nil)
+				transactions.On("ListByAsset", mock.Anything, uint64(10)).Return(ledger, nil)
+				transactions.On("Create", mock.Anything, mock.Anything).Return(nil)
+				assets.On("Update", mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name: "sell more units than held",
+			req: &request.CreateTransaction{
+				Type:  entities.TransactionTypeSell,
+				Units: decimal.NewFromInt(11),
+				Total: decimal.NewFromInt(800),
+			},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusUnprocessableEntity, Code: insufficientUnitsCode},
+			mockFunc: func(assets *MockAssetRepository, transactions *MockTransactionRepository, tx *MockTransactor) {
+				tx.On("WithTransaction", mock.Anything)
+				assets.On("GetByCodeForUpdate", mock.Anything, userID, assetCode).Return(holding(), nil)
+			},
+		},
+		{
+			name: "backdated sell covered by the units held then",
+			req: &request.CreateTransaction{
+				Type:       entities.TransactionTypeSell,
+				Units:      decimal.NewFromInt(4),
+				Total:      decimal.NewFromInt(800),
+				ExecutedAt: pointers.Pointer(now.Add(-4 * time.Hour)),
+			},
+			expectedUnits:         decimal.NewFromInt(6),
+			expectedInvestedTotal: decimal.NewFromInt(600),
+			mockFunc: func(assets *MockAssetRepository, transactions *MockTransactionRepository, tx *MockTransactor) {
+				tx.On("WithTransaction", mock.Anything)
+				assets.On("GetByCodeForUpdate", mock.Anything, userID, assetCode).Return(holding(), nil)
+				transactions.On("ListByAsset", mock.Anything, uint64(10)).Return(ledger, nil)
+				transactions.On("Create", mock.Anything, mock.Anything).Return(nil)
+				assets.On("Update", mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name: "backdated sell before the buy that covers it",
+			req: &request.CreateTransaction{
+				Type:       entities.TransactionTypeSell,
+				Units:      decimal.NewFromInt(4),
+				Total:      decimal.NewFromInt(800),
+				ExecutedAt: pointers.Pointer(now.Add(-6 * time.Hour)),
+			},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusUnprocessableEntity, Code: insufficientUnitsCode},
+			mockFunc: func(assets *MockAssetRepository, transactions *MockTransactionRepository, tx *MockTransactor) {
+				tx.On("WithTransaction", mock.Anything)
+				assets.On("GetByCodeForUpdate", mock.Anything, userID, assetCode).Return(holding(), nil)
+				transactions.On("ListByAsset", mock.Anything, uint64(10)).Return(ledger, nil)
+			},
+		},
+		{
+			name: "backdated sell uncovering a later sell",
+			req: &request.CreateTransaction{
+				Type:       entities.TransactionTypeSell,
+				Units:      decimal.NewFromInt(8),
+				Total:      decimal.NewFromInt(800),
+				ExecutedAt: pointers.Pointer(now.Add(-2 * time.Hour)),
+			},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusUnprocessableEntity, Code: insufficientUnitsCode},
+			mockFunc: func(assets *MockAssetRepository, transactions *MockTransactionRepository, tx *MockTransactor) {
+				tx.On("WithTransaction", mock.Anything)
+				assets.On("GetByCodeForUpdate", mock.Anything, userID, assetCode).Return(holding(), nil)
+				// 10 bought, 6 sold and 6 bought back: 10 held now, but only 4
+				// after the sale of an hour ago.
+				transactions.On("ListByAsset", mock.Anything, uint64(10)).Return(append(slices.Clone(ledger),
+					entities.Transaction{Code: uuid.New(), Type: entities.TransactionTypeSell, Units: decimal.NewFromInt(6), CreatedAt: now.Add(-time.Hour)},
+					entities.Transaction{Code: uuid.New(), Type: entities.TransactionTypeBuy, Units: decimal.NewFromInt(6), CreatedAt: now.Add(-30 * time.Minute)},
+				), nil)
+			},
+		},
+		{
+			name: "currency other than the asset's",
+			req: &request.CreateTransaction{
+				Type:     entities.TransactionTypeBuy,
+				Units:    decimal.NewFromInt(1),
+				Total:    decimal.NewFromInt(100),
+				Currency: "eur",
+			},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusBadRequest, Code: invalidTransactionCode},
+			mockFunc: func(assets *MockAssetRepository, transactions *MockTransactionRepository, tx *MockTransactor) {
+				tx.On("WithTransaction", mock.Anything)
+				assets.On("GetByCodeForUpdate", mock.Anything, userID, assetCode).Return(holding(), nil)
+			},
+		},
+		{
+			name: "unknown transaction type",
+			req: &request.CreateTransaction{
+				Type:  "DIVIDEND",
+				Units: decimal.NewFromInt(1),
+				Total: decimal.NewFromInt(1),
+			},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusBadRequest, Code: invalidTransactionCode},
+			mockFunc:      func(assets *MockAssetRepository, transactions *MockTransactionRepository, tx *MockTransactor) {},
+		},
+		{
+			name: "zero units",
+			req: &request.CreateTransaction{
+				Type:  entities.TransactionTypeBuy,
+				Total: decimal.NewFromInt(1),
+			},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusBadRequest, Code: invalidTransactionCode},
+			mockFunc:      func(assets *MockAssetRepository, transactions *MockTransactionRepository, tx *MockTransactor) {},
+		},
+		{
+			name: "asset not found",
+			req: &request.CreateTransaction{
+				Type:  entities.TransactionTypeBuy,
+				Units: decimal.NewFromInt(1),
+				Total: decimal.NewFromInt(1),
+			},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusNotFound, Code: assetNotFoundCode},
+			mockFunc: func(assets *MockAssetRepository, transactions *MockTransactionRepository, tx *MockTransactor) {
+				tx.On("WithTransaction", mock.Anything)
+				assets.On("GetByCodeForUpdate", mock.Anything, userID, assetCode).Return(nil, nil)
+			},
+		},
+		{
+			name: "asset update fails",
+			req: &request.CreateTransaction{
+				Type:  entities.TransactionTypeBuy,
+				Units: decimal.NewFromInt(1),
+				Total: decimal.NewFromInt(1),
+			},
+			expectedError: &errors.ErrorResponse{HttpCode: http.StatusInternalServerError, Code: "UPDATE_ASSET_ERROR"},
+			mockFunc: func(assets *MockAssetRepository, transactions *MockTransactionRepository, tx *MockTransactor) {
+				tx.On("WithTransaction", mock.Anything)
+				assets.On("GetByCodeForUpdate", mock.Anything, userID, assetCode).Return(holding(), nil)
+				transactions.On("Create", mock.Anything, mock.Anything).Return(nil)
+				assets.On("Update", mock.Anything, mock.Anything).Return(libErrors.New("db error"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assetRepository := new(MockAssetRepository)
+			transactionRepository := new(MockTransactionRepository)
+			transactor := new(MockTransactor)
+			tc.mockFunc(assetRepository, transactionRepository, transactor)
+			svc := NewService(assetRepository, transactionRepository, transactor)
+
+			resp, err := svc.CreateTransaction(ctx, userID, assetCode, tc.req)
+			if tc.expectedError != nil {
+				assert.Error(t, err)
+				errorResponse, ok := err.(*errors.ErrorResponse)
+				assert.True(t, ok)
+				assert.Equal(t, tc.expectedError.HttpCode, errorResponse.ErrorHTTPCode())
+				assert.Equal(t, tc.expectedError.Code, errorResponse.ErrorCode())
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, tc.expectedUnits.Equal(resp.Asset.TotalUnits), "units: %s", resp.Asset.TotalUnits)
+				assert.True(t, tc.expectedInvestedTotal.Equal(resp.Asset.InvestedTotal), "invested: %s", resp.Asset.InvestedTotal)
+			}
+			assetRepository.AssertExpectations(t)
+			transactionRepository.AssertExpectations(t)
+			transactor.AssertExpectations(t)
+		})
+	}
+}
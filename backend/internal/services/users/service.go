@@ -3,10 +3,13 @@ package users
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/costbasis"
 	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
 	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
 	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
@@ -15,45 +18,66 @@ import (
 
 type userRepository interface {
 	Create(ctx context.Context, user *entities.User) error
-	GetByEmail(ctx context.Context, email string) (*entities.User, error)
+	EmailTaken(ctx context.Context, email string) (bool, error)
+	UsernameTaken(ctx context.Context, username string) (bool, error)
+}
+
+type emailVerifier interface {
+	SendVerification(ctx context.Context, user *entities.User) error
 }
 
 type service struct {
 	userRepository userRepository
+	verifier       emailVerifier
+	logger         log.Logger
 }
 
-func NewService(userRepo userRepository) *service {
-	return &service{userRepository: userRepo}
+func NewService(userRepo userRepository, verifier emailVerifier, logger log.Logger) *service {
+	return &service{userRepository: userRepo, verifier: verifier, logger: logger}
 }
 
+// CreateUser registers a user with an unverified email and emails them the
+// link to verify it. A failed email does not undo the registration, a new link
+// can be requested. The email and username of deleted accounts stay taken
+// until they are purged.
 func (s *service) CreateUser(ctx context.Context, req *request.CreateUser) (*response.User, error) {
-	existingUser, err := s.userRepository.GetByEmail(ctx, req.Email)
+	taken, err := s.userRepository.EmailTaken(ctx, req.Email)
 	if err != nil {
 		return nil, err
 	}
-	if existingUser != nil {
+	if taken {
 		return nil, errors.New(http.StatusConflict, "USER_EXISTS", []string{"User already exists"})
 	}
-
-	salt, err := crypto.GeneratePasswordSalt()
+	taken, err = s.userRepository.UsernameTaken(ctx, req.UserName)
 	if err != nil {
-		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
+		return nil, err
+	}
+	if taken {
+		return nil, errors.New(http.StatusConflict, "USERNAME_TAKEN", []string{"Username is already taken"})
+	}
+
+	costBasisMethod := costbasis.DefaultMethod
+	if req.CostBasisMethod != "" {
+		costBasisMethod, err = costbasis.ParseMethod(req.CostBasisMethod)
+		if err != nil {
+			return nil, errors.New(http.StatusBadRequest, "INVALID_COST_BASIS_METHOD", []string{"cost_basis_method must be one of: FIFO, LIFO, AVERAGE"})
+		}
 	}
 
-	hashedPassword, err := crypto.HashPassword(req.Password, salt)
+	hashedPassword, err := crypto.HashPassword(req.Password)
 	if err != nil {
 		return nil, errors.New(http.StatusInternalServerError, errors.StatusInternalServerErrorCode, []string{"Unable to process request"})
 	}
 
 	newUser := &entities.User{
-		Code:         uuid.New(),
-		Username:     req.UserName,
-		Email:        req.Email,
-		PasswordHash: hashedPassword,
-		PasswordSalt: salt,
-		Currency:     req.Currency,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		Code:            uuid.New(),
+		Username:        req.UserName,
+		Email:           req.Email,
+		PasswordHash:    hashedPassword,
+		Currency:        strings.ToUpper(strings.TrimSpace(req.Currency)),
+		CostBasisMethod: string(costBasisMethod),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 
 	err = s.userRepository.Create(ctx, newUser)
@@ -61,5 +85,9 @@ func (s *service) CreateUser(ctx context.Context, req *request.CreateUser) (*res
 		return nil, errors.New(http.StatusInternalServerError, "CREATE_USER_ERROR", []string{"Unable to create user"})
 	}
 
+	if err := s.verifier.SendVerification(ctx, newUser); err != nil {
+		s.logger.Error(ctx, "create_user_verification", "error sending verification email", log.Field("user_code", newUser.Code), log.Field("error", err))
+	}
+
 	return response.ToUserResponse(newUser), nil
 }
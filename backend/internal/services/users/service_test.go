@@ -10,6 +10,7 @@ import (
 	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/request"
 	"github.com/juanMaAV92/zenith-financial/backend/internal/domain/response"
 	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -21,12 +22,24 @@ func (m *MockRepository) Create(ctx context.Context, user *entities.User) error
 	args := m.Called(ctx, user)
 	return args.Error(0)
 }
-func (m *MockRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+func (m *MockRepository) EmailTaken(ctx context.Context, email string) (bool, error) {
 	args := m.Called(ctx, email)
-	if user, ok := args.Get(0).(*entities.User); ok {
-		return user, args.Error(1)
-	}
-	return nil, args.Error(1)
+	return args.Bool(0), args.Error(1)
+}
+func (m *MockRepository) UsernameTaken(ctx context.Context, username string) (bool, error) {
+	args := m.Called(ctx, username)
+	return args.Bool(0), args.Error(1)
+}
+
+// fakeVerifier records the users sent a verification email.
+type fakeVerifier struct {
+	users []*entities.User
+	err   error
+}
+
+func (f *fakeVerifier) SendVerification(ctx context.Context, user *entities.User) error {
+	f.users = append(f.users, user)
+	return f.err
 }
 
 func Test_CreateUser(t *testing.T) {
@@ -54,10 +67,10 @@ func Test_CreateUser(t *testing.T) {
 				[]string{"Unable to process request"},
 			),
 			mockFunc: func(repo *MockRepository) {
-				repo.On("GetByEmail",
+				repo.On("EmailTaken",
 					ctx,
 					mock.Anything,
-				).Return(nil, errors.New(
+				).Return(false, errors.New(
 					http.StatusInternalServerError,
 					"ERROR",
 					[]string{"Unable to process request"},
@@ -78,10 +91,34 @@ func Test_CreateUser(t *testing.T) {
 				[]string{"User already exists"},
 			),
 			mockFunc: func(repo *MockRepository) {
-				repo.On("GetByEmail",
+				repo.On("EmailTaken",
 					ctx,
 					"user_exists@mail.com",
-				).Return(&entities.User{}, nil)
+				).Return(true, nil)
+			},
+		},
+		{
+			name: "username already taken error 409",
+			request: &request.CreateUser{
+				UserName: "taken",
+				Email:    "test@mail.com",
+				Password: "password123",
+			},
+			expectedResponse: nil,
+			expectError: errors.New(
+				http.StatusConflict,
+				"USERNAME_TAKEN",
+				[]string{"Username is already taken"},
+			),
+			mockFunc: func(repo *MockRepository) {
+				repo.On("EmailTaken",
+					ctx,
+					"test@mail.com",
+				).Return(false, nil)
+				repo.On("UsernameTaken",
+					ctx,
+					"taken",
+				).Return(true, nil)
 			},
 		},
 		{
@@ -99,10 +136,14 @@ func Test_CreateUser(t *testing.T) {
 				[]string{"Unable to create user"},
 			),
 			mockFunc: func(repo *MockRepository) {
-				repo.On("GetByEmail",
+				repo.On("EmailTaken",
 					ctx,
 					"test@mail.com",
-				).Return(nil, nil)
+				).Return(false, nil)
+				repo.On("UsernameTaken",
+					ctx,
+					"testuser",
+				).Return(false, nil)
 				repo.On("Create",
 					ctx,
 					mock.AnythingOfType("*entities.User"),
@@ -120,7 +161,7 @@ func Test_CreateUser(t *testing.T) {
 			mockRepo := new(MockRepository)
 			tc.mockFunc(mockRepo)
 
-			svc := NewService(mockRepo)
+			svc := NewService(mockRepo, &fakeVerifier{}, new(mocks.Logger))
 			response, err := svc.CreateUser(ctx, tc.request)
 
 			if tc.expectError != nil {
@@ -134,3 +175,36 @@ func Test_CreateUser(t *testing.T) {
 		})
 	}
 }
+
+func Test_CreateUser_SendsVerification(t *testing.T) {
+	ctx := context.Background()
+	req := &request.CreateUser{UserName: "testuser", Email: "new@mail.com", Password: "password123", Currency: "USD"}
+
+	t.Run("new users start unverified", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("EmailTaken", ctx, "new@mail.com").Return(false, nil)
+		mockRepo.On("UsernameTaken", ctx, "testuser").Return(false, nil)
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*entities.User")).Return(nil)
+		verifier := &fakeVerifier{}
+
+		response, err := NewService(mockRepo, verifier, new(mocks.Logger)).CreateUser(ctx, req)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, false, response.EmailVerified)
+		assert.Equal(t, 1, len(verifier.users))
+		assert.Equal(t, "new@mail.com", verifier.users[0].Email)
+	})
+
+	t.Run("a failed email keeps the user", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("EmailTaken", ctx, "new@mail.com").Return(false, nil)
+		mockRepo.On("UsernameTaken", ctx, "testuser").Return(false, nil)
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*entities.User")).Return(nil)
+		logger := new(mocks.Logger)
+		logger.On("Error", ctx, "create_user_verification", mock.Anything, mock.Anything).Return()
+
+		response, err := NewService(mockRepo, &fakeVerifier{err: errors.New(http.StatusInternalServerError, "MAIL", nil)}, logger).CreateUser(ctx, req)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "new@mail.com", response.Email)
+		logger.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,2 @@
+ALTER TABLE "Users"
+    ADD COLUMN "cost_basis_method" VARCHAR(16) NOT NULL DEFAULT 'FIFO'; -- FIFO / LIFO / AVERAGE
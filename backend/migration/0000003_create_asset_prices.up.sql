@@ -0,0 +1,12 @@
+CREATE TABLE "AssetPrices" (
+    "id" BIGSERIAL NOT NULL PRIMARY KEY,
+    "asset_id" BIGINT NOT NULL,
+    "date" DATE NOT NULL,                -- día de la cotización (UTC)
+    "price" DECIMAL NOT NULL,            -- precio unitario
+    "currency" VARCHAR(3) NOT NULL,
+    "source" VARCHAR(255) NOT NULL,      -- Ej: 'yahoo', 'coingecko', 'manual'
+    "created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (now()),
+    "updated_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (now()),
+    FOREIGN KEY ("asset_id") REFERENCES "Assets"("id") ON DELETE CASCADE,
+    UNIQUE ("asset_id", "date")
+);
@@ -0,0 +1,17 @@
+CREATE TABLE "PortfolioSnapshots" (
+    "id" BIGSERIAL NOT NULL PRIMARY KEY,
+    "user_id" BIGINT NOT NULL,
+    "asset_id" BIGINT NOT NULL,
+    "date" DATE NOT NULL,                -- día del snapshot (UTC)
+    "units" DECIMAL NOT NULL,
+    "invested_total" DECIMAL NOT NULL,
+    "market_value" DECIMAL NOT NULL,     -- valor de mercado en la moneda del activo
+    "currency" VARCHAR(3) NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (now()),
+    "updated_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (now()),
+    FOREIGN KEY ("user_id") REFERENCES "Users"("id") ON DELETE CASCADE,
+    FOREIGN KEY ("asset_id") REFERENCES "Assets"("id") ON DELETE CASCADE,
+    UNIQUE ("asset_id", "date")
+);
+
+CREATE INDEX "idx_portfolio_snapshots_user_date" ON "PortfolioSnapshots" ("user_id", "date");
@@ -0,0 +1,11 @@
+CREATE TABLE "FxRates" (
+    "id" BIGSERIAL NOT NULL PRIMARY KEY,
+    "base" VARCHAR(3) NOT NULL,          -- moneda pivote, Ej: 'USD'
+    "quote" VARCHAR(3) NOT NULL,         -- moneda cotizada, Ej: 'COP'
+    "date" DATE NOT NULL,                -- día de la tasa (UTC)
+    "rate" DECIMAL NOT NULL,             -- unidades de quote por 1 unidad de base
+    "source" VARCHAR(255) NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (now()),
+    "updated_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (now()),
+    UNIQUE ("base", "quote", "date")
+);
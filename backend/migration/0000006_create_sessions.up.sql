@@ -0,0 +1,16 @@
+CREATE TABLE "Sessions" (
+    "id" BIGSERIAL NOT NULL PRIMARY KEY,
+    "code" UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    "user_id" BIGINT NOT NULL,
+    "device_label" VARCHAR(255),                 -- nombre del dispositivo indicado al iniciar sesión
+    "user_agent" TEXT,
+    "ip_address" VARCHAR(45),                    -- admite direcciones IPv6
+    "refresh_token_id" UUID NOT NULL,            -- único refresh token vigente de la sesión
+    "created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (now()),
+    "last_used_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (now()),
+    "expires_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+    "revoked_at" TIMESTAMP WITH TIME ZONE,       -- NULL mientras la sesión esté activa
+    FOREIGN KEY ("user_id") REFERENCES "Users"("id") ON DELETE CASCADE
+);
+
+CREATE INDEX "idx_sessions_user_id" ON "Sessions" ("user_id");
@@ -0,0 +1,2 @@
+ALTER TABLE "Users"
+    ADD COLUMN "token_version" INTEGER NOT NULL DEFAULT 0; -- se incrementa para invalidar todos los tokens emitidos
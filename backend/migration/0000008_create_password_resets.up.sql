@@ -0,0 +1,11 @@
+CREATE TABLE "PasswordResets" (
+    "id" BIGSERIAL NOT NULL PRIMARY KEY,
+    "user_id" BIGINT NOT NULL,
+    "token_hash" VARCHAR(64) NOT NULL UNIQUE,   -- SHA-256 del token enviado por correo, nunca el token
+    "created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (now()),
+    "expires_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+    "used_at" TIMESTAMP WITH TIME ZONE,         -- NULL hasta que se usa, solo sirve una vez
+    FOREIGN KEY ("user_id") REFERENCES "Users"("id") ON DELETE CASCADE
+);
+
+CREATE INDEX "idx_password_resets_user_id" ON "PasswordResets" ("user_id");
@@ -0,0 +1,17 @@
+ALTER TABLE "Users"
+    ADD COLUMN "email_verified_at" TIMESTAMP WITH TIME ZONE; -- NULL mientras el correo no esté verificado
+
+-- las cuentas existentes se dan por verificadas para no bloquear su acceso
+UPDATE "Users" SET "email_verified_at" = "created_at";
+
+CREATE TABLE "EmailVerifications" (
+    "id" BIGSERIAL NOT NULL PRIMARY KEY,
+    "user_id" BIGINT NOT NULL,
+    "token_hash" VARCHAR(64) NOT NULL UNIQUE,   -- SHA-256 del token enviado por correo, nunca el token
+    "created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (now()),
+    "expires_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+    "used_at" TIMESTAMP WITH TIME ZONE,         -- NULL hasta que se usa, solo sirve una vez
+    FOREIGN KEY ("user_id") REFERENCES "Users"("id") ON DELETE CASCADE
+);
+
+CREATE INDEX "idx_email_verifications_user_id" ON "EmailVerifications" ("user_id");
@@ -0,0 +1,14 @@
+ALTER TABLE "Users"
+    ADD COLUMN "mfa_secret" TEXT,                        -- secreto TOTP cifrado con AES-GCM, nunca en claro
+    ADD COLUMN "mfa_enabled_at" TIMESTAMP WITH TIME ZONE, -- NULL mientras el segundo factor no esté confirmado
+    ADD COLUMN "mfa_last_step" BIGINT NOT NULL DEFAULT 0; -- último intervalo TOTP aceptado, evita reutilizar un código
+
+CREATE TABLE "RecoveryCodes" (
+    "id" BIGSERIAL NOT NULL PRIMARY KEY,
+    "user_id" BIGINT NOT NULL,
+    "code_hash" VARCHAR(64) NOT NULL,           -- SHA-256 del código, se muestra una sola vez al usuario
+    "created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (now()),
+    "used_at" TIMESTAMP WITH TIME ZONE,         -- NULL hasta que se usa, solo sirve una vez
+    FOREIGN KEY ("user_id") REFERENCES "Users"("id") ON DELETE CASCADE,
+    UNIQUE ("user_id", "code_hash")
+);
@@ -0,0 +1,2 @@
+ALTER TABLE "Users"
+    ALTER COLUMN "password_salt" SET DEFAULT ''; -- los hashes argon2id llevan su propia sal, solo los bcrypt antiguos la usan
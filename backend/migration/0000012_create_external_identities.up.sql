@@ -0,0 +1,12 @@
+CREATE TABLE "ExternalIdentities" (
+    "id" BIGSERIAL NOT NULL PRIMARY KEY,
+    "user_id" BIGINT NOT NULL,
+    "provider" VARCHAR(63) NOT NULL,             -- nombre del proveedor OIDC en la configuración, ej: 'google'
+    "subject" VARCHAR(255) NOT NULL,             -- claim "sub" del ID token, estable por proveedor
+    "email" VARCHAR(255),                        -- email verificado al vincular, solo informativo
+    "created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (now()),
+    FOREIGN KEY ("user_id") REFERENCES "Users"("id") ON DELETE CASCADE,
+    UNIQUE ("provider", "subject")
+);
+
+CREATE INDEX "idx_external_identities_user_id" ON "ExternalIdentities" ("user_id");
@@ -0,0 +1,16 @@
+CREATE TABLE "APIKeys" (
+    "id" BIGSERIAL NOT NULL PRIMARY KEY,
+    "code" UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    "user_id" BIGINT NOT NULL,
+    "name" VARCHAR(100) NOT NULL,                -- nombre dado por el usuario, ej: 'Google Sheets'
+    "prefix" VARCHAR(16) NOT NULL,               -- inicio de la llave para reconocerla, ej: 'zfk_AbC123'
+    "key_hash" VARCHAR(64) NOT NULL UNIQUE,      -- SHA-256 de la llave, la llave solo se muestra al crearla
+    "scopes" TEXT NOT NULL,                      -- permisos separados por coma, ej: 'portfolio:read,transactions:write'
+    "created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (now()),
+    "last_used_at" TIMESTAMP WITH TIME ZONE,
+    "expires_at" TIMESTAMP WITH TIME ZONE,       -- NULL si la llave no expira
+    "revoked_at" TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY ("user_id") REFERENCES "Users"("id") ON DELETE CASCADE
+);
+
+CREATE INDEX "idx_api_keys_user_id" ON "APIKeys" ("user_id");
@@ -0,0 +1,10 @@
+ALTER TABLE "Users"
+    ADD COLUMN "deleted_at" TIMESTAMP WITH TIME ZONE; -- NULL mientras la cuenta esté activa
+
+CREATE TABLE "AccountDeletions" (
+    "id" BIGSERIAL NOT NULL PRIMARY KEY,
+    "user_id" BIGINT NOT NULL UNIQUE,
+    "requested_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (now()),
+    "purge_after" TIMESTAMP WITH TIME ZONE NOT NULL,  -- fin del periodo de gracia, luego se borran sus datos
+    FOREIGN KEY ("user_id") REFERENCES "Users"("id") ON DELETE CASCADE
+);
@@ -0,0 +1,21 @@
+ALTER TABLE "Assets"
+    ADD COLUMN "opening_units" DECIMAL NOT NULL DEFAULT 0, -- unidades con las que se creó el activo, sin transacción en el libro
+    ADD COLUMN "opening_cost" DECIMAL NOT NULL DEFAULT 0;  -- costo de esas unidades en la moneda del activo
+
+-- Los activos existentes toman como saldo inicial las unidades que sus transacciones no explican.
+-- El costo es exacto mientras no haya ventas; con ventas es una aproximación.
+UPDATE "Assets" AS a
+SET "opening_units" = GREATEST(a."total_units" - l."net_units", 0),
+    "opening_cost" = CASE
+        WHEN a."total_units" > l."net_units" THEN GREATEST(a."invested_total" - l."inflow_cost", 0)
+        ELSE 0
+    END
+FROM (
+    SELECT asset."id",
+           COALESCE(SUM(CASE WHEN t."type" IN ('BUY', 'DEPOSIT') THEN t."units" ELSE -t."units" END), 0) AS "net_units",
+           COALESCE(SUM(CASE WHEN t."type" IN ('BUY', 'DEPOSIT') THEN t."total" + COALESCE(t."fee_total", 0) ELSE 0 END), 0) AS "inflow_cost"
+    FROM "Assets" AS asset
+    LEFT JOIN "Transactions" AS t ON t."asset_id" = asset."id"
+    GROUP BY asset."id"
+) AS l
+WHERE l."id" = a."id";
@@ -0,0 +1,5 @@
+ALTER TABLE "EmailVerifications"
+    ADD COLUMN "email" VARCHAR(255) NOT NULL DEFAULT ''; -- correo al que se envió el enlace, solo verifica esa dirección
+
+-- los enlaces pendientes no guardan a qué correo se enviaron, así que dejan de servir;
+-- el usuario puede pedir uno nuevo
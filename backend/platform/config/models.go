@@ -1,6 +1,9 @@
 package config
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/juanMaAV92/go-utils/cache"
 	"github.com/juanMaAV92/go-utils/database"
 	"github.com/juanMaAV92/go-utils/jwt"
@@ -12,5 +15,146 @@ type Config struct {
 	Telemetry *platform.TelemetryConfig
 	Database  *database.DBConfig
 	Jwt       *jwt.JwtConfig
+	JwtKeys   *JWTKeysConfig
 	Cache     *cache.CacheConfig
+	Pricing   *PricingConfig
+	Scheduler *SchedulerConfig
+	FX        *FXConfig
+	Mailer    *MailerConfig
+	Auth      *AuthConfig
+	Lockout   *LockoutConfig
+	Cookies   *CookieConfig
+	Proxy     *ProxyConfig
+	OIDC      *OIDCConfig
+	Export    *ExportConfig
+}
+
+// JWTKeysConfig are the keys tokens are signed with, identified by the kid of
+// their header. ActiveKeyID signs new tokens; the other keys are retiring and
+// only verify the tokens issued before a rotation. Keys are read from the
+// <kid>.pem files of Dir and from PEM, by kid. Without an ActiveKeyID the
+// shared secret of Jwt keeps signing.
+type JWTKeysConfig struct {
+	ActiveKeyID string
+	Dir         string
+	PEM         map[string]string
+}
+
+type PricingConfig struct {
+	CacheTTL  time.Duration
+	Providers map[string]PriceProviderConfig
+}
+
+type PriceProviderConfig struct {
+	BaseURL           string
+	APIKey            string
+	Timeout           time.Duration
+	RequestsPerMinute int
+}
+
+type SchedulerConfig struct {
+	Enabled bool
+	Jobs    map[string]JobConfig
+}
+
+type JobConfig struct {
+	Schedule string
+	Timeout  time.Duration
+}
+
+type FXConfig struct {
+	PivotCurrency string
+	BaseURL       string
+	Timeout       time.Duration
+	Currencies    []string
+}
+
+// MailerConfig selects how emails are delivered, see the drivers of the mailer
+// package. OutboxDir is only used by the file driver.
+type MailerConfig struct {
+	Driver    string
+	From      string
+	OutboxDir string
+}
+
+// AuthConfig holds the account recovery and verification settings.
+// FrontendURL is the base of the links sent by email. RequireVerifiedEmail
+// blocks the login of users who have not verified their email.
+// MFAEncryptionKey is the base64 encoded 32 byte key the TOTP secrets are
+// encrypted with. Deleted accounts are purged after
+// AccountDeletionGracePeriod.
+type AuthConfig struct {
+	FrontendURL                string
+	PasswordResetTTL           time.Duration
+	EmailVerificationTTL       time.Duration
+	VerificationResendCooldown time.Duration
+	RequireVerifiedEmail       bool
+	MFAEncryptionKey           string
+	AccountDeletionGracePeriod time.Duration
+}
+
+// LockoutConfig limits the failed logins. Failures are counted per email and
+// per IP over a Window that starts with the first failure. From DelayAfter
+// failures of an email on, each attempt waits BaseDelay after the last one,
+// doubled with every failure up to MaxDelay. Reaching the max failures locks
+// the email or IP for LockoutDuration.
+type LockoutConfig struct {
+	Window           time.Duration
+	MaxEmailFailures int
+	MaxIPFailures    int
+	LockoutDuration  time.Duration
+	DelayAfter       int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+}
+
+// CookieConfig enables the cookie mode of browser clients: the tokens are set
+// as HttpOnly cookies instead of returned in the body, and cookie
+// authenticated requests are protected with a double-submit CSRF token.
+// Domain may be empty to scope the cookies to the host of the API.
+type CookieConfig struct {
+	Enabled  bool
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// ProxyConfig lists the CIDR ranges of the proxies in front of the API. The
+// client IP is read from X-Forwarded-For only for requests coming through
+// them; without any, the IP of the connection is used and the header ignored.
+type ProxyConfig struct {
+	TrustedRanges []string
+}
+
+// OIDCConfig configures the OpenID Connect login. Providers are named in the
+// login URLs, e.g. /auth/oidc/google/authorize. StateTTL is how long a user
+// has to come back from the provider.
+type OIDCConfig struct {
+	StateTTL  time.Duration
+	Timeout   time.Duration
+	Providers map[string]OIDCProviderConfig
+}
+
+// OIDCProviderConfig identifies the client at a provider. RedirectURL is the
+// frontend page the provider sends the user back to, which posts the code and
+// state to the callback endpoint.
+type OIDCProviderConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// ExportConfig configures the data exports. Accounts with up to SyncAssetLimit
+// assets are exported in the request, larger ones in the background within
+// Timeout. The archive of a background export is kept in Dir, which the
+// replicas have to share, and can be downloaded for LinkTTL. Archives larger
+// than MaxArchiveSize bytes fail.
+type ExportConfig struct {
+	SyncAssetLimit int
+	Timeout        time.Duration
+	LinkTTL        time.Duration
+	Dir            string
+	MaxArchiveSize int64
 }
@@ -0,0 +1,109 @@
+package filestore
+
+import (
+	"context"
+	libErrors "errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Store keeps files in a directory. Files do not expire on their own, their
+// owner removes them with Purge.
+type Store struct {
+	dir string
+}
+
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Save writes the file with the name, replacing any previous one. The content
+// is written to a temporary file first, so a reader never sees it half written.
+func (s *Store) Save(ctx context.Context, name string, content []byte) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(s.dir, "."+name+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// Load returns the content of the file with the name, false when there is
+// none.
+func (s *Store) Load(ctx context.Context, name string) ([]byte, bool, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, false, err
+	}
+	content, err := os.ReadFile(path)
+	if libErrors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return content, true, nil
+}
+
+// Purge removes the files last written before cutoff, temporary ones left by
+// an interrupted Save included.
+func (s *Store) Purge(ctx context.Context, cutoff time.Time) error {
+	entries, err := os.ReadDir(s.dir)
+	if libErrors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if libErrors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, entry.Name())); err != nil && !libErrors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return libErrors.Join(errs...)
+}
+
+// path rejects the names that would leave the directory.
+func (s *Store) path(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || name[0] == '.' {
+		return "", fmt.Errorf("invalid file name %q", name)
+	}
+	return filepath.Join(s.dir, name), nil
+}
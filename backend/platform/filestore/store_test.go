@@ -0,0 +1,62 @@
+package filestore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SaveAndLoad(t *testing.T) {
+	ctx := context.Background()
+	store := New(filepath.Join(t.TempDir(), "exports"))
+
+	_, ok, err := store.Load(ctx, "missing.zip")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, store.Save(ctx, "archive.zip", []byte("first")))
+	assert.NoError(t, store.Save(ctx, "archive.zip", []byte("second")))
+	content, ok, err := store.Load(ctx, "archive.zip")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "second", string(content))
+
+	files, err := os.ReadDir(store.dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func Test_InvalidNames(t *testing.T) {
+	ctx := context.Background()
+	store := New(t.TempDir())
+
+	for _, name := range []string{"", "../archive.zip", "nested/archive.zip", ".hidden"} {
+		assert.Error(t, store.Save(ctx, name, []byte("content")), name)
+		_, _, err := store.Load(ctx, name)
+		assert.Error(t, err, name)
+	}
+}
+
+func Test_Purge(t *testing.T) {
+	ctx := context.Background()
+	store := New(t.TempDir())
+	now := time.Now()
+
+	assert.NoError(t, store.Save(ctx, "old.zip", []byte("old")))
+	assert.NoError(t, store.Save(ctx, "new.zip", []byte("new")))
+	old := now.Add(-2 * time.Hour)
+	assert.NoError(t, os.Chtimes(filepath.Join(store.dir, "old.zip"), old, old))
+
+	assert.NoError(t, store.Purge(ctx, now.Add(-time.Hour)))
+	_, ok, _ := store.Load(ctx, "old.zip")
+	assert.False(t, ok)
+	_, ok, _ = store.Load(ctx, "new.zip")
+	assert.True(t, ok)
+
+	// A directory that was never written to has nothing to purge.
+	assert.NoError(t, New(filepath.Join(t.TempDir(), "missing")).Purge(ctx, now))
+}
@@ -0,0 +1,29 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	mailer := NewFileMailer("no-reply@zenith.test", dir)
+	mailer.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	err := mailer.Send(context.Background(), Message{To: "ana/../x@example.com", Subject: "Hello", Body: "Line one\nLine two"})
+	assert.NoError(t, err)
+
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, "1700000000000000000-ana_.._x@example.com.eml", files[0].Name())
+		content, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+		assert.NoError(t, err)
+		assert.Equal(t, "From: no-reply@zenith.test\nTo: ana/../x@example.com\nSubject: Hello\n\nLine one\nLine two\n", string(content))
+	}
+}
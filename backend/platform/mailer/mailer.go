@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// DriverLog writes the emails to the log.
+	DriverLog = "log"
+	// DriverFile writes every email to a file of a directory.
+	DriverFile = "file"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails. The log and file mailers are meant for development
+// and tests, a provider only needs to implement Send.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+func format(from string, message Message) string {
+	return fmt.Sprintf("From: %s\nTo: %s\nSubject: %s\n\n%s\n", from, message.To, message.Subject, message.Body)
+}
@@ -0,0 +1,38 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const lockKeyPrefix = "scheduler_lock:"
+
+type cache interface {
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+	CompareAndDelete(ctx context.Context, key string, expected interface{}) (bool, error)
+}
+
+// CacheLocker is a lock shared by every replica, backed by Redis SET NX. Locks
+// expire after their TTL so a crashed replica cannot hold a job forever. Each
+// process owns its locks under a random ID, and only releases its own.
+type CacheLocker struct {
+	cache cache
+	owner string
+}
+
+func NewCacheLocker(cache cache, name string) *CacheLocker {
+	return &CacheLocker{cache: cache, owner: name + ":" + uuid.NewString()}
+}
+
+func (l *CacheLocker) Acquire(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	return l.cache.SetNX(ctx, lockKeyPrefix+name, l.owner, ttl)
+}
+
+// Release deletes the lock if this process still holds it. A lock that expired
+// and was taken by another replica is left alone.
+func (l *CacheLocker) Release(ctx context.Context, name string) error {
+	_, err := l.cache.CompareAndDelete(ctx, lockKeyPrefix+name, l.owner)
+	return err
+}
@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryCache keeps the values of the locks, ignoring their TTL.
+type memoryCache map[string]interface{}
+
+func (c memoryCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if _, ok := c[key]; ok {
+		return false, nil
+	}
+	c[key] = value
+	return true, nil
+}
+
+func (c memoryCache) CompareAndDelete(ctx context.Context, key string, expected interface{}) (bool, error) {
+	if value, ok := c[key]; !ok || value != expected {
+		return false, nil
+	}
+	delete(c, key)
+	return true, nil
+}
+
+func Test_CacheLocker(t *testing.T) {
+	ctx := context.Background()
+	cache := memoryCache{}
+	first := NewCacheLocker(cache, "zenith-financial")
+	second := NewCacheLocker(cache, "zenith-financial")
+
+	acquired, err := first.Acquire(ctx, "job", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = second.Acquire(ctx, "job", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, acquired)
+
+	// Replicas share the server name but not the owner of their locks.
+	assert.NoError(t, second.Release(ctx, "job"))
+	assert.Contains(t, cache, lockKeyPrefix+"job")
+
+	assert.NoError(t, first.Release(ctx, "job"))
+	assert.NotContains(t, cache, lockKeyPrefix+"job")
+}
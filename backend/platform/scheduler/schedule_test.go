@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseSchedule_Next(t *testing.T) {
+	from := time.Date(2025, 3, 14, 10, 17, 30, 0, time.UTC) // Friday
+
+	testCases := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{spec: "*/15 * * * *", expected: time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC)},
+		{spec: "50 23 * * *", expected: time.Date(2025, 3, 14, 23, 50, 0, 0, time.UTC)},
+		{spec: "0 9 * * 1-5", expected: time.Date(2025, 3, 17, 9, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1 * *", expected: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 12 13,20 * 1", expected: time.Date(2025, 3, 17, 12, 0, 0, 0, time.UTC)},
+		{spec: "5,40 10 * * *", expected: time.Date(2025, 3, 14, 10, 40, 0, 0, time.UTC)},
+		{spec: "0 0 29 2 *", expected: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{spec: "@hourly", expected: time.Date(2025, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{spec: "@daily", expected: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{spec: "@every 15m", expected: time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC)},
+		{spec: "@every 2h", expected: time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			schedule, err := ParseSchedule(tc.spec)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, schedule.Next(from))
+		})
+	}
+}
+
+func Test_ParseSchedule_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@every 10ms", "@weekly"} {
+		t.Run(spec, func(t *testing.T) {
+			_, err := ParseSchedule(spec)
+			assert.ErrorIs(t, err, ErrInvalidSchedule)
+		})
+	}
+}
+
+func Test_ParseSchedule_NeverMatches(t *testing.T) {
+	schedule, err := ParseSchedule("0 0 31 2 *")
+	assert.NoError(t, err)
+	assert.True(t, schedule.Next(time.Now()).IsZero())
+}
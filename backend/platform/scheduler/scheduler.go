@@ -0,0 +1,263 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/log"
+)
+
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped"
+
+	DefaultTimeout = 10 * time.Minute
+
+	jobStep = "scheduler_job"
+)
+
+var ErrDuplicateJob = errors.New("job already registered")
+
+type JobFunc func(ctx context.Context) error
+
+type locker interface {
+	Acquire(ctx context.Context, name string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, name string) error
+}
+
+// JobStatus is the outcome of the last run of a job on this replica.
+type JobStatus struct {
+	Name         string     `json:"name"`
+	Schedule     string     `json:"schedule"`
+	LastStatus   string     `json:"last_status,omitempty"`
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"`
+	LastDuration string     `json:"last_duration,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	NextRunAt    *time.Time `json:"next_run_at,omitempty"`
+}
+
+type job struct {
+	name     string
+	spec     string
+	schedule Schedule
+	run      JobFunc
+	timeout  time.Duration
+	status   JobStatus
+}
+
+// Scheduler runs named jobs on their schedules. Every run claims a lock named
+// after the job and its tick, so when several replicas share the locker only
+// one of them executes each tick.
+type Scheduler struct {
+	locker locker
+	logger log.Logger
+
+	mu      sync.RWMutex
+	jobs    map[string]*job
+	started bool
+
+	stop      chan struct{}
+	runCtx    context.Context
+	cancelRun context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+func New(locker locker, logger log.Logger) *Scheduler {
+	return &Scheduler{
+		locker: locker,
+		logger: logger,
+		jobs:   make(map[string]*job),
+	}
+}
+
+// Register adds a job. A timeout of zero uses DefaultTimeout; the timeout also
+// bounds how long the locks are held.
+func (s *Scheduler) Register(name, spec string, run JobFunc, timeout time.Duration) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[name]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, name)
+	}
+	s.jobs[name] = &job{
+		name:     name,
+		spec:     spec,
+		schedule: schedule,
+		run:      run,
+		timeout:  timeout,
+		status:   JobStatus{Name: name, Schedule: spec},
+	}
+	return nil
+}
+
+// Start launches one goroutine per registered job. Jobs registered afterwards
+// are not scheduled.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	s.stop = make(chan struct{})
+	s.runCtx, s.cancelRun = context.WithCancel(context.WithoutCancel(ctx))
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(j)
+	}
+}
+
+// Stop stops scheduling new runs and waits for the running ones. When ctx ends
+// first, running jobs are cancelled and ctx.Err() is returned.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return nil
+	}
+	s.started = false
+	close(s.stop)
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancelRun()
+		return nil
+	case <-ctx.Done():
+		s.cancelRun()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Status returns the status of every job ordered by name.
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		statuses = append(statuses, j.status)
+	}
+	sort.Slice(statuses, func(i, k int) bool {
+		return statuses[i].Name < statuses[k].Name
+	})
+	return statuses
+}
+
+func (s *Scheduler) loop(j *job) {
+	defer s.wg.Done()
+
+	for {
+		now := time.Now()
+		next := j.schedule.Next(now)
+		if next.IsZero() {
+			return
+		}
+		s.setNextRun(j, next)
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.execute(s.runCtx, j, next)
+		}
+	}
+}
+
+// execute runs the tick of a job unless another replica claimed it, and
+// records the outcome. The tick lock is left to expire, so a replica whose
+// timer fires late cannot run the tick again. The run lock keeps a slow run
+// from overlapping the next tick, and is released when the run ends.
+func (s *Scheduler) execute(ctx context.Context, j *job, tick time.Time) {
+	acquired, err := s.locker.Acquire(ctx, tickLockName(j.name, tick), j.timeout)
+	if err == nil && acquired {
+		acquired, err = s.locker.Acquire(ctx, j.name, j.timeout)
+	}
+	if err != nil {
+		s.logger.Error(ctx, jobStep, "error acquiring job lock", log.Field("job", j.name), log.Field("error", err))
+		s.finish(j, time.Now(), StatusFailed, err)
+		return
+	}
+	if !acquired {
+		s.finish(j, time.Now(), StatusSkipped, nil)
+		return
+	}
+	defer func() {
+		if err := s.locker.Release(context.WithoutCancel(ctx), j.name); err != nil {
+			s.logger.Error(ctx, jobStep, "error releasing job lock", log.Field("job", j.name), log.Field("error", err))
+		}
+	}()
+
+	startedAt := time.Now()
+	s.mu.Lock()
+	j.status.LastStatus = StatusRunning
+	j.status.LastRunAt = &startedAt
+	s.mu.Unlock()
+
+	runCtx, cancel := context.WithTimeout(ctx, j.timeout)
+	defer cancel()
+
+	err = safeRun(runCtx, j.run)
+	if err != nil {
+		s.logger.Error(ctx, jobStep, "job failed", log.Field("job", j.name), log.Field("error", err))
+		s.finish(j, startedAt, StatusFailed, err)
+		return
+	}
+	s.logger.Info(ctx, jobStep, "job finished", log.Field("job", j.name), log.Field("duration", time.Since(startedAt).String()))
+	s.finish(j, startedAt, StatusSucceeded, nil)
+}
+
+func (s *Scheduler) finish(j *job, startedAt time.Time, status string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j.status.LastStatus = status
+	j.status.LastRunAt = &startedAt
+	j.status.LastDuration = time.Since(startedAt).Round(time.Millisecond).String()
+	j.status.LastError = ""
+	if err != nil {
+		j.status.LastError = err.Error()
+	}
+}
+
+func (s *Scheduler) setNextRun(j *job, next time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.status.NextRunAt = &next
+}
+
+func tickLockName(name string, tick time.Time) string {
+	return fmt.Sprintf("%s:%d", name, tick.Unix())
+}
+
+func safeRun(ctx context.Context, run JobFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return run(ctx)
+}
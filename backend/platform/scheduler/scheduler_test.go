@@ -0,0 +1,215 @@
+package scheduler
+
+import (
+	"context"
+	libErrors "errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type fakeLocker struct {
+	mu    sync.Mutex
+	held  map[string]bool
+	err   error
+	taken int
+}
+
+func newFakeLocker() *fakeLocker {
+	return &fakeLocker{held: make(map[string]bool)}
+}
+
+func (l *fakeLocker) Acquire(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return false, l.err
+	}
+	if l.held[name] {
+		return false, nil
+	}
+	l.held[name] = true
+	l.taken++
+	return true, nil
+}
+
+func (l *fakeLocker) Release(ctx context.Context, name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.held, name)
+	return nil
+}
+
+func newLogger() *mocks.Logger {
+	logger := new(mocks.Logger)
+	logger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+	logger.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+	return logger
+}
+
+func Test_Scheduler_Execute(t *testing.T) {
+	ctx := context.Background()
+	tick := time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC)
+	tickLock := tickLockName("job", tick)
+
+	testCases := []struct {
+		name           string
+		run            JobFunc
+		lockHeld       bool
+		tickClaimed    bool
+		lockErr        error
+		expectedStatus string
+		expectedError  string
+	}{
+		{
+			name:           "successful run",
+			run:            func(ctx context.Context) error { return nil },
+			expectedStatus: StatusSucceeded,
+		},
+		{
+			name:           "failed run",
+			run:            func(ctx context.Context) error { return libErrors.New("provider down") },
+			expectedStatus: StatusFailed,
+			expectedError:  "provider down",
+		},
+		{
+			name:           "panics are reported as failures",
+			run:            func(ctx context.Context) error { panic("boom") },
+			expectedStatus: StatusFailed,
+			expectedError:  "job panicked: boom",
+		},
+		{
+			name:           "another replica holds the lock",
+			run:            func(ctx context.Context) error { t.Fatal("job must not run"); return nil },
+			lockHeld:       true,
+			expectedStatus: StatusSkipped,
+		},
+		{
+			name:           "another replica claimed the tick",
+			run:            func(ctx context.Context) error { t.Fatal("job must not run"); return nil },
+			tickClaimed:    true,
+			expectedStatus: StatusSkipped,
+		},
+		{
+			name:           "lock error",
+			run:            func(ctx context.Context) error { t.Fatal("job must not run"); return nil },
+			lockErr:        libErrors.New("redis down"),
+			expectedStatus: StatusFailed,
+			expectedError:  "redis down",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			locker := newFakeLocker()
+			locker.held["job"] = tc.lockHeld
+			locker.held[tickLock] = tc.tickClaimed
+			locker.err = tc.lockErr
+			s := New(locker, newLogger())
+			assert.NoError(t, s.Register("job", "@every 1m", tc.run, time.Second))
+
+			s.execute(ctx, s.jobs["job"], tick)
+
+			status := s.Status()[0]
+			assert.Equal(t, tc.expectedStatus, status.LastStatus)
+			assert.Equal(t, tc.expectedError, status.LastError)
+			assert.NotNil(t, status.LastRunAt)
+			assert.Equal(t, tc.lockHeld, locker.held["job"], "run lock must be released after the run")
+			assert.Equal(t, tc.lockErr == nil, locker.held[tickLock], "tick lock must be kept until it expires")
+		})
+	}
+}
+
+func Test_Scheduler_ReplicasRunEachTickOnce(t *testing.T) {
+	ctx := context.Background()
+	locker := newFakeLocker()
+	tick := time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC)
+
+	runs := 0
+	run := func(ctx context.Context) error { runs++; return nil }
+	first, second := New(locker, newLogger()), New(locker, newLogger())
+	assert.NoError(t, first.Register("job", "@every 15m", run, time.Minute))
+	assert.NoError(t, second.Register("job", "@every 15m", run, time.Minute))
+
+	first.execute(ctx, first.jobs["job"], tick)
+	// The second replica fires after the first one finished.
+	second.execute(ctx, second.jobs["job"], tick)
+	assert.Equal(t, 1, runs)
+	assert.Equal(t, StatusSkipped, second.Status()[0].LastStatus)
+
+	second.execute(ctx, second.jobs["job"], tick.Add(15*time.Minute))
+	assert.Equal(t, 2, runs)
+}
+
+func Test_Scheduler_Register(t *testing.T) {
+	s := New(newFakeLocker(), newLogger())
+
+	assert.NoError(t, s.Register("b", "@daily", func(ctx context.Context) error { return nil }, 0))
+	assert.NoError(t, s.Register("a", "*/5 * * * *", func(ctx context.Context) error { return nil }, 0))
+	assert.ErrorIs(t, s.Register("a", "@daily", func(ctx context.Context) error { return nil }, 0), ErrDuplicateJob)
+	assert.ErrorIs(t, s.Register("c", "daily", func(ctx context.Context) error { return nil }, 0), ErrInvalidSchedule)
+
+	statuses := s.Status()
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, "a", statuses[0].Name)
+	assert.Equal(t, DefaultTimeout, s.jobs["b"].timeout)
+}
+
+func Test_Scheduler_StopWaitsForRunningJobs(t *testing.T) {
+	locker := newFakeLocker()
+	s := New(locker, newLogger())
+
+	started := make(chan struct{})
+	finished := make(chan struct{})
+	err := s.Register("slow", "@every 1s", func(ctx context.Context) error {
+		close(started)
+		select {
+		case <-time.After(200 * time.Millisecond):
+			close(finished)
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}, time.Minute)
+	assert.NoError(t, err)
+
+	s.Start(context.Background())
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, s.Stop(ctx))
+
+	select {
+	case <-finished:
+	default:
+		t.Fatal("Stop returned before the running job finished")
+	}
+	assert.Equal(t, StatusSucceeded, s.Status()[0].LastStatus)
+	// The tick lock and the run lock of a single run.
+	assert.Equal(t, 2, locker.taken)
+}
+
+func Test_Scheduler_StopCancelsJobsAfterDeadline(t *testing.T) {
+	s := New(newFakeLocker(), newLogger())
+
+	started := make(chan struct{})
+	err := s.Register("stuck", "@every 1s", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}, time.Minute)
+	assert.NoError(t, err)
+
+	s.Start(context.Background())
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Stop(ctx), context.DeadlineExceeded)
+	assert.Equal(t, StatusFailed, s.Status()[0].LastStatus)
+}
@@ -0,0 +1,247 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/testhelpers"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/assets"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/middlewares"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/repositories"
+	assetService "github.com/juanMaAV92/zenith-financial/backend/internal/services/assets"
+	priceHistoryService "github.com/juanMaAV92/zenith-financial/backend/internal/services/pricehistory"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/helpers"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/validation"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (m *MockStore) FindAll(ctx context.Context, destination interface{}, conditions interface{}) error {
+	args := m.Called(ctx, destination, conditions)
+	return args.Error(0)
+}
+
+func (m *MockStore) Update(ctx context.Context, destination interface{}) error {
+	args := m.Called(ctx, destination)
+	return args.Error(0)
+}
+
+func (m *MockStore) Delete(ctx context.Context, destination interface{}, conditions interface{}) error {
+	args := m.Called(ctx, destination, conditions)
+	return args.Error(0)
+}
+
+func (m *MockStore) Raw(ctx context.Context, destination interface{}, query string, args ...interface{}) error {
+	called := m.Called(ctx, destination, query, args)
+	return called.Error(0)
+}
+
+func (m *MockStore) Exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	called := m.Called(ctx, query, args)
+	return called.Get(0).(int64), called.Error(1)
+}
+
+func Test_createAsset(t *testing.T) {
+	path := "/v1/assets"
+	cases := []testhelpers.HttpTestCase{
+		{
+			TestName: "Bad Request - Invalid JSON",
+			Request: testhelpers.TestRequest{
+				Method: "POST",
+				Url:    path,
+			},
+			RequestBody: map[string]interface{}{
+				"name": true,
+			},
+			ExpectError: &errors.ErrorResponse{
+				HttpCode: http.StatusBadRequest,
+				Code:     errors.StatusBadRequestCode,
+				Messages: []string{"Invalid request body"},
+			},
+		},
+		{
+			TestName: "Bad Request - Missing ticker for auto priced asset",
+			Request: testhelpers.TestRequest{
+				Method: "POST",
+				Url:    path,
+			},
+			RequestBody: map[string]interface{}{
+				"name":        "NVIDIA",
+				"symbol":      "NVDA",
+				"currency":    "USD",
+				"category_id": entities.CategoryStock,
+				"total_units": 2,
+			},
+			ExpectError: &errors.ErrorResponse{
+				HttpCode: http.StatusBadRequest,
+				Code:     "INVALID_ASSET",
+			},
+		},
+		{
+			TestName: "Bad Request - Invalid currency",
+			Request: testhelpers.TestRequest{
+				Method: "POST",
+				Url:    path,
+			},
+			RequestBody: map[string]interface{}{
+				"name":        "NVIDIA",
+				"symbol":      "NVDA",
+				"currency":    "US1",
+				"category_id": entities.CategoryStock,
+			},
+			ExpectError: &errors.ErrorResponse{
+				HttpCode: http.StatusBadRequest,
+				Code:     validation.ValidationErrorCode,
+			},
+		},
+		{
+			TestName: "success - Create asset priced in BTC",
+			Request: testhelpers.TestRequest{
+				Method: "POST",
+				Url:    path,
+			},
+			RequestBody: map[string]interface{}{
+				"name":         "Ethereum",
+				"symbol":       "ETH",
+				"ticker":       "ethereum",
+				"currency":     "BTC",
+				"category_id":  entities.CategoryCrypto,
+				"total_units":  2,
+				"price_source": "coingecko",
+			},
+			Response: testhelpers.ExpectedResponse{
+				Status: http.StatusCreated,
+			},
+			MockFunc: func(s *echo.Echo, c echo.Context) {
+				mockStore := c.Get("mockStore").(*MockStore)
+				mockStore.On("Create", mock.Anything, mock.AnythingOfType("*entities.Asset")).Return(nil)
+			},
+		},
+		{
+			TestName: "success - Create asset",
+			Request: testhelpers.TestRequest{
+				Method: "POST",
+				Url:    path,
+			},
+			RequestBody: map[string]interface{}{
+				"name":         "NVIDIA",
+				"symbol":       "NVDA",
+				"ticker":       "NVDA",
+				"currency":     "USD",
+				"category_id":  entities.CategoryStock,
+				"total_units":  2,
+				"price_source": "yahoo",
+			},
+			Response: testhelpers.ExpectedResponse{
+				Status: http.StatusCreated,
+			},
+			MockFunc: func(s *echo.Echo, c echo.Context) {
+				mockStore := c.Get("mockStore").(*MockStore)
+				mockStore.On("Create", mock.Anything, mock.AnythingOfType("*entities.Asset")).Return(nil)
+			},
+		},
+	}
+
+	app := helpers.NewTestServer()
+
+	for _, test := range cases {
+		t.Run(test.TestName, func(t *testing.T) {
+			ctx, recorder := testhelpers.PrepareContextFormTestCase(app.Server.Echo, test)
+			ctx.Set(middlewares.AuthenticatedUserKey, &entities.User{ID: 1, Code: uuid.New()})
+
+			mockStore := new(MockStore)
+			ctx.Set("mockStore", mockStore)
+
+			if test.MockFunc != nil {
+				test.MockFunc(app.Server.Echo, ctx)
+			}
+
+			assetRepository := repositories.NewAssetRepository(mockStore)
+			handler := assets.NewHandler(assetService.NewService(assetRepository, priceHistoryService.NewService(assetRepository, repositories.NewAssetPriceRepository(mockStore)), app.Logger))
+
+			err := handler.CreateAsset(ctx)
+			if test.ExpectError != nil {
+				assert.Error(t, err)
+				errorResponse, ok := err.(*errors.ErrorResponse)
+				assert.True(t, ok)
+				assert.Equal(t, test.ExpectError.HttpCode, errorResponse.ErrorHTTPCode())
+				assert.Equal(t, test.ExpectError.Code, errorResponse.ErrorCode())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.Response.Status, recorder.Code)
+			}
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_getAsset(t *testing.T) {
+	code := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	cases := []testhelpers.HttpTestCase{
+		{
+			TestName: "Bad Request - Invalid asset code",
+			Request: testhelpers.TestRequest{
+				Method: "GET",
+				Url:    "/v1/assets/not-a-uuid",
+			},
+			ExpectError: &errors.ErrorResponse{
+				HttpCode: http.StatusBadRequest,
+				Code:     errors.StatusBadRequestCode,
+				Messages: []string{"Invalid asset code"},
+			},
+		},
+		{
+			TestName: "Not Found - Asset of another user",
+			Request: testhelpers.TestRequest{
+				Method: "GET",
+				Url:    "/v1/assets/" + code.String(),
+			},
+			ExpectError: &errors.ErrorResponse{
+				HttpCode: http.StatusNotFound,
+				Code:     "ASSET_NOT_FOUND",
+				Messages: []string{"Asset not found"},
+			},
+			MockFunc: func(s *echo.Echo, c echo.Context) {
+				mockStore := c.Get("mockStore").(*MockStore)
+				mockStore.On("FindOne",
+					mock.Anything,
+					mock.Anything,
+					map[string]interface{}{"code": code, "user_id": uint64(1)}).Return(false, nil)
+			},
+		},
+	}
+
+	app := helpers.NewTestServer()
+
+	for _, test := range cases {
+		t.Run(test.TestName, func(t *testing.T) {
+			ctx, _ := testhelpers.PrepareContextFormTestCase(app.Server.Echo, test)
+			ctx.Set(middlewares.AuthenticatedUserKey, &entities.User{ID: 1, Code: uuid.New()})
+			ctx.SetParamNames("code")
+			ctx.SetParamValues(test.Request.Url[len("/v1/assets/"):])
+
+			mockStore := new(MockStore)
+			ctx.Set("mockStore", mockStore)
+
+			if test.MockFunc != nil {
+				test.MockFunc(app.Server.Echo, ctx)
+			}
+
+			assetRepository := repositories.NewAssetRepository(mockStore)
+			handler := assets.NewHandler(assetService.NewService(assetRepository, priceHistoryService.NewService(assetRepository, repositories.NewAssetPriceRepository(mockStore)), app.Logger))
+
+			err := handler.GetAsset(ctx)
+			assert.Error(t, err)
+			errorResponse, ok := err.(*errors.ErrorResponse)
+			assert.True(t, ok)
+			assert.Equal(t, test.ExpectError.HttpCode, errorResponse.ErrorHTTPCode())
+			assert.Equal(t, test.ExpectError.Code, errorResponse.ErrorCode())
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
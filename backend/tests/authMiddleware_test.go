@@ -0,0 +1,389 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/go-utils/errors"
+	jwtUtils "github.com/juanMaAV92/go-utils/jwt"
+	"github.com/juanMaAV92/go-utils/pointers"
+	"github.com/juanMaAV92/go-utils/testhelpers"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd/middlewares"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/repositories"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/apikeys"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/helpers"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/authcookies"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/crypto"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/tokens"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_authMiddleware(t *testing.T) {
+	path := "/v1/assets"
+	userCode := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+
+	jwtConfig := jwtUtils.JwtConfig{
+		SecretKey:       "test_secret",
+		Issuer:          "zenith-financial",
+		AccessTokenTTL:  -1 * time.Minute,
+		RefreshTokenTTL: 24 * time.Hour,
+		SigningMethod:   jwt.SigningMethodHS256,
+	}
+	jwtUtils.InitJWTConfig(&jwtConfig)
+	expiredToken, err := jwtUtils.GenerateAccessToken(userCode)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	jwtConfig.AccessTokenTTL = 15 * time.Minute
+	jwtUtils.InitJWTConfig(&jwtConfig)
+	accessToken, err := jwtUtils.GenerateAccessToken(userCode)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	refreshToken, err := jwtUtils.GenerateRefreshToken(userCode)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	keys, err := tokens.NewKeySet("", tokens.NewSecretKey(jwtConfig.SecretKey, jwtConfig.SigningMethod))
+	if err != nil {
+		t.Fatalf("failed to build key set: %v", err)
+	}
+	issuer := tokens.NewIssuer(&jwtConfig, keys)
+	cookies := authcookies.New(authcookies.Config{Enabled: true})
+	sessionToken, err := issuer.GenerateAccessToken(userCode, uuid.New(), 0)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	apiKey := entities.APIKeyPrefix + "0123456789abcdef"
+	apiKeyCondition := map[string]interface{}{"key_hash": crypto.HashToken(apiKey)}
+
+	cases := []testhelpers.HttpTestCase{
+		{
+			TestName: "Unauthorized - Missing Authorization header",
+			Request: testhelpers.TestRequest{
+				Method: "GET",
+				Url:    path,
+			},
+			ExpectError: &errors.ErrorResponse{
+				HttpCode: http.StatusUnauthorized,
+				Code:     errors.StatusUnauthorizedCode,
+				Messages: []string{"Authorization header is required"},
+			},
+		},
+		{
+			TestName: "Unauthorized - Malformed token",
+			Request: testhelpers.TestRequest{
+				Method: "GET",
+				Url:    path,
+				Header: map[string]string{"Authorization": "Bearer not-a-jwt"},
+			},
+			ExpectError: &errors.ErrorResponse{
+				HttpCode: http.StatusUnauthorized,
+				Code:     errors.StatusUnauthorizedCode,
+				Messages: []string{"Invalid token"},
+			},
+		},
+		{
+			TestName: "Unauthorized - Expired token",
+			Request: testhelpers.TestRequest{
+				Method: "GET",
+				Url:    path,
+				Header: map[string]string{"Authorization": "Bearer " + expiredToken},
+			},
+			ExpectError: &errors.ErrorResponse{
+				HttpCode: http.StatusUnauthorized,
+				Code:     errors.StatusUnauthorizedCode,
+				Messages: []string{"Token expired"},
+			},
+		},
+		{
+			TestName: "Unauthorized - Refresh token used as access token",
+			Request: testhelpers.TestRequest{
+				Method: "GET",
+				Url:    path,
+				Header: map[string]string{"Authorization": "Bearer " + refreshToken},
+			},
+			ExpectError: &errors.ErrorResponse{
+				HttpCode: http.StatusUnauthorized,
+				Code:     errors.StatusUnauthorizedCode,
+				Messages: []string{"Invalid token type"},
+			},
+		},
+		{
+			TestName: "Unauthorized - Revoked token for a user that no longer exists",
+			Request: testhelpers.TestRequest{
+				Method: "GET",
+				Url:    path,
+				Header: map[string]string{"Authorization": "Bearer " + accessToken},
+			},
+			ExpectError: &errors.ErrorResponse{
+				HttpCode: http.StatusUnauthorized,
+				Code:     errors.StatusUnauthorizedCode,
+				Messages: []string{"Token has been revoked"},
+			},
+			MockFunc: func(s *echo.Echo, c echo.Context) {
+				mockStore := c.Get("mockStore").(*MockStore)
+				mockStore.On("FindOne",
+					mock.Anything,
+					mock.Anything,
+					map[string]interface{}{"code": userCode}).Return(false, nil)
+			},
+		},
+		{
+			TestName: "Unauthorized - Denylisted token",
+			Request: testhelpers.TestRequest{
+				Method: "GET",
+				Url:    path,
+				Header: map[string]string{"Authorization": "Bearer " + sessionToken},
+			},
+			ExpectError: &errors.ErrorResponse{
+				HttpCode: http.StatusUnauthorized,
+				Code:     errors.StatusUnauthorizedCode,
+				Messages: []string{"Token has been revoked"},
+			},
+			MockFunc: func(s *echo.Echo, c echo.Context) {
+				mockCache := c.Get("mockCache").(*mocks.Cache)
+				mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Once()
+			},
+		},
+		{
+			TestName: "Unauthorized - Token issued before the token version was bumped",
+			Request: testhelpers.TestRequest{
+				Method: "GET",
+				Url:    path,
+				Header: map[string]string{"Authorization": "Bearer " + sessionToken},
+			},
+			ExpectError: &errors.ErrorResponse{
+				HttpCode: http.StatusUnauthorized,
+				Code:     errors.StatusUnauthorizedCode,
+				Messages: []string{"Token has been revoked"},
+			},
+			MockFunc: func(s *echo.Echo, c echo.Context) {
+				mockCache := c.Get("mockCache").(*mocks.Cache)
+				mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(false, nil).Twice()
+				mockStore := c.Get("mockStore").(*MockStore)
+				mockStore.On("FindOne",
+					mock.Anything,
+					mock.Anything,
+					map[string]interface{}{"code": userCode}).Return(true, nil).Run(func(args mock.Arguments) {
+					user := args.Get(1).(*entities.User)
+					user.Code = userCode
+					user.TokenVersion = 1
+				})
+			},
+		},
+		{
+			TestName: "success - Valid session token",
+			Request: testhelpers.TestRequest{
+				Method: "GET",
+				Url:    path,
+				Header: map[string]string{"Authorization": "Bearer " + sessionToken},
+			},
+			Response: testhelpers.ExpectedResponse{
+				Status: http.StatusOK,
+				Body:   pointers.Pointer(`{"code":"123e4567-e89b-12d3-a456-426614174000"}`),
+			},
+			MockFunc: func(s *echo.Echo, c echo.Context) {
+				mockCache := c.Get("mockCache").(*mocks.Cache)
+				mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(false, nil).Twice()
+				mockStore := c.Get("mockStore").(*MockStore)
+				mockStore.On("FindOne",
+					mock.Anything,
+					mock.Anything,
+					map[string]interface{}{"code": userCode}).Return(true, nil).Run(func(args mock.Arguments) {
+					user := args.Get(1).(*entities.User)
+					user.Code = userCode
+				})
+			},
+		},
+		{
+			TestName: "Forbidden - Cookie authenticated request without CSRF token",
+			Request: testhelpers.TestRequest{
+				Method: "POST",
+				Url:    path,
+				Header: map[string]string{"Cookie": "access_token=" + sessionToken + "; csrf_token=csrf"},
+			},
+			ExpectError: &errors.ErrorResponse{
+				HttpCode: http.StatusForbidden,
+				Code:     "INVALID_CSRF_TOKEN",
+				Messages: []string{"Invalid CSRF token"},
+			},
+		},
+		{
+			TestName: "success - Cookie authenticated request with CSRF token",
+			Request: testhelpers.TestRequest{
+				Method: "POST",
+				Url:    path,
+				Header: map[string]string{
+					"Cookie":       "access_token=" + sessionToken + "; csrf_token=csrf",
+					"X-CSRF-Token": "csrf",
+				},
+			},
+			Response: testhelpers.ExpectedResponse{
+				Status: http.StatusOK,
+				Body:   pointers.Pointer(`{"code":"123e4567-e89b-12d3-a456-426614174000"}`),
+			},
+			MockFunc: func(s *echo.Echo, c echo.Context) {
+				mockCache := c.Get("mockCache").(*mocks.Cache)
+				mockCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(false, nil).Twice()
+				mockStore := c.Get("mockStore").(*MockStore)
+				mockStore.On("FindOne",
+					mock.Anything,
+					mock.Anything,
+					map[string]interface{}{"code": userCode}).Return(true, nil).Run(func(args mock.Arguments) {
+					user := args.Get(1).(*entities.User)
+					user.Code = userCode
+				})
+			},
+		},
+		{
+			TestName: "Unauthorized - Unknown API key",
+			Request: testhelpers.TestRequest{
+				Method: "GET",
+				Url:    path,
+				Header: map[string]string{"X-API-Key": apiKey},
+			},
+			ExpectError: &errors.ErrorResponse{
+				HttpCode: http.StatusUnauthorized,
+				Code:     errors.StatusUnauthorizedCode,
+				Messages: []string{"Invalid API key"},
+			},
+			MockFunc: func(s *echo.Echo, c echo.Context) {
+				mockStore := c.Get("mockStore").(*MockStore)
+				mockStore.On("FindOne", mock.Anything, mock.Anything, apiKeyCondition).Return(false, nil)
+			},
+		},
+		{
+			TestName: "Forbidden - API key without the scope of the route",
+			Request: testhelpers.TestRequest{
+				Method: "GET",
+				Url:    path,
+				Header: map[string]string{"Authorization": "Bearer " + apiKey},
+			},
+			ExpectError: &errors.ErrorResponse{
+				HttpCode: http.StatusForbidden,
+				Code:     "INSUFFICIENT_SCOPE",
+				Messages: []string{"API key requires the portfolio:read scope"},
+			},
+			MockFunc: func(s *echo.Echo, c echo.Context) {
+				mockStore := c.Get("mockStore").(*MockStore)
+				mockStore.On("FindOne", mock.Anything, mock.Anything, apiKeyCondition).Return(true, nil).Run(func(args mock.Arguments) {
+					key := args.Get(1).(*entities.APIKey)
+					key.UserID = 7
+					key.Scopes = entities.APIKeyScopeTransactionsWrite
+					key.LastUsedAt = pointers.Pointer(time.Now())
+				})
+				mockStore.On("FindOne",
+					mock.Anything,
+					mock.Anything,
+					map[string]interface{}{"id": uint64(7)}).Return(true, nil).Run(func(args mock.Arguments) {
+					user := args.Get(1).(*entities.User)
+					user.Code = userCode
+				})
+			},
+		},
+		{
+			TestName: "success - API key with the scope of the route",
+			Request: testhelpers.TestRequest{
+				Method: "GET",
+				Url:    path,
+				Header: map[string]string{"X-API-Key": apiKey},
+			},
+			Response: testhelpers.ExpectedResponse{
+				Status: http.StatusOK,
+				Body:   pointers.Pointer(`{"code":"123e4567-e89b-12d3-a456-426614174000"}`),
+			},
+			MockFunc: func(s *echo.Echo, c echo.Context) {
+				mockStore := c.Get("mockStore").(*MockStore)
+				mockStore.On("FindOne", mock.Anything, mock.Anything, apiKeyCondition).Return(true, nil).Run(func(args mock.Arguments) {
+					key := args.Get(1).(*entities.APIKey)
+					key.UserID = 7
+					key.Scopes = entities.APIKeyScopePortfolioRead + "," + entities.APIKeyScopeTransactionsWrite
+				})
+				mockStore.On("FindOne",
+					mock.Anything,
+					mock.Anything,
+					map[string]interface{}{"id": uint64(7)}).Return(true, nil).Run(func(args mock.Arguments) {
+					user := args.Get(1).(*entities.User)
+					user.Code = userCode
+				})
+				// The first use of the key is recorded.
+				mockStore.On("Update", mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			TestName: "success - Valid access token",
+			Request: testhelpers.TestRequest{
+				Method: "GET",
+				Url:    path,
+				Header: map[string]string{"Authorization": "Bearer " + accessToken},
+			},
+			Response: testhelpers.ExpectedResponse{
+				Status: http.StatusOK,
+				Body:   pointers.Pointer(`{"code":"123e4567-e89b-12d3-a456-426614174000"}`),
+			},
+			MockFunc: func(s *echo.Echo, c echo.Context) {
+				mockStore := c.Get("mockStore").(*MockStore)
+				mockStore.On("FindOne",
+					mock.Anything,
+					mock.Anything,
+					map[string]interface{}{"code": userCode}).Return(true, nil).Run(func(args mock.Arguments) {
+					user := args.Get(1).(*entities.User)
+					user.Code = userCode
+				})
+			},
+		},
+	}
+
+	app := helpers.NewTestServer()
+	next := func(c echo.Context) error {
+		user, err := middlewares.GetAuthenticatedUser(c)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, map[string]string{"code": user.Code.String()})
+	}
+
+	for _, test := range cases {
+		t.Run(test.TestName, func(t *testing.T) {
+			ctx, recorder := testhelpers.PrepareContextFormTestCase(app.Server.Echo, test)
+
+			mockStore := new(MockStore)
+			ctx.Set("mockStore", mockStore)
+			mockCache := new(mocks.Cache)
+			ctx.Set("mockCache", mockCache)
+
+			if test.MockFunc != nil {
+				test.MockFunc(app.Server.Echo, ctx)
+			}
+
+			userRepository := repositories.NewUserRepository(mockStore)
+			apiKeyService := apikeys.NewService(repositories.NewAPIKeyRepository(mockStore), userRepository, new(mocks.Logger))
+			authenticate := middlewares.Authenticate(userRepository, issuer, tokens.NewDenylist(mockCache), cookies, apiKeyService)
+			handler := authenticate(middlewares.RequireScope(entities.APIKeyScopePortfolioRead)(next))
+
+			err := handler(ctx)
+			if test.ExpectError != nil {
+				assert.Error(t, err)
+				errorResponse, ok := err.(*errors.ErrorResponse)
+				assert.True(t, ok)
+				assert.Equal(t, test.ExpectError.HttpCode, errorResponse.ErrorHTTPCode())
+				assert.Equal(t, test.ExpectError.Code, errorResponse.ErrorCode())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.Response.Status, recorder.Code)
+				assert.JSONEq(t, *test.Response.Body, recorder.Body.String())
+			}
+			mockStore.AssertExpectations(t)
+			mockCache.AssertExpectations(t)
+		})
+	}
+}
@@ -9,7 +9,6 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
-	utilCache "github.com/juanMaAV92/go-utils/cache"
 	"github.com/juanMaAV92/go-utils/errors"
 	jwtUtils "github.com/juanMaAV92/go-utils/jwt"
 	"github.com/juanMaAV92/go-utils/testhelpers"
@@ -19,6 +18,10 @@ import (
 	"github.com/juanMaAV92/zenith-financial/backend/internal/repositories"
 	authService "github.com/juanMaAV92/zenith-financial/backend/internal/services/auth"
 	"github.com/juanMaAV92/zenith-financial/backend/tests/helpers"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/authcookies"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/lockout"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/tokens"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -28,20 +31,6 @@ type MockStore struct {
 	mock.Mock
 }
 
-type MockCache struct {
-	mock.Mock
-}
-
-func (m *MockCache) Set(ctx context.Context, key string, value interface{}, opts ...utilCache.SetOption) error {
-	args := m.Called(ctx, key, value, opts)
-	return args.Error(0)
-}
-
-func (m *MockCache) Delete(ctx context.Context, key string) error {
-	args := m.Called(ctx, key)
-	return args.Error(0)
-}
-
 func (m *MockStore) FindOne(ctx context.Context, destination interface{}, conditions interface{}) (bool, error) {
 	args := m.Called(ctx, destination, conditions)
 	return args.Get(0).(bool), args.Error(1)
@@ -97,12 +86,13 @@ func Test_login(t *testing.T) {
 					user.PasswordSalt = "bfd7d9e1a94ac31e"
 					user.PasswordHash = "$2a$12$WjCNyUfAhbxYO.PRBGaGc.CHIx/1OVvqh7JkPf9CWWgppKW1cgxv2"
 				})
-				mockCache := c.Get("mockCache").(*MockCache)
-				mockCache.On("Set",
+				// The legacy bcrypt hash is replaced with an argon2id one.
+				mockStore.On("Update",
 					mock.Anything,
-					"user_refresh_token:123e4567-e89b-12d3-a456-426614174000",
+					mock.AnythingOfType("*entities.User")).Return(nil)
+				mockStore.On("Create",
 					mock.Anything,
-					mock.AnythingOfType("[]cache.SetOption")).Return(nil)
+					mock.AnythingOfType("*entities.Session")).Return(nil)
 			},
 		},
 		{
@@ -133,12 +123,6 @@ func Test_login(t *testing.T) {
 					user.PasswordSalt = "bfd7d9e1a94ac31e"
 					user.PasswordHash = "$2a$12$WjCNyUfAhbxYO.PRBGaGc.CHIx/1OVvqh7JkPf9CWWgppKW1cgxv2"
 				})
-				mockCache := c.Get("mockCache").(*MockCache)
-				mockCache.On("Set",
-					mock.Anything,
-					"user_refresh_token:123e4567-e89b-12d3-a456-426614174000",
-					mock.Anything,
-					mock.AnythingOfType("[]cache.SetOption")).Return(nil)
 			},
 		},
 	}
@@ -152,23 +136,33 @@ func Test_login(t *testing.T) {
 		SigningMethod:   jwt.SigningMethodHS256,
 	}
 	jwtUtils.InitJWTConfig(&jwtConfig)
+	keys, err := tokens.NewKeySet("", tokens.NewSecretKey(jwtConfig.SecretKey, jwtConfig.SigningMethod))
+	if err != nil {
+		t.Fatalf("failed to build key set: %v", err)
+	}
 
 	for _, test := range cases {
 		t.Run(test.TestName, func(t *testing.T) {
 			ctx, recorder := testhelpers.PrepareContextFormTestCase(app.Server.Echo, test)
 
 			mockStore := new(MockStore)
-			MockCache := new(MockCache)
 			ctx.Set("mockStore", mockStore)
-			ctx.Set("mockCache", MockCache)
 
 			if test.MockFunc != nil {
 				test.MockFunc(app.Server.Echo, ctx)
 			}
 
+			guardCache := new(mocks.Cache)
+			guardCache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+			guardCache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			guardCache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+			guardCache.On("Incr", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil)
+			loginGuard := lockout.NewGuard(guardCache, lockout.Limits{Window: time.Minute, MaxEmailFailures: 5, LockoutDuration: time.Minute})
+
 			userRepository := repositories.NewUserRepository(mockStore)
-			authService := authService.NewService(userRepository, MockCache, app.Logger)
-			handler := auth.NewHandler(authService)
+			authService := authService.NewService(userRepository, repositories.NewSessionRepository(mockStore), tokens.NewIssuer(&jwtConfig, keys), tokens.NewDenylist(new(mocks.Cache)), nil,
+				loginGuard, app.Logger, false)
+			handler := auth.NewHandler(authService, nil, authcookies.New(authcookies.Config{}))
 
 			err := handler.Login(ctx)
 			if test.ExpectError != nil {
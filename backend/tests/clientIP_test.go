@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/juanMaAV92/go-utils/log"
+	"github.com/juanMaAV92/zenith-financial/backend/cmd"
+	"github.com/juanMaAV92/zenith-financial/backend/platform/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_ClientIP checks the IP the failed logins are counted on cannot be
+// chosen with a spoofed X-Forwarded-For header.
+func Test_ClientIP(t *testing.T) {
+	cases := []struct {
+		TestName      string
+		TrustedRanges []string
+		RemoteAddr    string
+		ForwardedFor  string
+		ExpectedIP    string
+	}{
+		{
+			TestName:     "the header is ignored without trusted proxies",
+			RemoteAddr:   "198.51.100.1:4321",
+			ForwardedFor: "203.0.113.7",
+			ExpectedIP:   "198.51.100.1",
+		},
+		{
+			TestName:      "the header is read behind a trusted proxy",
+			TrustedRanges: []string{"10.0.0.0/8"},
+			RemoteAddr:    "10.0.0.5:4321",
+			ForwardedFor:  "203.0.113.7",
+			ExpectedIP:    "203.0.113.7",
+		},
+		{
+			TestName:      "a spoofed entry before the trusted proxy is ignored",
+			TrustedRanges: []string{"10.0.0.0/8"},
+			RemoteAddr:    "10.0.0.5:4321",
+			ForwardedFor:  "203.0.113.7, 198.51.100.1",
+			ExpectedIP:    "198.51.100.1",
+		},
+		{
+			TestName:      "the header is ignored from other addresses",
+			TrustedRanges: []string{"10.0.0.0/8"},
+			RemoteAddr:    "198.51.100.1:4321",
+			ForwardedFor:  "203.0.113.7",
+			ExpectedIP:    "198.51.100.1",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.TestName, func(t *testing.T) {
+			cfg, _ := config.Load("local")
+			cfg.Proxy = &config.ProxyConfig{TrustedRanges: test.TrustedRanges}
+			app, err := cmd.NewServer(cfg, log.New(config.MicroserviceName, log.WithLevel(log.DebugLevel)))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.RemoteAddr = test.RemoteAddr
+			req.Header.Set("X-Forwarded-For", test.ForwardedFor)
+			ctx := app.Server.Echo.NewContext(req, httptest.NewRecorder())
+
+			assert.Equal(t, test.ExpectedIP, ctx.RealIP())
+		})
+	}
+}
+
+func Test_ClientIP_InvalidRange(t *testing.T) {
+	cfg, _ := config.Load("local")
+	cfg.Proxy = &config.ProxyConfig{TrustedRanges: []string{"10.0.0.0"}}
+	_, err := cmd.NewServer(cfg, log.New(config.MicroserviceName, log.WithLevel(log.DebugLevel)))
+	assert.Error(t, err)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/juanMaAV92/go-utils/testhelpers"
 	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/health"
 	healthService "github.com/juanMaAV92/zenith-financial/backend/internal/services/health"
+	"github.com/juanMaAV92/zenith-financial/backend/platform/scheduler"
 	"github.com/juanMaAV92/zenith-financial/backend/tests/helpers"
 	"github.com/stretchr/testify/assert"
 )
@@ -32,7 +33,7 @@ func Test_HealthCheck(t *testing.T) {
 	}
 
 	app := helpers.NewTestServer()
-	service := healthService.NewService()
+	service := healthService.NewService(scheduler.New(nil, app.Logger))
 	handler := health.NewHandler(service)
 
 	for _, test := range cases {
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/juanMaAV92/go-utils/cache"
 	"github.com/stretchr/testify/mock"
@@ -20,3 +21,18 @@ func (m *Cache) Delete(ctx context.Context, key string) error {
 	args := m.Called(ctx, key)
 	return args.Error(0)
 }
+
+func (m *Cache) Get(ctx context.Context, key string, destination interface{}) (bool, error) {
+	args := m.Called(ctx, key, destination)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *Cache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	args := m.Called(ctx, key, value, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *Cache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	args := m.Called(ctx, key, ttl)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,39 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
+	"github.com/stretchr/testify/mock"
+)
+
+type SessionRepository struct {
+	mock.Mock
+}
+
+func (m *SessionRepository) Create(ctx context.Context, session *entities.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *SessionRepository) GetByCode(ctx context.Context, code uuid.UUID) (*entities.Session, error) {
+	args := m.Called(ctx, code)
+	return args.Get(0).(*entities.Session), args.Error(1)
+}
+
+func (m *SessionRepository) ListActiveByUser(ctx context.Context, userID uint64, now time.Time) ([]entities.Session, error) {
+	args := m.Called(ctx, userID, now)
+	return args.Get(0).([]entities.Session), args.Error(1)
+}
+
+func (m *SessionRepository) Update(ctx context.Context, session *entities.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *SessionRepository) Rotate(ctx context.Context, session *entities.Session, previous uuid.UUID) (bool, error) {
+	args := m.Called(ctx, session, previous)
+	return args.Bool(0), args.Error(1)
+}
@@ -3,6 +3,8 @@ package mocks
 import (
 	"context"
 
+	"github.com/google/uuid"
+
 	"github.com/juanMaAV92/zenith-financial/backend/internal/entities"
 	"github.com/stretchr/testify/mock"
 )
@@ -15,3 +17,24 @@ func (m *UserRepository) GetByEmail(ctx context.Context, email string) (*entitie
 	args := m.Called(ctx, email)
 	return args.Get(0).(*entities.User), args.Error(1)
 }
+
+func (m *UserRepository) GetByCode(ctx context.Context, code uuid.UUID) (*entities.User, error) {
+	args := m.Called(ctx, code)
+	if user, ok := args.Get(0).(*entities.User); ok {
+		return user, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *UserRepository) Update(ctx context.Context, user *entities.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *UserRepository) GetByID(ctx context.Context, id uint64) (*entities.User, error) {
+	args := m.Called(ctx, id)
+	if user, ok := args.Get(0).(*entities.User); ok {
+		return user, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
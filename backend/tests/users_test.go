@@ -4,13 +4,18 @@ import (
 	"context"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/juanMaAV92/go-utils/errors"
 	"github.com/juanMaAV92/go-utils/testhelpers"
 	"github.com/juanMaAV92/zenith-financial/backend/cmd/handlers/users"
 	"github.com/juanMaAV92/zenith-financial/backend/internal/repositories"
+	"github.com/juanMaAV92/zenith-financial/backend/internal/services/emailverification"
 	userService "github.com/juanMaAV92/zenith-financial/backend/internal/services/users"
+	"github.com/juanMaAV92/zenith-financial/backend/platform/mailer"
 	"github.com/juanMaAV92/zenith-financial/backend/tests/helpers"
+	"github.com/juanMaAV92/zenith-financial/backend/tests/mocks"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/validation"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,6 +42,28 @@ func Test_createUser(t *testing.T) {
 				Messages: []string{"Invalid request body"},
 			},
 		},
+		{
+			TestName: "Bad Request - Invalid fields",
+			Request: testhelpers.TestRequest{
+				Method: "POST",
+				Url:    path,
+			},
+			RequestBody: map[string]interface{}{
+				"user_name": "juan",
+				"email":     "not-an-email",
+				"password":  "weak",
+				"currency":  "XX",
+			},
+			ExpectError: &errors.ErrorResponse{
+				HttpCode: http.StatusBadRequest,
+				Code:     validation.ValidationErrorCode,
+				Messages: []string{
+					"email: must be a valid email address",
+					"password: must have between 8 and 128 characters",
+					"currency: must be an ISO 4217 currency code",
+				},
+			},
+		},
 	}
 
 	app := helpers.NewTestServer()
@@ -53,7 +80,9 @@ func Test_createUser(t *testing.T) {
 			}
 
 			userRepository := repositories.NewUserRepository(mockStore)
-			userService := userService.NewService(userRepository)
+			verificationService := emailverification.NewService(userRepository, repositories.NewEmailVerificationRepository(mockStore), new(mocks.Cache),
+				mailer.NewLogMailer("", app.Logger), app.Logger, "", time.Hour, time.Minute)
+			userService := userService.NewService(userRepository, verificationService, app.Logger)
 			handler := users.NewHandler(userService)
 
 			err := handler.CreateUser(ctx)
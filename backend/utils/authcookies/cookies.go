@@ -0,0 +1,119 @@
+package authcookies
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/juanMaAV92/zenith-financial/backend/utils/crypto"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	AccessTokenCookie  = "access_token"
+	RefreshTokenCookie = "refresh_token"
+	CSRFTokenCookie    = "csrf_token"
+	CSRFTokenHeader    = "X-CSRF-Token"
+)
+
+// Config configures the cookie delivery of the tokens for browser clients.
+// RefreshPath scopes the refresh token cookie to the auth endpoints that take
+// it, refresh and logout, so the browser sends it nowhere else.
+type Config struct {
+	Enabled     bool
+	Domain      string
+	Secure      bool
+	SameSite    http.SameSite
+	RefreshPath string
+	AccessTTL   time.Duration
+	RefreshTTL  time.Duration
+}
+
+// Manager sets the tokens as HttpOnly cookies and reads them back. Cookie
+// authenticated requests are exposed to CSRF, so every login also sets a CSRF
+// token in a cookie the frontend can read, to be echoed in the X-CSRF-Token
+// header of mutating requests (double-submit).
+type Manager struct {
+	cfg Config
+}
+
+func New(cfg Config) *Manager {
+	return &Manager{cfg: cfg}
+}
+
+func (m *Manager) Enabled() bool {
+	return m.cfg.Enabled
+}
+
+// SetTokens sets the access and refresh tokens and a new CSRF token.
+func (m *Manager) SetTokens(c echo.Context, accessToken, refreshToken string) error {
+	csrfToken, err := crypto.GenerateToken()
+	if err != nil {
+		return err
+	}
+	c.SetCookie(m.cookie(AccessTokenCookie, accessToken, "/", maxAge(m.cfg.AccessTTL), true))
+	c.SetCookie(m.cookie(RefreshTokenCookie, refreshToken, m.cfg.RefreshPath, maxAge(m.cfg.RefreshTTL), true))
+	c.SetCookie(m.cookie(CSRFTokenCookie, csrfToken, "/", maxAge(m.cfg.RefreshTTL), false))
+	return nil
+}
+
+// Clear expires the cookies of SetTokens.
+func (m *Manager) Clear(c echo.Context) {
+	c.SetCookie(m.cookie(AccessTokenCookie, "", "/", -1, true))
+	c.SetCookie(m.cookie(RefreshTokenCookie, "", m.cfg.RefreshPath, -1, true))
+	c.SetCookie(m.cookie(CSRFTokenCookie, "", "/", -1, false))
+}
+
+// AccessToken returns the access token cookie of the request, empty when cookie
+// mode is disabled.
+func (m *Manager) AccessToken(c echo.Context) string {
+	return m.read(c, AccessTokenCookie)
+}
+
+// RefreshToken returns the refresh token cookie of the request, empty when
+// cookie mode is disabled.
+func (m *Manager) RefreshToken(c echo.Context) string {
+	return m.read(c, RefreshTokenCookie)
+}
+
+// ValidCSRF reports whether the CSRF header of the request matches its cookie.
+// Safe methods do not change state and always pass.
+func (m *Manager) ValidCSRF(c echo.Context) bool {
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	cookie := m.read(c, CSRFTokenCookie)
+	header := c.Request().Header.Get(CSRFTokenHeader)
+	return cookie != "" && subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+func (m *Manager) read(c echo.Context, name string) string {
+	if !m.cfg.Enabled {
+		return ""
+	}
+	cookie, err := c.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+func (m *Manager) cookie(name, value, path string, maxAge int, httpOnly bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   m.cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   m.cfg.Secure,
+		HttpOnly: httpOnly,
+		SameSite: m.cfg.SameSite,
+	}
+}
+
+// maxAge rounds ttl to whole seconds. A cookie with a zero MaxAge would last
+// until the browser closes instead of expiring.
+func maxAge(ttl time.Duration) int {
+	return max(int(ttl.Seconds()), 1)
+}
@@ -0,0 +1,109 @@
+package authcookies
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var config = Config{
+	Enabled:     true,
+	Secure:      true,
+	SameSite:    http.SameSiteStrictMode,
+	RefreshPath: "/zenith-financial/v1/auth/refresh-token",
+	AccessTTL:   15 * time.Minute,
+	RefreshTTL:  24 * time.Hour,
+}
+
+func newContext(method string, cookies ...*http.Cookie) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, "/", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	recorder := httptest.NewRecorder()
+	return echo.New().NewContext(req, recorder), recorder
+}
+
+func responseCookies(recorder *httptest.ResponseRecorder) map[string]*http.Cookie {
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range recorder.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	return cookies
+}
+
+func Test_SetTokens(t *testing.T) {
+	c, recorder := newContext(http.MethodPost)
+	assert.NoError(t, New(config).SetTokens(c, "access", "refresh"))
+
+	cookies := responseCookies(recorder)
+	access := cookies[AccessTokenCookie]
+	assert.Equal(t, "access", access.Value)
+	assert.Equal(t, "/", access.Path)
+	assert.Equal(t, 900, access.MaxAge)
+	assert.True(t, access.HttpOnly)
+	assert.True(t, access.Secure)
+	assert.Equal(t, http.SameSiteStrictMode, access.SameSite)
+
+	refresh := cookies[RefreshTokenCookie]
+	assert.Equal(t, "refresh", refresh.Value)
+	assert.Equal(t, config.RefreshPath, refresh.Path)
+	assert.Equal(t, 86400, refresh.MaxAge)
+	assert.True(t, refresh.HttpOnly)
+
+	csrf := cookies[CSRFTokenCookie]
+	assert.NotEmpty(t, csrf.Value)
+	assert.False(t, csrf.HttpOnly)
+}
+
+func Test_Clear(t *testing.T) {
+	c, recorder := newContext(http.MethodPost)
+	New(config).Clear(c)
+
+	cookies := responseCookies(recorder)
+	assert.Len(t, cookies, 3)
+	for _, cookie := range cookies {
+		assert.Empty(t, cookie.Value)
+		assert.Negative(t, cookie.MaxAge)
+	}
+	assert.Equal(t, config.RefreshPath, cookies[RefreshTokenCookie].Path)
+}
+
+func Test_ReadTokens(t *testing.T) {
+	c, _ := newContext(http.MethodGet, &http.Cookie{Name: AccessTokenCookie, Value: "access"}, &http.Cookie{Name: RefreshTokenCookie, Value: "refresh"})
+
+	manager := New(config)
+	assert.Equal(t, "access", manager.AccessToken(c))
+	assert.Equal(t, "refresh", manager.RefreshToken(c))
+
+	disabled := New(Config{})
+	assert.Empty(t, disabled.AccessToken(c))
+	assert.Empty(t, disabled.RefreshToken(c))
+}
+
+func Test_ValidCSRF(t *testing.T) {
+	manager := New(config)
+	csrf := &http.Cookie{Name: CSRFTokenCookie, Value: "token"}
+
+	c, _ := newContext(http.MethodGet)
+	assert.True(t, manager.ValidCSRF(c))
+
+	c, _ = newContext(http.MethodPost, csrf)
+	assert.False(t, manager.ValidCSRF(c))
+
+	c, _ = newContext(http.MethodDelete, csrf)
+	c.Request().Header.Set(CSRFTokenHeader, "other")
+	assert.False(t, manager.ValidCSRF(c))
+
+	c, _ = newContext(http.MethodPatch, csrf)
+	c.Request().Header.Set(CSRFTokenHeader, "token")
+	assert.True(t, manager.ValidCSRF(c))
+
+	c, _ = newContext(http.MethodPost)
+	c.Request().Header.Set(CSRFTokenHeader, "")
+	assert.False(t, manager.ValidCSRF(c))
+}
@@ -0,0 +1,33 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestCipher(t *testing.T) {
+	cipher, err := NewCipher(bytes.Repeat([]byte{7}, CipherKeyLength))
+	if err != nil {
+		t.Fatalf("Error creating cipher: %v", err)
+	}
+
+	ciphertext, err := cipher.Encrypt("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("Error encrypting: %v", err)
+	}
+	other, _ := cipher.Encrypt("JBSWY3DPEHPK3PXP")
+	assert.NotEqual(t, ciphertext, other)
+
+	plaintext, err := cipher.Decrypt(ciphertext)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", plaintext)
+
+	foreign, _ := NewCipher(bytes.Repeat([]byte{8}, CipherKeyLength))
+	_, err = foreign.Decrypt(ciphertext)
+	assert.Equal(t, ErrInvalidCiphertext, err)
+
+	_, err = NewCipher([]byte("short"))
+	assert.NotEqual(t, nil, err)
+}
@@ -2,36 +2,27 @@ package crypto
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
-const (
-	SaltLength = 16
-	BCryptCost = 12
-)
+const TokenLength = 32
 
-func GeneratePasswordSalt() (string, error) {
-	bytes := make([]byte, SaltLength)
+// GenerateToken returns a random URL-safe token for links sent to the user.
+// Only its HashToken should be stored.
+func GenerateToken() (string, error) {
+	bytes := make([]byte, TokenLength)
 	_, err := rand.Read(bytes)
 	if err != nil {
 		return "", err
 	}
-	return hex.EncodeToString(bytes), nil
-}
-
-func HashPassword(password, salt string) (string, error) {
-	saltedPassword := password + salt
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(saltedPassword), BCryptCost)
-	if err != nil {
-		return "", err
-	}
-	return string(hashedBytes), nil
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
 }
 
-func ValidatePassword(password, salt, hash string) bool {
-	saltedPassword := password + salt
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(saltedPassword))
-	return err == nil
+// HashToken returns the SHA-256 of a token in hex. Tokens are random, so unlike
+// passwords they need neither salt nor a slow hash.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
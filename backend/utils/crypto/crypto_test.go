@@ -1,26 +1,94 @@
 package crypto
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/go-playground/assert/v2"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestCrypto(t *testing.T) {
 	password := "as2354@#$"
-	salt, err := GeneratePasswordSalt()
+	hash, err := HashPassword(password)
 	if err != nil {
-		t.Fatalf("Error generating password salt: %v", err)
+		t.Fatalf("Error hashing password: %v", err)
+	}
+	assert.Equal(t, true, strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=2$"))
+
+	valid, needsRehash := VerifyPassword(password, "", hash)
+	assert.Equal(t, true, valid)
+	assert.Equal(t, false, needsRehash)
+
+	valid, _ = VerifyPassword("as2354@#%", "", hash)
+	assert.Equal(t, false, valid)
+
+	other, err := HashPassword(password)
+	if err != nil {
+		t.Fatalf("Error hashing password: %v", err)
 	}
-	hash, err := HashPassword(password, salt)
+	assert.NotEqual(t, hash, other)
+}
+
+func TestCryptoLongPasswords(t *testing.T) {
+	// bcrypt ignored everything past 72 bytes.
+	prefix := strings.Repeat("a", 72)
+	hash, err := HashPassword(prefix + "1")
 	if err != nil {
 		t.Fatalf("Error hashing password: %v", err)
 	}
 
-	valid := ValidatePassword(password, salt, hash)
-	if !valid {
-		t.Fatalf("Error validating password: %v", err)
+	valid, _ := VerifyPassword(prefix+"2", "", hash)
+	assert.Equal(t, false, valid)
+}
+
+func TestCryptoLegacyHashes(t *testing.T) {
+	password := "as2354@#$"
+	salt := "7da8aa7388bbe6e878064f084ac736a4"
+	legacy, err := bcrypt.GenerateFromPassword([]byte(password+salt), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Error hashing password: %v", err)
 	}
 
+	valid, needsRehash := VerifyPassword(password, salt, string(legacy))
 	assert.Equal(t, true, valid)
+	assert.Equal(t, true, needsRehash)
+
+	valid, needsRehash = VerifyPassword(password, "other-salt", string(legacy))
+	assert.Equal(t, false, valid)
+	assert.Equal(t, false, needsRehash)
+}
+
+func TestCryptoOutdatedParams(t *testing.T) {
+	// A hash made with lower costs than the current ones.
+	hash := "$argon2id$v=19$m=16,t=2,p=1$c29tZXNhbHQ$BYICwHI82IwkQIzKwcv4KN7mO884Q6FQ6jZKHgtOH/g"
+	valid, needsRehash := VerifyPassword("password", "", hash)
+	assert.Equal(t, true, valid)
+	assert.Equal(t, true, needsRehash)
+
+	for _, invalid := range []string{
+		"$argon2id$v=18$m=16,t=2,p=1$c29tZXNhbHQ$BYICwHI82IwkQIzKwcv4KN7mO884Q6FQ6jZKHgtOH/g",
+		"$argon2id$v=19$m=16,t=2$c29tZXNhbHQ$BYICwHI82IwkQIzKwcv4KN7mO884Q6FQ6jZKHgtOH/g",
+		"$argon2id$v=19$m=16,t=2,p=1$c29tZXNhbHQ",
+		"",
+	} {
+		valid, _ := VerifyPassword("password", "", invalid)
+		assert.Equal(t, false, valid)
+	}
+}
+
+func TestToken(t *testing.T) {
+	token, err := GenerateToken()
+	if err != nil {
+		t.Fatalf("Error generating token: %v", err)
+	}
+	other, err := GenerateToken()
+	if err != nil {
+		t.Fatalf("Error generating token: %v", err)
+	}
+
+	assert.NotEqual(t, token, other)
+	assert.Equal(t, 64, len(HashToken(token)))
+	assert.Equal(t, HashToken(token), HashToken(token))
+	assert.NotEqual(t, HashToken(token), HashToken(other))
 }
@@ -0,0 +1,18 @@
+package lockout
+
+import "time"
+
+// RetryError wraps the response of a login rejected by the Guard with how long
+// the client has to wait, for the Retry-After header.
+type RetryError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *RetryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
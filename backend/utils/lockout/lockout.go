@@ -0,0 +1,161 @@
+package lockout
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	utilCache "github.com/juanMaAV92/go-utils/cache"
+	"github.com/juanMaAV92/zenith-financial/backend/utils/crypto"
+)
+
+const (
+	failuresKeyPrefix    = "login_failures:"
+	lastFailureKeyPrefix = "login_last_failure:"
+	lockKeyPrefix        = "login_lock:"
+
+	emailSubject = "email:"
+	ipSubject    = "ip:"
+)
+
+type cache interface {
+	Set(ctx context.Context, key string, value interface{}, opts ...utilCache.SetOption) error
+	Get(ctx context.Context, key string, destination interface{}) (bool, error)
+	Delete(ctx context.Context, key string) error
+	// Incr increments the counter in the key, setting the ttl when the key is
+	// created, and returns its new value.
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+}
+
+// Limits configures the Guard. Failures are counted over a Window that starts
+// with the first failure.
+// From DelayAfter failures of an email on, each attempt has to wait BaseDelay
+// after the last failure, doubled with every failure up to MaxDelay. Reaching
+// the max failures of an email or an IP locks them for LockoutDuration.
+type Limits struct {
+	Window           time.Duration
+	MaxEmailFailures int
+	MaxIPFailures    int
+	LockoutDuration  time.Duration
+	DelayAfter       int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+}
+
+// Guard keeps in Redis the failed logins per email and per IP. The failures
+// are counted with atomic increments, so concurrent failures are all counted.
+type Guard struct {
+	cache  cache
+	limits Limits
+	now    func() time.Time
+}
+
+func NewGuard(cache cache, limits Limits) *Guard {
+	return &Guard{cache: cache, limits: limits, now: time.Now}
+}
+
+// Check returns how long the login of the email from the IP has to wait, zero
+// when it may go ahead. locked tells a lockout apart from a progressive delay.
+func (g *Guard) Check(ctx context.Context, email, ip string) (retryAfter time.Duration, locked bool, err error) {
+	now := g.now()
+	for _, subject := range subjects(email, ip) {
+		var lockedUntil time.Time
+		found, err := g.cache.Get(ctx, lockKeyPrefix+subject, &lockedUntil)
+		if err != nil {
+			return 0, false, err
+		}
+		if found && lockedUntil.After(now) {
+			return lockedUntil.Sub(now), true, nil
+		}
+	}
+
+	subject := emailSubject + emailKey(email)
+	var failures int
+	if _, err := g.cache.Get(ctx, failuresKeyPrefix+subject, &failures); err != nil {
+		return 0, false, err
+	}
+	delay := g.delay(failures)
+	if delay == 0 {
+		return 0, false, nil
+	}
+	var lastFailure time.Time
+	found, err := g.cache.Get(ctx, lastFailureKeyPrefix+subject, &lastFailure)
+	if err != nil {
+		return 0, false, err
+	}
+	if wait := lastFailure.Add(delay).Sub(now); found && wait > 0 {
+		return wait, false, nil
+	}
+	return 0, false, nil
+}
+
+// RecordFailure adds a failed login of the email from the IP to their windows,
+// locking them when they reach their limit.
+func (g *Guard) RecordFailure(ctx context.Context, email, ip string) error {
+	now := g.now()
+	if err := g.recordFailure(ctx, emailSubject+emailKey(email), g.limits.MaxEmailFailures, now); err != nil {
+		return err
+	}
+	if ip == "" {
+		return nil
+	}
+	return g.recordFailure(ctx, ipSubject+ip, g.limits.MaxIPFailures, now)
+}
+
+// Reset clears the failures and the lockout of the email, after a successful
+// login or a password reset. Those of the IP are kept, as it may be trying
+// other accounts.
+func (g *Guard) Reset(ctx context.Context, email string) error {
+	subject := emailSubject + emailKey(email)
+	for _, prefix := range []string{failuresKeyPrefix, lastFailureKeyPrefix, lockKeyPrefix} {
+		if err := g.cache.Delete(ctx, prefix+subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Guard) recordFailure(ctx context.Context, subject string, max int, now time.Time) error {
+	failures, err := g.cache.Incr(ctx, failuresKeyPrefix+subject, g.limits.Window)
+	if err != nil {
+		return err
+	}
+
+	if max > 0 && failures >= int64(max) {
+		lockedUntil := now.Add(g.limits.LockoutDuration)
+		if err := g.cache.Set(ctx, lockKeyPrefix+subject, lockedUntil, utilCache.WithTTL(g.limits.LockoutDuration)); err != nil {
+			return err
+		}
+		// The window starts over once the lockout ends.
+		return g.cache.Delete(ctx, failuresKeyPrefix+subject)
+	}
+	// Only the time of the last failure is kept, for the delays; concurrent
+	// failures overwrite it with about the same time.
+	return g.cache.Set(ctx, lastFailureKeyPrefix+subject, now, utilCache.WithTTL(g.limits.Window))
+}
+
+// delay is the wait required after the last of the given number of failures,
+// capped at MaxDelay.
+func (g *Guard) delay(failures int) time.Duration {
+	if g.limits.BaseDelay <= 0 || failures == 0 || failures < g.limits.DelayAfter {
+		return 0
+	}
+	delay := g.limits.BaseDelay
+	for i := g.limits.DelayAfter; i < failures && delay < g.limits.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, g.limits.MaxDelay)
+}
+
+func subjects(email, ip string) []string {
+	subjects := []string{emailSubject + emailKey(email)}
+	if ip != "" {
+		subjects = append(subjects, ipSubject+ip)
+	}
+	return subjects
+}
+
+// emailKey hashes the email so addresses are not stored in Redis.
+func emailKey(email string) string {
+	return crypto.HashToken(strings.ToLower(strings.TrimSpace(email)))
+}
@@ -0,0 +1,168 @@
+package lockout
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	utilCache "github.com/juanMaAV92/go-utils/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryCache stores the values as JSON, like Redis does. Only the TTL of the
+// counters is honored, against the clock of the test.
+type memoryCache struct {
+	values  map[string][]byte
+	expires map[string]time.Time
+	now     *time.Time
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value interface{}, opts ...utilCache.SetOption) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.values[key] = data
+	return nil
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string, destination interface{}) (bool, error) {
+	if expires, ok := c.expires[key]; ok && !c.now.Before(expires) {
+		_ = c.Delete(ctx, key)
+	}
+	data, ok := c.values[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, destination)
+}
+
+func (c *memoryCache) Delete(ctx context.Context, key string) error {
+	delete(c.values, key)
+	delete(c.expires, key)
+	return nil
+}
+
+func (c *memoryCache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	var counter int64
+	found, err := c.Get(ctx, key, &counter)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		c.expires[key] = c.now.Add(ttl)
+	}
+	counter++
+	return counter, c.Set(ctx, key, counter)
+}
+
+var limits = Limits{
+	Window:           15 * time.Minute,
+	MaxEmailFailures: 5,
+	MaxIPFailures:    8,
+	LockoutDuration:  15 * time.Minute,
+	DelayAfter:       2,
+	BaseDelay:        time.Second,
+	MaxDelay:         4 * time.Second,
+}
+
+func newGuard(now *time.Time) *Guard {
+	guard := NewGuard(&memoryCache{values: map[string][]byte{}, expires: map[string]time.Time{}, now: now}, limits)
+	guard.now = func() time.Time { return *now }
+	return guard
+}
+
+func Test_Guard(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("delays grow with the failures", func(t *testing.T) {
+		now := start
+		guard := newGuard(&now)
+
+		var waits []time.Duration
+		for range 4 {
+			assert.NoError(t, guard.RecordFailure(ctx, "ana@example.com", "10.0.0.1"))
+			wait, locked, err := guard.Check(ctx, "ana@example.com", "10.0.0.1")
+			assert.NoError(t, err)
+			assert.False(t, locked)
+			waits = append(waits, wait)
+		}
+		assert.Equal(t, []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second}, waits)
+
+		now = now.Add(4 * time.Second)
+		wait, _, err := guard.Check(ctx, "ana@example.com", "10.0.0.1")
+		assert.NoError(t, err)
+		assert.Zero(t, wait)
+	})
+
+	t.Run("the email is locked after the max failures", func(t *testing.T) {
+		now := start
+		guard := newGuard(&now)
+
+		for range limits.MaxEmailFailures {
+			assert.NoError(t, guard.RecordFailure(ctx, "Ana@Example.com", "10.0.0.1"))
+			now = now.Add(10 * time.Second)
+		}
+		wait, locked, err := guard.Check(ctx, "ana@example.com", "10.0.0.2")
+		assert.NoError(t, err)
+		assert.True(t, locked)
+		assert.Equal(t, limits.LockoutDuration-10*time.Second, wait)
+
+		// Other accounts from the same IP are not locked yet.
+		wait, _, err = guard.Check(ctx, "bob@example.com", "10.0.0.1")
+		assert.NoError(t, err)
+		assert.Zero(t, wait)
+
+		now = now.Add(limits.LockoutDuration)
+		wait, locked, err = guard.Check(ctx, "ana@example.com", "10.0.0.2")
+		assert.NoError(t, err)
+		assert.False(t, locked)
+		assert.Zero(t, wait)
+	})
+
+	t.Run("the IP is locked after failing on many accounts", func(t *testing.T) {
+		now := start
+		guard := newGuard(&now)
+
+		for i := range limits.MaxIPFailures {
+			assert.NoError(t, guard.RecordFailure(ctx, string(rune('a'+i))+"@example.com", "10.0.0.1"))
+		}
+		_, locked, err := guard.Check(ctx, "new@example.com", "10.0.0.1")
+		assert.NoError(t, err)
+		assert.True(t, locked)
+		_, locked, err = guard.Check(ctx, "new@example.com", "10.0.0.2")
+		assert.NoError(t, err)
+		assert.False(t, locked)
+	})
+
+	t.Run("failures are forgotten when the window ends", func(t *testing.T) {
+		now := start
+		guard := newGuard(&now)
+
+		for range limits.MaxEmailFailures - 1 {
+			assert.NoError(t, guard.RecordFailure(ctx, "ana@example.com", ""))
+		}
+		now = now.Add(limits.Window)
+		assert.NoError(t, guard.RecordFailure(ctx, "ana@example.com", ""))
+		wait, locked, err := guard.Check(ctx, "ana@example.com", "")
+		assert.NoError(t, err)
+		assert.False(t, locked)
+		assert.Zero(t, wait)
+	})
+
+	t.Run("reset unlocks the email", func(t *testing.T) {
+		now := start
+		guard := newGuard(&now)
+
+		for range limits.MaxEmailFailures {
+			assert.NoError(t, guard.RecordFailure(ctx, "ana@example.com", ""))
+		}
+		assert.NoError(t, guard.Reset(ctx, "ana@example.com"))
+		wait, locked, err := guard.Check(ctx, "ana@example.com", "")
+		assert.NoError(t, err)
+		assert.False(t, locked)
+		assert.Zero(t, wait)
+	})
+}
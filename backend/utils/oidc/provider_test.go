@@ -0,0 +1,108 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/juanMaAV92/zenith-financial/backend/utils/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+)
+
+const redirectURL = "http://localhost:3000/auth/callback/test"
+
+func newTestProvider(server *oidctest.Server) *Provider {
+	return NewProvider(Config{
+		Issuer:       server.Issuer(),
+		ClientID:     oidctest.ClientID,
+		ClientSecret: oidctest.ClientSecret,
+		RedirectURL:  redirectURL,
+	}, http.DefaultClient)
+}
+
+func Test_Provider_Login(t *testing.T) {
+	server := oidctest.NewServer()
+	defer server.Close()
+	provider := newTestProvider(server)
+	ctx := context.Background()
+	identity := oidctest.Identity{Subject: "user-1", Email: "juan@mail.com", EmailVerified: true, Name: "Juan"}
+
+	verifier, challenge, err := NewPKCE()
+	assert.NoError(t, err)
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", challenge)
+	assert.NoError(t, err)
+
+	query := mustQuery(t, authURL)
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+	assert.Equal(t, redirectURL, query.Get("redirect_uri"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	code, state, err := server.Authorize(authURL, identity)
+	assert.NoError(t, err)
+	assert.Equal(t, "state-1", state)
+
+	claims, err := provider.Exchange(ctx, code, verifier, "nonce-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, "juan@mail.com", claims.Email)
+	assert.True(t, claims.EmailVerified)
+	assert.Equal(t, "Juan", claims.Name)
+
+	t.Run("codes are single use", func(t *testing.T) {
+		_, err := provider.Exchange(ctx, code, verifier, "nonce-1")
+		assert.ErrorIs(t, err, ErrExchange)
+	})
+}
+
+func Test_Provider_RejectsTamperedLogins(t *testing.T) {
+	server := oidctest.NewServer()
+	defer server.Close()
+	provider := newTestProvider(server)
+	ctx := context.Background()
+	identity := oidctest.Identity{Subject: "user-1", Email: "juan@mail.com", EmailVerified: true}
+
+	authorize := func(t *testing.T) (code, verifier string) {
+		verifier, challenge, err := NewPKCE()
+		assert.NoError(t, err)
+		authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", challenge)
+		assert.NoError(t, err)
+		code, _, err = server.Authorize(authURL, identity)
+		assert.NoError(t, err)
+		return code, verifier
+	}
+
+	t.Run("wrong code verifier", func(t *testing.T) {
+		code, _ := authorize(t)
+		other, _, _ := NewPKCE()
+		_, err := provider.Exchange(ctx, code, other, "nonce")
+		assert.ErrorIs(t, err, ErrExchange)
+	})
+
+	t.Run("nonce of another login", func(t *testing.T) {
+		code, verifier := authorize(t)
+		_, err := provider.Exchange(ctx, code, verifier, "other-nonce")
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("issuer mismatch", func(t *testing.T) {
+		wrong := NewProvider(Config{Issuer: server.Issuer() + "/other", ClientID: oidctest.ClientID}, http.DefaultClient)
+		_, err := wrong.AuthCodeURL(ctx, "state", "nonce", "challenge")
+		assert.Error(t, err)
+	})
+}
+
+func Test_Registry(t *testing.T) {
+	registry := NewRegistry(map[string]Config{}, http.DefaultClient)
+	_, err := registry.AuthCodeURL(context.Background(), "missing", "state", "nonce", "challenge")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+	_, err = registry.Exchange(context.Background(), "missing", "code", "verifier", "nonce")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
+
+func mustQuery(t *testing.T, rawURL string) url.Values {
+	t.Helper()
+	parsed, err := url.Parse(rawURL)
+	assert.NoError(t, err)
+	return parsed.Query()
+}
@@ -0,0 +1,35 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+)
+
+// Registry holds the configured providers by name.
+type Registry struct {
+	providers map[string]*Provider
+}
+
+func NewRegistry(configs map[string]Config, client *http.Client) *Registry {
+	providers := make(map[string]*Provider, len(configs))
+	for name, cfg := range configs {
+		providers[name] = NewProvider(cfg, client)
+	}
+	return &Registry{providers: providers}
+}
+
+func (r *Registry) AuthCodeURL(ctx context.Context, provider, state, nonce, challenge string) (string, error) {
+	p, ok := r.providers[provider]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+	return p.AuthCodeURL(ctx, state, nonce, challenge)
+}
+
+func (r *Registry) Exchange(ctx context.Context, provider, code, verifier, nonce string) (*Claims, error) {
+	p, ok := r.providers[provider]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p.Exchange(ctx, code, verifier, nonce)
+}
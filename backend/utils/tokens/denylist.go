@@ -0,0 +1,71 @@
+package tokens
+
+import (
+	"context"
+	"time"
+
+	utilCache "github.com/juanMaAV92/go-utils/cache"
+)
+
+const (
+	revokedTokenKeyPrefix   = "revoked_access_token:"
+	revokedSessionKeyPrefix = "revoked_session:"
+)
+
+type cache interface {
+	Set(ctx context.Context, key string, value interface{}, opts ...utilCache.SetOption) error
+	Get(ctx context.Context, key string, destination interface{}) (bool, error)
+}
+
+// Denylist keeps in Redis the access tokens revoked before they expire. Entries
+// only live as long as the tokens they revoke could still be used.
+type Denylist struct {
+	cache cache
+	now   func() time.Time
+}
+
+func NewDenylist(cache cache) *Denylist {
+	return &Denylist{cache: cache, now: time.Now}
+}
+
+// RevokeToken denylists one access token by its ID until it expires.
+func (d *Denylist) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ttl := expiresAt.Sub(d.now())
+	if tokenID == "" || ttl <= 0 {
+		return nil
+	}
+	return d.cache.Set(ctx, revokedTokenKeyPrefix+tokenID, true, utilCache.WithTTL(ttl))
+}
+
+// RevokeSession denylists every access token of a session. The ttl must cover
+// the lifetime of the last access token issued for it.
+func (d *Denylist) RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error {
+	if sessionID == "" || ttl <= 0 {
+		return nil
+	}
+	return d.cache.Set(ctx, revokedSessionKeyPrefix+sessionID, true, utilCache.WithTTL(ttl))
+}
+
+// IsRevoked reports whether the token or the session it belongs to was revoked.
+// Empty IDs, from tokens issued before they were added, are never revoked.
+func (d *Denylist) IsRevoked(ctx context.Context, tokenID, sessionID string) (bool, error) {
+	keys := make([]string, 0, 2)
+	if tokenID != "" {
+		keys = append(keys, revokedTokenKeyPrefix+tokenID)
+	}
+	if sessionID != "" {
+		keys = append(keys, revokedSessionKeyPrefix+sessionID)
+	}
+
+	for _, key := range keys {
+		var revoked bool
+		found, err := d.cache.Get(ctx, key, &revoked)
+		if err != nil {
+			return false, err
+		}
+		if found {
+			return true, nil
+		}
+	}
+	return false, nil
+}
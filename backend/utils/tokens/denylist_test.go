@@ -0,0 +1,57 @@
+package tokens
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	utilCache "github.com/juanMaAV92/go-utils/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryCache records the keys set, ignoring their TTL.
+type memoryCache map[string]bool
+
+func (c memoryCache) Set(ctx context.Context, key string, value interface{}, opts ...utilCache.SetOption) error {
+	c[key] = true
+	return nil
+}
+
+func (c memoryCache) Get(ctx context.Context, key string, destination interface{}) (bool, error) {
+	return c[key], nil
+}
+
+func Test_Denylist(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	cache := memoryCache{}
+	denylist := NewDenylist(cache)
+	denylist.now = func() time.Time { return now }
+
+	assert.NoError(t, denylist.RevokeToken(ctx, "token-1", now.Add(time.Minute)))
+	assert.NoError(t, denylist.RevokeSession(ctx, "session-1", time.Minute))
+
+	testCases := []struct {
+		name      string
+		tokenID   string
+		sessionID string
+		revoked   bool
+	}{
+		{name: "revoked token", tokenID: "token-1", sessionID: "session-2", revoked: true},
+		{name: "token of a revoked session", tokenID: "token-2", sessionID: "session-1", revoked: true},
+		{name: "active token", tokenID: "token-2", sessionID: "session-2", revoked: false},
+		{name: "token without IDs", revoked: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			revoked, err := denylist.IsRevoked(ctx, tc.tokenID, tc.sessionID)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.revoked, revoked)
+		})
+	}
+
+	t.Run("expired tokens are not stored", func(t *testing.T) {
+		assert.NoError(t, denylist.RevokeToken(ctx, "token-3", now.Add(-time.Second)))
+		assert.NotContains(t, cache, revokedTokenKeyPrefix+"token-3")
+	})
+}
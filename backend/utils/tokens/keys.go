@@ -0,0 +1,177 @@
+package tokens
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	keyFileExtension = ".pem"
+	minRSAKeyBits    = 2048
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// SigningKey is a key tokens are signed or verified with, identified by the kid
+// of their header. Keys loaded from a public key only verify tokens.
+type SigningKey struct {
+	ID     string
+	Method jwt.SigningMethod
+	sign   interface{}
+	verify interface{}
+}
+
+// CanSign reports whether the private part of the key is known.
+func (k *SigningKey) CanSign() bool {
+	return k.sign != nil
+}
+
+// NewSecretKey wraps the shared HMAC secret used before the asymmetric keys.
+// It has no ID, as the tokens signed with it carry no kid, and it is never
+// published in the JWKS.
+func NewSecretKey(secret string, method jwt.SigningMethod) *SigningKey {
+	return &SigningKey{Method: method, sign: []byte(secret), verify: []byte(secret)}
+}
+
+// ParseKey reads a PEM encoded key: a PKCS#8 or PKCS#1 private key, or a PKIX
+// public key for keys that only verify. RSA keys sign with RS256 and Ed25519
+// keys with EdDSA.
+func ParseKey(id string, data []byte) (*SigningKey, error) {
+	if id == "" {
+		return nil, errors.New("signing key without an ID")
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %q is not PEM encoded", id)
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("signing key %q has unsupported PEM type %q", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing key %q: %w", id, err)
+	}
+
+	key := &SigningKey{ID: id}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.sign, key.verify = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.verify = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.sign, key.verify = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.verify = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("signing key %q has an unsupported algorithm", id)
+	}
+	if public, ok := key.verify.(*rsa.PublicKey); ok && public.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("signing key %q: RSA keys must have at least %d bits", id, minRSAKeyBits)
+	}
+	return key, nil
+}
+
+// LoadKeys reads the keys of a directory, one <kid>.pem file each. An empty
+// dir has no keys.
+func LoadKeys(dir string) ([]*SigningKey, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+keyFileExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*SigningKey, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParseKey(strings.TrimSuffix(filepath.Base(path), keyFileExtension), data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// KeySet holds the active key, which signs new tokens, and the retiring ones,
+// which only verify the tokens issued before a rotation until they expire.
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+func NewKeySet(activeID string, keys ...*SigningKey) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*SigningKey, len(keys))}
+	for _, key := range keys {
+		if _, ok := set.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicated signing key %q", key.ID)
+		}
+		set.keys[key.ID] = key
+	}
+
+	active, ok := set.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("active signing key %q not found", activeID)
+	}
+	if !active.CanSign() {
+		return nil, fmt.Errorf("active signing key %q has no private key", activeID)
+	}
+	set.active = active
+	return set, nil
+}
+
+// Active returns the key new tokens are signed with.
+func (s *KeySet) Active() *SigningKey {
+	return s.active
+}
+
+// verificationKey is the jwt.Keyfunc of the set: it picks the key by the kid of
+// the token and checks the token was signed with its algorithm.
+func (s *KeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrInvalidToken
+	}
+	return key.verify, nil
+}
+
+// algorithms returns the algorithms of the keys of the set.
+func (s *KeySet) algorithms() []string {
+	seen := map[string]bool{}
+	algorithms := make([]string, 0, len(s.keys))
+	for _, key := range s.keys {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			algorithms = append(algorithms, alg)
+		}
+	}
+	sort.Strings(algorithms)
+	return algorithms
+}
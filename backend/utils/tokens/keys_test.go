@@ -0,0 +1,161 @@
+package tokens
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func ed25519PEM(t *testing.T) []byte {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func rsaPEM(t *testing.T, bits int) []byte {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, bits)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)})
+}
+
+func Test_ParseKey(t *testing.T) {
+	key, err := ParseKey("ed", ed25519PEM(t))
+	assert.NoError(t, err)
+	assert.Equal(t, jwt.SigningMethodEdDSA, key.Method)
+	assert.True(t, key.CanSign())
+
+	key, err = ParseKey("rsa", rsaPEM(t, 2048))
+	assert.NoError(t, err)
+	assert.Equal(t, jwt.SigningMethodRS256, key.Method)
+
+	public, err := x509.MarshalPKIXPublicKey(key.verify)
+	assert.NoError(t, err)
+	key, err = ParseKey("rsa-public", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}))
+	assert.NoError(t, err)
+	assert.False(t, key.CanSign())
+
+	_, err = ParseKey("weak", rsaPEM(t, 1024))
+	assert.Error(t, err)
+
+	_, err = ParseKey("garbage", []byte("not a key"))
+	assert.Error(t, err)
+
+	_, err = ParseKey("", ed25519PEM(t))
+	assert.Error(t, err)
+}
+
+func Test_LoadKeys(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "2026-01.pem"), ed25519PEM(t), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("ignored"), 0o600))
+
+	keys, err := LoadKeys(dir)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.Equal(t, "2026-01", keys[0].ID)
+
+	keys, err = LoadKeys("")
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func Test_NewKeySet(t *testing.T) {
+	key, err := ParseKey("current", ed25519PEM(t))
+	assert.NoError(t, err)
+
+	_, err = NewKeySet("missing", key)
+	assert.Error(t, err)
+
+	_, err = NewKeySet("current", key, key)
+	assert.Error(t, err)
+
+	public, err := x509.MarshalPKIXPublicKey(key.verify)
+	assert.NoError(t, err)
+	verifyOnly, err := ParseKey("public", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}))
+	assert.NoError(t, err)
+	_, err = NewKeySet("public", verifyOnly)
+	assert.Error(t, err)
+}
+
+func Test_KeyRotation(t *testing.T) {
+	userCode, sessionID := uuid.New(), uuid.New()
+	retiring, err := ParseKey("retiring", rsaPEM(t, 2048))
+	assert.NoError(t, err)
+	current, err := ParseKey("current", ed25519PEM(t))
+	assert.NoError(t, err)
+
+	before, err := NewKeySet("retiring", retiring)
+	assert.NoError(t, err)
+	oldToken, err := NewIssuer(config, before).GenerateAccessToken(userCode, sessionID, 0)
+	assert.NoError(t, err)
+
+	after, err := NewKeySet("current", current, retiring)
+	assert.NoError(t, err)
+	issuer := NewIssuer(config, after)
+
+	newToken, err := issuer.GenerateAccessToken(userCode, sessionID, 0)
+	assert.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &Claims{})
+	assert.NoError(t, err)
+	assert.Equal(t, "current", parsed.Header["kid"])
+	assert.Equal(t, "EdDSA", parsed.Method.Alg())
+
+	for _, token := range []string{oldToken, newToken} {
+		claims, err := issuer.ParseAccessToken(token)
+		assert.NoError(t, err)
+		assert.Equal(t, userCode.String(), claims.UserCode)
+	}
+
+	t.Run("retired key", func(t *testing.T) {
+		retired, err := NewKeySet("current", current)
+		assert.NoError(t, err)
+		_, err = NewIssuer(config, retired).ParseAccessToken(oldToken)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("shared secret token", func(t *testing.T) {
+		token, err := secretIssuer(t, config).GenerateAccessToken(userCode, sessionID, 0)
+		assert.NoError(t, err)
+		_, err = issuer.ParseAccessToken(token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func Test_JWKS(t *testing.T) {
+	rsaKey, err := ParseKey("b-rsa", rsaPEM(t, 2048))
+	assert.NoError(t, err)
+	edKey, err := ParseKey("a-ed", ed25519PEM(t))
+	assert.NoError(t, err)
+	set, err := NewKeySet("a-ed", edKey, rsaKey, NewSecretKey("secret", jwt.SigningMethodHS256))
+	assert.NoError(t, err)
+
+	jwks := set.JWKS()
+	assert.Len(t, jwks.Keys, 2)
+
+	ed := jwks.Keys[0]
+	assert.Equal(t, "a-ed", ed.Kid)
+	assert.Equal(t, "OKP", ed.Kty)
+	assert.Equal(t, "Ed25519", ed.Crv)
+	assert.Equal(t, "EdDSA", ed.Alg)
+	assert.NotEmpty(t, ed.X)
+
+	rsaJWK := jwks.Keys[1]
+	assert.Equal(t, "b-rsa", rsaJWK.Kid)
+	assert.Equal(t, "RSA", rsaJWK.Kty)
+	assert.Equal(t, "RS256", rsaJWK.Alg)
+	assert.Equal(t, "AQAB", rsaJWK.E)
+	assert.NotEmpty(t, rsaJWK.N)
+}
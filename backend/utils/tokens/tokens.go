@@ -0,0 +1,164 @@
+package tokens
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	jwtUtils "github.com/juanMaAV92/go-utils/jwt"
+)
+
+const (
+	AccessTokenType  = "access"
+	RefreshTokenType = "refresh"
+	// MFATokenType is the challenge given by a login with a second factor, it
+	// is only exchanged for real tokens along with a valid code.
+	MFATokenType = "mfa"
+
+	MFATokenTTL = 5 * time.Minute
+)
+
+var (
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrInvalidTokenType = errors.New("invalid token type")
+	ErrExpiredToken     = fmt.Errorf("%w: expired", ErrInvalidToken)
+)
+
+// Claims are shared by access and refresh tokens. SessionID is the login the
+// token belongs to, every token obtained by refreshing keeps it. TokenVersion is
+// the token version of the user when the token was issued, tokens of an older
+// version are no longer accepted.
+type Claims struct {
+	jwt.RegisteredClaims
+	UserCode     string `json:"user_code"`
+	Type         string `json:"type"`
+	SessionID    string `json:"session_id"`
+	TokenVersion int    `json:"token_version"`
+}
+
+// Issuer signs the tokens with the active key of its key set, naming it in the
+// kid header, and verifies them with any key of the set. The TTLs and issuer
+// come from the go-utils jwt configuration.
+type Issuer struct {
+	keys       *KeySet
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+func NewIssuer(cfg *jwtUtils.JwtConfig, keys *KeySet) *Issuer {
+	return &Issuer{
+		keys:       keys,
+		issuer:     cfg.Issuer,
+		accessTTL:  cfg.AccessTokenTTL,
+		refreshTTL: cfg.RefreshTokenTTL,
+		now:        time.Now,
+	}
+}
+
+func (i *Issuer) AccessTTL() time.Duration {
+	return i.accessTTL
+}
+
+func (i *Issuer) RefreshTTL() time.Duration {
+	return i.refreshTTL
+}
+
+// GenerateAccessToken signs an access token for a session.
+func (i *Issuer) GenerateAccessToken(userCode, sessionID uuid.UUID, version int) (string, error) {
+	token, _, err := i.generate(userCode, sessionID, version, AccessTokenType, i.accessTTL)
+	return token, err
+}
+
+// GenerateRefreshToken signs a refresh token for a session and returns it with
+// its ID.
+func (i *Issuer) GenerateRefreshToken(userCode, sessionID uuid.UUID, version int) (string, uuid.UUID, error) {
+	return i.generate(userCode, sessionID, version, RefreshTokenType, i.refreshTTL)
+}
+
+func (i *Issuer) generate(userCode, sessionID uuid.UUID, version int, tokenType string, ttl time.Duration) (string, uuid.UUID, error) {
+	now := i.now()
+	tokenID := uuid.New()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID.String(),
+			Issuer:    i.issuer,
+			Subject:   userCode.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		UserCode:     userCode.String(),
+		Type:         tokenType,
+		SessionID:    sessionID.String(),
+		TokenVersion: version,
+	}
+
+	key := i.keys.Active()
+	unsigned := jwt.NewWithClaims(key.Method, claims)
+	if key.ID != "" {
+		unsigned.Header["kid"] = key.ID
+	}
+	token, err := unsigned.SignedString(key.sign)
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+	return token, tokenID, nil
+}
+
+// GenerateMFAToken signs the challenge of a login that still needs the second
+// factor. It belongs to no session yet.
+func (i *Issuer) GenerateMFAToken(userCode uuid.UUID) (string, error) {
+	token, _, err := i.generate(userCode, uuid.Nil, 0, MFATokenType, MFATokenTTL)
+	return token, err
+}
+
+// ParseAccessToken validates the signature and expiration of an access token.
+// Access tokens issued before token IDs and sessions existed are accepted
+// without them.
+func (i *Issuer) ParseAccessToken(token string) (*Claims, error) {
+	return i.parse(token, AccessTokenType)
+}
+
+// ParseRefreshToken validates the signature and expiration of a refresh token.
+// Tokens of another type return their claims along with ErrInvalidTokenType.
+func (i *Issuer) ParseRefreshToken(token string) (*Claims, error) {
+	claims, err := i.parse(token, RefreshTokenType)
+	if err != nil {
+		return claims, err
+	}
+	if claims.ID == "" || claims.SessionID == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// ParseMFAToken validates an MFA challenge like ParseRefreshToken does.
+func (i *Issuer) ParseMFAToken(token string) (*Claims, error) {
+	claims, err := i.parse(token, MFATokenType)
+	if err != nil {
+		return claims, err
+	}
+	if claims.ID == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (i *Issuer) parse(token, tokenType string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, i.keys.verificationKey,
+		jwt.WithValidMethods(i.keys.algorithms()), jwt.WithTimeFunc(i.now))
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, ErrExpiredToken
+	}
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.Type != tokenType {
+		return claims, ErrInvalidTokenType
+	}
+	return claims, nil
+}
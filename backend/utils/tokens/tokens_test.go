@@ -0,0 +1,98 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	jwtUtils "github.com/juanMaAV92/go-utils/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+var config = &jwtUtils.JwtConfig{
+	SecretKey:       "test_secret",
+	Issuer:          "zenith-financial",
+	AccessTokenTTL:  time.Minute,
+	RefreshTokenTTL: time.Hour,
+	SigningMethod:   jwt.SigningMethodHS256,
+}
+
+func secretIssuer(t *testing.T, cfg *jwtUtils.JwtConfig) *Issuer {
+	t.Helper()
+	keys, err := NewKeySet("", NewSecretKey(cfg.SecretKey, cfg.SigningMethod))
+	if err != nil {
+		t.Fatalf("failed to build key set: %v", err)
+	}
+	return NewIssuer(cfg, keys)
+}
+
+func Test_RefreshToken(t *testing.T) {
+	userCode, sessionID := uuid.New(), uuid.New()
+	issuer := secretIssuer(t, config)
+
+	token, tokenID, err := issuer.GenerateRefreshToken(userCode, sessionID, 3)
+	assert.NoError(t, err)
+
+	claims, err := issuer.ParseRefreshToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, tokenID.String(), claims.ID)
+	assert.Equal(t, userCode.String(), claims.UserCode)
+	assert.Equal(t, sessionID.String(), claims.SessionID)
+	assert.Equal(t, 3, claims.TokenVersion)
+
+	other, otherID, err := issuer.GenerateRefreshToken(userCode, sessionID, 3)
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
+	assert.NotEqual(t, tokenID, otherID)
+
+	t.Run("expired token", func(t *testing.T) {
+		expired := secretIssuer(t, config)
+		expired.now = func() time.Time { return time.Now().Add(-2 * time.Hour) }
+		token, _, err := expired.GenerateRefreshToken(userCode, sessionID, 3)
+		assert.NoError(t, err)
+
+		_, err = issuer.ParseRefreshToken(token)
+		assert.ErrorIs(t, err, ErrExpiredToken)
+	})
+
+	t.Run("token signed with another key", func(t *testing.T) {
+		foreign := secretIssuer(t, &jwtUtils.JwtConfig{SecretKey: "other", RefreshTokenTTL: time.Hour, SigningMethod: jwt.SigningMethodHS256})
+		token, _, err := foreign.GenerateRefreshToken(userCode, sessionID, 3)
+		assert.NoError(t, err)
+
+		_, err = issuer.ParseRefreshToken(token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("access token", func(t *testing.T) {
+		token, err := issuer.GenerateAccessToken(userCode, sessionID, 3)
+		assert.NoError(t, err)
+
+		claims, err := issuer.ParseRefreshToken(token)
+		assert.ErrorIs(t, err, ErrInvalidTokenType)
+		assert.Equal(t, userCode.String(), claims.UserCode)
+		assert.Equal(t, sessionID.String(), claims.SessionID)
+	})
+}
+
+func Test_MFAToken(t *testing.T) {
+	userCode := uuid.New()
+	issuer := secretIssuer(t, config)
+
+	token, err := issuer.GenerateMFAToken(userCode)
+	assert.NoError(t, err)
+
+	claims, err := issuer.ParseMFAToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, userCode.String(), claims.UserCode)
+	assert.Equal(t, issuer.now().Add(MFATokenTTL).Unix(), claims.ExpiresAt.Unix())
+
+	_, err = issuer.ParseRefreshToken(token)
+	assert.ErrorIs(t, err, ErrInvalidTokenType)
+
+	refreshToken, _, err := issuer.GenerateRefreshToken(userCode, uuid.New(), 0)
+	assert.NoError(t, err)
+	_, err = issuer.ParseMFAToken(refreshToken)
+	assert.ErrorIs(t, err, ErrInvalidTokenType)
+}
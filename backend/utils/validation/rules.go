@@ -0,0 +1,120 @@
+package validation
+
+import (
+	"net/mail"
+	"strings"
+	"unicode"
+)
+
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 128
+	MinUsernameLength = 3
+	MaxUsernameLength = 30
+	maxEmailLength    = 254
+)
+
+// Required checks value is not blank.
+func (e *Errors) Required(field, value string) {
+	e.Check(strings.TrimSpace(value) != "", field, "is required")
+}
+
+// Email checks value is a bare address, without a display name.
+func (e *Errors) Email(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		e.Add(field, "is required")
+		return
+	}
+	e.Check(IsEmail(value), field, "must be a valid email address")
+}
+
+// Password checks value is between MinPasswordLength and MaxPasswordLength
+// characters and mixes letters and digits.
+func (e *Errors) Password(field, value string) {
+	if value == "" {
+		e.Add(field, "is required")
+		return
+	}
+	length := len([]rune(value))
+	if length < MinPasswordLength || length > MaxPasswordLength {
+		e.Add(field, "must have between 8 and 128 characters")
+		return
+	}
+	var letter, digit bool
+	for _, r := range value {
+		letter = letter || unicode.IsLetter(r)
+		digit = digit || unicode.IsDigit(r)
+	}
+	e.Check(letter && digit, field, "must contain at least one letter and one digit")
+}
+
+// Username checks value has between MinUsernameLength and MaxUsernameLength
+// characters, all ASCII letters, digits, dots, hyphens or underscores.
+func (e *Errors) Username(field, value string) {
+	if value == "" {
+		e.Add(field, "is required")
+		return
+	}
+	if len(value) < MinUsernameLength || len(value) > MaxUsernameLength {
+		e.Add(field, "must have between 3 and 30 characters")
+		return
+	}
+	for _, r := range value {
+		if !isUsernameRune(r) {
+			e.Add(field, "may only contain letters, digits, dots, hyphens and underscores")
+			return
+		}
+	}
+}
+
+// Currency checks value is an ISO 4217 code, in any case, as the services
+// upper case them. An empty value passes, so optional currencies only add
+// Required when they are not.
+func (e *Errors) Currency(field, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	e.Check(IsCurrency(strings.ToUpper(value)), field, "must be an ISO 4217 currency code")
+}
+
+// AssetCurrency checks value is a three letter code, in any case. Assets may be
+// priced in codes outside ISO 4217, like BTC, so only the shape is checked. An
+// empty value passes, like in Currency.
+func (e *Errors) AssetCurrency(field, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	e.Check(isAssetCurrency(value), field, "must be a three letter currency code")
+}
+
+func IsEmail(value string) bool {
+	if len(value) > maxEmailLength {
+		return false
+	}
+	address, err := mail.ParseAddress(value)
+	return err == nil && address.Address == value && strings.Contains(value[strings.LastIndex(value, "@"):], ".")
+}
+
+// IsCurrency reports whether code is an active ISO 4217 code, in upper case.
+func IsCurrency(code string) bool {
+	_, ok := currencies[code]
+	return ok
+}
+
+func isAssetCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isUsernameRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_')
+}
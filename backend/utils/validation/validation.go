@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"net/http"
+
+	"github.com/juanMaAV92/go-utils/errors"
+)
+
+const ValidationErrorCode = "VALIDATION_ERROR"
+
+// Validatable is implemented by the request structs that have rules.
+type Validatable interface {
+	Validate(v *Errors)
+}
+
+// Validator is the echo.Validator of the server: handlers call c.Validate after
+// binding a request and return its error as is.
+type Validator struct{}
+
+func New() *Validator {
+	return &Validator{}
+}
+
+func (v *Validator) Validate(i interface{}) error {
+	req, ok := i.(Validatable)
+	if !ok {
+		return nil
+	}
+	errs := &Errors{}
+	req.Validate(errs)
+	return errs.Err()
+}
+
+// Errors collects the failed rules of a request. Only the first failure of each
+// field is kept, as "<field>: <message>".
+type Errors struct {
+	messages []string
+	fields   map[string]bool
+}
+
+func (e *Errors) Add(field, message string) {
+	if e.fields == nil {
+		e.fields = map[string]bool{}
+	}
+	if e.fields[field] {
+		return
+	}
+	e.fields[field] = true
+	e.messages = append(e.messages, field+": "+message)
+}
+
+// Check adds message to field when ok is false.
+func (e *Errors) Check(ok bool, field, message string) {
+	if !ok {
+		e.Add(field, message)
+	}
+}
+
+func (e *Errors) Messages() []string {
+	return e.messages
+}
+
+// Err returns the errors in the go-utils response shape, or nil if every rule
+// passed.
+func (e *Errors) Err() error {
+	if len(e.messages) == 0 {
+		return nil
+	}
+	return errors.New(http.StatusBadRequest, ValidationErrorCode, e.messages)
+}
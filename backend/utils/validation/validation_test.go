@@ -0,0 +1,86 @@
+package validation
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type signUp struct {
+	Name     string
+	Email    string
+	Password string
+	Currency string
+}
+
+func (r *signUp) Validate(v *Errors) {
+	v.Username("user_name", r.Name)
+	v.Email("email", r.Email)
+	v.Password("password", r.Password)
+	v.Currency("currency", r.Currency)
+}
+
+func Test_Validator(t *testing.T) {
+	validator := New()
+
+	assert.NoError(t, validator.Validate(&signUp{Name: "juan_92", Email: "juan@mail.com", Password: "secret123", Currency: "usd"}))
+	assert.NoError(t, validator.Validate(&struct{ Name string }{}))
+
+	err := validator.Validate(&signUp{Name: "ju", Email: "juan", Password: "short", Currency: "XX"})
+	response, ok := err.(*errors.ErrorResponse)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, response.ErrorHTTPCode())
+	assert.Equal(t, ValidationErrorCode, response.ErrorCode())
+	assert.Equal(t, []string{
+		"user_name: must have between 3 and 30 characters",
+		"email: must be a valid email address",
+		"password: must have between 8 and 128 characters",
+		"currency: must be an ISO 4217 currency code",
+	}, response.Messages)
+}
+
+func Test_Errors_KeepsFirstFailureOfAField(t *testing.T) {
+	errs := &Errors{}
+	errs.Required("token", "")
+	errs.Add("token", "is invalid")
+	assert.Equal(t, []string{"token: is required"}, errs.Messages())
+}
+
+func Test_Rules(t *testing.T) {
+	cases := []struct {
+		name  string
+		check func(e *Errors)
+		valid bool
+	}{
+		{"email", func(e *Errors) { e.Email("f", "a.b+c@mail.example.com") }, true},
+		{"email with display name", func(e *Errors) { e.Email("f", "Juan <juan@mail.com>") }, false},
+		{"email without domain dot", func(e *Errors) { e.Email("f", "juan@localhost") }, false},
+		{"empty email", func(e *Errors) { e.Email("f", " ") }, false},
+		{"password", func(e *Errors) { e.Password("f", "contraseña1") }, true},
+		{"password without digits", func(e *Errors) { e.Password("f", "onlyletters") }, false},
+		{"password without letters", func(e *Errors) { e.Password("f", "1234567890") }, false},
+		{"username", func(e *Errors) { e.Username("f", "juan.ma-92") }, true},
+		{"username with spaces", func(e *Errors) { e.Username("f", "juan ma") }, false},
+		{"username with accents", func(e *Errors) { e.Username("f", "josé") }, false},
+		{"currency", func(e *Errors) { e.Currency("f", "COP") }, true},
+		{"empty currency", func(e *Errors) { e.Currency("f", "") }, true},
+		{"unknown currency", func(e *Errors) { e.Currency("f", "ABC") }, false},
+		{"crypto currency", func(e *Errors) { e.Currency("f", "BTC") }, false},
+		{"asset currency", func(e *Errors) { e.AssetCurrency("f", "USD") }, true},
+		{"crypto asset currency", func(e *Errors) { e.AssetCurrency("f", "btc") }, true},
+		{"empty asset currency", func(e *Errors) { e.AssetCurrency("f", "") }, true},
+		{"asset currency with digits", func(e *Errors) { e.AssetCurrency("f", "US1") }, false},
+		{"long asset currency", func(e *Errors) { e.AssetCurrency("f", "USDT") }, false},
+		{"required", func(e *Errors) { e.Required("f", "value") }, true},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			errs := &Errors{}
+			test.check(errs)
+			assert.Equal(t, test.valid, errs.Err() == nil, errs.Messages())
+		})
+	}
+}